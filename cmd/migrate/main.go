@@ -0,0 +1,104 @@
+// Command migrate manages the database schema of the backend selected by DB_TYPE.
+//
+//	migrate up               apply every pending migration
+//	migrate down <version>   roll back the migrations newer than version (0 rolls back everything)
+//	migrate status           list migrations and whether they are applied
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github/k-tsurumaki/quilldeck/internal/config"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/database"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/database/migrate"
+)
+
+const usage = `usage:
+  migrate up               apply every pending migration
+  migrate down <version>   roll back the migrations newer than version (0 rolls back everything)
+  migrate status           list migrations and whether they are applied`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to open database:", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	switch os.Args[1] {
+	case "up":
+		applied, err := db.Migrator.Up(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		for _, version := range applied {
+			fmt.Printf("applied %d\n", version)
+		}
+	case "down":
+		if len(os.Args) != 3 {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		target, err := strconv.ParseInt(os.Args[2], 10, 64)
+		if err != nil {
+			log.Fatalf("invalid version %q", os.Args[2])
+		}
+		reverted, err := db.Migrator.Down(ctx, target)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("nothing to roll back")
+		}
+		for _, version := range reverted {
+			fmt.Printf("rolled back %d\n", version)
+		}
+	case "status":
+		statuses, err := db.Migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printStatus(statuses)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func printStatus(statuses []migrate.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state := "pending"
+		switch {
+		case status.Missing:
+			state = "applied, file missing"
+		case status.Modified:
+			state = "applied, modified"
+		case status.Applied:
+			state = "applied"
+		}
+		appliedAt := ""
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Local().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	w.Flush()
+}
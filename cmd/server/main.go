@@ -1,40 +1,58 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github/k-tsurumaki/quilldeck/internal/config"
-	"github/k-tsurumaki/quilldeck/internal/infrastructure/database/sqlite"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/database"
 	httpServer "github/k-tsurumaki/quilldeck/internal/interfaces/http"
 )
 
 func main() {
 	cfg := config.Load()
-	
-	// Connect to database
-	db, err := sqlite.NewConnection(cfg.Database.Path)
-	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+	if cfg.Auth.JWTSecret == "" {
+		log.Fatal("JWT_SECRET must be set")
 	}
-	defer db.Close()
-	
+
 	// Create data directory
 	if err := os.MkdirAll("./data", 0755); err != nil {
 		log.Fatal("Failed to create data directory:", err)
 	}
-	
-	// Run database migrations
-	if err := db.RunMigrations(); err != nil {
+
+	// Connect to the database selected by DB_TYPE
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to open database:", err)
+	}
+	defer db.Close()
+
+	// Apply pending migrations; cmd/migrate can also roll them back or show their status
+	applied, err := db.Migrator.Up(context.Background())
+	if err != nil {
 		log.Fatal("Failed to run migrations:", err)
 	}
-	
-	server := httpServer.NewServer(db, cfg)
-	
+	for _, version := range applied {
+		log.Printf("applied migration %d", version)
+	}
+
+	server, err := httpServer.NewServer(db.Repositories, cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize server:", err)
+	}
+
+	// Stop accepting requests and let running jobs wind down on Ctrl+C or docker stop.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	fmt.Printf("Starting QuillDeck server on port %s...\n", cfg.Server.Port)
-	
-	if err := server.Start(cfg.Server.Port); err != nil {
+
+	if err := server.Start(ctx, cfg.Server.Port); err != nil && err != http.ErrServerClosed {
 		log.Fatal("Failed to start server:", err)
 	}
-}
\ No newline at end of file
+}
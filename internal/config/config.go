@@ -2,12 +2,20 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	LLM      LLMConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Storage   StorageConfig
+	Auth      AuthConfig
+	LLM       LLMConfig
+	Summary   SummaryConfig
+	Embedding EmbeddingConfig
+	Chat      ChatConfig
+	Jobs      JobsConfig
 }
 
 type ServerConfig struct {
@@ -15,14 +23,61 @@ type ServerConfig struct {
 }
 
 type DatabaseConfig struct {
-	Type string
-	Path string
+	Type string // sqlite or postgres
+	Path string // SQLite database file
+	URL  string // Postgres connection string
+}
+
+type StorageConfig struct {
+	Dir string // root of the blob store for generated files
+}
+
+type AuthConfig struct {
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	Password        PasswordConfig
+}
+
+type PasswordConfig struct {
+	Algorithm         string
+	Argon2Memory      int // KiB
+	Argon2Iterations  int
+	Argon2Parallelism int
+	BcryptCost        int
 }
 
 type LLMConfig struct {
+	LLM_PROVIDER string // openai (default, any OpenAI-compatible API), anthropic or ollama
 	LLM_API_KEY  string
 	LLM_BASE_URL string
-	LLM_MODEL   string
+	LLM_MODEL    string
+	// LLM_TIMEOUT bounds a model call made while a request waits on it; calls in background jobs are
+	// bounded by Jobs.Timeout instead.
+	LLM_TIMEOUT time.Duration
+	// LLM_EMBEDDING_MODEL enables semantic search with this embedding model of the same provider.
+	LLM_EMBEDDING_MODEL string
+}
+
+type SummaryConfig struct {
+	MaxChunkChars int
+	Concurrency   int
+}
+
+type EmbeddingConfig struct {
+	ChunkChars int
+	BatchSize  int
+}
+
+type ChatConfig struct {
+	ContextChars int // budget for the passages and earlier turns sent with each question
+}
+
+type JobsConfig struct {
+	Workers      int
+	PollInterval time.Duration
+	Timeout      time.Duration
+	MaxAttempts  int
 }
 
 func Load() *Config {
@@ -33,11 +88,47 @@ func Load() *Config {
 		Database: DatabaseConfig{
 			Type: getEnv("DB_TYPE", "sqlite"),
 			Path: getEnv("DB_PATH", "./data/quilldeck.db"),
+			URL:  getEnv("DATABASE_URL", ""),
+		},
+		Storage: StorageConfig{
+			Dir: getEnv("STORAGE_DIR", "./data/blobs"),
+		},
+		Auth: AuthConfig{
+			JWTSecret:       getEnv("JWT_SECRET", ""),
+			AccessTokenTTL:  getEnvDuration("JWT_ACCESS_TTL", 15*time.Minute),
+			RefreshTokenTTL: getEnvDuration("JWT_REFRESH_TTL", 30*24*time.Hour),
+			Password: PasswordConfig{
+				Algorithm:         getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+				Argon2Memory:      getEnvInt("ARGON2_MEMORY_KIB", 64*1024),
+				Argon2Iterations:  getEnvInt("ARGON2_ITERATIONS", 3),
+				Argon2Parallelism: getEnvInt("ARGON2_PARALLELISM", 2),
+				BcryptCost:        getEnvInt("BCRYPT_COST", 12),
+			},
 		},
 		LLM: LLMConfig{
-			LLM_API_KEY:  getEnv("LLM_API_KEY", ""),
-			LLM_BASE_URL: getEnv("LLM_BASE_URL", ""),
-			LLM_MODEL: getEnv("LLM_MODEL", ""),
+			LLM_PROVIDER:        getEnv("LLM_PROVIDER", "openai"),
+			LLM_API_KEY:         getEnv("LLM_API_KEY", ""),
+			LLM_BASE_URL:        getEnv("LLM_BASE_URL", ""),
+			LLM_MODEL:           getEnv("LLM_MODEL", ""),
+			LLM_TIMEOUT:         getEnvDuration("LLM_TIMEOUT", 30*time.Second),
+			LLM_EMBEDDING_MODEL: getEnv("LLM_EMBEDDING_MODEL", ""),
+		},
+		Summary: SummaryConfig{
+			MaxChunkChars: getEnvInt("SUMMARY_CHUNK_CHARS", 12000),
+			Concurrency:   getEnvInt("SUMMARY_CONCURRENCY", 4),
+		},
+		Embedding: EmbeddingConfig{
+			ChunkChars: getEnvInt("EMBEDDING_CHUNK_CHARS", 1000),
+			BatchSize:  getEnvInt("EMBEDDING_BATCH_SIZE", 32),
+		},
+		Chat: ChatConfig{
+			ContextChars: getEnvInt("CHAT_CONTEXT_CHARS", 24000),
+		},
+		Jobs: JobsConfig{
+			Workers:      getEnvInt("JOB_WORKERS", 2),
+			PollInterval: getEnvDuration("JOB_POLL_INTERVAL", 5*time.Second),
+			Timeout:      getEnvDuration("JOB_TIMEOUT", 10*time.Minute),
+			MaxAttempts:  getEnvInt("JOB_MAX_ATTEMPTS", 3),
 		},
 	}
 }
@@ -48,3 +139,21 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,57 @@
+package llm
+
+import "context"
+
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type Request struct {
+	Messages []Message
+	// MaxTokens caps the length of the reply; zero leaves it to the provider.
+	MaxTokens int
+	// Temperature is left to the provider when nil.
+	Temperature *float64
+}
+
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+type Response struct {
+	Content      string
+	Model        string
+	FinishReason string
+	Usage        Usage
+}
+
+// LLM is a chat-style language model. Implementations live in internal/infrastructure/ai and translate
+// the request into their provider's wire format.
+type LLM interface {
+	Complete(ctx context.Context, req Request) (*Response, error)
+}
+
+// Streamer is implemented by models that can deliver the reply incrementally. onDelta is called with
+// each piece of text as it arrives; returning an error from it aborts the stream.
+type Streamer interface {
+	Stream(ctx context.Context, req Request, onDelta func(delta string) error) (*Response, error)
+}
+
+// Embedder turns texts into vectors whose closeness reflects closeness in meaning. The result has one
+// vector per text, in order; vectors of the same model all have the same length.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// UserPrompt builds a request consisting of a single user message.
+func UserPrompt(prompt string) Request {
+	return Request{Messages: []Message{{Role: RoleUser, Content: prompt}}}
+}
@@ -0,0 +1,23 @@
+package models
+
+import "github.com/google/uuid"
+
+// Citation points at a passage of the document that supports an answer. Start and End are character
+// (rune) offsets into the document content, so a client can highlight the source.
+type Citation struct {
+	Passage int    `json:"passage"` // the number the model cited the passage by, e.g. 2 for [2]
+	Heading string `json:"heading,omitempty"`
+	Page    int    `json:"page,omitempty"` // the page the passage starts on, for documents with pages
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	Text    string `json:"text"`
+}
+
+// Answer is the model's reply to a question about one document. Citations are the passages the answer
+// refers to, in the order they are first cited.
+type Answer struct {
+	DocumentID uuid.UUID
+	Question   string
+	Content    string
+	Citations  []Citation
+}
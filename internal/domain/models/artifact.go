@@ -0,0 +1,93 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArtifactKind is what generated an artifact.
+type ArtifactKind string
+
+const (
+	ArtifactKindDeck    ArtifactKind = "deck"
+	ArtifactKindDiagram ArtifactKind = "diagram"
+	ArtifactKindImage   ArtifactKind = "image"
+)
+
+// Artifact is a file generated from a document, such as an exported deck or a diagram's source. Its
+// content lives in the blob store under StorageKey; Checksum is the hex SHA-256 of the content and Params
+// the parameters it was generated with.
+type Artifact struct {
+	ID         uuid.UUID      `json:"id"`
+	UserID     uuid.UUID      `json:"user_id"`
+	DocumentID uuid.UUID      `json:"document_id"`
+	Kind       ArtifactKind   `json:"kind"`
+	Name       string         `json:"name"`
+	MIMEType   string         `json:"mime_type"`
+	Params     map[string]any `json:"params"`
+	Size       int64          `json:"size"`
+	Checksum   string         `json:"checksum"`
+	StorageKey string         `json:"-"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+func NewArtifact(userID, documentID uuid.UUID, kind ArtifactKind, name, mimeType string, data []byte) *Artifact {
+	return &Artifact{
+		ID:         uuid.New(),
+		UserID:     userID,
+		DocumentID: documentID,
+		Kind:       kind,
+		Name:       name,
+		MIMEType:   mimeType,
+		Params:     map[string]any{},
+		Size:       int64(len(data)),
+		Checksum:   Checksum(data),
+		CreatedAt:  time.Now(),
+	}
+}
+
+func (a *Artifact) Validate() error {
+	if a.UserID == uuid.Nil {
+		return &ValidationError{Field: "user_id", Message: "user_id is required"}
+	}
+	if a.DocumentID == uuid.Nil {
+		return &ValidationError{Field: "document_id", Message: "document_id is required"}
+	}
+	if !IsValidArtifactKind(a.Kind) {
+		return &ValidationError{Field: "kind", Message: "kind must be one of deck, diagram, image"}
+	}
+	if a.Name == "" {
+		return &ValidationError{Field: "name", Message: "name is required"}
+	}
+	if a.MIMEType == "" {
+		return &ValidationError{Field: "mime_type", Message: "mime_type is required"}
+	}
+	if a.StorageKey == "" {
+		return &ValidationError{Field: "storage_key", Message: "storage_key is required"}
+	}
+	return nil
+}
+
+// Previewable tells whether the artifact can be shown in the browser as is: text and images can, office
+// files have to be downloaded.
+func (a *Artifact) Previewable() bool {
+	return strings.HasPrefix(a.MIMEType, "text/") || strings.HasPrefix(a.MIMEType, "image/")
+}
+
+func IsValidArtifactKind(kind ArtifactKind) bool {
+	switch kind {
+	case ArtifactKindDeck, ArtifactKindDiagram, ArtifactKindImage:
+		return true
+	}
+	return false
+}
+
+// Checksum is the hex SHA-256 of data, as recorded on artifacts.
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxConversationTitleChars = 200
+	MaxMessageChars           = 2000
+)
+
+// Conversation is a chat thread about one document.
+type Conversation struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	DocumentID uuid.UUID `json:"document_id"`
+	Title      string    `json:"title"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"` // time of the last message
+}
+
+func NewConversation(userID, documentID uuid.UUID, title string) *Conversation {
+	now := time.Now()
+	return &Conversation{
+		ID:         uuid.New(),
+		UserID:     userID,
+		DocumentID: documentID,
+		Title:      title,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+func (c *Conversation) Validate() error {
+	if c.UserID == uuid.Nil {
+		return &ValidationError{Field: "user_id", Message: "user_id is required"}
+	}
+	if c.DocumentID == uuid.Nil {
+		return &ValidationError{Field: "document_id", Message: "document_id is required"}
+	}
+	if utf8.RuneCountInString(c.Title) > MaxConversationTitleChars {
+		return &ValidationError{Field: "title", Message: "title must be at most 200 characters"}
+	}
+	return nil
+}
+
+// Touch records activity in the conversation.
+func (c *Conversation) Touch() {
+	c.UpdatedAt = time.Now()
+}
+
+// Message is one turn of a conversation. Role is llm.RoleUser or llm.RoleAssistant; assistant messages
+// carry the citations of their answer.
+type Message struct {
+	ID             uuid.UUID  `json:"id"`
+	ConversationID uuid.UUID  `json:"conversation_id"`
+	Role           string     `json:"role"`
+	Content        string     `json:"content"`
+	Citations      []Citation `json:"citations,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func NewMessage(conversationID uuid.UUID, role, content string) *Message {
+	return &Message{
+		ID:             uuid.New(),
+		ConversationID: conversationID,
+		Role:           role,
+		Content:        content,
+		CreatedAt:      time.Now(),
+	}
+}
+
+func (m *Message) Validate() error {
+	if m.ConversationID == uuid.Nil {
+		return &ValidationError{Field: "conversation_id", Message: "conversation_id is required"}
+	}
+	if m.Role != "user" && m.Role != "assistant" {
+		return &ValidationError{Field: "role", Message: "role must be user or assistant"}
+	}
+	if m.Content == "" {
+		return &ValidationError{Field: "content", Message: "content is required"}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SlideKind is the role a slide plays in a report deck.
+type SlideKind string
+
+const (
+	SlideKindTitle   SlideKind = "title"
+	SlideKindAgenda  SlideKind = "agenda"
+	SlideKindPoints  SlideKind = "points"
+	SlideKindActions SlideKind = "actions"
+)
+
+// DeckFormat is a file format a deck can be exported to.
+type DeckFormat string
+
+const (
+	DeckFormatMarp DeckFormat = "marp" // Marp-flavoured Markdown
+	DeckFormatPPTX DeckFormat = "pptx"
+)
+
+const (
+	DefaultDeckSlides = 8
+	MinDeckSlides     = 4
+	MaxDeckSlides     = 20
+	// MaxSlideBullets keeps the key-point and action slides readable at a glance.
+	MaxSlideBullets = 6
+)
+
+// DeckOptions records how a deck was requested; the zero value means the defaults (8 slides, Japanese).
+type DeckOptions struct {
+	SlideCount int    `json:"slide_count,omitempty"` // total, including the title, agenda and actions slides
+	Language   string `json:"language,omitempty"`
+}
+
+func (o DeckOptions) WithDefaults() DeckOptions {
+	if o.SlideCount == 0 {
+		o.SlideCount = DefaultDeckSlides
+	}
+	if o.Language == "" {
+		o.Language = DefaultSummaryLanguage
+	}
+	return o
+}
+
+func (o DeckOptions) Validate() error {
+	if o.SlideCount != 0 && (o.SlideCount < MinDeckSlides || o.SlideCount > MaxDeckSlides) {
+		return &ValidationError{Field: "slide_count", Message: "slide_count must be between 4 and 20"}
+	}
+	if o.Language != "" && !languageTagPattern.MatchString(o.Language) {
+		return &ValidationError{Field: "language", Message: "language must be a language code such as ja or en"}
+	}
+	return nil
+}
+
+// Slide is one slide of a deck. Subtitle is only used by title slides; Notes are the speaker notes.
+type Slide struct {
+	Kind     SlideKind `json:"kind"`
+	Title    string    `json:"title"`
+	Subtitle string    `json:"subtitle,omitempty"`
+	Bullets  []string  `json:"bullets,omitempty"`
+	Notes    string    `json:"notes,omitempty"`
+}
+
+// Deck is a slide deck generated from a document for reporting on it: a title slide, an agenda, the key
+// points and the next actions.
+type Deck struct {
+	ID         uuid.UUID `json:"id"`
+	DocumentID uuid.UUID `json:"document_id"`
+	Title      string    `json:"title"`
+	Slides     []Slide   `json:"slides"`
+	DeckOptions
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewDeck(documentID uuid.UUID, title string, slides []Slide) *Deck {
+	return &Deck{
+		ID:          uuid.New(),
+		DocumentID:  documentID,
+		Title:       title,
+		Slides:      slides,
+		DeckOptions: DeckOptions{}.WithDefaults(),
+		CreatedAt:   time.Now(),
+	}
+}
+
+func (d *Deck) Validate() error {
+	if d.DocumentID == uuid.Nil {
+		return &ValidationError{Field: "document_id", Message: "document_id is required"}
+	}
+	if d.Title == "" {
+		return &ValidationError{Field: "title", Message: "title is required"}
+	}
+	if len(d.Slides) == 0 {
+		return &ValidationError{Field: "slides", Message: "a deck needs at least one slide"}
+	}
+	for _, slide := range d.Slides {
+		switch slide.Kind {
+		case SlideKindTitle, SlideKindAgenda, SlideKindPoints, SlideKindActions:
+		default:
+			return &ValidationError{Field: "slides", Message: "slide kind must be one of title, agenda, points, actions"}
+		}
+		if slide.Title == "" {
+			return &ValidationError{Field: "slides", Message: "every slide needs a title"}
+		}
+	}
+	return d.DeckOptions.Validate()
+}
+
+func IsValidDeckFormat(format DeckFormat) bool {
+	switch format {
+	case DeckFormatMarp, DeckFormatPPTX:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DiagramKind is the type of a generated diagram.
+type DiagramKind string
+
+const (
+	DiagramKindFlowchart DiagramKind = "flowchart"
+	DiagramKindSequence  DiagramKind = "sequence"
+	DiagramKindMindmap   DiagramKind = "mindmap"
+)
+
+// DiagramKinds are the kinds proposed when none are requested, in the order they are proposed.
+var DiagramKinds = []DiagramKind{DiagramKindFlowchart, DiagramKindSequence, DiagramKindMindmap}
+
+// DiagramSyntax is the language a diagram's source is written in.
+type DiagramSyntax string
+
+const (
+	DiagramSyntaxMermaid  DiagramSyntax = "mermaid"
+	DiagramSyntaxPlantUML DiagramSyntax = "plantuml"
+)
+
+const (
+	DefaultDiagramAlternatives = 2
+	MaxDiagramAlternatives     = 3
+)
+
+// DiagramOptions describes the diagrams to propose for a document; the zero value means one diagram of
+// each kind, in Mermaid, labelled in Japanese.
+type DiagramOptions struct {
+	Kinds    []DiagramKind `json:"kinds,omitempty"`
+	Syntax   DiagramSyntax `json:"syntax,omitempty"`
+	Language string        `json:"language,omitempty"`
+}
+
+func (o DiagramOptions) WithDefaults() DiagramOptions {
+	if len(o.Kinds) == 0 {
+		o.Kinds = DiagramKinds
+	}
+	if o.Syntax == "" {
+		o.Syntax = DiagramSyntaxMermaid
+	}
+	if o.Language == "" {
+		o.Language = DefaultSummaryLanguage
+	}
+	return o
+}
+
+func (o DiagramOptions) Validate() error {
+	seen := make(map[DiagramKind]bool)
+	for _, kind := range o.Kinds {
+		if !IsValidDiagramKind(kind) {
+			return &ValidationError{Field: "kinds", Message: "kinds must be among flowchart, sequence, mindmap"}
+		}
+		if seen[kind] {
+			return &ValidationError{Field: "kinds", Message: "kinds must not repeat"}
+		}
+		seen[kind] = true
+	}
+	if o.Syntax != "" && !IsValidDiagramSyntax(o.Syntax) {
+		return &ValidationError{Field: "syntax", Message: "syntax must be one of mermaid, plantuml"}
+	}
+	if o.Language != "" && !languageTagPattern.MatchString(o.Language) {
+		return &ValidationError{Field: "language", Message: "language must be a language code such as ja or en"}
+	}
+	return nil
+}
+
+// Diagram is a diagram proposed for a document, kept as its Mermaid or PlantUML source.
+type Diagram struct {
+	ID          uuid.UUID     `json:"id"`
+	DocumentID  uuid.UUID     `json:"document_id"`
+	Kind        DiagramKind   `json:"kind"`
+	Syntax      DiagramSyntax `json:"syntax"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Source      string        `json:"source"`
+	Language    string        `json:"language"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+func NewDiagram(documentID uuid.UUID, kind DiagramKind, syntax DiagramSyntax, title, source string) *Diagram {
+	return &Diagram{
+		ID:         uuid.New(),
+		DocumentID: documentID,
+		Kind:       kind,
+		Syntax:     syntax,
+		Title:      title,
+		Source:     source,
+		Language:   DefaultSummaryLanguage,
+		CreatedAt:  time.Now(),
+	}
+}
+
+func (d *Diagram) Validate() error {
+	if d.DocumentID == uuid.Nil {
+		return &ValidationError{Field: "document_id", Message: "document_id is required"}
+	}
+	if !IsValidDiagramKind(d.Kind) {
+		return &ValidationError{Field: "kind", Message: "kind must be one of flowchart, sequence, mindmap"}
+	}
+	if !IsValidDiagramSyntax(d.Syntax) {
+		return &ValidationError{Field: "syntax", Message: "syntax must be one of mermaid, plantuml"}
+	}
+	if d.Title == "" {
+		return &ValidationError{Field: "title", Message: "title is required"}
+	}
+	if d.Source == "" {
+		return &ValidationError{Field: "source", Message: "source is required"}
+	}
+	return nil
+}
+
+func IsValidDiagramKind(kind DiagramKind) bool {
+	switch kind {
+	case DiagramKindFlowchart, DiagramKindSequence, DiagramKindMindmap:
+		return true
+	}
+	return false
+}
+
+func IsValidDiagramSyntax(syntax DiagramSyntax) bool {
+	switch syntax {
+	case DiagramSyntaxMermaid, DiagramSyntaxPlantUML:
+		return true
+	}
+	return false
+}
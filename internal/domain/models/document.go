@@ -1,7 +1,11 @@
 package models
 
 import (
+	"fmt"
+	"path"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
@@ -11,6 +15,40 @@ type DocumentType string
 const (
 	DocumentTypeTXT DocumentType = "txt"
 	DocumentTypeMD  DocumentType = "md"
+	DocumentTypePDF DocumentType = "pdf"
+	// Office Open XML files, converted to Markdown on upload.
+	DocumentTypeDOCX DocumentType = "docx"
+	DocumentTypePPTX DocumentType = "pptx"
+	DocumentTypeXLSX DocumentType = "xlsx"
+	// Web pages, saved as HTML or as MHTML web archives, whose main content is converted to Markdown.
+	DocumentTypeHTML DocumentType = "html"
+)
+
+// extensionTypes maps the file extensions that are not themselves document types to the type they are
+// uploaded as.
+var extensionTypes = map[string]DocumentType{
+	"htm":   DocumentTypeHTML,
+	"mhtml": DocumentTypeHTML,
+	"mht":   DocumentTypeHTML,
+}
+
+// DocumentTypeForFilename returns the type of document a file is uploaded as, by its extension.
+func DocumentTypeForFilename(filename string) (DocumentType, bool) {
+	ext := strings.TrimPrefix(strings.ToLower(path.Ext(filename)), ".")
+	if docType, ok := extensionTypes[ext]; ok {
+		return docType, true
+	}
+	docType := DocumentType(ext)
+	return docType, IsValidDocumentType(docType)
+}
+
+// Keys of Document.Metadata.
+const (
+	// MetadataTitle is the title a web page gives itself.
+	MetadataTitle = "title"
+	// MetadataCanonicalURL is the URL a web page names as its canonical address, or else the one it was
+	// saved from.
+	MetadataCanonicalURL = "canonical_url"
 )
 
 type Document struct {
@@ -22,6 +60,54 @@ type Document struct {
 	Size        int64        `json:"size"`
 	UploadedAt  time.Time    `json:"uploaded_at"`
 	ProcessedAt *time.Time   `json:"processed_at,omitempty"`
+	// Pages locates the pages of documents extracted from paged formats such as PDF in Content.
+	Pages []PageSpan `json:"pages,omitempty"`
+	// OriginalKey is where the uploaded file of a converted document is kept in blob storage.
+	OriginalKey string `json:"original_key,omitempty"`
+	// Metadata holds what the uploaded file says about itself, keyed by the Metadata constants.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// SourceEncoding is the character encoding uploaded text or web pages were in before they were
+	// converted to UTF-8, such as "Shift_JIS". It is empty for formats with an encoding of their own.
+	SourceEncoding string `json:"source_encoding,omitempty"`
+}
+
+// PageSpan is where a page of the original file is in a document's content, as rune offsets: the page's
+// text is the runes from Start up to End.
+type PageSpan struct {
+	Number int `json:"number"`
+	Start  int `json:"start"`
+	End    int `json:"end"`
+}
+
+// pageSeparator is put between the pages of a paged document, so that each page starts a paragraph.
+const pageSeparator = "\n\n"
+
+// JoinPages returns the content of a document made of the given pages, with where each page is in it.
+func JoinPages(pages []string) (string, []PageSpan) {
+	var b strings.Builder
+	spans := make([]PageSpan, len(pages))
+	offset := 0
+	for i, page := range pages {
+		if i > 0 {
+			b.WriteString(pageSeparator)
+			offset += utf8.RuneCountInString(pageSeparator)
+		}
+		b.WriteString(page)
+		spans[i] = PageSpan{Number: i + 1, Start: offset, End: offset + utf8.RuneCountInString(page)}
+		offset = spans[i].End
+	}
+	return b.String(), spans
+}
+
+// DocumentListItem is the projection used by listings; it omits the document body.
+type DocumentListItem struct {
+	ID          uuid.UUID    `json:"id"`
+	UserID      uuid.UUID    `json:"user_id"`
+	Title       string       `json:"title"`
+	Type        DocumentType `json:"type"`
+	Size        int64        `json:"size"`
+	UploadedAt  time.Time    `json:"uploaded_at"`
+	ProcessedAt *time.Time   `json:"processed_at,omitempty"`
 }
 
 func NewDocument(userID uuid.UUID, title, content string, docType DocumentType, size int64) *Document {
@@ -46,12 +132,52 @@ func (d *Document) Validate() error {
 	if d.Content == "" {
 		return &ValidationError{Field: "content", Message: "content is required"}
 	}
-	if d.Type != DocumentTypeTXT && d.Type != DocumentTypeMD {
+	if !IsValidDocumentType(d.Type) {
 		return &ValidationError{Field: "type", Message: "invalid document type"}
 	}
+	end := 0
+	for i, page := range d.Pages {
+		if page.Number != i+1 || page.Start < end || page.End < page.Start {
+			return &ValidationError{Field: "pages", Message: "pages must be numbered from 1 and in order"}
+		}
+		end = page.End
+	}
 	return nil
 }
 
+// PagedContent is Content with a "[Page N]" line at the start of each page, for prompts whose output should
+// be able to say which page something is on. Documents without pages return Content.
+func (d *Document) PagedContent() string {
+	if len(d.Pages) == 0 {
+		return d.Content
+	}
+	runes := []rune(d.Content)
+	var b strings.Builder
+	for i, page := range d.Pages {
+		if i > 0 {
+			b.WriteString(pageSeparator)
+		}
+		fmt.Fprintf(&b, "[Page %d]\n", page.Number)
+		if page.Start <= page.End && page.End <= len(runes) {
+			b.WriteString(string(runes[page.Start:page.End]))
+		}
+	}
+	return b.String()
+}
+
+// PageAt returns the number of the page the rune offset falls on, or 0 for documents without pages. An
+// offset in the break between two pages counts as the earlier one.
+func (d *Document) PageAt(offset int) int {
+	number := 0
+	for _, page := range d.Pages {
+		if page.Start > offset {
+			break
+		}
+		number = page.Number
+	}
+	return number
+}
+
 func (d *Document) MarkProcessed() {
 	now := time.Now()
 	d.ProcessedAt = &now
@@ -59,4 +185,25 @@ func (d *Document) MarkProcessed() {
 
 func (d *Document) IsProcessed() bool {
 	return d.ProcessedAt != nil
-}
\ No newline at end of file
+}
+
+func (d *Document) Rename(title string) {
+	d.Title = title
+}
+
+// UpdateContent replaces the body and clears ProcessedAt, since existing summaries no longer describe it.
+// The page spans go too, since they no longer match the content.
+func (d *Document) UpdateContent(content string) {
+	d.Content = content
+	d.Size = int64(len(content))
+	d.ProcessedAt = nil
+	d.Pages = nil
+}
+
+func IsValidDocumentType(t DocumentType) bool {
+	switch t {
+	case DocumentTypeTXT, DocumentTypeMD, DocumentTypePDF, DocumentTypeDOCX, DocumentTypePPTX, DocumentTypeXLSX, DocumentTypeHTML:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DocumentChunk is a piece of a document embedded for semantic search. Start and End are byte offsets
+// into the document content at the time it was embedded.
+type DocumentChunk struct {
+	ID         uuid.UUID
+	DocumentID uuid.UUID
+	Index      int
+	Heading    string
+	Start      int
+	End        int
+	Content    string
+	Model      string
+	Embedding  []float32 // normalized to unit length
+	CreatedAt  time.Time
+}
+
+// ChunkMatch is a chunk found by semantic search. Score is the cosine similarity to the query, from -1
+// to 1. The chunk's Embedding is not loaded.
+type ChunkMatch struct {
+	DocumentChunk
+	Title string
+	Score float64
+}
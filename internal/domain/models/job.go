@@ -0,0 +1,122 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type JobType string
+
+const (
+	JobTypeSummary   JobType = "summary"
+	JobTypeEmbedding JobType = "embedding"
+)
+
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+)
+
+// Job is a unit of background work, persisted so that queued and interrupted work survives restarts.
+type Job struct {
+	ID         uuid.UUID       `json:"id"`
+	UserID     uuid.UUID       `json:"user_id"`
+	DocumentID uuid.UUID       `json:"document_id"`
+	Type       JobType         `json:"type"`
+	Status     JobStatus       `json:"status"`
+	Payload    json.RawMessage `json:"payload"`
+	ResultID   *uuid.UUID      `json:"result_id,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Attempts   int             `json:"attempts"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	// WorkerID is the process running the job, which holds it until LockedUntil and renews that lease
+	// while it works. A running job whose lease has run out was left by a process that stopped.
+	WorkerID    string     `json:"-"`
+	LockedUntil *time.Time `json:"-"`
+}
+
+func NewJob(userID, documentID uuid.UUID, jobType JobType, payload json.RawMessage) *Job {
+	now := time.Now()
+	return &Job{
+		ID:         uuid.New(),
+		UserID:     userID,
+		DocumentID: documentID,
+		Type:       jobType,
+		Status:     JobStatusQueued,
+		Payload:    payload,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+func (j *Job) Validate() error {
+	if j.UserID == uuid.Nil {
+		return &ValidationError{Field: "user_id", Message: "user_id is required"}
+	}
+	if j.DocumentID == uuid.Nil {
+		return &ValidationError{Field: "document_id", Message: "document_id is required"}
+	}
+	if j.Type != JobTypeSummary && j.Type != JobTypeEmbedding {
+		return &ValidationError{Field: "type", Message: "invalid job type"}
+	}
+	return nil
+}
+
+// Start marks the job as picked up by a worker.
+func (j *Job) Start() {
+	now := time.Now()
+	j.Status = JobStatusRunning
+	j.Attempts++
+	j.StartedAt = &now
+	j.UpdatedAt = now
+}
+
+// Succeed marks the job as done. resultID is the record it produced, or uuid.Nil for jobs without one.
+func (j *Job) Succeed(resultID uuid.UUID) {
+	now := time.Now()
+	j.Status = JobStatusSucceeded
+	j.ResultID = nil
+	if resultID != uuid.Nil {
+		j.ResultID = &resultID
+	}
+	j.Error = ""
+	j.FinishedAt = &now
+	j.UpdatedAt = now
+	j.release()
+}
+
+func (j *Job) Fail(reason string) {
+	now := time.Now()
+	j.Status = JobStatusFailed
+	j.Error = reason
+	j.FinishedAt = &now
+	j.UpdatedAt = now
+	j.release()
+}
+
+// Requeue puts an interrupted job back in the queue.
+func (j *Job) Requeue() {
+	j.Status = JobStatusQueued
+	j.StartedAt = nil
+	j.UpdatedAt = time.Now()
+	j.release()
+}
+
+// release gives up the worker's lease, once the job is no longer running.
+func (j *Job) release() {
+	j.WorkerID = ""
+	j.LockedUntil = nil
+}
+
+func (j *Job) IsFinished() bool {
+	return j.Status == JobStatusSucceeded || j.Status == JobStatusFailed
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SearchHitKind string
+
+const (
+	SearchHitDocument SearchHitKind = "document"
+	SearchHitSummary  SearchHitKind = "summary"
+)
+
+// SearchHit is a document or summary matching a search. Text is the matched body, from which the
+// service cuts Snippet; it is not returned to clients.
+type SearchHit struct {
+	Kind       SearchHitKind
+	DocumentID uuid.UUID
+	SummaryID  *uuid.UUID // set for summary hits
+	Title      string     // the document title, also for summary hits
+	Text       string
+	Snippet    string
+	CreatedAt  time.Time
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is a refresh-token backed login on one device. Only a hash of the refresh token is stored.
+type Session struct {
+	ID               uuid.UUID  `json:"id"`
+	UserID           uuid.UUID  `json:"user_id"`
+	RefreshTokenHash string     `json:"-"`
+	UserAgent        string     `json:"user_agent"`
+	IPAddress        string     `json:"ip_address"`
+	CreatedAt        time.Time  `json:"created_at"`
+	LastUsedAt       time.Time  `json:"last_used_at"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+}
+
+func NewSession(userID uuid.UUID, refreshTokenHash, userAgent, ipAddress string, ttl time.Duration) *Session {
+	now := time.Now()
+	return &Session{
+		ID:               uuid.New(),
+		UserID:           userID,
+		RefreshTokenHash: refreshTokenHash,
+		UserAgent:        userAgent,
+		IPAddress:        ipAddress,
+		CreatedAt:        now,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(ttl),
+	}
+}
+
+func (s *Session) Validate() error {
+	if s.UserID == uuid.Nil {
+		return &ValidationError{Field: "user_id", Message: "user_id is required"}
+	}
+	if s.RefreshTokenHash == "" {
+		return &ValidationError{Field: "refresh_token_hash", Message: "refresh_token_hash is required"}
+	}
+	if !s.ExpiresAt.After(s.CreatedAt) {
+		return &ValidationError{Field: "expires_at", Message: "expires_at must be after created_at"}
+	}
+	return nil
+}
+
+// Rotate replaces the refresh token hash and records the use.
+func (s *Session) Rotate(refreshTokenHash string) {
+	s.RefreshTokenHash = refreshTokenHash
+	s.LastUsedAt = time.Now()
+}
+
+func (s *Session) Revoke() {
+	if s.RevokedAt != nil {
+		return
+	}
+	now := time.Now()
+	s.RevokedAt = &now
+}
+
+func (s *Session) IsRevoked() bool {
+	return s.RevokedAt != nil
+}
+
+func (s *Session) IsActive() bool {
+	return !s.IsRevoked() && time.Now().Before(s.ExpiresAt)
+}
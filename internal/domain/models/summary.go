@@ -1,26 +1,86 @@
 package models
 
 import (
+	"regexp"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type SummaryLength string
+
+const (
+	SummaryLengthShort  SummaryLength = "short"
+	SummaryLengthMedium SummaryLength = "medium"
+	SummaryLengthLong   SummaryLength = "long"
+)
+
+type SummaryStyle string
+
+const (
+	SummaryStyleBusiness  SummaryStyle = "business"
+	SummaryStyleBullets   SummaryStyle = "bullets"
+	SummaryStyleExecutive SummaryStyle = "executive"
+	SummaryStyleCasual    SummaryStyle = "casual"
+)
+
+// SummaryStatus tells whether a summary holds the complete model output. Streamed summaries that were
+// interrupted are kept as partial, or failed when no text arrived at all.
+type SummaryStatus string
+
+const (
+	SummaryStatusCompleted SummaryStatus = "completed"
+	SummaryStatusPartial   SummaryStatus = "partial"
+	SummaryStatusFailed    SummaryStatus = "failed"
+)
+
+const (
+	DefaultSummaryLanguage = "ja"
+
+	MinSummaryTargetChars = 20
+	MaxSummaryTargetChars = 5000
+	MinSummaryTargetWords = 10
+	MaxSummaryTargetWords = 1000
+)
+
+// summaryLengthChars is the approximate character budget of each length preset.
+var summaryLengthChars = map[SummaryLength]int{
+	SummaryLengthShort:  100,
+	SummaryLengthMedium: 200,
+	SummaryLengthLong:   600,
+}
+
+var languageTagPattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// SummaryOptions records how a summary was requested. At most one of Length, TargetChars and TargetWords
+// is set; the zero value means the defaults (medium, business, Japanese).
+type SummaryOptions struct {
+	Length      SummaryLength `json:"length,omitempty"`
+	TargetChars int           `json:"target_chars,omitempty"`
+	TargetWords int           `json:"target_words,omitempty"`
+	Style       SummaryStyle  `json:"style,omitempty"`
+	Language    string        `json:"language,omitempty"` // BCP 47 tag, e.g. "ja" or "en-US"
+}
+
 type Summary struct {
-	ID         uuid.UUID `json:"id"`
-	DocumentID uuid.UUID `json:"document_id"`
-	Content    string    `json:"content"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID         uuid.UUID     `json:"id"`
+	DocumentID uuid.UUID     `json:"document_id"`
+	Content    string        `json:"content"`
+	Status     SummaryStatus `json:"status"`
+	SummaryOptions
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 func NewSummary(documentID uuid.UUID, content string) *Summary {
 	return &Summary{
-		ID:         uuid.New(),
-		DocumentID: documentID,
-		Content:    content,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+		ID:             uuid.New(),
+		DocumentID:     documentID,
+		Content:        content,
+		Status:         SummaryStatusCompleted,
+		SummaryOptions: SummaryOptions{}.WithDefaults(),
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
 }
 
@@ -28,13 +88,87 @@ func (s *Summary) Validate() error {
 	if s.DocumentID == uuid.Nil {
 		return &ValidationError{Field: "document_id", Message: "document_id is required"}
 	}
-	if s.Content == "" {
+	switch s.Status {
+	case "", SummaryStatusCompleted, SummaryStatusPartial, SummaryStatusFailed:
+	default:
+		return &ValidationError{Field: "status", Message: "status must be one of completed, partial, failed"}
+	}
+	// A failed summary records the attempt even though the model produced nothing.
+	if s.Content == "" && s.Status != SummaryStatusFailed {
 		return &ValidationError{Field: "content", Message: "content is required"}
 	}
-	return nil
+	return s.SummaryOptions.Validate()
 }
 
 func (s *Summary) UpdateContent(content string) {
 	s.Content = content
 	s.UpdatedAt = time.Now()
-}
\ No newline at end of file
+}
+
+// WithDefaults fills in the style and language, and the medium length preset when no length was chosen.
+func (o SummaryOptions) WithDefaults() SummaryOptions {
+	if o.Length == "" && o.TargetChars == 0 && o.TargetWords == 0 {
+		o.Length = SummaryLengthMedium
+	}
+	if o.Style == "" {
+		o.Style = SummaryStyleBusiness
+	}
+	if o.Language == "" {
+		o.Language = DefaultSummaryLanguage
+	}
+	return o
+}
+
+func (o SummaryOptions) Validate() error {
+	chosen := 0
+	if o.Length != "" {
+		chosen++
+		if _, ok := summaryLengthChars[o.Length]; !ok {
+			return &ValidationError{Field: "length", Message: "length must be one of short, medium, long"}
+		}
+	}
+	if o.TargetChars != 0 {
+		chosen++
+		if o.TargetChars < MinSummaryTargetChars || o.TargetChars > MaxSummaryTargetChars {
+			return &ValidationError{Field: "target_chars", Message: "target_chars must be between 20 and 5000"}
+		}
+	}
+	if o.TargetWords != 0 {
+		chosen++
+		if o.TargetWords < MinSummaryTargetWords || o.TargetWords > MaxSummaryTargetWords {
+			return &ValidationError{Field: "target_words", Message: "target_words must be between 10 and 1000"}
+		}
+	}
+	if chosen > 1 {
+		return &ValidationError{Field: "length", Message: "specify only one of length, target_chars, target_words"}
+	}
+	if o.Style != "" && !IsValidSummaryStyle(o.Style) {
+		return &ValidationError{Field: "style", Message: "style must be one of business, bullets, executive, casual"}
+	}
+	if o.Language != "" && !languageTagPattern.MatchString(o.Language) {
+		return &ValidationError{Field: "language", Message: "language must be a language code such as ja or en"}
+	}
+	return nil
+}
+
+// TargetLength returns the requested size as a number and whether it counts words rather than characters.
+func (o SummaryOptions) TargetLength() (int, bool) {
+	if o.TargetWords > 0 {
+		return o.TargetWords, true
+	}
+	if o.TargetChars > 0 {
+		return o.TargetChars, false
+	}
+	if chars, ok := summaryLengthChars[o.Length]; ok {
+		return chars, false
+	}
+	return summaryLengthChars[SummaryLengthMedium], false
+}
+
+func IsValidSummaryStyle(style SummaryStyle) bool {
+	switch style {
+	case SummaryStyleBusiness, SummaryStyleBullets, SummaryStyleExecutive, SummaryStyleCasual:
+		return true
+	}
+	return false
+}
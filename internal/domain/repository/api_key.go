@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error)
+	Update(ctx context.Context, key *models.APIKey) error
+}
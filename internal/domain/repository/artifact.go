@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+// ArtifactFilter narrows a listing of artifacts; zero fields match everything.
+type ArtifactFilter struct {
+	DocumentID uuid.UUID
+	Kind       models.ArtifactKind
+}
+
+type ArtifactRepository interface {
+	Create(ctx context.Context, artifact *models.Artifact) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Artifact, error)
+	// GetByUserID returns the user's artifacts matching filter, newest first.
+	GetByUserID(ctx context.Context, userID uuid.UUID, filter ArtifactFilter) ([]*models.Artifact, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// BlobStore keeps the content of files such as artifacts, addressed by slash-separated keys.
+type BlobStore interface {
+	// Put stores data under key, replacing what was there.
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns nil, nil when nothing is stored under key.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete removes the data under key; deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
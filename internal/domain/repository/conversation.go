@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type ConversationRepository interface {
+	Create(ctx context.Context, conversation *models.Conversation) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Conversation, error)
+	// ListByDocumentID returns the user's conversations about a document, most recently active first.
+	ListByDocumentID(ctx context.Context, userID, documentID uuid.UUID) ([]*models.Conversation, error)
+	Update(ctx context.Context, conversation *models.Conversation) error
+	// Delete removes a conversation together with its messages.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	CreateMessage(ctx context.Context, message *models.Message) error
+	// ListMessages returns the messages of a conversation, oldest first.
+	ListMessages(ctx context.Context, conversationID uuid.UUID) ([]*models.Message, error)
+}
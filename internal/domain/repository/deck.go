@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type DeckRepository interface {
+	Create(ctx context.Context, deck *models.Deck) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Deck, error)
+	// GetByDocumentID returns the decks of a document, newest first.
+	GetByDocumentID(ctx context.Context, documentID uuid.UUID) ([]*models.Deck, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
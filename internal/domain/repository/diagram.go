@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type DiagramRepository interface {
+	Create(ctx context.Context, diagram *models.Diagram) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Diagram, error)
+	// GetByDocumentID returns the diagrams of a document, newest first.
+	GetByDocumentID(ctx context.Context, documentID uuid.UUID) ([]*models.Diagram, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
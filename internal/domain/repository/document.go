@@ -2,15 +2,55 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github/k-tsurumaki/quilldeck/internal/domain/models"
 	"github.com/google/uuid"
 )
 
+type DocumentSortField string
+
+const (
+	DocumentSortUploadedAt DocumentSortField = "uploaded_at"
+	DocumentSortTitle      DocumentSortField = "title"
+	DocumentSortSize       DocumentSortField = "size"
+)
+
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// DocumentCursor is the keyset position of the last row of a page. Only the field matching the
+// listing's sort is meaningful; ID breaks ties between rows with equal sort values.
+type DocumentCursor struct {
+	UploadedAt time.Time `json:"uploaded_at,omitempty"`
+	Title      string    `json:"title,omitempty"`
+	Size       int64     `json:"size,omitempty"`
+	ID         uuid.UUID `json:"id"`
+}
+
+type DocumentListOptions struct {
+	Type          models.DocumentType
+	Processed     *bool
+	UploadedFrom  *time.Time
+	UploadedTo    *time.Time
+	TitleContains string
+	SortBy        DocumentSortField
+	SortOrder     SortOrder
+	After         *DocumentCursor
+	Limit         int
+}
+
 type DocumentRepository interface {
 	Create(ctx context.Context, document *models.Document) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Document, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Document, error)
+	// ListByUserID returns up to opts.Limit documents after opts.After in the requested order,
+	// without their content.
+	ListByUserID(ctx context.Context, userID uuid.UUID, opts DocumentListOptions) ([]*models.DocumentListItem, error)
 	Update(ctx context.Context, document *models.Document) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -21,4 +61,5 @@ type SummaryRepository interface {
 	GetByDocumentID(ctx context.Context, documentID uuid.UUID) ([]*models.Summary, error)
 	Update(ctx context.Context, summary *models.Summary) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByDocumentID(ctx context.Context, documentID uuid.UUID) error
 }
\ No newline at end of file
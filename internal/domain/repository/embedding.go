@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+// UnembeddedDocument identifies a document that has no chunks for the current embedding model.
+type UnembeddedDocument struct {
+	DocumentID uuid.UUID
+	UserID     uuid.UUID
+}
+
+type EmbeddingRepository interface {
+	// ReplaceDocumentChunks atomically replaces every chunk of a document.
+	ReplaceDocumentChunks(ctx context.Context, documentID uuid.UUID, chunks []*models.DocumentChunk) error
+	DeleteByDocumentID(ctx context.Context, documentID uuid.UUID) error
+	// ListByDocumentID returns the chunks of a document embedded with model, in document order.
+	ListByDocumentID(ctx context.Context, documentID uuid.UUID, model string) ([]*models.DocumentChunk, error)
+	// Nearest returns up to limit chunks of the user's documents embedded with model, most similar to
+	// the normalized query vector first.
+	Nearest(ctx context.Context, userID uuid.UUID, model string, query []float32, limit int) ([]*models.ChunkMatch, error)
+	// ListUnembedded returns non-empty documents without chunks for model that have no embedding job
+	// queued or running, oldest first.
+	ListUnembedded(ctx context.Context, model string, limit int) ([]UnembeddedDocument, error)
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type JobRepository interface {
+	Create(ctx context.Context, job *models.Job) error
+	// CreateIfNotQueued is Create for embedding jobs, of which a document needs at most one in the queue. It
+	// creates nothing and returns false when one is already queued for the document.
+	CreateIfNotQueued(ctx context.Context, job *models.Job) (bool, error)
+	// HasQueued reports whether a job of the given type is queued for the document.
+	HasQueued(ctx context.Context, documentID uuid.UUID, jobType models.JobType) (bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Job, error)
+	Update(ctx context.Context, job *models.Job) error
+	// ClaimNext atomically marks the oldest queued job as running under workerID, leased for the given
+	// duration, and returns it, or nil when the queue is empty.
+	ClaimNext(ctx context.Context, workerID string, lease time.Duration) (*models.Job, error)
+	// RenewLease extends the lease of a running job to until. It returns false when the job is no longer
+	// running under workerID, because it was recovered and possibly claimed by another worker.
+	RenewLease(ctx context.Context, id uuid.UUID, workerID string, until time.Time) (bool, error)
+	// UpdateLeased is Update for a job claimed by workerID. It writes nothing and returns false when the job
+	// is no longer running under workerID, because its lease ran out and it was recovered, so that a worker
+	// that lost its lease does not overwrite the outcome recorded by the one holding it now.
+	UpdateLeased(ctx context.Context, job *models.Job, workerID string) (bool, error)
+	// GetByStatus returns jobs in the given status, oldest first.
+	GetByStatus(ctx context.Context, status models.JobStatus) ([]*models.Job, error)
+	// GetExpired returns running jobs whose lease ran out before now, oldest first. Jobs claimed before
+	// leases existed have none and are returned too.
+	GetExpired(ctx context.Context, now time.Time) ([]*models.Job, error)
+	// UpdateExpired is Update for a job returned by GetExpired. It writes nothing and returns false when
+	// the job is no longer running with a lease that ran out before now, so that processes recovering the
+	// same job at once do not undo each other or a worker that has claimed it since.
+	UpdateExpired(ctx context.Context, job *models.Job, now time.Time) (bool, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+// Repositories bundles one implementation of every repository, so the storage backend is chosen in a
+// single place.
+type Repositories struct {
+	Users         UserRepository
+	Documents     DocumentRepository
+	Summaries     SummaryRepository
+	Sessions      SessionRepository
+	APIKeys       APIKeyRepository
+	Jobs          JobRepository
+	Search        SearchRepository
+	Embeddings    EmbeddingRepository
+	Conversations ConversationRepository
+	Decks         DeckRepository
+	Diagrams      DiagramRepository
+	Artifacts     ArtifactRepository
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type SearchOptions struct {
+	// Terms must all occur, case-insensitively, in a document's title or content, or in a summary.
+	Terms  []string
+	Limit  int
+	Offset int
+}
+
+type SearchRepository interface {
+	// Search returns the user's documents and summaries matching every term, best matches first.
+	Search(ctx context.Context, userID uuid.UUID, opts SearchOptions) ([]*models.SearchHit, error)
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error)
+	GetByRefreshTokenHash(ctx context.Context, hash string) (*models.Session, error)
+	GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error)
+	Update(ctx context.Context, session *models.Session) error
+	// Rotate writes the refresh token hash and last use of a session whose token was previousHash. It writes
+	// nothing and returns false when the session no longer has that token or has been revoked, because
+	// another request presented the same token first.
+	Rotate(ctx context.Context, session *models.Session, previousHash string) (bool, error)
+	RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error
+}
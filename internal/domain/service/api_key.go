@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/pkg/crypto"
+	"github/k-tsurumaki/quilldeck/internal/pkg/errors"
+)
+
+const (
+	// APIKeyPrefix marks QuillDeck API keys so they can be told apart from access tokens.
+	APIKeyPrefix = "qd_"
+
+	// apiKeyLookupBytes sizes the unique lookup part of keys. At 64 bits, a collision, which would fail
+	// creating the key, stays unlikely until billions of keys exist.
+	apiKeyLookupBytes = 8
+	apiKeySecretBytes = 32
+
+	// apiKeyUsageResolution throttles last_used_at writes for keys hammered by CI jobs.
+	apiKeyUsageResolution = time.Minute
+)
+
+type APIKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+	userRepo   repository.UserRepository
+}
+
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository, userRepo repository.UserRepository) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// IsAPIKey reports whether a bearer credential looks like an API key rather than an access token.
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// CreateKey generates a key of the form qd_<prefix>_<secret> and returns it in plaintext exactly once.
+func (s *APIKeyService) CreateKey(ctx context.Context, userID uuid.UUID, name string, scopes []string, expiresAt *time.Time) (*models.APIKey, string, error) {
+	// The lookup part is hex so it never contains the "_" separator.
+	lookup, err := crypto.RandomHex(apiKeyLookupBytes)
+	if err != nil {
+		return nil, "", errors.Wrap(err, errors.ErrCodeInternal, "failed to generate API key")
+	}
+	secret, err := crypto.RandomToken(apiKeySecretBytes)
+	if err != nil {
+		return nil, "", errors.Wrap(err, errors.ErrCodeInternal, "failed to generate API key")
+	}
+
+	prefix := APIKeyPrefix + lookup
+	plaintext := prefix + "_" + secret
+
+	key := models.NewAPIKey(userID, strings.TrimSpace(name), prefix, crypto.HashToken(plaintext), dedupeScopes(scopes), expiresAt)
+	if err := key.Validate(); err != nil {
+		return nil, "", errors.Wrap(err, errors.ErrCodeValidation, "invalid API key data")
+	}
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, "", errors.Wrap(err, errors.ErrCodeInternal, "failed to create API key")
+	}
+	return key, plaintext, nil
+}
+
+func (s *APIKeyService) ListKeys(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	keys, err := s.apiKeyRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list API keys")
+	}
+	return keys, nil
+}
+
+func (s *APIKeyService) RevokeKey(ctx context.Context, userID, keyID uuid.UUID) error {
+	key, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to look up API key")
+	}
+	if key == nil || key.UserID != userID {
+		return errors.New(errors.ErrCodeNotFound, "API key not found")
+	}
+
+	key.Revoke()
+	if err := s.apiKeyRepo.Update(ctx, key); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to revoke API key")
+	}
+	return nil
+}
+
+// Authenticate resolves a plaintext API key to its owner and the key record carrying its scopes.
+func (s *APIKeyService) Authenticate(ctx context.Context, plaintext string) (*models.User, *models.APIKey, error) {
+	prefix, ok := apiKeyLookupPrefix(plaintext)
+	if !ok {
+		return nil, nil, errors.New(errors.ErrCodeUnauthorized, "invalid API key")
+	}
+
+	key, err := s.apiKeyRepo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to look up API key")
+	}
+	if key == nil || subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(crypto.HashToken(plaintext))) != 1 {
+		return nil, nil, errors.New(errors.ErrCodeUnauthorized, "invalid API key")
+	}
+	if !key.IsActive() {
+		return nil, nil, errors.New(errors.ErrCodeUnauthorized, "API key has expired or been revoked")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, key.UserID)
+	if err != nil || user == nil {
+		return nil, nil, errors.New(errors.ErrCodeUnauthorized, "invalid API key")
+	}
+
+	if key.LastUsedAt == nil || time.Since(*key.LastUsedAt) > apiKeyUsageResolution {
+		key.MarkUsed()
+		if err := s.apiKeyRepo.Update(ctx, key); err != nil {
+			log.Printf("failed to record API key usage for %s: %v", key.ID, err)
+		}
+	}
+
+	return user, key, nil
+}
+
+// apiKeyLookupPrefix extracts the qd_<prefix> part used to find the stored key.
+func apiKeyLookupPrefix(plaintext string) (string, bool) {
+	if !IsAPIKey(plaintext) {
+		return "", false
+	}
+	rest := strings.TrimPrefix(plaintext, APIKeyPrefix)
+	lookup, secret, found := strings.Cut(rest, "_")
+	if !found || lookup == "" || secret == "" {
+		return "", false
+	}
+	return APIKeyPrefix + lookup, true
+}
+
+func dedupeScopes(scopes []string) []string {
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" || seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		result = append(result, scope)
+	}
+	return result
+}
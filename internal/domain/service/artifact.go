@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/pkg/errors"
+)
+
+// ArtifactService keeps the files generated from documents: their metadata in the database and their
+// content in the blob store. Every artifact belongs to the owner of its document.
+type ArtifactService struct {
+	artifactRepo repository.ArtifactRepository
+	blobs        repository.BlobStore
+	docService   *DocumentService
+}
+
+func NewArtifactService(artifactRepo repository.ArtifactRepository, blobs repository.BlobStore, docService *DocumentService) *ArtifactService {
+	return &ArtifactService{
+		artifactRepo: artifactRepo,
+		blobs:        blobs,
+		docService:   docService,
+	}
+}
+
+// SaveArtifact stores a file generated from a document. The caller has already checked that the document
+// belongs to the user it acts for.
+func (s *ArtifactService) SaveArtifact(ctx context.Context, document *models.Document, kind models.ArtifactKind, name, mimeType string, params map[string]any, data []byte) (*models.Artifact, error) {
+	artifact := models.NewArtifact(document.UserID, document.ID, kind, name, mimeType, data)
+	if params != nil {
+		artifact.Params = params
+	}
+	artifact.StorageKey = artifactStorageKey(artifact.ID)
+	if err := artifact.Validate(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeValidation, "invalid artifact data")
+	}
+
+	if err := s.blobs.Put(ctx, artifact.StorageKey, data); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to store artifact content")
+	}
+	if err := s.artifactRepo.Create(ctx, artifact); err != nil {
+		if err := s.blobs.Delete(context.WithoutCancel(ctx), artifact.StorageKey); err != nil {
+			log.Printf("failed to remove content of unsaved artifact %s: %v", artifact.ID, err)
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to create artifact")
+	}
+	return artifact, nil
+}
+
+// artifactStorageKey spreads artifacts over directories by the first characters of their ID.
+func artifactStorageKey(id uuid.UUID) string {
+	name := id.String()
+	return "artifacts/" + name[:2] + "/" + name
+}
+
+// ListArtifacts lists the user's artifacts matching filter, newest first. Filtering by a document the
+// user does not own is reported as not found.
+func (s *ArtifactService) ListArtifacts(ctx context.Context, userID uuid.UUID, filter repository.ArtifactFilter) ([]*models.Artifact, error) {
+	if filter.Kind != "" && !models.IsValidArtifactKind(filter.Kind) {
+		return nil, errors.New(errors.ErrCodeValidation, "kind must be one of deck, diagram, image")
+	}
+	if filter.DocumentID != uuid.Nil {
+		if _, err := s.docService.GetUserDocument(ctx, userID, filter.DocumentID); err != nil {
+			return nil, err
+		}
+	}
+
+	artifacts, err := s.artifactRepo.GetByUserID(ctx, userID, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list artifacts")
+	}
+	return artifacts, nil
+}
+
+// GetArtifact returns an artifact the user owns; anyone else's is reported as not found.
+func (s *ArtifactService) GetArtifact(ctx context.Context, userID, artifactID uuid.UUID) (*models.Artifact, error) {
+	artifact, err := s.artifactRepo.GetByID(ctx, artifactID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get artifact")
+	}
+	if artifact == nil || artifact.UserID != userID {
+		return nil, errors.New(errors.ErrCodeNotFound, "artifact not found")
+	}
+	return artifact, nil
+}
+
+// ReadArtifact returns an artifact the user owns with its content.
+func (s *ArtifactService) ReadArtifact(ctx context.Context, userID, artifactID uuid.UUID) (*models.Artifact, []byte, error) {
+	artifact, err := s.GetArtifact(ctx, userID, artifactID)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := s.content(ctx, artifact)
+	if err != nil {
+		return nil, nil, err
+	}
+	return artifact, data, nil
+}
+
+// PreviewArtifact is ReadArtifact for artifacts that can be shown in the browser.
+func (s *ArtifactService) PreviewArtifact(ctx context.Context, userID, artifactID uuid.UUID) (*models.Artifact, []byte, error) {
+	artifact, err := s.GetArtifact(ctx, userID, artifactID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !artifact.Previewable() {
+		return nil, nil, errors.New(errors.ErrCodeValidation, "artifacts of type "+artifact.MIMEType+" cannot be previewed; download them instead")
+	}
+	data, err := s.content(ctx, artifact)
+	if err != nil {
+		return nil, nil, err
+	}
+	return artifact, data, nil
+}
+
+// content reads an artifact's content from the blob store, checked against the recorded checksum.
+func (s *ArtifactService) content(ctx context.Context, artifact *models.Artifact) ([]byte, error) {
+	data, err := s.blobs.Get(ctx, artifact.StorageKey)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to read artifact content")
+	}
+	if data == nil {
+		return nil, errors.New(errors.ErrCodeInternal, "artifact content is missing")
+	}
+	if models.Checksum(data) != artifact.Checksum {
+		return nil, errors.New(errors.ErrCodeInternal, "artifact content does not match its checksum")
+	}
+	return data, nil
+}
+
+func (s *ArtifactService) DeleteArtifact(ctx context.Context, userID, artifactID uuid.UUID) error {
+	artifact, err := s.GetArtifact(ctx, userID, artifactID)
+	if err != nil {
+		return err
+	}
+	return s.remove(ctx, artifact)
+}
+
+// DeleteDocumentArtifacts deletes the artifacts of a document the user owns. It runs before the document
+// itself is deleted, since the database forgets the artifacts together with the document but the blob
+// store does not.
+func (s *ArtifactService) DeleteDocumentArtifacts(ctx context.Context, userID, documentID uuid.UUID) error {
+	artifacts, err := s.ListArtifacts(ctx, userID, repository.ArtifactFilter{DocumentID: documentID})
+	if err != nil {
+		return err
+	}
+	for _, artifact := range artifacts {
+		if err := s.remove(ctx, artifact); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove deletes the record before the content, so that an artifact is never listed without its content.
+// Content left behind by a failed removal is only logged.
+func (s *ArtifactService) remove(ctx context.Context, artifact *models.Artifact) error {
+	if err := s.artifactRepo.Delete(ctx, artifact.ID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete artifact")
+	}
+	if err := s.blobs.Delete(ctx, artifact.StorageKey); err != nil {
+		log.Printf("failed to remove content of deleted artifact %s: %v", artifact.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/pkg/chunker"
+	"github/k-tsurumaki/quilldeck/internal/pkg/errors"
+)
+
+const (
+	defaultAskPassageChars = 1000
+	defaultAskMaxPassages  = 5
+	defaultAskContextChars = 24000
+)
+
+type AskOptions struct {
+	// PassageChars is the largest passage, in characters, the document is cut into when it has no
+	// embedded chunks to retrieve from.
+	PassageChars int
+	// MaxPassages is how many of the most relevant passages are given to the model.
+	MaxPassages int
+	// ContextChars is the budget, in characters, for everything sent to the model in one turn of a
+	// conversation. The oldest turns are left out to stay within it.
+	ContextChars int
+}
+
+// AskService answers questions about a document from its most relevant passages and reports which
+// passages the answer is based on.
+type AskService struct {
+	docService       *DocumentService
+	embeddingService *EmbeddingService
+	model            llm.LLM
+	opts             AskOptions
+}
+
+func NewAskService(docService *DocumentService, embeddingService *EmbeddingService, model llm.LLM, opts AskOptions) *AskService {
+	if opts.PassageChars <= 0 {
+		opts.PassageChars = defaultAskPassageChars
+	}
+	if opts.MaxPassages <= 0 {
+		opts.MaxPassages = defaultAskMaxPassages
+	}
+	if opts.ContextChars <= 0 {
+		opts.ContextChars = defaultAskContextChars
+	}
+	return &AskService{
+		docService:       docService,
+		embeddingService: embeddingService,
+		model:            model,
+		opts:             opts,
+	}
+}
+
+// Ask answers question from the passages of a document the user owns that are most relevant to it.
+// Passages are retrieved by embedding similarity when the document has been embedded, and by shared
+// words otherwise.
+func (s *AskService) Ask(ctx context.Context, userID, documentID uuid.UUID, question string) (*models.Answer, error) {
+	question, err := validateQuestion(question)
+	if err != nil {
+		return nil, err
+	}
+	document, err := s.askableDocument(ctx, userID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.answer(ctx, document, question, nil)
+}
+
+// validateQuestion trims a question and checks its length.
+func validateQuestion(question string) (string, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return "", errors.New(errors.ErrCodeValidation, "question is required")
+	}
+	if utf8.RuneCountInString(question) > models.MaxMessageChars {
+		return "", errors.New(errors.ErrCodeValidation, "question must be at most 2000 characters")
+	}
+	return question, nil
+}
+
+// askableDocument returns a document the user owns that has content to answer from.
+func (s *AskService) askableDocument(ctx context.Context, userID, documentID uuid.UUID) (*models.Document, error) {
+	document, err := s.docService.GetUserDocument(ctx, userID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(document.Content) == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "document has no content to ask about")
+	}
+	return document, nil
+}
+
+// answer asks the model about document after the earlier turns of a conversation, oldest first. As many
+// of the most recent turns are sent as fit in the context budget.
+func (s *AskService) answer(ctx context.Context, document *models.Document, question string, history []llm.Message) (*models.Answer, error) {
+	// A follow-up such as "and the second one?" often only makes sense together with the turn before it.
+	query := question
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == llm.RoleUser {
+			query = history[i].Content + "\n" + question
+			break
+		}
+	}
+
+	passages := s.retrieve(ctx, document, query)
+	prompt := askPrompt(document, passages, question)
+	budget := s.opts.ContextChars - utf8.RuneCountInString(askSystemPrompt) - utf8.RuneCountInString(prompt)
+
+	messages := []llm.Message{{Role: llm.RoleSystem, Content: askSystemPrompt}}
+	messages = append(messages, trimHistory(history, budget)...)
+	messages = append(messages, llm.Message{Role: llm.RoleUser, Content: prompt})
+	log.Printf("LLM Request: prompt_chars=%d messages=%d", utf8.RuneCountInString(prompt), len(messages))
+	resp, err := s.model.Complete(ctx, llm.Request{Messages: messages})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get answer from LLM")
+	}
+
+	return &models.Answer{
+		DocumentID: document.ID,
+		Question:   question,
+		Content:    resp.Content,
+		Citations:  citations(document, passages, resp.Content),
+	}, nil
+}
+
+// trimHistory returns the most recent turns of history whose combined length is within budget
+// characters. It never starts with an assistant reply, whose question would be missing.
+func trimHistory(history []llm.Message, budget int) []llm.Message {
+	start := len(history)
+	used := 0
+	for start > 0 {
+		size := utf8.RuneCountInString(history[start-1].Content)
+		if used+size > budget {
+			break
+		}
+		used += size
+		start--
+	}
+	for start < len(history) && history[start].Role != llm.RoleUser {
+		start++
+	}
+	return history[start:]
+}
+
+// retrieve returns the passages most relevant to question, in document order.
+func (s *AskService) retrieve(ctx context.Context, document *models.Document, question string) []chunker.Chunk {
+	var passages []chunker.Chunk
+	if s.embeddingService != nil {
+		ranked, err := s.embeddingService.RankDocumentChunks(ctx, document, question, s.opts.MaxPassages)
+		if err != nil {
+			// Answering from word overlap is still better than not answering at all.
+			log.Printf("failed to rank embedded chunks of document %s: %v", document.ID, err)
+		}
+		passages = ranked
+	}
+	if len(passages) == 0 {
+		passages = rankByOverlap(chunker.Split(document.Content, s.opts.PassageChars), question, s.opts.MaxPassages)
+	}
+
+	sort.Slice(passages, func(i, j int) bool { return passages[i].Start < passages[j].Start })
+	return passages
+}
+
+// rankByOverlap returns up to limit chunks sharing the most character bigrams with query, best first.
+// Bigrams work for languages written without spaces as well as for inflected words; each is weighted by
+// how rare it is among the chunks, so common ones like "th" barely count.
+func rankByOverlap(chunks []chunker.Chunk, query string, limit int) []chunker.Chunk {
+	queryGrams := bigrams(query)
+	chunkGrams := make([]map[string]bool, len(chunks))
+	frequency := make(map[string]int)
+	for i, chunk := range chunks {
+		chunkGrams[i] = bigrams(chunk.Text)
+		for gram := range chunkGrams[i] {
+			if queryGrams[gram] {
+				frequency[gram]++
+			}
+		}
+	}
+
+	scores := make([]float64, len(chunks))
+	for i := range chunks {
+		for gram := range queryGrams {
+			if chunkGrams[i][gram] {
+				scores[i] += math.Log(1 + float64(len(chunks))/float64(frequency[gram]))
+			}
+		}
+	}
+
+	order := make([]int, len(chunks))
+	for i := range order {
+		order[i] = i
+	}
+	// Ties keep document order, so an unrelated question still gets the beginning of the document.
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	ranked := make([]chunker.Chunk, 0, min(limit, len(chunks)))
+	for _, i := range order[:min(limit, len(order))] {
+		ranked = append(ranked, chunks[i])
+	}
+	return ranked
+}
+
+// bigrams returns the set of lower-cased pairs of adjacent letters or digits in text.
+func bigrams(text string) map[string]bool {
+	grams := make(map[string]bool)
+	var prev rune
+	for _, r := range text {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) {
+			prev = 0
+			continue
+		}
+		r = unicode.ToLower(r)
+		if prev != 0 {
+			grams[string([]rune{prev, r})] = true
+		}
+		prev = r
+	}
+	return grams
+}
+
+const askSystemPrompt = "You answer questions about a document using only the numbered passages from it that you are given. " +
+	"After each statement, cite the passages that support it by their numbers in square brackets, e.g. [2] or [1][3]. " +
+	"If the passages do not contain the answer, say so instead of guessing. Answer in the language of the question."
+
+func askPrompt(document *models.Document, passages []chunker.Chunk, question string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Passages from the document %q:\n\n", document.Title)
+	for i, passage := range passages {
+		fmt.Fprintf(&b, "[%d]", i+1)
+		var where []string
+		if page := document.PageAt(utf8.RuneCountInString(document.Content[:passage.Start])); page > 0 {
+			where = append(where, fmt.Sprintf("page %d", page))
+		}
+		if passage.Heading != "" {
+			where = append(where, fmt.Sprintf("section %q", passage.Heading))
+		}
+		if len(where) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(where, ", "))
+		}
+		fmt.Fprintf(&b, "\n%s\n\n", strings.TrimSpace(passage.Text))
+	}
+	fmt.Fprintf(&b, "Question: %s", question)
+	return b.String()
+}
+
+// citationPattern matches citations such as [2] and [1, 3].
+var citationPattern = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)\]`)
+
+// citations returns the passages cited in answer, in the order they are first cited, with their
+// offsets converted from bytes to characters of the document's content. Numbers that do not name a
+// passage are ignored.
+func citations(document *models.Document, passages []chunker.Chunk, answer string) []models.Citation {
+	content := document.Content
+	result := []models.Citation{}
+	seen := make(map[int]bool)
+	for _, match := range citationPattern.FindAllStringSubmatch(answer, -1) {
+		for _, field := range strings.Split(match[1], ",") {
+			number, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || number < 1 || number > len(passages) || seen[number] {
+				continue
+			}
+			seen[number] = true
+
+			passage := passages[number-1]
+			start := utf8.RuneCountInString(content[:passage.Start])
+			result = append(result, models.Citation{
+				Passage: number,
+				Heading: passage.Heading,
+				Page:    document.PageAt(start),
+				Start:   start,
+				End:     start + utf8.RuneCountInString(passage.Text),
+				Text:    passage.Text,
+			})
+		}
+	}
+	return result
+}
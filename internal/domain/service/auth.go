@@ -2,21 +2,32 @@ package service
 
 import (
 	"context"
-	"crypto/sha256"
-	"fmt"
+	"log"
+	"time"
 
+	"github.com/google/uuid"
 	"github/k-tsurumaki/quilldeck/internal/domain/models"
 	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/pkg/crypto"
 	"github/k-tsurumaki/quilldeck/internal/pkg/errors"
-	"github.com/google/uuid"
 )
 
+const refreshTokenBytes = 32
+
 type AuthService struct {
-	userRepo repository.UserRepository
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+	hasher      crypto.PasswordHasher
+	refreshTTL  time.Duration
 }
 
-func NewAuthService(userRepo repository.UserRepository) *AuthService {
-	return &AuthService{userRepo: userRepo}
+func NewAuthService(userRepo repository.UserRepository, sessionRepo repository.SessionRepository, hasher crypto.PasswordHasher, refreshTTL time.Duration) *AuthService {
+	return &AuthService{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		hasher:      hasher,
+		refreshTTL:  refreshTTL,
+	}
 }
 
 func (s *AuthService) Register(ctx context.Context, email, password, name string) (*models.User, error) {
@@ -25,7 +36,14 @@ func (s *AuthService) Register(ctx context.Context, email, password, name string
 		return nil, errors.New(errors.ErrCodeValidation, "email already exists")
 	}
 
-	hashedPassword := s.hashPassword(password)
+	if password == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "password is required")
+	}
+
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to hash password")
+	}
 	user := models.NewUser(email, hashedPassword, name)
 
 	if err := user.Validate(); err != nil {
@@ -41,15 +59,20 @@ func (s *AuthService) Register(ctx context.Context, email, password, name string
 
 func (s *AuthService) Login(ctx context.Context, email, password string) (*models.User, error) {
 	user, err := s.userRepo.GetByEmail(ctx, email)
-	if err != nil {
+	if err != nil || user == nil {
 		return nil, errors.New(errors.ErrCodeUnauthorized, "invalid credentials")
 	}
 
-	hashedPassword := s.hashPassword(password)
-	if user.Password != hashedPassword {
+	ok, err := s.hasher.Verify(password, user.Password)
+	if err != nil || !ok {
 		return nil, errors.New(errors.ErrCodeUnauthorized, "invalid credentials")
 	}
 
+	// Upgrade hashes written with an older algorithm or weaker parameters while the plaintext is at hand.
+	if s.hasher.NeedsRehash(user.Password) {
+		s.rehashPassword(ctx, user, password)
+	}
+
 	return user, nil
 }
 
@@ -58,10 +81,162 @@ func (s *AuthService) GetUser(ctx context.Context, userID uuid.UUID) (*models.Us
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeNotFound, "user not found")
 	}
+	if user == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "user not found")
+	}
 	return user, nil
 }
 
-func (s *AuthService) hashPassword(password string) string {
-	hash := sha256.Sum256([]byte(password))
-	return fmt.Sprintf("%x", hash)
-}
\ No newline at end of file
+// ChangePassword replaces the user's password and revokes every session, so all outstanding refresh
+// tokens stop working and other devices have to sign in again.
+func (s *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.hasher.Verify(currentPassword, user.Password)
+	if err != nil || !ok {
+		return errors.New(errors.ErrCodeUnauthorized, "current password is incorrect")
+	}
+	if newPassword == "" {
+		return errors.New(errors.ErrCodeValidation, "new password is required")
+	}
+
+	hashedPassword, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to hash password")
+	}
+	user.Password = hashedPassword
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update password")
+	}
+
+	if err := s.sessionRepo.RevokeAllByUserID(ctx, userID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to revoke sessions")
+	}
+	return nil
+}
+
+// StartSession creates a session for a freshly authenticated user and returns it with the plaintext
+// refresh token, which is only ever handed to the client.
+func (s *AuthService) StartSession(ctx context.Context, user *models.User, userAgent, ipAddress string) (*models.Session, string, error) {
+	refreshToken, err := crypto.RandomToken(refreshTokenBytes)
+	if err != nil {
+		return nil, "", errors.Wrap(err, errors.ErrCodeInternal, "failed to generate refresh token")
+	}
+
+	session := models.NewSession(user.ID, crypto.HashToken(refreshToken), userAgent, ipAddress, s.refreshTTL)
+	if err := session.Validate(); err != nil {
+		return nil, "", errors.Wrap(err, errors.ErrCodeValidation, "invalid session data")
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, "", errors.Wrap(err, errors.ErrCodeInternal, "failed to create session")
+	}
+	return session, refreshToken, nil
+}
+
+// RefreshSession exchanges a refresh token for a rotated one. Presenting a token that has already been
+// revoked, or that a concurrent request rotated first, is treated as theft and revokes every session of the
+// owner.
+func (s *AuthService) RefreshSession(ctx context.Context, refreshToken string) (*models.User, *models.Session, string, error) {
+	session, err := s.sessionRepo.GetByRefreshTokenHash(ctx, crypto.HashToken(refreshToken))
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, errors.ErrCodeInternal, "failed to look up session")
+	}
+	if session == nil {
+		return nil, nil, "", errors.New(errors.ErrCodeUnauthorized, "invalid refresh token")
+	}
+	if session.IsRevoked() {
+		s.revokeAfterReuse(ctx, session.UserID)
+		return nil, nil, "", errors.New(errors.ErrCodeUnauthorized, "refresh token has been revoked")
+	}
+	if !session.IsActive() {
+		return nil, nil, "", errors.New(errors.ErrCodeUnauthorized, "refresh token has expired")
+	}
+
+	user, err := s.GetUser(ctx, session.UserID)
+	if err != nil {
+		return nil, nil, "", errors.New(errors.ErrCodeUnauthorized, "invalid refresh token")
+	}
+
+	newRefreshToken, err := crypto.RandomToken(refreshTokenBytes)
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, errors.ErrCodeInternal, "failed to generate refresh token")
+	}
+	session.Rotate(crypto.HashToken(newRefreshToken))
+	rotated, err := s.sessionRepo.Rotate(ctx, session, crypto.HashToken(refreshToken))
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, errors.ErrCodeInternal, "failed to update session")
+	}
+	if !rotated {
+		// Another request rotated or revoked the session since it was read: the token was used twice.
+		s.revokeAfterReuse(ctx, session.UserID)
+		return nil, nil, "", errors.New(errors.ErrCodeUnauthorized, "refresh token has been revoked")
+	}
+
+	return user, session, newRefreshToken, nil
+}
+
+// revokeAfterReuse signs every device of a user out once one of their refresh tokens is presented twice.
+func (s *AuthService) revokeAfterReuse(ctx context.Context, userID uuid.UUID) {
+	if err := s.sessionRepo.RevokeAllByUserID(ctx, userID); err != nil {
+		log.Printf("failed to revoke sessions for user %s after refresh token reuse: %v", userID, err)
+	}
+}
+
+// ValidateSession reports an error unless sessionID is an active session belonging to userID.
+func (s *AuthService) ValidateSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to look up session")
+	}
+	if session == nil || session.UserID != userID || !session.IsActive() {
+		return errors.New(errors.ErrCodeUnauthorized, "session is no longer valid")
+	}
+	return nil
+}
+
+func (s *AuthService) ListSessions(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
+	sessions, err := s.sessionRepo.GetActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list sessions")
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs one of the user's devices out.
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to look up session")
+	}
+	if session == nil || session.UserID != userID {
+		return errors.New(errors.ErrCodeNotFound, "session not found")
+	}
+
+	session.Revoke()
+	if err := s.sessionRepo.Update(ctx, session); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to revoke session")
+	}
+	return nil
+}
+
+// rehashPassword stores a fresh hash for user. Failures are logged rather than returned so that a
+// successful login is never rejected because the upgrade could not be persisted.
+func (s *AuthService) rehashPassword(ctx context.Context, user *models.User, password string) {
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		log.Printf("failed to rehash password for user %s: %v", user.ID, err)
+		return
+	}
+
+	previous := user.Password
+	user.Password = hashedPassword
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		user.Password = previous
+		log.Printf("failed to store rehashed password for user %s: %v", user.ID, err)
+	}
+}
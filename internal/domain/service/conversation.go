@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/pkg/errors"
+)
+
+// conversationTitleChars is how much of the first question names a conversation created without a title.
+const conversationTitleChars = 50
+
+// citationMarker matches a citation together with the space before it.
+var citationMarker = regexp.MustCompile(`\s*` + citationPattern.String())
+
+// ConversationService keeps chat threads about documents. Every message is answered like a single
+// question, with the earlier turns of the thread sent along as context.
+type ConversationService struct {
+	conversationRepo repository.ConversationRepository
+	askService       *AskService
+}
+
+func NewConversationService(conversationRepo repository.ConversationRepository, askService *AskService) *ConversationService {
+	return &ConversationService{
+		conversationRepo: conversationRepo,
+		askService:       askService,
+	}
+}
+
+// CreateConversation starts a thread about a document the user owns. Without a title, the thread is
+// named after its first question.
+func (s *ConversationService) CreateConversation(ctx context.Context, userID, documentID uuid.UUID, title string) (*models.Conversation, error) {
+	if _, err := s.askService.docService.GetUserDocument(ctx, userID, documentID); err != nil {
+		return nil, err
+	}
+
+	conversation := models.NewConversation(userID, documentID, strings.TrimSpace(title))
+	if err := conversation.Validate(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeValidation, "invalid conversation data")
+	}
+	if err := s.conversationRepo.Create(ctx, conversation); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to create conversation")
+	}
+	return conversation, nil
+}
+
+// ListConversations lists the user's threads about a document, most recently active first.
+func (s *ConversationService) ListConversations(ctx context.Context, userID, documentID uuid.UUID) ([]*models.Conversation, error) {
+	if _, err := s.askService.docService.GetUserDocument(ctx, userID, documentID); err != nil {
+		return nil, err
+	}
+
+	conversations, err := s.conversationRepo.ListByDocumentID(ctx, userID, documentID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list conversations")
+	}
+	return conversations, nil
+}
+
+// GetConversation returns a thread the user owns. Threads of other users are reported as not found.
+func (s *ConversationService) GetConversation(ctx context.Context, userID, conversationID uuid.UUID) (*models.Conversation, error) {
+	conversation, err := s.conversationRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get conversation")
+	}
+	if conversation == nil || conversation.UserID != userID {
+		return nil, errors.New(errors.ErrCodeNotFound, "conversation not found")
+	}
+	return conversation, nil
+}
+
+// ListMessages returns the history of a thread the user owns, oldest first.
+func (s *ConversationService) ListMessages(ctx context.Context, userID, conversationID uuid.UUID) ([]*models.Message, error) {
+	if _, err := s.GetConversation(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	messages, err := s.conversationRepo.ListMessages(ctx, conversationID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list messages")
+	}
+	return messages, nil
+}
+
+// PostMessage adds the user's question to a thread and answers it. Both messages are saved only once the
+// model has answered, so a failed turn leaves the thread as it was.
+func (s *ConversationService) PostMessage(ctx context.Context, userID, conversationID uuid.UUID, content string) (*models.Message, *models.Message, error) {
+	question, err := validateQuestion(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	conversation, err := s.GetConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	document, err := s.askService.askableDocument(ctx, userID, conversation.DocumentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	previous, err := s.conversationRepo.ListMessages(ctx, conversationID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list messages")
+	}
+
+	userMessage := models.NewMessage(conversationID, llm.RoleUser, question)
+	answer, err := s.askService.answer(ctx, document, question, historyMessages(previous))
+	if err != nil {
+		return nil, nil, err
+	}
+	assistantMessage := models.NewMessage(conversationID, llm.RoleAssistant, answer.Content)
+	assistantMessage.Citations = answer.Citations
+	if err := assistantMessage.Validate(); err != nil {
+		return nil, nil, errors.New(errors.ErrCodeInternal, "LLM returned an empty answer")
+	}
+
+	for _, message := range []*models.Message{userMessage, assistantMessage} {
+		if err := s.conversationRepo.CreateMessage(ctx, message); err != nil {
+			return nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to save message")
+		}
+	}
+
+	if conversation.Title == "" {
+		conversation.Title = conversationTitle(question)
+	}
+	conversation.Touch()
+	if err := s.conversationRepo.Update(ctx, conversation); err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to update conversation")
+	}
+	return userMessage, assistantMessage, nil
+}
+
+// DeleteConversation removes a thread the user owns together with its messages.
+func (s *ConversationService) DeleteConversation(ctx context.Context, userID, conversationID uuid.UUID) error {
+	if _, err := s.GetConversation(ctx, userID, conversationID); err != nil {
+		return err
+	}
+	if err := s.conversationRepo.Delete(ctx, conversationID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete conversation")
+	}
+	return nil
+}
+
+// historyMessages turns stored messages into model messages. Citation markers are removed from earlier
+// answers, since they refer to passages that are not sent again.
+func historyMessages(messages []*models.Message) []llm.Message {
+	history := make([]llm.Message, 0, len(messages))
+	for _, message := range messages {
+		content := message.Content
+		if message.Role == llm.RoleAssistant {
+			content = strings.TrimSpace(citationMarker.ReplaceAllString(content, ""))
+		}
+		history = append(history, llm.Message{Role: message.Role, Content: content})
+	}
+	return history
+}
+
+// conversationTitle shortens a question to a conversation title.
+func conversationTitle(question string) string {
+	title := strings.Join(strings.Fields(question), " ")
+	if utf8.RuneCountInString(title) <= conversationTitleChars {
+		return title
+	}
+	return string([]rune(title)[:conversationTitleChars]) + "…"
+}
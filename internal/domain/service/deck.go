@@ -0,0 +1,336 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/pkg/errors"
+	"github/k-tsurumaki/quilldeck/internal/pkg/slides"
+)
+
+// deckFixedSlides are the slides every deck has besides its key points: the title, the agenda and the
+// next actions.
+const deckFixedSlides = 3
+
+// DeckService turns documents into slide decks for reporting to management and exports them as Marp
+// Markdown or PowerPoint files.
+type DeckService struct {
+	deckRepo   repository.DeckRepository
+	docService *DocumentService
+	artifacts  *ArtifactService
+	model      llm.LLM
+}
+
+func NewDeckService(deckRepo repository.DeckRepository, docService *DocumentService, artifacts *ArtifactService, model llm.LLM) *DeckService {
+	return &DeckService{
+		deckRepo:   deckRepo,
+		docService: docService,
+		artifacts:  artifacts,
+		model:      model,
+	}
+}
+
+// DeckFile is a deck rendered to a file format.
+type DeckFile struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// GenerateDeck asks the model for an outline of a document the user owns and stores it as a deck: a title
+// slide, an agenda, one slide per key point and the next actions. Documents too long for one prompt are
+// summarized first. The deck's PowerPoint and Marp files are kept as artifacts.
+func (s *DeckService) GenerateDeck(ctx context.Context, userID, documentID uuid.UUID, opts models.DeckOptions) (*models.Deck, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, errors.New(errors.ErrCodeValidation, err.Error())
+	}
+	opts = opts.WithDefaults()
+
+	document, err := s.docService.GetUserDocument(ctx, userID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(document.Content) == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "document has no content to make slides from")
+	}
+
+	source, err := s.docService.summarizer.Condense(ctx, document.Content, opts.Language)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to summarize document for slides")
+	}
+
+	sections := opts.SlideCount - deckFixedSlides
+	resp, err := s.model.Complete(ctx, llm.Request{Messages: []llm.Message{
+		{Role: llm.RoleSystem, Content: deckSystemPrompt},
+		{Role: llm.RoleUser, Content: deckPrompt(document.Title, source, sections, opts.Language)},
+	}})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get slide outline from LLM")
+	}
+	outline, err := parseDeckOutline(resp.Content)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "LLM returned an invalid slide outline")
+	}
+
+	deck, err := outline.deck(document, sections)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "LLM returned an invalid slide outline")
+	}
+	deck.DeckOptions = opts
+	if err := deck.Validate(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "LLM returned an invalid slide outline")
+	}
+	if err := s.deckRepo.Create(ctx, deck); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to create deck")
+	}
+	s.saveArtifacts(ctx, document, deck)
+	return deck, nil
+}
+
+// saveArtifacts keeps the deck's files as artifacts. The deck itself is already saved and can still be
+// exported, so failures are only logged.
+func (s *DeckService) saveArtifacts(ctx context.Context, document *models.Document, deck *models.Deck) {
+	for _, format := range []models.DeckFormat{models.DeckFormatPPTX, models.DeckFormatMarp} {
+		file, err := renderDeck(deck, format)
+		if err == nil {
+			_, err = s.artifacts.SaveArtifact(ctx, document, models.ArtifactKindDeck, file.Name, file.ContentType, map[string]any{
+				"deck_id":     deck.ID.String(),
+				"format":      string(format),
+				"slide_count": deck.SlideCount,
+				"language":    deck.Language,
+			}, file.Data)
+		}
+		if err != nil {
+			log.Printf("failed to save %s file of deck %s as an artifact: %v", format, deck.ID, err)
+		}
+	}
+}
+
+// GetDeck returns a deck of a document the user owns.
+func (s *DeckService) GetDeck(ctx context.Context, userID, deckID uuid.UUID) (*models.Deck, error) {
+	deck, err := s.deckRepo.GetByID(ctx, deckID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get deck")
+	}
+	if deck == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "deck not found")
+	}
+	if _, err := s.docService.GetUserDocument(ctx, userID, deck.DocumentID); err != nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "deck not found")
+	}
+	return deck, nil
+}
+
+// ListDecks lists the decks of a document the user owns, newest first.
+func (s *DeckService) ListDecks(ctx context.Context, userID, documentID uuid.UUID) ([]*models.Deck, error) {
+	if _, err := s.docService.GetUserDocument(ctx, userID, documentID); err != nil {
+		return nil, err
+	}
+
+	decks, err := s.deckRepo.GetByDocumentID(ctx, documentID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list decks")
+	}
+	return decks, nil
+}
+
+func (s *DeckService) DeleteDeck(ctx context.Context, userID, deckID uuid.UUID) error {
+	if _, err := s.GetDeck(ctx, userID, deckID); err != nil {
+		return err
+	}
+	if err := s.deckRepo.Delete(ctx, deckID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete deck")
+	}
+	return nil
+}
+
+// ExportDeck renders a deck of a document the user owns as a file to download.
+func (s *DeckService) ExportDeck(ctx context.Context, userID, deckID uuid.UUID, format models.DeckFormat) (*DeckFile, error) {
+	if !models.IsValidDeckFormat(format) {
+		return nil, errors.New(errors.ErrCodeValidation, "format must be one of marp, pptx")
+	}
+	deck, err := s.GetDeck(ctx, userID, deckID)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := renderDeck(deck, format)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to write "+string(format))
+	}
+	return file, nil
+}
+
+func renderDeck(deck *models.Deck, format models.DeckFormat) (*DeckFile, error) {
+	presentation := deckPresentation(deck)
+	name := fileName(deck.Title, "deck")
+	if format == models.DeckFormatMarp {
+		return &DeckFile{
+			Name:        name + ".md",
+			ContentType: "text/markdown; charset=utf-8",
+			Data:        []byte(slides.RenderMarp(presentation)),
+		}, nil
+	}
+
+	var buf bytes.Buffer
+	if err := slides.WritePPTX(&buf, presentation); err != nil {
+		return nil, err
+	}
+	return &DeckFile{Name: name + ".pptx", ContentType: slides.PPTXContentType, Data: buf.Bytes()}, nil
+}
+
+func deckPresentation(deck *models.Deck) slides.Presentation {
+	presentation := slides.Presentation{
+		Title:    deck.Title,
+		Language: deck.Language,
+		Created:  deck.CreatedAt,
+		Slides:   make([]slides.Slide, 0, len(deck.Slides)),
+	}
+	for _, slide := range deck.Slides {
+		presentation.Slides = append(presentation.Slides, slides.Slide{
+			Cover:    slide.Kind == models.SlideKindTitle,
+			Title:    slide.Title,
+			Subtitle: slide.Subtitle,
+			Bullets:  slide.Bullets,
+			Notes:    slide.Notes,
+		})
+	}
+	return presentation
+}
+
+// fileName makes a file name from a title, without characters file systems reject, using fallback for
+// titles with nothing left.
+func fileName(title, fallback string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, strings.Join(strings.Fields(title), " "))
+	if utf8.RuneCountInString(name) > 100 {
+		name = string([]rune(name)[:100])
+	}
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+// deckOutline is the JSON the model is asked to reply with.
+type deckOutline struct {
+	Title        string `json:"title"`
+	Subtitle     string `json:"subtitle"`
+	AgendaTitle  string `json:"agenda_title"`
+	ActionsTitle string `json:"actions_title"`
+	Sections     []struct {
+		Title   string   `json:"title"`
+		Bullets []string `json:"bullets"`
+		Notes   string   `json:"notes"`
+	} `json:"sections"`
+	NextActions []string `json:"next_actions"`
+}
+
+func parseDeckOutline(reply string) (*deckOutline, error) {
+	var outline deckOutline
+	if err := unmarshalReply(reply, &outline); err != nil {
+		return nil, err
+	}
+	return &outline, nil
+}
+
+// unmarshalReply reads the JSON object of a reply into v, ignoring any text or code fence around it.
+func unmarshalReply(reply string, v any) error {
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return fmt.Errorf("no JSON object in reply")
+	}
+	return json.Unmarshal([]byte(reply[start:end+1]), v)
+}
+
+// deck lays the outline out as slides, keeping at most sections key-point slides of at most
+// models.MaxSlideBullets bullets each.
+func (o *deckOutline) deck(document *models.Document, sections int) (*models.Deck, error) {
+	title := strings.TrimSpace(o.Title)
+	if title == "" {
+		title = document.Title
+	}
+
+	var points []models.Slide
+	for _, section := range o.Sections {
+		if len(points) == sections {
+			break
+		}
+		if strings.TrimSpace(section.Title) == "" {
+			continue
+		}
+		points = append(points, models.Slide{
+			Kind:    models.SlideKindPoints,
+			Title:   strings.TrimSpace(section.Title),
+			Bullets: cleanBullets(section.Bullets),
+			Notes:   strings.TrimSpace(section.Notes),
+		})
+	}
+
+	if len(points) == 0 {
+		return nil, fmt.Errorf("outline has no sections")
+	}
+
+	agenda := make([]string, len(points))
+	for i, point := range points {
+		agenda[i] = point.Title
+	}
+
+	deckSlides := []models.Slide{
+		{Kind: models.SlideKindTitle, Title: title, Subtitle: strings.TrimSpace(o.Subtitle)},
+		{Kind: models.SlideKindAgenda, Title: orDefault(o.AgendaTitle, "Agenda"), Bullets: agenda},
+	}
+	deckSlides = append(deckSlides, points...)
+	if actions := cleanBullets(o.NextActions); len(actions) > 0 {
+		deckSlides = append(deckSlides, models.Slide{Kind: models.SlideKindActions, Title: orDefault(o.ActionsTitle, "Next Actions"), Bullets: actions})
+	}
+	return models.NewDeck(document.ID, title, deckSlides), nil
+}
+
+func cleanBullets(bullets []string) []string {
+	cleaned := make([]string, 0, min(len(bullets), models.MaxSlideBullets))
+	for _, bullet := range bullets {
+		if bullet = strings.TrimSpace(bullet); bullet != "" && len(cleaned) < models.MaxSlideBullets {
+			cleaned = append(cleaned, bullet)
+		}
+	}
+	return cleaned
+}
+
+func orDefault(value, fallback string) string {
+	if value = strings.TrimSpace(value); value != "" {
+		return value
+	}
+	return fallback
+}
+
+const deckSystemPrompt = "You turn documents into slide decks that report on them to management. " +
+	"Reply with a single JSON object and nothing else."
+
+func deckPrompt(title, content string, sections int, language string) string {
+	return fmt.Sprintf(`Create the outline of a slide deck that reports on the document below to management, in %s.
+Reply with JSON of this shape:
+{"title": "deck title", "subtitle": "one line, such as the purpose or the audience",
+ "agenda_title": "title of the agenda slide", "actions_title": "title of the next actions slide",
+ "sections": [{"title": "slide title", "bullets": ["short key point"], "notes": "what the presenter says"}],
+ "next_actions": ["concrete next step"]}
+Write exactly %d sections in the order they should be presented, each with 2 to %d short bullets, and 2 to %d next actions.
+The title slide, the agenda and the next actions get slides of their own, so do not add them as sections.
+
+Document %q:
+
+%s`, languageName(language), sections, models.MaxSlideBullets, models.MaxSlideBullets, title, content)
+}
@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/pkg/diagrams"
+	"github/k-tsurumaki/quilldeck/internal/pkg/errors"
+)
+
+// diagramAttempts bounds the requests for one set of diagrams: the first, and one more asking the model
+// to fix the diagrams that failed validation.
+const diagramAttempts = 2
+
+// DiagramService proposes Mermaid and PlantUML diagrams for documents. Every diagram is validated before
+// it is stored, so users are never handed one that fails to render.
+type DiagramService struct {
+	diagramRepo repository.DiagramRepository
+	docService  *DocumentService
+	artifacts   *ArtifactService
+	model       llm.LLM
+}
+
+func NewDiagramService(diagramRepo repository.DiagramRepository, docService *DocumentService, artifacts *ArtifactService, model llm.LLM) *DiagramService {
+	return &DiagramService{
+		diagramRepo: diagramRepo,
+		docService:  docService,
+		artifacts:   artifacts,
+		model:       model,
+	}
+}
+
+// GenerateDiagrams proposes one diagram of each requested kind for a document the user owns. Kinds the
+// model fails to draw validly are left out; it is an error only when none is valid.
+func (s *DiagramService) GenerateDiagrams(ctx context.Context, userID, documentID uuid.UUID, opts models.DiagramOptions) ([]*models.Diagram, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, errors.New(errors.ErrCodeValidation, err.Error())
+	}
+	opts = opts.WithDefaults()
+
+	document, err := s.docService.GetUserDocument(ctx, userID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.generate(ctx, document, opts.Syntax, opts.Language, opts.Kinds, nil)
+}
+
+// RegenerateDiagram proposes count alternatives to a diagram, of the same kind and syntax but drawn
+// differently. The diagram itself is kept.
+func (s *DiagramService) RegenerateDiagram(ctx context.Context, userID, diagramID uuid.UUID, count int) ([]*models.Diagram, error) {
+	if count == 0 {
+		count = models.DefaultDiagramAlternatives
+	}
+	if count < 1 || count > models.MaxDiagramAlternatives {
+		return nil, errors.New(errors.ErrCodeValidation, fmt.Sprintf("count must be between 1 and %d", models.MaxDiagramAlternatives))
+	}
+
+	original, err := s.GetDiagram(ctx, userID, diagramID)
+	if err != nil {
+		return nil, err
+	}
+	document, err := s.docService.GetUserDocument(ctx, userID, original.DocumentID)
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := make([]models.DiagramKind, count)
+	for i := range kinds {
+		kinds[i] = original.Kind
+	}
+	return s.generate(ctx, document, original.Syntax, original.Language, kinds, original)
+}
+
+// generate asks the model for a diagram of each of kinds and stores the valid ones. When some are
+// invalid or missing, the model is told what was wrong and asked once more for those. The source of each
+// stored diagram is also kept as an artifact.
+func (s *DiagramService) generate(ctx context.Context, document *models.Document, syntax models.DiagramSyntax, language string, kinds []models.DiagramKind, previous *models.Diagram) ([]*models.Diagram, error) {
+	if strings.TrimSpace(document.Content) == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "document has no content to draw diagrams from")
+	}
+	source, err := s.docService.summarizer.Condense(ctx, document.Content, language)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to summarize document for diagrams")
+	}
+
+	messages := []llm.Message{
+		{Role: llm.RoleSystem, Content: diagramSystemPrompt},
+		{Role: llm.RoleUser, Content: diagramPrompt(document.Title, source, syntax, language, kinds, previous)},
+	}
+	var result []*models.Diagram
+	missing := kinds
+	for attempt := 1; ; attempt++ {
+		resp, err := s.model.Complete(ctx, llm.Request{Messages: messages})
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get diagrams from LLM")
+		}
+
+		var accepted []*models.Diagram
+		var problems []string
+		accepted, missing, problems = readDiagrams(resp.Content, document.ID, syntax, language, missing)
+		result = append(result, accepted...)
+		if len(missing) == 0 || attempt == diagramAttempts {
+			break
+		}
+		messages = append(messages,
+			llm.Message{Role: llm.RoleAssistant, Content: resp.Content},
+			llm.Message{Role: llm.RoleUser, Content: diagramRepairPrompt(problems, missing)},
+		)
+	}
+	if len(result) == 0 {
+		return nil, errors.New(errors.ErrCodeInternal, "LLM did not produce a valid diagram")
+	}
+
+	for _, diagram := range result {
+		if err := s.diagramRepo.Create(ctx, diagram); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to create diagram")
+		}
+		s.saveArtifact(ctx, document, diagram)
+	}
+	return result, nil
+}
+
+// diagramExtensions are the usual file extensions of each syntax.
+var diagramExtensions = map[models.DiagramSyntax]string{
+	models.DiagramSyntaxMermaid:  ".mmd",
+	models.DiagramSyntaxPlantUML: ".puml",
+}
+
+// saveArtifact keeps a diagram's source as an artifact. The diagram itself is already saved, so failures
+// are only logged.
+func (s *DiagramService) saveArtifact(ctx context.Context, document *models.Document, diagram *models.Diagram) {
+	_, err := s.artifacts.SaveArtifact(ctx, document, models.ArtifactKindDiagram,
+		fileName(diagram.Title, "diagram")+diagramExtensions[diagram.Syntax], "text/plain; charset=utf-8",
+		map[string]any{
+			"diagram_id": diagram.ID.String(),
+			"kind":       string(diagram.Kind),
+			"syntax":     string(diagram.Syntax),
+			"language":   diagram.Language,
+		}, []byte(diagram.Source))
+	if err != nil {
+		log.Printf("failed to save diagram %s as an artifact: %v", diagram.ID, err)
+	}
+}
+
+// diagramReply is the JSON the model is asked to reply with.
+type diagramReply struct {
+	Diagrams []struct {
+		Kind        string `json:"kind"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Source      string `json:"source"`
+	} `json:"diagrams"`
+}
+
+// readDiagrams takes the valid diagrams of wanted kinds from a reply. It returns the kinds still
+// missing and, for the model, what was wrong with the reply.
+func readDiagrams(reply string, documentID uuid.UUID, syntax models.DiagramSyntax, language string, wanted []models.DiagramKind) ([]*models.Diagram, []models.DiagramKind, []string) {
+	var parsed diagramReply
+	if err := unmarshalReply(reply, &parsed); err != nil {
+		return nil, wanted, []string{fmt.Sprintf("The reply was not the JSON object asked for: %v.", err)}
+	}
+
+	missing := append([]models.DiagramKind(nil), wanted...)
+	var accepted []*models.Diagram
+	var problems []string
+	for _, candidate := range parsed.Diagrams {
+		kind := models.DiagramKind(strings.ToLower(strings.TrimSpace(candidate.Kind)))
+		i := indexOfKind(missing, kind)
+		if i < 0 {
+			continue
+		}
+
+		diagram := models.NewDiagram(documentID, kind, syntax, strings.TrimSpace(candidate.Title), stripCodeFence(candidate.Source))
+		diagram.Description = strings.TrimSpace(candidate.Description)
+		diagram.Language = language
+		if err := validateDiagram(diagram); err != nil {
+			problems = append(problems, fmt.Sprintf("The %s diagram %q is invalid: %v.", kind, diagram.Title, err))
+			continue
+		}
+		accepted = append(accepted, diagram)
+		missing = append(missing[:i], missing[i+1:]...)
+	}
+	return accepted, missing, problems
+}
+
+func indexOfKind(kinds []models.DiagramKind, kind models.DiagramKind) int {
+	for i, k := range kinds {
+		if k == kind {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateDiagram checks the fields of a diagram and that its source renders as the kind it claims.
+func validateDiagram(diagram *models.Diagram) error {
+	if err := diagram.Validate(); err != nil {
+		return err
+	}
+	var kind diagrams.Kind
+	var err error
+	if diagram.Syntax == models.DiagramSyntaxPlantUML {
+		kind, err = diagrams.ValidatePlantUML(diagram.Source)
+	} else {
+		kind, err = diagrams.ValidateMermaid(diagram.Source)
+	}
+	if err != nil {
+		return err
+	}
+	if string(kind) != string(diagram.Kind) {
+		return fmt.Errorf("the source draws a %s, not a %s", kind, diagram.Kind)
+	}
+	return nil
+}
+
+// stripCodeFence removes a Markdown code fence the model may put around a source.
+func stripCodeFence(source string) string {
+	source = strings.TrimSpace(source)
+	if !strings.HasPrefix(source, "```") {
+		return source
+	}
+	if i := strings.IndexByte(source, '\n'); i >= 0 {
+		source = source[i+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(source), "```"))
+}
+
+// GetDiagram returns a diagram of a document the user owns.
+func (s *DiagramService) GetDiagram(ctx context.Context, userID, diagramID uuid.UUID) (*models.Diagram, error) {
+	diagram, err := s.diagramRepo.GetByID(ctx, diagramID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get diagram")
+	}
+	if diagram == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "diagram not found")
+	}
+	if _, err := s.docService.GetUserDocument(ctx, userID, diagram.DocumentID); err != nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "diagram not found")
+	}
+	return diagram, nil
+}
+
+// ListDiagrams lists the diagrams of a document the user owns, newest first.
+func (s *DiagramService) ListDiagrams(ctx context.Context, userID, documentID uuid.UUID) ([]*models.Diagram, error) {
+	if _, err := s.docService.GetUserDocument(ctx, userID, documentID); err != nil {
+		return nil, err
+	}
+
+	result, err := s.diagramRepo.GetByDocumentID(ctx, documentID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list diagrams")
+	}
+	return result, nil
+}
+
+func (s *DiagramService) DeleteDiagram(ctx context.Context, userID, diagramID uuid.UUID) error {
+	if _, err := s.GetDiagram(ctx, userID, diagramID); err != nil {
+		return err
+	}
+	if err := s.diagramRepo.Delete(ctx, diagramID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete diagram")
+	}
+	return nil
+}
+
+const diagramSystemPrompt = "You draw diagrams that explain documents, as Mermaid or PlantUML source. " +
+	"Reply with a single JSON object and nothing else."
+
+// diagramSyntaxRules steer the model away from the constructs the validator rejects.
+var diagramSyntaxRules = map[models.DiagramSyntax]string{
+	models.DiagramSyntaxMermaid: `Write Mermaid. Start a flowchart with "flowchart TD", a sequence diagram with "sequenceDiagram" and a mindmap with "mindmap".
+Put node labels that contain brackets, quotes or punctuation in double quotes, like A["Budget (draft)"]. Never use "end" as a node id.
+Write sequence messages as A->>B: text, without semicolons in the text. Give a mindmap a single root, with every other node indented under it.`,
+	models.DiagramSyntaxPlantUML: `Write PlantUML. Draw a flowchart as an activity diagram and a sequence diagram between @startuml and @enduml, and a mindmap between @startmindmap and @endmindmap with * nodes.
+End every activity with ;, close every if with endif and every alt, loop or group with end. Give a mindmap a single root.`,
+}
+
+var diagramKindDescriptions = map[models.DiagramKind]string{
+	models.DiagramKindFlowchart: "a flowchart of the process, decisions or structure the document describes",
+	models.DiagramKindSequence:  "a sequence diagram of the interactions between the people or systems in the document",
+	models.DiagramKindMindmap:   "a mindmap of the document's main topics and their key points",
+}
+
+func diagramPrompt(title, content string, syntax models.DiagramSyntax, language string, kinds []models.DiagramKind, previous *models.Diagram) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Propose diagrams that help explain the document below, with titles, descriptions and labels in %s:\n", languageName(language))
+	for _, kind := range kinds {
+		fmt.Fprintf(&b, "- %s (kind %q)\n", diagramKindDescriptions[kind], kind)
+	}
+	if previous != nil {
+		fmt.Fprintf(&b, "These are alternatives to the diagram below. Make each one differ from it and from each other in structure or viewpoint, not just wording:\n%s\n", previous.Source)
+	}
+	fmt.Fprintf(&b, `%s
+Reply with JSON of this shape:
+{"diagrams": [{"kind": "flowchart, sequence or mindmap", "title": "short title", "description": "one sentence on what the diagram shows", "source": "the diagram source"}]}
+
+Document %q:
+
+%s`, diagramSyntaxRules[syntax], title, content)
+	return b.String()
+}
+
+// diagramRepairPrompt asks for the diagrams still missing, with what was wrong with the previous reply.
+func diagramRepairPrompt(problems []string, missing []models.DiagramKind) string {
+	names := make([]string, len(missing))
+	for i, kind := range missing {
+		names[i] = string(kind)
+	}
+	if len(problems) == 0 {
+		problems = []string{"Some of the diagrams asked for were missing."}
+	}
+	return fmt.Sprintf("%s\nReply with JSON of the same shape containing only these diagrams: %s.",
+		strings.Join(problems, "\n"), strings.Join(names, ", "))
+}
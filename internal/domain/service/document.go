@@ -1,18 +1,26 @@
 package service
 
 import (
-	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"log"
-	"net/http"
+	"path"
+	"strings"
 	"time"
 
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
 	"github/k-tsurumaki/quilldeck/internal/domain/models"
 	"github/k-tsurumaki/quilldeck/internal/domain/repository"
 	"github/k-tsurumaki/quilldeck/internal/pkg/errors"
+	"github/k-tsurumaki/quilldeck/internal/pkg/ooxml"
+	"github/k-tsurumaki/quilldeck/internal/pkg/pdf"
+	"github/k-tsurumaki/quilldeck/internal/pkg/textenc"
+	"github/k-tsurumaki/quilldeck/internal/pkg/webpage"
+	"github/k-tsurumaki/quilldeck/internal/pkg/ziparchive"
 
 	"github.com/google/uuid"
 )
@@ -20,86 +28,411 @@ import (
 type DocumentService struct {
 	docRepo     repository.DocumentRepository
 	summaryRepo repository.SummaryRepository
-	llmClinet   *LLMClient
+	// blobs keeps the uploaded files of documents converted from other formats.
+	blobs      repository.BlobStore
+	summarizer *Summarizer
 }
 
-type LLMClient struct {
-	apiKey  string
-	baseURL string
-	model   string
-	client  *http.Client
+func NewDocumentService(docRepo repository.DocumentRepository, summaryRepo repository.SummaryRepository, blobs repository.BlobStore, model llm.LLM, summarizerOpts SummarizerOptions) *DocumentService {
+	return &DocumentService{
+		docRepo:     docRepo,
+		summaryRepo: summaryRepo,
+		blobs:       blobs,
+		summarizer:  NewSummarizer(model, summarizerOpts),
+	}
 }
 
-type LLMRequest struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
+// UploadFile creates a document from an uploaded file of the given type: text files decoded to UTF-8, and
+// other formats from the text extracted from them.
+func (s *DocumentService) UploadFile(ctx context.Context, userID uuid.UUID, filename string, docType models.DocumentType, data []byte) (*models.Document, error) {
+	switch docType {
+	case models.DocumentTypeTXT, models.DocumentTypeMD:
+		return s.UploadText(ctx, userID, filename, docType, data)
+	case models.DocumentTypePDF:
+		return s.UploadPDF(ctx, userID, filename, data)
+	case models.DocumentTypeDOCX, models.DocumentTypePPTX, models.DocumentTypeXLSX:
+		return s.UploadOffice(ctx, userID, filename, docType, data)
+	case models.DocumentTypeHTML:
+		return s.UploadWebPage(ctx, userID, filename, data)
+	}
+	return nil, errors.New(errors.ErrCodeValidation, "unsupported document type")
 }
 
-type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+// UploadResult is what became of one file of a batch upload: the document created from it, or the error it
+// was rejected with.
+type UploadResult struct {
+	// Filename names the file, by its path in Archive for files that came in a zip archive.
+	Filename string
+	Archive  string
+	Document *models.Document
+	Err      error
 }
 
-type LLMResponse struct {
-	ID      string `json:"id"`
-	Model   string `json:"model"`
-	Object  string `json:"object"`
-	Created int64  `json:"created"`
-	Choices []struct {
-		Index        int     `json:"index"`
-		FinishReason string  `json:"finish_reason"`
-		Message      Message `json:"message"`
-	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
-}
+// MaxFileSize bounds each uploaded file, alone, in a batch or in a zip archive. Files are read into memory
+// whole to extract their text.
+const MaxFileSize = ziparchive.MaxFileSize
+
+// UploadBatch creates documents from one file of a batch upload, of size bytes: from each of the files in
+// a zip archive, or from the file itself. Archives are read from file as they are expanded, one file at a
+// time, rather than loaded whole. Files are rejected one by one, so that one unreadable file does not keep
+// the rest out; archives past the limits of ziparchive, and archives inside archives, are rejected whole,
+// except that an archive found to expand to more than it declared stops where it is found out.
+func (s *DocumentService) UploadBatch(ctx context.Context, userID uuid.UUID, filename string, file io.ReaderAt, size int64) []UploadResult {
+	if !isZipArchive(filename) {
+		if size > MaxFileSize {
+			return []UploadResult{{Filename: filename, Err: errFileTooLarge()}}
+		}
+		data, err := io.ReadAll(io.NewSectionReader(file, 0, size))
+		if err != nil {
+			return []UploadResult{{Filename: filename, Err: errors.Wrap(err, errors.ErrCodeInternal, "failed to read file")}}
+		}
+		document, err := s.uploadNamed(ctx, userID, filename, data)
+		return []UploadResult{{Filename: filename, Document: document, Err: err}}
+	}
 
-// NewLLMClient creates a new instance of LLMClient with configuration from the global Config.
-func NewLLMClient(apiKey, baseURL, model string) *LLMClient {
-	return &LLMClient{
-		apiKey:  apiKey,
-		baseURL: baseURL,
-		model:   model,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+	var results []UploadResult
+	err := ziparchive.Walk(file, size, func(file ziparchive.File) error {
+		result := UploadResult{Filename: file.Name, Archive: filename}
+		switch {
+		case stderrors.Is(file.Err, ziparchive.ErrFileTooLarge):
+			result.Err = errFileTooLarge()
+		case file.Err != nil:
+			result.Err = errors.Wrap(file.Err, errors.ErrCodeValidation, "the file's path leads out of the archive")
+		case isZipArchive(file.Name):
+			result.Err = errors.New(errors.ErrCodeValidation, "archives inside archives are not expanded")
+		default:
+			result.Document, result.Err = s.uploadNamed(ctx, userID, file.Name, file.Data)
+		}
+		results = append(results, result)
+		return nil
+	})
+	switch {
+	case stderrors.Is(err, ziparchive.ErrTooManyFiles):
+		err = errors.Wrap(err, errors.ErrCodeValidation, fmt.Sprintf("the archive holds more than %d files", ziparchive.MaxFiles))
+	case stderrors.Is(err, ziparchive.ErrTooLarge):
+		err = errors.Wrap(err, errors.ErrCodeValidation, fmt.Sprintf("the archive expands to more than %d MB", ziparchive.MaxTotalSize>>20))
+	case err != nil:
+		err = errors.Wrap(err, errors.ErrCodeValidation, "the file is not a readable zip archive")
 	}
+	if err != nil {
+		results = append(results, UploadResult{Filename: filename, Err: err})
+	}
+	return results
 }
 
-func NewDocumentService(docRepo repository.DocumentRepository, summaryRepo repository.SummaryRepository, llmAPIKey string, llmBaseURL string, llmModel string) *DocumentService {
-	llmClient := NewLLMClient(llmAPIKey, llmBaseURL, llmModel)
-	return &DocumentService{
-		docRepo:     docRepo,
-		summaryRepo: summaryRepo,
-		llmClinet:   llmClient,
+func errFileTooLarge() error {
+	return errors.New(errors.ErrCodeValidation, fmt.Sprintf("the file is larger than %d MB", MaxFileSize>>20))
+}
+
+// uploadNamed creates a document from a file of the type its name says.
+func (s *DocumentService) uploadNamed(ctx context.Context, userID uuid.UUID, filename string, data []byte) (*models.Document, error) {
+	docType, ok := models.DocumentTypeForFilename(filename)
+	if !ok {
+		return nil, errors.New(errors.ErrCodeValidation, "unsupported file type")
 	}
+	return s.UploadFile(ctx, userID, filename, docType, data)
+}
+
+func isZipArchive(filename string) bool {
+	return strings.EqualFold(path.Ext(filename), ".zip")
 }
 
 func (s *DocumentService) UploadDocument(ctx context.Context, userID uuid.UUID, title, content string, docType models.DocumentType) (*models.Document, error) {
 	document := models.NewDocument(userID, title, content, docType, int64(len(content)))
+	return s.createDocument(ctx, document, nil)
+}
+
+// UploadText creates a document from a text or Markdown file in whatever encoding it was written in, such
+// as the Shift_JIS or EUC-JP of older Japanese systems. The text is stored as normalized UTF-8 and the
+// encoding it was detected in is recorded on the document.
+func (s *DocumentService) UploadText(ctx context.Context, userID uuid.UUID, title string, docType models.DocumentType, data []byte) (*models.Document, error) {
+	content, encoding := textenc.Decode(data, "")
+	document := models.NewDocument(userID, title, content, docType, int64(len(content)))
+	document.SourceEncoding = encoding
+	return s.createDocument(ctx, document, nil)
+}
+
+// UploadPDF creates a document from the text of a PDF file, remembering where each page is in it so that
+// citations can name their page. Files without text, such as scans, are rejected.
+func (s *DocumentService) UploadPDF(ctx context.Context, userID uuid.UUID, title string, data []byte) (*models.Document, error) {
+	pages, err := pdf.ExtractText(data)
+	if stderrors.Is(err, pdf.ErrEncrypted) {
+		return nil, errors.Wrap(err, errors.ErrCodeValidation, "encrypted PDF files are not supported")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeValidation, "the file is not a readable PDF")
+	}
+
+	content, spans := models.JoinPages(pages)
+	if strings.TrimSpace(content) == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "the PDF has no text to extract; scanned PDFs are not supported")
+	}
+	document := models.NewDocument(userID, title, content, models.DocumentTypePDF, int64(len(content)))
+	document.Pages = spans
+	return s.createDocument(ctx, document, data)
+}
+
+// officeExtractors convert Office Open XML files to Markdown.
+var officeExtractors = map[models.DocumentType]func([]byte) (string, error){
+	models.DocumentTypeDOCX: ooxml.ExtractDOCX,
+	models.DocumentTypePPTX: ooxml.ExtractPPTX,
+	models.DocumentTypeXLSX: ooxml.ExtractXLSX,
+}
+
+// UploadOffice creates a document from a Word, PowerPoint or Excel file, converted to Markdown. The file
+// itself is kept as the document's original.
+func (s *DocumentService) UploadOffice(ctx context.Context, userID uuid.UUID, title string, docType models.DocumentType, data []byte) (*models.Document, error) {
+	extract, ok := officeExtractors[docType]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeValidation, "unsupported Office document type")
+	}
+	content, err := extract(data)
+	if stderrors.Is(err, ooxml.ErrEncrypted) {
+		return nil, errors.Wrap(err, errors.ErrCodeValidation, "password-protected Office files are not supported")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeValidation, fmt.Sprintf("the file is not a readable .%s file", docType))
+	}
+	if strings.TrimSpace(content) == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "the file has no text to extract")
+	}
+
+	document := models.NewDocument(userID, title, content, docType, int64(len(content)))
+	return s.createDocument(ctx, document, data)
+}
+
+// UploadWebPage creates a document from the main content of a web page saved as HTML or as an MHTML web
+// archive, converted to Markdown without the navigation and other boilerplate around it. The page's title
+// and canonical URL are kept as metadata, and the file itself as the document's original.
+func (s *DocumentService) UploadWebPage(ctx context.Context, userID uuid.UUID, title string, data []byte) (*models.Document, error) {
+	page, err := webpage.Extract(data)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeValidation, "the file is not a readable web page")
+	}
+	if strings.TrimSpace(page.Content) == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "the page has no text to extract")
+	}
+
+	document := models.NewDocument(userID, title, page.Content, models.DocumentTypeHTML, int64(len(page.Content)))
+	document.SourceEncoding = page.Encoding
+	document.Metadata = make(map[string]string)
+	if page.Title != "" {
+		document.Metadata[models.MetadataTitle] = page.Title
+	}
+	if page.CanonicalURL != "" {
+		document.Metadata[models.MetadataCanonicalURL] = page.CanonicalURL
+	}
+	return s.createDocument(ctx, document, data)
+}
 
+// createDocument saves a new document, with the file it was converted from when original is not nil.
+func (s *DocumentService) createDocument(ctx context.Context, document *models.Document, original []byte) (*models.Document, error) {
 	if err := document.Validate(); err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeValidation, "invalid document data")
 	}
 
+	if original != nil {
+		document.OriginalKey = originalStorageKey(document.ID)
+		if err := s.blobs.Put(ctx, document.OriginalKey, original); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to store the original file")
+		}
+	}
 	if err := s.docRepo.Create(ctx, document); err != nil {
+		if original != nil {
+			if err := s.blobs.Delete(context.WithoutCancel(ctx), document.OriginalKey); err != nil {
+				log.Printf("failed to remove the original file of unsaved document %s: %v", document.ID, err)
+			}
+		}
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to create document")
 	}
 
 	return document, nil
 }
 
+// originalStorageKey spreads original files over directories by the first characters of their document's
+// ID, as artifacts are.
+func originalStorageKey(id uuid.UUID) string {
+	name := id.String()
+	return "originals/" + name[:2] + "/" + name
+}
+
+// GetOriginal returns the file a document the user owns was converted from.
+func (s *DocumentService) GetOriginal(ctx context.Context, userID, documentID uuid.UUID) (*models.Document, []byte, error) {
+	document, err := s.GetUserDocument(ctx, userID, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if document.OriginalKey == "" {
+		return nil, nil, errors.New(errors.ErrCodeNotFound, "the document has no original file")
+	}
+
+	data, err := s.blobs.Get(ctx, document.OriginalKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to read the original file")
+	}
+	if data == nil {
+		return nil, nil, errors.New(errors.ErrCodeNotFound, "the original file is missing")
+	}
+	return document, data, nil
+}
+
 func (s *DocumentService) GetDocument(ctx context.Context, documentID uuid.UUID) (*models.Document, error) {
 	document, err := s.docRepo.GetByID(ctx, documentID)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeNotFound, "document not found")
 	}
+	if document == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "document not found")
+	}
+	return document, nil
+}
+
+// GetUserDocument returns the document only if it belongs to userID.
+// Documents owned by someone else are reported as not found so their existence is not leaked.
+func (s *DocumentService) GetUserDocument(ctx context.Context, userID, documentID uuid.UUID) (*models.Document, error) {
+	document, err := s.GetDocument(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if document.UserID != userID {
+		return nil, errors.New(errors.ErrCodeNotFound, "document not found")
+	}
 	return document, nil
 }
 
+const (
+	defaultDocumentPageSize = 20
+	maxDocumentPageSize     = 100
+)
+
+type ListDocumentsParams struct {
+	Type          models.DocumentType
+	Processed     *bool
+	UploadedFrom  *time.Time
+	UploadedTo    *time.Time
+	TitleContains string
+	SortBy        repository.DocumentSortField
+	SortOrder     repository.SortOrder
+	Cursor        string
+	Limit         int
+}
+
+type DocumentPage struct {
+	Documents  []*models.DocumentListItem
+	NextCursor string
+}
+
+// documentCursor is serialized into the opaque cursor handed to clients. It remembers the sort it was
+// issued for so that a cursor cannot be replayed against a different ordering.
+type documentCursor struct {
+	SortBy    repository.DocumentSortField `json:"s"`
+	SortOrder repository.SortOrder         `json:"o"`
+	repository.DocumentCursor
+}
+
+// ListUserDocuments returns one page of the user's documents, without content, using keyset pagination.
+func (s *DocumentService) ListUserDocuments(ctx context.Context, userID uuid.UUID, params ListDocumentsParams) (*DocumentPage, error) {
+	opts, err := documentListOptions(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row to learn whether another page exists.
+	limit := opts.Limit
+	opts.Limit = limit + 1
+	items, err := s.docRepo.ListByUserID(ctx, userID, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list documents")
+	}
+
+	page := &DocumentPage{Documents: items}
+	if len(items) > limit {
+		page.Documents = items[:limit]
+		last := page.Documents[limit-1]
+		page.NextCursor, err = encodeDocumentCursor(documentCursor{
+			SortBy:    opts.SortBy,
+			SortOrder: opts.SortOrder,
+			DocumentCursor: repository.DocumentCursor{
+				UploadedAt: last.UploadedAt,
+				Title:      last.Title,
+				Size:       last.Size,
+				ID:         last.ID,
+			},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to encode cursor")
+		}
+	}
+	return page, nil
+}
+
+func documentListOptions(params ListDocumentsParams) (repository.DocumentListOptions, error) {
+	opts := repository.DocumentListOptions{
+		Type:          params.Type,
+		Processed:     params.Processed,
+		UploadedFrom:  params.UploadedFrom,
+		UploadedTo:    params.UploadedTo,
+		TitleContains: params.TitleContains,
+		SortBy:        params.SortBy,
+		SortOrder:     params.SortOrder,
+		Limit:         params.Limit,
+	}
+
+	if opts.Type != "" && !models.IsValidDocumentType(opts.Type) {
+		return opts, errors.New(errors.ErrCodeValidation, "invalid document type filter")
+	}
+	switch opts.SortBy {
+	case "":
+		opts.SortBy = repository.DocumentSortUploadedAt
+	case repository.DocumentSortUploadedAt, repository.DocumentSortTitle, repository.DocumentSortSize:
+	default:
+		return opts, errors.New(errors.ErrCodeValidation, "sort must be one of uploaded_at, title, size")
+	}
+	switch opts.SortOrder {
+	case "":
+		opts.SortOrder = repository.SortDesc
+	case repository.SortAsc, repository.SortDesc:
+	default:
+		return opts, errors.New(errors.ErrCodeValidation, "order must be asc or desc")
+	}
+	if opts.UploadedFrom != nil && opts.UploadedTo != nil && !opts.UploadedFrom.Before(*opts.UploadedTo) {
+		return opts, errors.New(errors.ErrCodeValidation, "uploaded_from must be before uploaded_to")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = defaultDocumentPageSize
+	}
+	if opts.Limit > maxDocumentPageSize {
+		opts.Limit = maxDocumentPageSize
+	}
+
+	if params.Cursor != "" {
+		cursor, err := decodeDocumentCursor(params.Cursor)
+		if err != nil || cursor.SortBy != opts.SortBy || cursor.SortOrder != opts.SortOrder {
+			return opts, errors.New(errors.ErrCodeValidation, "invalid cursor for this listing")
+		}
+		opts.After = &cursor.DocumentCursor
+	}
+	return opts, nil
+}
+
+func encodeDocumentCursor(cursor documentCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeDocumentCursor(encoded string) (documentCursor, error) {
+	var cursor documentCursor
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
+}
+
 func (s *DocumentService) GetUserDocuments(ctx context.Context, userID uuid.UUID) ([]*models.Document, error) {
 	documents, err := s.docRepo.GetByUserID(ctx, userID)
 	if err != nil {
@@ -108,88 +441,171 @@ func (s *DocumentService) GetUserDocuments(ctx context.Context, userID uuid.UUID
 	return documents, nil
 }
 
-func (s *DocumentService) GenerateSummary(ctx context.Context, documentID uuid.UUID) (*models.Summary, error) {
-	document, err := s.docRepo.GetByID(ctx, documentID)
+// UpdateDocument renames and/or replaces the content of a document the user owns. Nil fields are left unchanged.
+func (s *DocumentService) UpdateDocument(ctx context.Context, userID, documentID uuid.UUID, title, content *string) (*models.Document, error) {
+	document, err := s.GetUserDocument(ctx, userID, documentID)
 	if err != nil {
-		return nil, errors.Wrap(err, errors.ErrCodeNotFound, "document not found")
+		return nil, err
 	}
 
-	// Generate summary using LLM API
-	summaryContent, err := s.getSummaryFromLLM(ctx, document.Content)
-	if err != nil {
-		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get summary from LLM")
+	if title != nil {
+		document.Rename(*title)
 	}
-
-	summary := models.NewSummary(documentID, summaryContent)
-
-	if err := summary.Validate(); err != nil {
-		return nil, errors.Wrap(err, errors.ErrCodeValidation, "invalid summary data")
+	if content != nil && *content != document.Content {
+		document.UpdateContent(*content)
 	}
 
-	if err := s.summaryRepo.Create(ctx, summary); err != nil {
-		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to create summary")
+	if err := document.Validate(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeValidation, "invalid document data")
 	}
 
-	// Mark document as processed
-	document.MarkProcessed()
 	if err := s.docRepo.Update(ctx, document); err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to update document")
 	}
 
-	return summary, nil
+	return document, nil
+}
+
+// DeleteDocument removes a document the user owns together with its summaries and original file.
+func (s *DocumentService) DeleteDocument(ctx context.Context, userID, documentID uuid.UUID) error {
+	document, err := s.GetUserDocument(ctx, userID, documentID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.summaryRepo.DeleteByDocumentID(ctx, documentID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete summaries")
+	}
+	if err := s.docRepo.Delete(ctx, documentID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete document")
+	}
+	if document.OriginalKey != "" {
+		// The document is gone either way; a file left behind only takes space.
+		if err := s.blobs.Delete(ctx, document.OriginalKey); err != nil {
+			log.Printf("failed to remove the original file of document %s: %v", documentID, err)
+		}
+	}
+	return nil
 }
 
-func (s *DocumentService) getSummaryFromLLM(ctx context.Context, content string) (string, error) {
+// GetDocumentSummaries lists the summaries of a document the user owns, newest first.
+func (s *DocumentService) GetDocumentSummaries(ctx context.Context, userID, documentID uuid.UUID) ([]*models.Summary, error) {
+	if _, err := s.GetUserDocument(ctx, userID, documentID); err != nil {
+		return nil, err
+	}
 
-	prompt := fmt.Sprintf("Please summarize the following content in Japanese business style within 200 characters:\n\n%s", content)
-	log.Printf("LLM Request Prompt: %s", prompt)
+	summaries, err := s.summaryRepo.GetByDocumentID(ctx, documentID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get summaries")
+	}
+	return summaries, nil
+}
 
-	resBody := LLMRequest{
-		Model: s.llmClinet.model,
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
+// GetSummary returns a summary of a document the user owns.
+func (s *DocumentService) GetSummary(ctx context.Context, userID, summaryID uuid.UUID) (*models.Summary, error) {
+	summary, err := s.summaryRepo.GetByID(ctx, summaryID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get summary")
+	}
+	if summary == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "summary not found")
+	}
+	if _, err := s.GetUserDocument(ctx, userID, summary.DocumentID); err != nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "summary not found")
 	}
+	return summary, nil
+}
 
-	jsonData, err := json.Marshal(resBody)
+// GenerateSummary summarizes a document the user owns and records the options it was produced with.
+func (s *DocumentService) GenerateSummary(ctx context.Context, userID, documentID uuid.UUID, opts models.SummaryOptions) (*models.Summary, error) {
+	document, opts, err := s.prepareSummary(ctx, userID, documentID, opts)
 	if err != nil {
-		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal LLM request")
+		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", s.llmClinet.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+	// Generate summary using LLM API
+	summaryContent, err := s.summarizer.Summarize(ctx, document.PagedContent(), opts)
 	if err != nil {
-		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to create LLM request")
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get summary from LLM")
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+s.llmClinet.apiKey)
+	summary := models.NewSummary(documentID, summaryContent)
+	summary.SummaryOptions = opts
+	if err := s.saveSummary(ctx, document, summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
 
-	resp, err := s.llmClinet.client.Do(req)
+// StreamSummary is GenerateSummary with the final summary passed to onDelta as the model produces it.
+// When the stream is cut short, because the model fails, ctx is cancelled or onDelta returns an error,
+// the text received so far is still saved as a partial summary (or a failed one if there was none) and
+// returned together with the error.
+func (s *DocumentService) StreamSummary(ctx context.Context, userID, documentID uuid.UUID, opts models.SummaryOptions, onDelta func(delta string) error) (*models.Summary, error) {
+	document, opts, err := s.prepareSummary(ctx, userID, documentID, opts)
 	if err != nil {
-		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to call LLM API")
+		return nil, err
+	}
+
+	var received strings.Builder
+	summaryContent, streamErr := s.summarizer.SummarizeStream(ctx, document.PagedContent(), opts, func(delta string) error {
+		received.WriteString(delta)
+		return onDelta(delta)
+	})
+
+	summary := models.NewSummary(documentID, summaryContent)
+	summary.SummaryOptions = opts
+	if streamErr == nil {
+		if err := s.saveSummary(ctx, document, summary); err != nil {
+			return nil, err
+		}
+		return summary, nil
+	}
+
+	summary.Content = received.String()
+	summary.Status = models.SummaryStatusPartial
+	if summary.Content == "" {
+		summary.Status = models.SummaryStatusFailed
+	}
+	// The request context is usually gone by now, but the interrupted attempt should still be recorded.
+	if err := s.summaryRepo.Create(context.WithoutCancel(ctx), summary); err != nil {
+		log.Printf("failed to record %s summary %s: %v", summary.Status, summary.ID, err)
+		return nil, errors.Wrap(streamErr, errors.ErrCodeInternal, "summary stream interrupted")
 	}
-	defer resp.Body.Close()
+	return summary, errors.Wrap(streamErr, errors.ErrCodeInternal, "summary stream interrupted")
+}
+
+// prepareSummary checks the options and the document before any model call is made.
+func (s *DocumentService) prepareSummary(ctx context.Context, userID, documentID uuid.UUID, opts models.SummaryOptions) (*models.Document, models.SummaryOptions, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, opts, errors.New(errors.ErrCodeValidation, err.Error())
+	}
+	opts = opts.WithDefaults()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	document, err := s.GetUserDocument(ctx, userID, documentID)
 	if err != nil {
-		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to read LLM response body")
+		return nil, opts, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("LLM API returned error: %s", string(bodyBytes))
+	if strings.TrimSpace(document.Content) == "" {
+		return nil, opts, errors.New(errors.ErrCodeValidation, "document has no content to summarize")
 	}
+	return document, opts, nil
+}
 
-	var llmResponse LLMResponse
-	if err := json.Unmarshal(bodyBytes, &llmResponse); err != nil {
-		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal LLM response")
+func (s *DocumentService) saveSummary(ctx context.Context, document *models.Document, summary *models.Summary) error {
+	if err := summary.Validate(); err != nil {
+		return errors.Wrap(err, errors.ErrCodeValidation, "invalid summary data")
 	}
 
-	if len(llmResponse.Choices) == 0 {
-		return "", fmt.Errorf("LLM API returned no choices in response")
+	if err := s.summaryRepo.Create(ctx, summary); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create summary")
 	}
 
-	return llmResponse.Choices[0].Message.Content, nil
+	// Mark document as processed
+	document.MarkProcessed()
+	if err := s.docRepo.Update(ctx, document); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update document")
+	}
+	return nil
 }
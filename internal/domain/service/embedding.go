@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/pkg/chunker"
+	"github/k-tsurumaki/quilldeck/internal/pkg/errors"
+	"github/k-tsurumaki/quilldeck/internal/pkg/vector"
+)
+
+const (
+	defaultEmbeddingChunkChars = 1000
+	defaultEmbeddingBatchSize  = 32
+
+	defaultSemanticSearchLimit = 10
+	maxSemanticSearchLimit     = 50
+	maxSemanticQueryChars      = 1000
+)
+
+type EmbeddingOptions struct {
+	// Model names the embedding model; it is stored with every chunk so that vectors of different models
+	// are never compared.
+	Model string
+	// ChunkChars is the largest chunk, in characters, embedded as one vector.
+	ChunkChars int
+	// BatchSize is the number of chunks sent to the provider in one request.
+	BatchSize int
+}
+
+// EmbeddingService splits documents into chunks, embeds them and finds the chunks closest in meaning to a
+// query. Without an embedder it is disabled and semantic search reports itself unavailable.
+type EmbeddingService struct {
+	embeddingRepo repository.EmbeddingRepository
+	docRepo       repository.DocumentRepository
+	embedder      llm.Embedder
+	opts          EmbeddingOptions
+}
+
+func NewEmbeddingService(embeddingRepo repository.EmbeddingRepository, docRepo repository.DocumentRepository, embedder llm.Embedder, opts EmbeddingOptions) *EmbeddingService {
+	if opts.ChunkChars <= 0 {
+		opts.ChunkChars = defaultEmbeddingChunkChars
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultEmbeddingBatchSize
+	}
+	return &EmbeddingService{
+		embeddingRepo: embeddingRepo,
+		docRepo:       docRepo,
+		embedder:      embedder,
+		opts:          opts,
+	}
+}
+
+func (s *EmbeddingService) Enabled() bool {
+	return s.embedder != nil
+}
+
+// IndexDocument embeds the current content of a document, replacing its previous chunks.
+func (s *EmbeddingService) IndexDocument(ctx context.Context, documentID uuid.UUID) error {
+	if !s.Enabled() {
+		return errors.New(errors.ErrCodeUnavailable, "semantic search is not configured")
+	}
+
+	document, err := s.docRepo.GetByID(ctx, documentID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to get document")
+	}
+	if document == nil {
+		return errors.New(errors.ErrCodeNotFound, "document not found")
+	}
+
+	pieces := chunker.Split(document.Content, s.opts.ChunkChars)
+	chunks := make([]*models.DocumentChunk, 0, len(pieces))
+	now := time.Now()
+	for start := 0; start < len(pieces); start += s.opts.BatchSize {
+		batch := pieces[start:min(start+s.opts.BatchSize, len(pieces))]
+		texts := make([]string, len(batch))
+		for i, piece := range batch {
+			texts[i] = embeddingInput(document.Title, piece)
+		}
+
+		vectors, err := s.embedder.Embed(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("failed to embed chunks %d-%d of %d: %w", start+1, start+len(batch), len(pieces), err)
+		}
+		for i, piece := range batch {
+			chunks = append(chunks, &models.DocumentChunk{
+				ID:         uuid.New(),
+				DocumentID: document.ID,
+				Index:      piece.Index,
+				Heading:    piece.Heading,
+				Start:      piece.Start,
+				End:        piece.End,
+				Content:    piece.Text,
+				Model:      s.opts.Model,
+				Embedding:  vector.Normalize(vectors[i]),
+				CreatedAt:  now,
+			})
+		}
+	}
+
+	if err := s.embeddingRepo.ReplaceDocumentChunks(ctx, document.ID, chunks); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save chunks")
+	}
+	return nil
+}
+
+// embeddingInput prefixes a chunk with its document title and section heading, which often carry the
+// context a short chunk lacks.
+func embeddingInput(title string, chunk chunker.Chunk) string {
+	var b strings.Builder
+	b.WriteString(title)
+	if chunk.Heading != "" {
+		b.WriteString(" > ")
+		b.WriteString(chunk.Heading)
+	}
+	b.WriteString("\n\n")
+	b.WriteString(chunk.Text)
+	return b.String()
+}
+
+// SemanticSearch returns the user's chunks closest in meaning to query, best first.
+func (s *EmbeddingService) SemanticSearch(ctx context.Context, userID uuid.UUID, query string, limit int) ([]*models.ChunkMatch, error) {
+	if !s.Enabled() {
+		return nil, errors.New(errors.ErrCodeUnavailable, "semantic search is not configured")
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "search query is required")
+	}
+	if utf8.RuneCountInString(query) > maxSemanticQueryChars {
+		return nil, errors.New(errors.ErrCodeValidation, "search query must be at most 1000 characters")
+	}
+	switch {
+	case limit <= 0:
+		limit = defaultSemanticSearchLimit
+	case limit > maxSemanticSearchLimit:
+		limit = maxSemanticSearchLimit
+	}
+
+	vectors, err := s.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to embed query")
+	}
+	if len(vectors) != 1 {
+		return nil, errors.New(errors.ErrCodeInternal, "embedding provider returned no vector for the query")
+	}
+
+	matches, err := s.embeddingRepo.Nearest(ctx, userID, s.opts.Model, vector.Normalize(vectors[0]), limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to search chunks")
+	}
+	return matches, nil
+}
+
+// RankDocumentChunks returns up to limit stored chunks of document, most similar to query first. It
+// returns nil when the document has no chunks for the configured model or they no longer match its
+// content, for example while an update is still being embedded.
+func (s *EmbeddingService) RankDocumentChunks(ctx context.Context, document *models.Document, query string, limit int) ([]chunker.Chunk, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+	stored, err := s.embeddingRepo.ListByDocumentID(ctx, document.ID, s.opts.Model)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to load chunks")
+	}
+	for _, chunk := range stored {
+		if chunk.Start < 0 || chunk.End > len(document.Content) || chunk.Start > chunk.End ||
+			document.Content[chunk.Start:chunk.End] != chunk.Content {
+			return nil, nil
+		}
+	}
+	if len(stored) == 0 {
+		return nil, nil
+	}
+
+	vectors, err := s.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to embed query")
+	}
+	if len(vectors) != 1 {
+		return nil, errors.New(errors.ErrCodeInternal, "embedding provider returned no vector for the query")
+	}
+	queryVector := vector.Normalize(vectors[0])
+
+	best := vector.NewTopK[*models.DocumentChunk](limit)
+	for _, chunk := range stored {
+		if len(chunk.Embedding) == len(queryVector) {
+			best.Push(chunk, vector.Dot(queryVector, chunk.Embedding))
+		}
+	}
+	results := best.Results()
+	ranked := make([]chunker.Chunk, len(results))
+	for i, result := range results {
+		chunk := result.Value
+		ranked[i] = chunker.Chunk{
+			Index:   chunk.Index,
+			Heading: chunk.Heading,
+			Start:   chunk.Start,
+			End:     chunk.End,
+			Text:    chunk.Content,
+		}
+	}
+	return ranked, nil
+}
+
+// Unembedded lists documents still missing chunks for the configured model, for example ones uploaded
+// before semantic search was enabled or embedded with a previous model.
+func (s *EmbeddingService) Unembedded(ctx context.Context, limit int) ([]repository.UnembeddedDocument, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+	return s.embeddingRepo.ListUnembedded(ctx, s.opts.Model, limit)
+}
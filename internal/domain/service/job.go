@@ -0,0 +1,389 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/pkg/errors"
+)
+
+const (
+	defaultJobWorkers      = 2
+	defaultJobPollInterval = 5 * time.Second
+	defaultJobTimeout      = 10 * time.Minute
+	defaultJobMaxAttempts  = 3
+	defaultJobLease        = time.Minute
+
+	// embeddingBackfillBatch bounds how many unembedded documents are queued per start.
+	embeddingBackfillBatch = 500
+)
+
+type JobOptions struct {
+	// Workers is the number of jobs processed at the same time.
+	Workers int
+	// PollInterval is how often idle workers look for jobs enqueued by another process.
+	PollInterval time.Duration
+	// Timeout bounds a single run of a job.
+	Timeout time.Duration
+	// MaxAttempts is how many times a job interrupted by a restart is retried before it is failed.
+	MaxAttempts int
+	// Lease is how long a worker holds a job without renewing it. Workers renew their jobs three times per
+	// lease, and a running job whose lease has run out is taken for interrupted and queued again.
+	Lease time.Duration
+}
+
+// JobService persists background work and runs it on an in-process worker pool.
+type JobService struct {
+	jobRepo          repository.JobRepository
+	docService       *DocumentService
+	embeddingService *EmbeddingService
+	opts             JobOptions
+	// workerID identifies this process in the leases of the jobs it runs.
+	workerID string
+
+	wake chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewJobService(jobRepo repository.JobRepository, docService *DocumentService, embeddingService *EmbeddingService, opts JobOptions) *JobService {
+	if opts.Workers <= 0 {
+		opts.Workers = defaultJobWorkers
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultJobPollInterval
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultJobTimeout
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultJobMaxAttempts
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = defaultJobLease
+	}
+	return &JobService{
+		jobRepo:          jobRepo,
+		docService:       docService,
+		embeddingService: embeddingService,
+		opts:             opts,
+		workerID:         uuid.NewString(),
+		wake:             make(chan struct{}, 1),
+	}
+}
+
+// EnqueueSummary validates the request up front, so bad options and foreign documents fail immediately,
+// then queues the summary for a worker.
+func (s *JobService) EnqueueSummary(ctx context.Context, userID, documentID uuid.UUID, opts models.SummaryOptions) (*models.Job, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, errors.New(errors.ErrCodeValidation, err.Error())
+	}
+	if _, err := s.docService.GetUserDocument(ctx, userID, documentID); err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(opts.WithDefaults())
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to encode job payload")
+	}
+
+	job := models.NewJob(userID, documentID, models.JobTypeSummary, payload)
+	if err := job.Validate(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeValidation, "invalid job data")
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to enqueue job")
+	}
+
+	s.notify()
+	return job, nil
+}
+
+// EnqueueEmbedding queues (re)embedding of a document for semantic search. It returns nil without
+// queueing anything when semantic search is not configured or the document is already queued for
+// embedding, since that job embeds its latest content.
+func (s *JobService) EnqueueEmbedding(ctx context.Context, userID, documentID uuid.UUID) (*models.Job, error) {
+	if s.embeddingService == nil || !s.embeddingService.Enabled() {
+		return nil, nil
+	}
+
+	job := models.NewJob(userID, documentID, models.JobTypeEmbedding, json.RawMessage(`{}`))
+	if err := job.Validate(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeValidation, "invalid job data")
+	}
+	created, err := s.jobRepo.CreateIfNotQueued(ctx, job)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to enqueue job")
+	}
+	if !created {
+		return nil, nil
+	}
+
+	s.notify()
+	return job, nil
+}
+
+// GetJob returns a job the user owns. Jobs of other users are reported as not found.
+func (s *JobService) GetJob(ctx context.Context, userID, jobID uuid.UUID) (*models.Job, error) {
+	job, err := s.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get job")
+	}
+	if job == nil || job.UserID != userID {
+		return nil, errors.New(errors.ErrCodeNotFound, "job not found")
+	}
+	return job, nil
+}
+
+// Start recovers jobs left running by a process that stopped and launches the workers, along with a loop
+// that keeps recovering such jobs while other processes come and go. They stop when ctx is cancelled;
+// Wait blocks until they have.
+func (s *JobService) Start(ctx context.Context) error {
+	if err := s.recoverExpired(ctx); err != nil {
+		return err
+	}
+	if err := s.backfillEmbeddings(ctx); err != nil {
+		return err
+	}
+
+	for i := 0; i < s.opts.Workers; i++ {
+		s.wg.Add(1)
+		go s.work(ctx)
+	}
+	s.wg.Add(1)
+	go s.recoverLoop(ctx)
+	return nil
+}
+
+// Wait blocks until every worker started by Start has returned.
+func (s *JobService) Wait() {
+	s.wg.Wait()
+}
+
+// recoverExpired requeues running jobs whose lease has run out, because the process running them stopped,
+// failing those that have already used up their attempts so a job that crashes the process cannot loop
+// forever. Jobs that live workers, in this process or another, are running keep a current lease.
+func (s *JobService) recoverExpired(ctx context.Context) error {
+	now := time.Now()
+	jobs, err := s.jobRepo.GetExpired(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to load interrupted jobs: %w", err)
+	}
+
+	requeued := false
+	for _, job := range jobs {
+		if job.Attempts >= s.opts.MaxAttempts {
+			job.Fail(fmt.Sprintf("interrupted %d times", job.Attempts))
+		} else if err := s.requeue(ctx, job); err != nil {
+			return fmt.Errorf("failed to recover job %s: %w", job.ID, err)
+		}
+		recovered, err := s.jobRepo.UpdateExpired(ctx, job, now)
+		if err != nil {
+			return fmt.Errorf("failed to recover job %s: %w", job.ID, err)
+		}
+		if !recovered {
+			// Another process recovered the job first.
+			continue
+		}
+		log.Printf("recovered interrupted job %s: %s", job.ID, job.Status)
+		requeued = requeued || job.Status == models.JobStatusQueued
+	}
+	if requeued {
+		s.notify()
+	}
+	return nil
+}
+
+// requeue puts an interrupted job back in the queue. An embedding job is failed instead when its document
+// has been queued for embedding again since the job started: the queued job embeds the latest content, and
+// a document has at most one embedding job in the queue.
+func (s *JobService) requeue(ctx context.Context, job *models.Job) error {
+	if job.Type == models.JobTypeEmbedding {
+		queued, err := s.jobRepo.HasQueued(ctx, job.DocumentID, job.Type)
+		if err != nil {
+			return err
+		}
+		if queued {
+			job.Fail("superseded by a newer embedding of the document")
+			return nil
+		}
+	}
+	job.Requeue()
+	return nil
+}
+
+func (s *JobService) recoverLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.opts.Lease)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.recoverExpired(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("%v", err)
+		}
+	}
+}
+
+// backfillEmbeddings queues embedding of documents that have no chunks for the configured model yet,
+// such as documents uploaded before semantic search was enabled or embedded with another model.
+func (s *JobService) backfillEmbeddings(ctx context.Context) error {
+	if s.embeddingService == nil {
+		return nil
+	}
+	docs, err := s.embeddingService.Unembedded(ctx, embeddingBackfillBatch)
+	if err != nil {
+		return fmt.Errorf("failed to load unembedded documents: %w", err)
+	}
+
+	for _, doc := range docs {
+		if _, err := s.EnqueueEmbedding(ctx, doc.UserID, doc.DocumentID); err != nil {
+			return fmt.Errorf("failed to queue embedding of document %s: %w", doc.DocumentID, err)
+		}
+	}
+	if len(docs) > 0 {
+		log.Printf("queued embedding of %d documents", len(docs))
+	}
+	return nil
+}
+
+func (s *JobService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *JobService) work(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain the queue before going back to sleep.
+		for ctx.Err() == nil {
+			job, err := s.jobRepo.ClaimNext(ctx, s.workerID, s.opts.Lease)
+			if err != nil {
+				log.Printf("failed to claim job: %v", err)
+				break
+			}
+			if job == nil {
+				break
+			}
+			s.run(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *JobService) run(ctx context.Context, job *models.Job) {
+	runCtx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+
+	var lost atomic.Bool
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		s.renewLease(runCtx, job, func() {
+			lost.Store(true)
+			cancel()
+		})
+	}()
+
+	resultID, err := s.execute(runCtx, job)
+	cancel()
+	<-renewed
+
+	switch {
+	case lost.Load():
+		// The job was taken for interrupted and queued again; its outcome is no longer this worker's to record.
+		log.Printf("stopped job %s: its lease was lost", job.ID)
+		return
+	case err == nil:
+		job.Succeed(resultID)
+	case ctx.Err() != nil:
+		// The server is shutting down; leave the job for the next start instead of failing it. Should that
+		// fail too, the job's lease runs out and it is recovered as interrupted.
+		if err := s.requeue(context.WithoutCancel(ctx), job); err != nil {
+			log.Printf("failed to requeue job %s: %v", job.ID, err)
+			return
+		}
+	default:
+		job.Fail(err.Error())
+	}
+
+	// Record the outcome even when ctx has been cancelled by shutdown, unless the lease ran out meanwhile.
+	held, err := s.jobRepo.UpdateLeased(context.WithoutCancel(ctx), job, s.workerID)
+	if err != nil {
+		log.Printf("failed to update job %s: %v", job.ID, err)
+	} else if !held {
+		log.Printf("dropped the outcome of job %s: its lease was lost", job.ID)
+	}
+}
+
+// renewLease extends the lease on job until ctx ends, calling lost if the job is found to be no longer
+// running under this worker.
+func (s *JobService) renewLease(ctx context.Context, job *models.Job, lost func()) {
+	ticker := time.NewTicker(s.opts.Lease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		held, err := s.jobRepo.RenewLease(ctx, job.ID, s.workerID, time.Now().Add(s.opts.Lease))
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("failed to renew the lease of job %s: %v", job.ID, err)
+			}
+			continue
+		}
+		if !held {
+			lost()
+			return
+		}
+	}
+}
+
+func (s *JobService) execute(ctx context.Context, job *models.Job) (uuid.UUID, error) {
+	switch job.Type {
+	case models.JobTypeSummary:
+		var opts models.SummaryOptions
+		if err := json.Unmarshal(job.Payload, &opts); err != nil {
+			return uuid.Nil, fmt.Errorf("invalid job payload: %w", err)
+		}
+		summary, err := s.docService.GenerateSummary(ctx, job.UserID, job.DocumentID, opts)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return summary.ID, nil
+	case models.JobTypeEmbedding:
+		if s.embeddingService == nil {
+			return uuid.Nil, fmt.Errorf("semantic search is not configured")
+		}
+		return uuid.Nil, s.embeddingService.IndexDocument(ctx, job.DocumentID)
+	default:
+		return uuid.Nil, fmt.Errorf("unknown job type %q", job.Type)
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/pkg/errors"
+	"github/k-tsurumaki/quilldeck/internal/pkg/highlight"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchTerms     = 8
+	maxSearchTermChars = 100
+	searchSnippetChars = 160
+)
+
+type SearchParams struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+// SearchService finds text across the documents and summaries of one user.
+type SearchService struct {
+	searchRepo repository.SearchRepository
+}
+
+func NewSearchService(searchRepo repository.SearchRepository) *SearchService {
+	return &SearchService{searchRepo: searchRepo}
+}
+
+// Search splits the query into whitespace-separated terms, all of which must match, and returns the
+// ranked hits with a highlighted snippet of the matched text.
+func (s *SearchService) Search(ctx context.Context, userID uuid.UUID, params SearchParams) ([]*models.SearchHit, error) {
+	terms, err := searchTerms(params.Query)
+	if err != nil {
+		return nil, err
+	}
+	limit := params.Limit
+	switch {
+	case limit <= 0:
+		limit = defaultSearchLimit
+	case limit > maxSearchLimit:
+		limit = maxSearchLimit
+	}
+	if params.Offset < 0 {
+		return nil, errors.New(errors.ErrCodeValidation, "offset must not be negative")
+	}
+
+	hits, err := s.searchRepo.Search(ctx, userID, repository.SearchOptions{
+		Terms:  terms,
+		Limit:  limit,
+		Offset: params.Offset,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to search")
+	}
+
+	for _, hit := range hits {
+		hit.Snippet = highlight.Snippet(hit.Text, terms, searchSnippetChars)
+	}
+	return hits, nil
+}
+
+// searchTerms returns the distinct terms of query.
+func searchTerms(query string) ([]string, error) {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, term := range strings.Fields(query) {
+		key := strings.ToLower(term)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if utf8.RuneCountInString(term) > maxSearchTermChars {
+			return nil, errors.New(errors.ErrCodeValidation, "search terms must be at most 100 characters")
+		}
+		terms = append(terms, term)
+	}
+
+	switch {
+	case len(terms) == 0:
+		return nil, errors.New(errors.ErrCodeValidation, "search query is required")
+	case len(terms) > maxSearchTerms:
+		return nil, errors.New(errors.ErrCodeValidation, "search query must have at most 8 terms")
+	}
+	return terms, nil
+}
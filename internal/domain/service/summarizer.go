@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/pkg/chunker"
+)
+
+const (
+	defaultSummaryChunkChars  = chunker.DefaultMaxChars
+	defaultSummaryConcurrency = 4
+
+	// maxReduceRounds bounds the hierarchical reduce in case partial summaries refuse to shrink.
+	maxReduceRounds = 5
+)
+
+type SummarizerOptions struct {
+	// MaxChunkChars is the largest piece of text, in characters, sent to the model in one prompt.
+	MaxChunkChars int
+	// Concurrency caps the number of chunk summaries requested at the same time.
+	Concurrency int
+}
+
+// Summarizer summarizes documents of any size with a map-reduce over Markdown-aware chunks: each chunk
+// is summarized independently, then the partial summaries are combined into the final summary.
+type Summarizer struct {
+	model llm.LLM
+	opts  SummarizerOptions
+}
+
+func NewSummarizer(model llm.LLM, opts SummarizerOptions) *Summarizer {
+	if opts.MaxChunkChars <= 0 {
+		opts.MaxChunkChars = defaultSummaryChunkChars
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultSummaryConcurrency
+	}
+	return &Summarizer{
+		model: model,
+		opts:  opts,
+	}
+}
+
+// finalizeFunc runs the prompt that produces the final summary.
+type finalizeFunc func(ctx context.Context, prompt string) (string, error)
+
+// Summarize produces a summary of content shaped by opts, which should already carry its defaults.
+func (s *Summarizer) Summarize(ctx context.Context, content string, opts models.SummaryOptions) (string, error) {
+	return s.summarize(ctx, content, opts, s.complete)
+}
+
+// SummarizeStream works like Summarize but passes the final summary to onDelta as the model generates
+// it. The map step is not streamed. Models that cannot stream deliver the whole summary in one delta.
+func (s *Summarizer) SummarizeStream(ctx context.Context, content string, opts models.SummaryOptions, onDelta func(delta string) error) (string, error) {
+	return s.summarize(ctx, content, opts, func(ctx context.Context, prompt string) (string, error) {
+		return s.stream(ctx, prompt, onDelta)
+	})
+}
+
+// Condense returns content as it is when it fits in one prompt, and otherwise a bulleted summary of it in
+// language, for prompts that work from a whole document.
+func (s *Summarizer) Condense(ctx context.Context, content, language string) (string, error) {
+	if utf8.RuneCountInString(content) <= s.opts.MaxChunkChars {
+		return content, nil
+	}
+	return s.Summarize(ctx, content, models.SummaryOptions{
+		TargetChars: models.MaxSummaryTargetChars,
+		Style:       models.SummaryStyleBullets,
+		Language:    language,
+	})
+}
+
+func (s *Summarizer) summarize(ctx context.Context, content string, opts models.SummaryOptions, finalize finalizeFunc) (string, error) {
+	chunks := chunker.Split(content, s.opts.MaxChunkChars)
+	if len(chunks) == 0 {
+		return "", fmt.Errorf("nothing to summarize")
+	}
+
+	// Small documents fit in one prompt and skip the map step entirely.
+	if len(chunks) == 1 {
+		return finalize(ctx, finalSummaryPrompt(chunks[0].Text, opts))
+	}
+
+	partials, err := s.summarizeChunks(ctx, chunks, opts)
+	if err != nil {
+		return "", err
+	}
+	return s.reduce(ctx, partials, opts, finalize)
+}
+
+// summarizeChunks runs the map step, keeping at most opts.Concurrency requests in flight. The first
+// failure cancels the remaining requests.
+func (s *Summarizer) summarizeChunks(ctx context.Context, chunks []chunker.Chunk, opts models.SummaryOptions) ([]string, error) {
+	mapCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]string, len(chunks))
+	sem := make(chan struct{}, s.opts.Concurrency)
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-mapCtx.Done():
+				return
+			}
+			defer func() { <-sem }()
+
+			summary, err := s.complete(mapCtx, chunkSummaryPrompt(chunk, len(chunks), opts))
+			if err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("failed to summarize chunk %d of %d: %w", i+1, len(chunks), err)
+					cancel()
+				})
+				return
+			}
+			results[i] = summary
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// reduce combines partial summaries into the final summary. When they are too long for one prompt they
+// are first condensed in groups, level by level.
+func (s *Summarizer) reduce(ctx context.Context, partials []string, opts models.SummaryOptions, finalize finalizeFunc) (string, error) {
+	for round := 0; ; round++ {
+		combined := joinPartials(partials)
+		if utf8.RuneCountInString(combined) <= s.opts.MaxChunkChars {
+			return finalize(ctx, reduceSummaryPrompt(combined, opts))
+		}
+		if round == maxReduceRounds {
+			return "", fmt.Errorf("partial summaries did not fit in %d reduce rounds", maxReduceRounds)
+		}
+
+		var err error
+		partials, err = s.summarizeChunks(ctx, chunker.Split(combined, s.opts.MaxChunkChars), opts)
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+func (s *Summarizer) complete(ctx context.Context, prompt string) (string, error) {
+	log.Printf("LLM Request: prompt_chars=%d", utf8.RuneCountInString(prompt))
+	resp, err := s.model.Complete(ctx, llm.UserPrompt(prompt))
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
+
+func (s *Summarizer) stream(ctx context.Context, prompt string, onDelta func(delta string) error) (string, error) {
+	streamer, ok := s.model.(llm.Streamer)
+	if !ok {
+		content, err := s.complete(ctx, prompt)
+		if err != nil {
+			return "", err
+		}
+		return content, onDelta(content)
+	}
+
+	log.Printf("LLM Stream Request: prompt_chars=%d", utf8.RuneCountInString(prompt))
+	resp, err := streamer.Stream(ctx, llm.UserPrompt(prompt), onDelta)
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
+
+func joinPartials(partials []string) string {
+	var b strings.Builder
+	for i, partial := range partials {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "## Part %d\n\n%s", i+1, strings.TrimSpace(partial))
+	}
+	return b.String()
+}
+
+var summaryStyleInstructions = map[models.SummaryStyle]string{
+	models.SummaryStyleBusiness:  "in a formal business-report style",
+	models.SummaryStyleBullets:   "as a bulleted list of the key points",
+	models.SummaryStyleExecutive: "as an executive brief that leads with the conclusion, followed by key decisions, risks and next steps",
+	models.SummaryStyleCasual:    "in a casual, conversational tone",
+}
+
+var languageNames = map[string]string{
+	"ja": "Japanese",
+	"en": "English",
+	"zh": "Chinese",
+	"ko": "Korean",
+	"fr": "French",
+	"de": "German",
+	"es": "Spanish",
+	"pt": "Portuguese",
+	"it": "Italian",
+}
+
+// languageName turns a language tag into a name the model follows reliably, falling back to the tag.
+func languageName(tag string) string {
+	base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+	if name, ok := languageNames[base]; ok {
+		return name
+	}
+	return fmt.Sprintf("the language identified by %q", tag)
+}
+
+// summaryInstruction describes the requested language, style and length, e.g.
+// "in Japanese, in a formal business-report style, within about 200 characters".
+func summaryInstruction(opts models.SummaryOptions) string {
+	length, words := opts.TargetLength()
+	unit := "characters"
+	if words {
+		unit = "words"
+	}
+	style := summaryStyleInstructions[opts.Style]
+	if style == "" {
+		style = summaryStyleInstructions[models.SummaryStyleBusiness]
+	}
+	return fmt.Sprintf("in %s, %s, within about %d %s", languageName(opts.Language), style, length, unit)
+}
+
+func finalSummaryPrompt(content string, opts models.SummaryOptions) string {
+	return fmt.Sprintf("Please summarize the following content %s:\n\n%s", summaryInstruction(opts), content)
+}
+
+func chunkSummaryPrompt(chunk chunker.Chunk, total int, opts models.SummaryOptions) string {
+	section := ""
+	if chunk.Heading != "" {
+		section = fmt.Sprintf(" from the section %q", chunk.Heading)
+	}
+	return fmt.Sprintf("The following is part %d of %d of a longer document%s. Summarize its key points in %s, "+
+		"keeping important facts, figures and names:\n\n%s", chunk.Index+1, total, section, languageName(opts.Language), chunk.Text)
+}
+
+func reduceSummaryPrompt(partials string, opts models.SummaryOptions) string {
+	return fmt.Sprintf("The following are summaries of consecutive parts of one document. Combine them into a single "+
+		"summary of the whole document %s:\n\n%s", summaryInstruction(opts), partials)
+}
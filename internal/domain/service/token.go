@@ -0,0 +1,88 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/pkg/errors"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const tokenIssuer = "quilldeck"
+
+type TokenService struct {
+	secret    []byte
+	accessTTL time.Duration
+}
+
+type AccessClaims struct {
+	Email     string `json:"email"`
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+func NewTokenService(secret string, accessTTL time.Duration) *TokenService {
+	return &TokenService{
+		secret:    []byte(secret),
+		accessTTL: accessTTL,
+	}
+}
+
+// IssueAccessToken signs a short-lived HS256 token identifying the user and the session it belongs to.
+func (s *TokenService) IssueAccessToken(user *models.User, sessionID uuid.UUID) (string, time.Time, error) {
+	if len(s.secret) == 0 {
+		return "", time.Time{}, errors.New(errors.ErrCodeInternal, "JWT secret is not configured")
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.accessTTL)
+	claims := AccessClaims{
+		Email:     user.Email,
+		SessionID: sessionID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			ID:        uuid.NewString(),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return "", time.Time{}, errors.Wrap(err, errors.ErrCodeInternal, "failed to sign access token")
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseAccessToken verifies the signature and expiry of an access token and returns its claims.
+func (s *TokenService) ParseAccessToken(tokenString string) (*AccessClaims, error) {
+	if len(s.secret) == 0 {
+		return nil, errors.New(errors.ErrCodeInternal, "JWT secret is not configured")
+	}
+
+	var claims AccessClaims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.secret, nil
+	}, jwt.WithIssuer(tokenIssuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeUnauthorized, "invalid access token")
+	}
+	return &claims, nil
+}
+
+// UserID returns the authenticated user ID carried in the subject claim.
+func (c *AccessClaims) UserID() (uuid.UUID, error) {
+	return uuid.Parse(c.Subject)
+}
+
+// SessionUUID returns the session the token was issued for.
+func (c *AccessClaims) SessionUUID() (uuid.UUID, error) {
+	return uuid.Parse(c.SessionID)
+}
@@ -0,0 +1,56 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github/k-tsurumaki/quilldeck/internal/config"
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/ai/anthropic"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/ai/ollama"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/ai/openai"
+)
+
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+)
+
+// NewLLM builds the language model client selected by cfg.LLM_PROVIDER.
+func NewLLM(cfg config.LLMConfig) (llm.LLM, error) {
+	switch strings.ToLower(cfg.LLM_PROVIDER) {
+	case "", ProviderOpenAI:
+		return openai.New(cfg.LLM_API_KEY, cfg.LLM_BASE_URL, cfg.LLM_MODEL, cfg.LLM_TIMEOUT), nil
+	case ProviderAnthropic:
+		if cfg.LLM_API_KEY == "" {
+			return nil, fmt.Errorf("LLM_API_KEY is required for the %s provider", ProviderAnthropic)
+		}
+		return anthropic.New(cfg.LLM_API_KEY, cfg.LLM_BASE_URL, cfg.LLM_MODEL, cfg.LLM_TIMEOUT), nil
+	case ProviderOllama:
+		if cfg.LLM_MODEL == "" {
+			return nil, fmt.Errorf("LLM_MODEL is required for the %s provider", ProviderOllama)
+		}
+		return ollama.New(cfg.LLM_BASE_URL, cfg.LLM_MODEL, cfg.LLM_TIMEOUT), nil
+	default:
+		return nil, fmt.Errorf("unsupported LLM provider: %q", cfg.LLM_PROVIDER)
+	}
+}
+
+// NewEmbedder builds a client for cfg.LLM_EMBEDDING_MODEL on the configured provider. It returns nil when
+// no embedding model is set, which disables semantic search.
+func NewEmbedder(cfg config.LLMConfig) (llm.Embedder, error) {
+	if cfg.LLM_EMBEDDING_MODEL == "" {
+		return nil, nil
+	}
+	switch strings.ToLower(cfg.LLM_PROVIDER) {
+	case "", ProviderOpenAI:
+		return openai.New(cfg.LLM_API_KEY, cfg.LLM_BASE_URL, cfg.LLM_EMBEDDING_MODEL, cfg.LLM_TIMEOUT), nil
+	case ProviderOllama:
+		return ollama.New(cfg.LLM_BASE_URL, cfg.LLM_EMBEDDING_MODEL, cfg.LLM_TIMEOUT), nil
+	case ProviderAnthropic:
+		return nil, fmt.Errorf("the %s provider has no embeddings API; unset LLM_EMBEDDING_MODEL", ProviderAnthropic)
+	default:
+		return nil, fmt.Errorf("unsupported LLM provider: %q", cfg.LLM_PROVIDER)
+	}
+}
@@ -0,0 +1,207 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/ai/transport"
+)
+
+const (
+	providerName   = "Anthropic"
+	DefaultBaseURL = "https://api.anthropic.com/v1"
+	apiVersion     = "2023-06-01"
+
+	// defaultMaxTokens is sent when the request does not set one, since the Messages API requires it.
+	defaultMaxTokens = 4096
+)
+
+// Client speaks Anthropic's Messages API.
+type Client struct {
+	apiKey     string
+	baseURL    string
+	model      string
+	httpClient *http.Client
+	// streamClient has no deadline on reading the body, so that long streams are not cut off.
+	streamClient *http.Client
+}
+
+type messagesRequest struct {
+	Model       string        `json:"model"`
+	System      string        `json:"system,omitempty"`
+	Messages    []llm.Message `json:"messages"`
+	MaxTokens   int           `json:"max_tokens"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	Stream      bool          `json:"stream,omitempty"`
+}
+
+type messagesResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// streamEvent covers the fields used from the message_start, content_block_delta, message_delta and
+// error events of a streamed response.
+type streamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Model string `json:"model"`
+		Usage struct {
+			InputTokens int `json:"input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func New(apiKey, baseURL, model string, timeout time.Duration) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		apiKey:       apiKey,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		model:        model,
+		httpClient:   transport.NewClient(timeout),
+		streamClient: transport.NewStreamingClient(timeout),
+	}
+}
+
+func (c *Client) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	resp, err := c.post(ctx, c.newRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var out messagesResponse
+	if err := transport.DecodeJSON(resp, providerName, &out); err != nil {
+		return nil, err
+	}
+
+	var text strings.Builder
+	for _, block := range out.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("%s API returned no text content", providerName)
+	}
+
+	return &llm.Response{
+		Content:      text.String(),
+		Model:        out.Model,
+		FinishReason: out.StopReason,
+		Usage: llm.Usage{
+			PromptTokens:     out.Usage.InputTokens,
+			CompletionTokens: out.Usage.OutputTokens,
+		},
+	}, nil
+}
+
+// Stream requests the message with "stream": true and forwards each text delta.
+func (c *Client) Stream(ctx context.Context, req llm.Request, onDelta func(delta string) error) (*llm.Response, error) {
+	payload := c.newRequest(req)
+	payload.Stream = true
+	resp, err := c.post(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out llm.Response
+	var content strings.Builder
+	stopped := false
+	err = transport.ReadSSE(resp.Body, func(_, data string) error {
+		var event streamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("failed to decode %s stream: %w", providerName, err)
+		}
+		switch event.Type {
+		case "message_start":
+			out.Model = event.Message.Model
+			out.Usage.PromptTokens = event.Message.Usage.InputTokens
+		case "content_block_delta":
+			if event.Delta.Type != "text_delta" || event.Delta.Text == "" {
+				return nil
+			}
+			content.WriteString(event.Delta.Text)
+			return onDelta(event.Delta.Text)
+		case "message_delta":
+			out.FinishReason = event.Delta.StopReason
+			out.Usage.CompletionTokens = event.Usage.OutputTokens
+		case "message_stop":
+			stopped = true
+		case "error":
+			return fmt.Errorf("%s stream error: %s", providerName, event.Error.Message)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !stopped {
+		return nil, fmt.Errorf("%s: %w", providerName, transport.ErrTruncated)
+	}
+
+	out.Content = content.String()
+	return &out, nil
+}
+
+func (c *Client) post(ctx context.Context, payload messagesRequest) (*http.Response, error) {
+	header := http.Header{}
+	header.Set("x-api-key", c.apiKey)
+	header.Set("anthropic-version", apiVersion)
+	client := c.httpClient
+	if payload.Stream {
+		client = c.streamClient
+	}
+	return transport.PostJSON(ctx, client, providerName, c.baseURL+"/messages", header, payload)
+}
+
+// newRequest moves system messages into the top-level system prompt, which is where the Messages API
+// expects them.
+func (c *Client) newRequest(req llm.Request) messagesRequest {
+	out := messagesRequest{
+		Model:       c.model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+	}
+	if out.MaxTokens <= 0 {
+		out.MaxTokens = defaultMaxTokens
+	}
+
+	var system []string
+	for _, msg := range req.Messages {
+		if msg.Role == llm.RoleSystem {
+			system = append(system, msg.Content)
+			continue
+		}
+		out.Messages = append(out.Messages, msg)
+	}
+	out.System = strings.Join(system, "\n\n")
+	return out
+}
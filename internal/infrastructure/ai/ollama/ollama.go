@@ -0,0 +1,171 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/ai/transport"
+)
+
+const (
+	providerName   = "Ollama"
+	DefaultBaseURL = "http://localhost:11434"
+)
+
+// Client speaks the /api/chat and /api/embed endpoints of a local Ollama server.
+type Client struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+	// streamClient has no deadline on reading the body, so that long streams are not cut off.
+	streamClient *http.Client
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []llm.Message `json:"messages"`
+	Stream   bool          `json:"stream"`
+	Options  *chatOptions  `json:"options,omitempty"`
+}
+
+type chatOptions struct {
+	NumPredict  int      `json:"num_predict,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+}
+
+type chatResponse struct {
+	Model           string      `json:"model"`
+	Message         llm.Message `json:"message"`
+	Done            bool        `json:"done"`
+	DoneReason      string      `json:"done_reason"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	EvalCount       int         `json:"eval_count"`
+	Error           string      `json:"error"`
+}
+
+type embedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+func New(baseURL, model string, timeout time.Duration) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		model:        model,
+		httpClient:   transport.NewClient(timeout),
+		streamClient: transport.NewStreamingClient(timeout),
+	}
+}
+
+func (c *Client) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	resp, err := c.post(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var out chatResponse
+	if err := transport.DecodeJSON(resp, providerName, &out); err != nil {
+		return nil, err
+	}
+
+	return &llm.Response{
+		Content:      out.Message.Content,
+		Model:        out.Model,
+		FinishReason: out.DoneReason,
+		Usage: llm.Usage{
+			PromptTokens:     out.PromptEvalCount,
+			CompletionTokens: out.EvalCount,
+		},
+	}, nil
+}
+
+// Stream reads the newline-delimited JSON that /api/chat returns with "stream": true.
+func (c *Client) Stream(ctx context.Context, req llm.Request, onDelta func(delta string) error) (*llm.Response, error) {
+	resp, err := c.post(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out llm.Response
+	var content strings.Builder
+	done := false
+	err = transport.ReadLines(resp.Body, func(line []byte) error {
+		var chunk chatResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("failed to decode %s stream: %w", providerName, err)
+		}
+		if chunk.Error != "" {
+			return fmt.Errorf("%s stream error: %s", providerName, chunk.Error)
+		}
+		if chunk.Done {
+			done = true
+			out.Model = chunk.Model
+			out.FinishReason = chunk.DoneReason
+			out.Usage = llm.Usage{PromptTokens: chunk.PromptEvalCount, CompletionTokens: chunk.EvalCount}
+		}
+		if chunk.Message.Content == "" {
+			return nil
+		}
+		content.WriteString(chunk.Message.Content)
+		return onDelta(chunk.Message.Content)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !done {
+		return nil, fmt.Errorf("%s: %w", providerName, transport.ErrTruncated)
+	}
+
+	out.Content = content.String()
+	return &out, nil
+}
+
+// Embed calls /api/embed with the client's model, which must be an embedding model such as
+// nomic-embed-text.
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	resp, err := transport.PostJSON(ctx, c.httpClient, providerName, c.baseURL+"/api/embed", nil, embedRequest{
+		Model: c.model,
+		Input: texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var out embedResponse
+	if err := transport.DecodeJSON(resp, providerName, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("%s API returned %d embeddings for %d inputs", providerName, len(out.Embeddings), len(texts))
+	}
+	return out.Embeddings, nil
+}
+
+func (c *Client) post(ctx context.Context, req llm.Request, stream bool) (*http.Response, error) {
+	payload := chatRequest{
+		Model:    c.model,
+		Messages: req.Messages,
+		Stream:   stream,
+	}
+	if req.MaxTokens > 0 || req.Temperature != nil {
+		payload.Options = &chatOptions{NumPredict: req.MaxTokens, Temperature: req.Temperature}
+	}
+	client := c.httpClient
+	if stream {
+		client = c.streamClient
+	}
+	return transport.PostJSON(ctx, client, providerName, c.baseURL+"/api/chat", nil, payload)
+}
@@ -0,0 +1,217 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/ai/transport"
+)
+
+const (
+	providerName   = "OpenAI"
+	DefaultBaseURL = "https://api.openai.com/v1"
+)
+
+// Client speaks the OpenAI /chat/completions API, which OpenRouter, Azure-style gateways, vLLM and most
+// hosted models also implement.
+type Client struct {
+	apiKey     string
+	baseURL    string
+	model      string
+	httpClient *http.Client
+	// streamClient has no deadline on reading the body, so that long streams are not cut off.
+	streamClient *http.Client
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []llm.Message `json:"messages"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	Stream      bool          `json:"stream,omitempty"`
+}
+
+type chatResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index        int         `json:"index"`
+		FinishReason string      `json:"finish_reason"`
+		Message      llm.Message `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+type chatChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func New(apiKey, baseURL, model string, timeout time.Duration) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		apiKey:       apiKey,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		model:        model,
+		httpClient:   transport.NewClient(timeout),
+		streamClient: transport.NewStreamingClient(timeout),
+	}
+}
+
+func (c *Client) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	resp, err := c.post(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var out chatResponse
+	if err := transport.DecodeJSON(resp, providerName, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Choices) == 0 {
+		return nil, fmt.Errorf("%s API returned no choices in response", providerName)
+	}
+
+	return &llm.Response{
+		Content:      out.Choices[0].Message.Content,
+		Model:        out.Model,
+		FinishReason: out.Choices[0].FinishReason,
+		Usage: llm.Usage{
+			PromptTokens:     out.Usage.PromptTokens,
+			CompletionTokens: out.Usage.CompletionTokens,
+		},
+	}, nil
+}
+
+// Stream requests the completion with "stream": true and forwards each content delta.
+func (c *Client) Stream(ctx context.Context, req llm.Request, onDelta func(delta string) error) (*llm.Response, error) {
+	resp, err := c.post(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out llm.Response
+	var content strings.Builder
+	err = transport.ReadSSE(resp.Body, func(_, data string) error {
+		if data == "[DONE]" {
+			return errStreamDone
+		}
+		var chunk chatChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to decode %s stream: %w", providerName, err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("%s stream error: %s", providerName, chunk.Error.Message)
+		}
+		if chunk.Model != "" {
+			out.Model = chunk.Model
+		}
+		for _, choice := range chunk.Choices {
+			if choice.FinishReason != nil {
+				out.FinishReason = *choice.FinishReason
+			}
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if err := onDelta(choice.Delta.Content); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && err != errStreamDone {
+		return nil, err
+	}
+	if err == nil && out.FinishReason == "" {
+		return nil, fmt.Errorf("%s: %w", providerName, transport.ErrTruncated)
+	}
+
+	out.Content = content.String()
+	return &out, nil
+}
+
+// Embed calls /embeddings with the client's model, which must be an embedding model.
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	resp, err := transport.PostJSON(ctx, c.httpClient, providerName, c.baseURL+"/embeddings", c.header(), embeddingRequest{
+		Model: c.model,
+		Input: texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var out embeddingResponse
+	if err := transport.DecodeJSON(resp, providerName, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Data) != len(texts) {
+		return nil, fmt.Errorf("%s API returned %d embeddings for %d inputs", providerName, len(out.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range out.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("%s API returned an embedding for unknown input %d", providerName, item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
+
+// errStreamDone stops reading at the [DONE] sentinel.
+var errStreamDone = errors.New("stream done")
+
+func (c *Client) post(ctx context.Context, req llm.Request, stream bool) (*http.Response, error) {
+	client := c.httpClient
+	if stream {
+		client = c.streamClient
+	}
+	return transport.PostJSON(ctx, client, providerName, c.baseURL+"/chat/completions", c.header(), chatRequest{
+		Model:       c.model,
+		Messages:    req.Messages,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		Stream:      stream,
+	})
+}
+
+func (c *Client) header() http.Header {
+	header := http.Header{}
+	if c.apiKey != "" {
+		header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	return header
+}
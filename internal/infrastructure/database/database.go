@@ -0,0 +1,53 @@
+// Package database opens the storage backend selected by the configuration.
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github/k-tsurumaki/quilldeck/internal/config"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/database/migrate"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/database/postgres"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/database/sqlite"
+)
+
+// Database is an open connection to one backend with its repositories and schema migrator.
+type Database struct {
+	Repositories *repository.Repositories
+	Migrator     *migrate.Migrator
+	Close        func() error
+}
+
+// Open connects to the backend selected by DB_TYPE. It does not migrate the schema.
+func Open(cfg config.DatabaseConfig) (*Database, error) {
+	switch strings.ToLower(cfg.Type) {
+	case "", "sqlite", "sqlite3":
+		db, err := sqlite.NewConnection(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		migrator, err := db.Migrator()
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		return &Database{Repositories: sqlite.NewRepositories(db), Migrator: migrator, Close: db.Close}, nil
+	case "postgres", "postgresql":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("DATABASE_URL is required when DB_TYPE is postgres")
+		}
+		db, err := postgres.NewConnection(cfg.URL)
+		if err != nil {
+			return nil, err
+		}
+		migrator, err := db.Migrator()
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		return &Database{Repositories: postgres.NewRepositories(db), Migrator: migrator, Close: db.Close}, nil
+	default:
+		return nil, fmt.Errorf("unsupported DB_TYPE %q (use sqlite or postgres)", cfg.Type)
+	}
+}
@@ -0,0 +1,402 @@
+// Package migrate applies numbered, reversible SQL migrations and records them in a schema_migrations
+// ledger.
+//
+// Migrations are files named <version>_<name>.up.sql and <version>_<name>.down.sql, for example
+// 0002_add_summary_index.up.sql. Versions are applied in ascending order, each in its own transaction
+// together with its ledger row. The ledger stores a checksum of every applied up file, so a migration
+// that was edited after it ran is reported instead of silently diverging.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Dialect int
+
+const (
+	SQLite Dialect = iota
+	Postgres
+)
+
+// postgresLockID is the advisory lock that keeps two processes from migrating a Postgres database at once.
+const postgresLockID = 4213890672
+
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Status describes one migration known from the files, the ledger, or both.
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	// Modified is set when the up file no longer matches the checksum recorded when it was applied.
+	Modified bool
+	// Missing is set when the ledger records a version for which there is no migration file.
+	Missing bool
+}
+
+type Options struct {
+	// Adopt runs in the transaction of the first migration, right after its SQL. It brings databases that
+	// were created before the ledger existed up to the baseline schema.
+	Adopt func(ctx context.Context, tx *sql.Tx) error
+	// AfterUp runs at the end of every Up, in a transaction of its own, whether or not migrations were
+	// pending. It repairs schema that depends on the build rather than on the ledger.
+	AfterUp func(ctx context.Context, tx *sql.Tx) error
+}
+
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+	opts       Options
+}
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Load reads the migrations in the root of fsys. Every version needs an up file; the down file is optional
+// and a migration without one cannot be rolled back.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+			migration.Checksum = checksum(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// New returns a migrator over migrations, which may come from several Load calls and need not be sorted.
+func New(db *sql.DB, dialect Dialect, migrations []Migration, opts Options) *Migrator {
+	migrations = slices.Clone(migrations)
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: migrations,
+		opts:       opts,
+	}
+}
+
+// Latest returns the highest known version, or 0 when there are no migrations.
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every pending migration and returns the versions it applied. It refuses to run when an
+// applied migration was modified or is missing, since the schema would no longer match the files.
+func (m *Migrator) Up(ctx context.Context) ([]int64, error) {
+	var applied []int64
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		ledger, err := m.readLedger(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(ledger); err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := ledger[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			applied = append(applied, migration.Version)
+		}
+		if m.opts.AfterUp != nil {
+			return m.afterUp(ctx, conn)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+func (m *Migrator) afterUp(ctx context.Context, conn *sql.Conn) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := m.opts.AfterUp(ctx, tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to complete migrations: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to complete migrations: %w", err)
+	}
+	return nil
+}
+
+// Down rolls back applied migrations newer than target, newest first, and returns the versions it rolled
+// back. A target of 0 rolls back everything.
+func (m *Migrator) Down(ctx context.Context, target int64) ([]int64, error) {
+	if target < 0 {
+		return nil, fmt.Errorf("invalid target version %d", target)
+	}
+
+	var reverted []int64
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		ledger, err := m.readLedger(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if target != 0 && !m.known(target) {
+			return fmt.Errorf("unknown target version %d", target)
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if migration.Version <= target {
+				break
+			}
+			if _, ok := ledger[migration.Version]; !ok {
+				continue
+			}
+			if strings.TrimSpace(migration.Down) == "" {
+				return fmt.Errorf("migration %04d_%s cannot be rolled back: it has no down file", migration.Version, migration.Name)
+			}
+			if err := m.revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			reverted = append(reverted, migration.Version)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status reports every migration file and every ledger entry, in version order.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	ledger, err := m.readLedger(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if entry, ok := ledger[migration.Version]; ok {
+			appliedAt := entry.appliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			status.Modified = entry.checksum != migration.Checksum
+			delete(ledger, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for version, entry := range ledger {
+		appliedAt := entry.appliedAt
+		statuses = append(statuses, Status{Version: version, Name: entry.name, Applied: true, AppliedAt: &appliedAt, Missing: true})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+type ledgerEntry struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+const createLedgerTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version BIGINT PRIMARY KEY,
+	name TEXT NOT NULL,
+	checksum TEXT NOT NULL,
+	applied_at TIMESTAMP NOT NULL
+)`
+
+func (m *Migrator) readLedger(ctx context.Context, conn *sql.Conn) (map[int64]ledgerEntry, error) {
+	if _, err := conn.ExecContext(ctx, createLedgerTable); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	ledger := map[int64]ledgerEntry{}
+	for rows.Next() {
+		var version int64
+		var entry ledgerEntry
+		if err := rows.Scan(&version, &entry.name, &entry.checksum, &entry.appliedAt); err != nil {
+			return nil, err
+		}
+		ledger[version] = entry
+	}
+	return ledger, rows.Err()
+}
+
+func (m *Migrator) verify(ledger map[int64]ledgerEntry) error {
+	for _, migration := range m.migrations {
+		if entry, ok := ledger[migration.Version]; ok && entry.checksum != migration.Checksum {
+			return fmt.Errorf("migration %04d_%s was modified after it was applied (checksum mismatch)", migration.Version, migration.Name)
+		}
+	}
+	for version, entry := range ledger {
+		if !m.known(version) {
+			return fmt.Errorf("applied migration %04d_%s is missing from this build", version, entry.name)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) known(version int64) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	return m.inTx(ctx, conn, migration, "apply", func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+			return err
+		}
+		if migration.Version == m.migrations[0].Version && m.opts.Adopt != nil {
+			if err := m.opts.Adopt(ctx, tx); err != nil {
+				return err
+			}
+		}
+		_, err := tx.ExecContext(ctx, m.bind(`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`),
+			migration.Version, migration.Name, migration.Checksum, time.Now().UTC())
+		return err
+	})
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	return m.inTx(ctx, conn, migration, "roll back", func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, m.bind(`DELETE FROM schema_migrations WHERE version = ?`), migration.Version)
+		return err
+	})
+}
+
+func (m *Migrator) inTx(ctx context.Context, conn *sql.Conn, migration Migration, action string, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to %s migration %04d_%s: %w", action, migration.Version, migration.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to %s migration %04d_%s: %w", action, migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// withLock runs fn on a single connection. On Postgres that connection holds an advisory lock, so servers
+// starting at the same time apply each migration once; SQLite serializes the writes by itself.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.dialect == Postgres {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, postgresLockID); err != nil {
+			return fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		defer conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, postgresLockID)
+	}
+	return fn(conn)
+}
+
+// bind rewrites ? placeholders for Postgres.
+func (m *Migrator) bind(query string) string {
+	if m.dialect != Postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
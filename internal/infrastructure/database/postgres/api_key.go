@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type APIKeyRepository struct {
+	db *DB
+}
+
+func NewAPIKeyRepository(db *DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, created_at, expires_at, last_used_at, revoked_at`
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	query := `
+		INSERT INTO api_keys (` + apiKeyColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	scopes := key.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	_, err := r.db.ExecContext(ctx, query,
+		key.ID.String(),
+		key.UserID.String(),
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		pq.Array(scopes),
+		key.CreatedAt,
+		key.ExpiresAt,
+		key.LastUsedAt,
+		key.RevokedAt,
+	)
+	return err
+}
+
+func (r *APIKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = $1`
+	return scanAPIKey(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE prefix = $1`
+	return scanAPIKey(r.db.QueryRowContext(ctx, query, prefix))
+}
+
+func (r *APIKeyRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *APIKeyRepository) Update(ctx context.Context, key *models.APIKey) error {
+	query := `UPDATE api_keys SET name = $1, last_used_at = $2, revoked_at = $3 WHERE id = $4`
+
+	_, err := r.db.ExecContext(ctx, query,
+		key.Name,
+		key.LastUsedAt,
+		key.RevokedAt,
+		key.ID.String(),
+	)
+	return err
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var idStr, userIDStr string
+	var scopes []string
+	err := row.Scan(
+		&idStr,
+		&userIDStr,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		pq.Array(&scopes),
+		&key.CreatedAt,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key.ID = uuid.MustParse(idStr)
+	key.UserID = uuid.MustParse(userIDStr)
+	if len(scopes) > 0 {
+		key.Scopes = scopes
+	}
+	return &key, nil
+}
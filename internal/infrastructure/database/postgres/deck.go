@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type DeckRepository struct {
+	db *DB
+}
+
+func NewDeckRepository(db *DB) *DeckRepository {
+	return &DeckRepository{db: db}
+}
+
+const deckColumns = `id, document_id, title, slides, slide_count, language, created_at`
+
+func (r *DeckRepository) Create(ctx context.Context, deck *models.Deck) error {
+	slides, err := json.Marshal(deck.Slides)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO decks (` + deckColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err = r.db.ExecContext(ctx, query,
+		deck.ID.String(),
+		deck.DocumentID.String(),
+		deck.Title,
+		string(slides),
+		deck.SlideCount,
+		deck.Language,
+		deck.CreatedAt,
+	)
+	return err
+}
+
+func (r *DeckRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Deck, error) {
+	query := `SELECT ` + deckColumns + ` FROM decks WHERE id = $1`
+	return scanDeck(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *DeckRepository) GetByDocumentID(ctx context.Context, documentID uuid.UUID) ([]*models.Deck, error) {
+	query := `SELECT ` + deckColumns + ` FROM decks WHERE document_id = $1 ORDER BY created_at DESC, id`
+
+	rows, err := r.db.QueryContext(ctx, query, documentID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var decks []*models.Deck
+	for rows.Next() {
+		deck, err := scanDeck(rows)
+		if err != nil {
+			return nil, err
+		}
+		decks = append(decks, deck)
+	}
+	return decks, rows.Err()
+}
+
+func (r *DeckRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM decks WHERE id = $1`, id.String())
+	return err
+}
+
+func scanDeck(row rowScanner) (*models.Deck, error) {
+	var deck models.Deck
+	var idStr, documentIDStr, slides string
+	err := row.Scan(
+		&idStr,
+		&documentIDStr,
+		&deck.Title,
+		&slides,
+		&deck.SlideCount,
+		&deck.Language,
+		&deck.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(slides), &deck.Slides); err != nil {
+		return nil, err
+	}
+
+	deck.ID = uuid.MustParse(idStr)
+	deck.DocumentID = uuid.MustParse(documentIDStr)
+	return &deck, nil
+}
@@ -0,0 +1,410 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+)
+
+type DocumentRepository struct {
+	db *DB
+}
+
+func NewDocumentRepository(db *DB) *DocumentRepository {
+	return &DocumentRepository{db: db}
+}
+
+const documentColumns = `id, user_id, title, content, type, size, uploaded_at, processed_at, pages, original_key, metadata, source_encoding`
+
+func (r *DocumentRepository) Create(ctx context.Context, document *models.Document) error {
+	query := `
+		INSERT INTO documents (` + documentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	pages, err := marshalPages(document.Pages)
+	if err != nil {
+		return err
+	}
+	metadata, err := marshalMetadata(document.Metadata)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, query,
+		document.ID.String(),
+		document.UserID.String(),
+		document.Title,
+		document.Content,
+		string(document.Type),
+		document.Size,
+		document.UploadedAt,
+		document.ProcessedAt,
+		pages,
+		document.OriginalKey,
+		metadata,
+		document.SourceEncoding,
+	)
+	return err
+}
+
+func (r *DocumentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Document, error) {
+	query := `SELECT ` + documentColumns + ` FROM documents WHERE id = $1`
+
+	document, err := scanDocument(r.db.QueryRowContext(ctx, query, id.String()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+func (r *DocumentRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Document, error) {
+	query := `SELECT ` + documentColumns + ` FROM documents WHERE user_id = $1`
+
+	rows, err := r.db.QueryContext(ctx, query, userID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var documents []*models.Document
+	for rows.Next() {
+		document, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+
+	return documents, rows.Err()
+}
+
+func scanDocument(row rowScanner) (*models.Document, error) {
+	var document models.Document
+	var idStr, userIDStr, typeStr, pages, metadata string
+	err := row.Scan(
+		&idStr,
+		&userIDStr,
+		&document.Title,
+		&document.Content,
+		&typeStr,
+		&document.Size,
+		&document.UploadedAt,
+		&document.ProcessedAt,
+		&pages,
+		&document.OriginalKey,
+		&metadata,
+		&document.SourceEncoding,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	document.ID = uuid.MustParse(idStr)
+	document.UserID = uuid.MustParse(userIDStr)
+	document.Type = models.DocumentType(typeStr)
+	if document.Pages, err = unmarshalPages(pages); err != nil {
+		return nil, err
+	}
+	if document.Metadata, err = unmarshalMetadata(metadata); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+func (r *DocumentRepository) ListByUserID(ctx context.Context, userID uuid.UUID, opts repository.DocumentListOptions) ([]*models.DocumentListItem, error) {
+	var args []interface{}
+	// arg adds a query argument and returns its placeholder.
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"user_id = " + arg(userID.String())}
+
+	if opts.Type != "" {
+		conditions = append(conditions, "type = "+arg(string(opts.Type)))
+	}
+	if opts.Processed != nil {
+		if *opts.Processed {
+			conditions = append(conditions, "processed_at IS NOT NULL")
+		} else {
+			conditions = append(conditions, "processed_at IS NULL")
+		}
+	}
+	if opts.UploadedFrom != nil {
+		conditions = append(conditions, "uploaded_at >= "+arg(*opts.UploadedFrom))
+	}
+	if opts.UploadedTo != nil {
+		conditions = append(conditions, "uploaded_at < "+arg(*opts.UploadedTo))
+	}
+	if opts.TitleContains != "" {
+		// ILIKE matches the case-insensitive LIKE of the SQLite backend.
+		conditions = append(conditions, `title ILIKE `+arg("%"+escapeLike(opts.TitleContains)+"%")+` ESCAPE '\'`)
+	}
+
+	column, cursorValue := documentSortColumn(opts.SortBy, opts.After)
+	direction, comparator := "DESC", "<"
+	if opts.SortOrder == repository.SortAsc {
+		direction, comparator = "ASC", ">"
+	}
+	if opts.After != nil {
+		value := arg(cursorValue)
+		conditions = append(conditions, fmt.Sprintf("(%[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND id %[2]s %[4]s))",
+			column, comparator, value, arg(opts.After.ID.String())))
+	}
+
+	query := fmt.Sprintf(`SELECT id, user_id, title, type, size, uploaded_at, processed_at FROM documents
+		WHERE %s
+		ORDER BY %s %s, id %s
+		LIMIT %s`, strings.Join(conditions, " AND "), column, direction, direction, arg(opts.Limit))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.DocumentListItem
+	for rows.Next() {
+		var item models.DocumentListItem
+		var idStr, userIDStr, typeStr string
+		err := rows.Scan(
+			&idStr,
+			&userIDStr,
+			&item.Title,
+			&typeStr,
+			&item.Size,
+			&item.UploadedAt,
+			&item.ProcessedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		item.ID = uuid.MustParse(idStr)
+		item.UserID = uuid.MustParse(userIDStr)
+		item.Type = models.DocumentType(typeStr)
+		items = append(items, &item)
+	}
+
+	return items, rows.Err()
+}
+
+// documentSortColumn maps a sort field to its column and the cursor value to compare it with.
+// Unknown fields fall back to the upload time. Titles compare byte-wise, as in SQLite, so the order does
+// not depend on the database locale.
+func documentSortColumn(field repository.DocumentSortField, cursor *repository.DocumentCursor) (string, interface{}) {
+	var value interface{}
+	switch field {
+	case repository.DocumentSortTitle:
+		if cursor != nil {
+			value = cursor.Title
+		}
+		return `title COLLATE "C"`, value
+	case repository.DocumentSortSize:
+		if cursor != nil {
+			value = cursor.Size
+		}
+		return "size", value
+	default:
+		if cursor != nil {
+			value = cursor.UploadedAt
+		}
+		return "uploaded_at", value
+	}
+}
+
+func escapeLike(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(s)
+}
+
+func (r *DocumentRepository) Update(ctx context.Context, document *models.Document) error {
+	query := `UPDATE documents SET title = $1, content = $2, size = $3, processed_at = $4, pages = $5 WHERE id = $6`
+
+	pages, err := marshalPages(document.Pages)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, query,
+		document.Title,
+		document.Content,
+		document.Size,
+		document.ProcessedAt,
+		pages,
+		document.ID.String(),
+	)
+	return err
+}
+
+// marshalPages encodes page spans for the pages column, which holds an empty array for documents without
+// pages.
+func marshalPages(pages []models.PageSpan) (string, error) {
+	if pages == nil {
+		pages = []models.PageSpan{}
+	}
+	data, err := json.Marshal(pages)
+	return string(data), err
+}
+
+func unmarshalPages(data string) ([]models.PageSpan, error) {
+	var pages []models.PageSpan
+	if err := json.Unmarshal([]byte(data), &pages); err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return nil, nil
+	}
+	return pages, nil
+}
+
+// marshalMetadata encodes metadata for the metadata column, which holds an empty object for documents
+// without metadata.
+func marshalMetadata(metadata map[string]string) (string, error) {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	data, err := json.Marshal(metadata)
+	return string(data), err
+}
+
+func unmarshalMetadata(data string) (map[string]string, error) {
+	var metadata map[string]string
+	if err := json.Unmarshal([]byte(data), &metadata); err != nil {
+		return nil, err
+	}
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	return metadata, nil
+}
+
+func (r *DocumentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM documents WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id.String())
+	return err
+}
+
+type SummaryRepository struct {
+	db *DB
+}
+
+func NewSummaryRepository(db *DB) *SummaryRepository {
+	return &SummaryRepository{db: db}
+}
+
+const summaryColumns = `id, document_id, content, status, length, target_chars, target_words, style, language, created_at, updated_at`
+
+func (r *SummaryRepository) Create(ctx context.Context, summary *models.Summary) error {
+	query := `
+		INSERT INTO summaries (` + summaryColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		summary.ID.String(),
+		summary.DocumentID.String(),
+		summary.Content,
+		string(summary.Status),
+		string(summary.Length),
+		summary.TargetChars,
+		summary.TargetWords,
+		string(summary.Style),
+		summary.Language,
+		summary.CreatedAt,
+		summary.UpdatedAt,
+	)
+	return err
+}
+
+func (r *SummaryRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Summary, error) {
+	query := `SELECT ` + summaryColumns + ` FROM summaries WHERE id = $1`
+
+	summary, err := scanSummary(r.db.QueryRowContext(ctx, query, id.String()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+func (r *SummaryRepository) GetByDocumentID(ctx context.Context, documentID uuid.UUID) ([]*models.Summary, error) {
+	query := `SELECT ` + summaryColumns + ` FROM summaries WHERE document_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, documentID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []*models.Summary
+	for rows.Next() {
+		summary, err := scanSummary(rows)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, rows.Err()
+}
+
+func scanSummary(row rowScanner) (*models.Summary, error) {
+	var summary models.Summary
+	var idStr, docIDStr, status, length, style string
+	err := row.Scan(
+		&idStr,
+		&docIDStr,
+		&summary.Content,
+		&status,
+		&length,
+		&summary.TargetChars,
+		&summary.TargetWords,
+		&style,
+		&summary.Language,
+		&summary.CreatedAt,
+		&summary.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.ID = uuid.MustParse(idStr)
+	summary.DocumentID = uuid.MustParse(docIDStr)
+	summary.Status = models.SummaryStatus(status)
+	summary.Length = models.SummaryLength(length)
+	summary.Style = models.SummaryStyle(style)
+	return &summary, nil
+}
+
+func (r *SummaryRepository) Update(ctx context.Context, summary *models.Summary) error {
+	query := `UPDATE summaries SET content = $1, updated_at = $2 WHERE id = $3`
+
+	_, err := r.db.ExecContext(ctx, query,
+		summary.Content,
+		summary.UpdatedAt,
+		summary.ID.String(),
+	)
+	return err
+}
+
+func (r *SummaryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM summaries WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id.String())
+	return err
+}
+
+func (r *SummaryRepository) DeleteByDocumentID(ctx context.Context, documentID uuid.UUID) error {
+	query := `DELETE FROM summaries WHERE document_id = $1`
+	_, err := r.db.ExecContext(ctx, query, documentID.String())
+	return err
+}
@@ -0,0 +1,223 @@
+package postgres
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/pkg/vector"
+)
+
+type EmbeddingRepository struct {
+	db *DB
+}
+
+func NewEmbeddingRepository(db *DB) *EmbeddingRepository {
+	return &EmbeddingRepository{db: db}
+}
+
+func (r *EmbeddingRepository) ReplaceDocumentChunks(ctx context.Context, documentID uuid.UUID, chunks []*models.DocumentChunk) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM document_chunks WHERE document_id = $1`, documentID.String()); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO document_chunks (id, document_id, chunk_index, heading, start_offset, end_offset, content, model, embedding, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, chunk := range chunks {
+		_, err := stmt.ExecContext(ctx,
+			chunk.ID.String(),
+			chunk.DocumentID.String(),
+			chunk.Index,
+			chunk.Heading,
+			chunk.Start,
+			chunk.End,
+			chunk.Content,
+			chunk.Model,
+			vector.Encode(chunk.Embedding),
+			chunk.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *EmbeddingRepository) DeleteByDocumentID(ctx context.Context, documentID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM document_chunks WHERE document_id = $1`, documentID.String())
+	return err
+}
+
+func (r *EmbeddingRepository) ListByDocumentID(ctx context.Context, documentID uuid.UUID, model string) ([]*models.DocumentChunk, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, document_id, chunk_index, heading, start_offset, end_offset, content, model, embedding, created_at
+		FROM document_chunks WHERE document_id = $1 AND model = $2
+		ORDER BY chunk_index`, documentID.String(), model)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chunks []*models.DocumentChunk
+	for rows.Next() {
+		var chunk models.DocumentChunk
+		var idStr, docIDStr string
+		var blob []byte
+		err := rows.Scan(
+			&idStr,
+			&docIDStr,
+			&chunk.Index,
+			&chunk.Heading,
+			&chunk.Start,
+			&chunk.End,
+			&chunk.Content,
+			&chunk.Model,
+			&blob,
+			&chunk.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if chunk.Embedding, err = vector.Decode(blob); err != nil {
+			return nil, err
+		}
+
+		chunk.ID = uuid.MustParse(idStr)
+		chunk.DocumentID = uuid.MustParse(docIDStr)
+		chunks = append(chunks, &chunk)
+	}
+	return chunks, rows.Err()
+}
+
+// Nearest scores every chunk of the user against the query and keeps the best ones, then loads their
+// text. Exhaustive search is exact and fast enough for thousands of chunks.
+func (r *EmbeddingRepository) Nearest(ctx context.Context, userID uuid.UUID, model string, query []float32, limit int) ([]*models.ChunkMatch, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.id, c.embedding FROM document_chunks c JOIN documents d ON d.id = c.document_id
+		WHERE d.user_id = $1 AND c.model = $2`, userID.String(), model)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	best := vector.NewTopK[string](limit)
+	for rows.Next() {
+		var id string
+		var blob []byte
+		if err := rows.Scan(&id, &blob); err != nil {
+			return nil, err
+		}
+		embedding, err := vector.Decode(blob)
+		if err != nil {
+			return nil, err
+		}
+		if len(embedding) != len(query) {
+			continue
+		}
+		best.Push(id, vector.Dot(query, embedding))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	results := best.Results()
+	if len(results) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.Value
+	}
+	matches, err := r.loadMatches(ctx, `c.id = ANY($1::uuid[])`, []interface{}{pq.Array(ids)})
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make([]*models.ChunkMatch, 0, len(results))
+	for _, result := range results {
+		if match, ok := matches[result.Value]; ok {
+			match.Score = float64(result.Score)
+			ordered = append(ordered, match)
+		}
+	}
+	return ordered, nil
+}
+
+func (r *EmbeddingRepository) loadMatches(ctx context.Context, condition string, args []interface{}) (map[string]*models.ChunkMatch, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.id, c.document_id, c.chunk_index, c.heading, c.start_offset, c.end_offset, c.content, c.model, c.created_at, d.title
+		FROM document_chunks c JOIN documents d ON d.id = c.document_id
+		WHERE `+condition, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make(map[string]*models.ChunkMatch)
+	for rows.Next() {
+		var match models.ChunkMatch
+		var idStr, docIDStr string
+		err := rows.Scan(
+			&idStr,
+			&docIDStr,
+			&match.Index,
+			&match.Heading,
+			&match.Start,
+			&match.End,
+			&match.Content,
+			&match.Model,
+			&match.CreatedAt,
+			&match.Title,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		match.ID = uuid.MustParse(idStr)
+		match.DocumentID = uuid.MustParse(docIDStr)
+		matches[idStr] = &match
+	}
+	return matches, rows.Err()
+}
+
+func (r *EmbeddingRepository) ListUnembedded(ctx context.Context, model string, limit int) ([]repository.UnembeddedDocument, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT d.id, d.user_id FROM documents d
+		WHERE d.content <> ''
+			AND NOT EXISTS (SELECT 1 FROM document_chunks c WHERE c.document_id = d.id AND c.model = $1)
+			AND NOT EXISTS (SELECT 1 FROM jobs j WHERE j.document_id = d.id AND j.type = $2 AND j.status IN ($3, $4))
+		ORDER BY d.uploaded_at
+		LIMIT $5`,
+		model, string(models.JobTypeEmbedding), string(models.JobStatusQueued), string(models.JobStatusRunning), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var documents []repository.UnembeddedDocument
+	for rows.Next() {
+		var idStr, userIDStr string
+		if err := rows.Scan(&idStr, &userIDStr); err != nil {
+			return nil, err
+		}
+		documents = append(documents, repository.UnembeddedDocument{
+			DocumentID: uuid.MustParse(idStr),
+			UserID:     uuid.MustParse(userIDStr),
+		})
+	}
+	return documents, rows.Err()
+}
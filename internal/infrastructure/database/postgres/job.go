@@ -0,0 +1,245 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type JobRepository struct {
+	db *DB
+}
+
+func NewJobRepository(db *DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+const jobColumns = `id, user_id, document_id, type, status, payload, result_id, error, attempts, created_at, updated_at, started_at, finished_at, worker_id, locked_until`
+
+func (r *JobRepository) Create(ctx context.Context, job *models.Job) error {
+	query := `
+		INSERT INTO jobs (` + jobColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+
+	_, err := r.db.ExecContext(ctx, query, jobInsertArgs(job)...)
+	return err
+}
+
+func (r *JobRepository) CreateIfNotQueued(ctx context.Context, job *models.Job) (bool, error) {
+	// The unique index on queued embedding jobs rejects a second one, even when both are inserted at once.
+	query := `
+		INSERT INTO jobs (` + jobColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT DO NOTHING`
+
+	result, err := r.db.ExecContext(ctx, query, jobInsertArgs(job)...)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+func (r *JobRepository) HasQueued(ctx context.Context, documentID uuid.UUID, jobType models.JobType) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM jobs WHERE document_id = $1 AND type = $2 AND status = $3)`
+
+	var queued bool
+	err := r.db.QueryRowContext(ctx, query, documentID.String(), string(jobType), string(models.JobStatusQueued)).Scan(&queued)
+	return queued, err
+}
+
+func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE id = $1`
+	return scanJob(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+// jobUpdateColumns take $1 to $9; queries using them continue from $10.
+const jobUpdateColumns = `status = $1, result_id = $2, error = $3, attempts = $4, updated_at = $5, started_at = $6, finished_at = $7, worker_id = $8, locked_until = $9`
+
+func (r *JobRepository) Update(ctx context.Context, job *models.Job) error {
+	query := `UPDATE jobs SET ` + jobUpdateColumns + ` WHERE id = $10`
+
+	_, err := r.db.ExecContext(ctx, query, append(jobUpdateArgs(job), job.ID.String())...)
+	return err
+}
+
+func (r *JobRepository) ClaimNext(ctx context.Context, workerID string, lease time.Duration) (*models.Job, error) {
+	// SKIP LOCKED lets several workers, possibly in different processes, claim different jobs at once
+	// instead of queueing on the same row.
+	query := `
+		UPDATE jobs SET status = $1, attempts = attempts + 1, started_at = $2, updated_at = $2, worker_id = $3, locked_until = $4
+		WHERE id = (
+			SELECT id FROM jobs WHERE status = $5 ORDER BY created_at, id LIMIT 1 FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + jobColumns
+
+	now := time.Now()
+	return scanJob(r.db.QueryRowContext(ctx, query,
+		string(models.JobStatusRunning),
+		now,
+		workerID,
+		now.Add(lease),
+		string(models.JobStatusQueued),
+	))
+}
+
+func (r *JobRepository) RenewLease(ctx context.Context, id uuid.UUID, workerID string, until time.Time) (bool, error) {
+	query := `UPDATE jobs SET locked_until = $1 WHERE id = $2 AND worker_id = $3 AND status = $4`
+
+	result, err := r.db.ExecContext(ctx, query, until, id.String(), workerID, string(models.JobStatusRunning))
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+func (r *JobRepository) UpdateLeased(ctx context.Context, job *models.Job, workerID string) (bool, error) {
+	query := `UPDATE jobs SET ` + jobUpdateColumns + ` WHERE id = $10 AND worker_id = $11 AND status = $12`
+
+	args := append(jobUpdateArgs(job), job.ID.String(), workerID, string(models.JobStatusRunning))
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+func (r *JobRepository) GetByStatus(ctx context.Context, status models.JobStatus) ([]*models.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE status = $1 ORDER BY created_at, id`
+	return r.queryJobs(ctx, query, string(status))
+}
+
+func (r *JobRepository) GetExpired(ctx context.Context, now time.Time) ([]*models.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE status = $1 AND (locked_until IS NULL OR locked_until < $2) ORDER BY created_at, id`
+	return r.queryJobs(ctx, query, string(models.JobStatusRunning), now)
+}
+
+func (r *JobRepository) UpdateExpired(ctx context.Context, job *models.Job, now time.Time) (bool, error) {
+	query := `
+		UPDATE jobs SET ` + jobUpdateColumns + `
+		WHERE id = $10 AND status = $11 AND (locked_until IS NULL OR locked_until < $12)`
+
+	args := append(jobUpdateArgs(job), job.ID.String(), string(models.JobStatusRunning), now)
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+func (r *JobRepository) queryJobs(ctx context.Context, query string, args ...interface{}) ([]*models.Job, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*models.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// jobInsertArgs returns the values of jobColumns.
+func jobInsertArgs(job *models.Job) []interface{} {
+	return []interface{}{
+		job.ID.String(),
+		job.UserID.String(),
+		job.DocumentID.String(),
+		string(job.Type),
+		string(job.Status),
+		jobPayload(job.Payload),
+		nullableUUID(job.ResultID),
+		job.Error,
+		job.Attempts,
+		job.CreatedAt,
+		job.UpdatedAt,
+		job.StartedAt,
+		job.FinishedAt,
+		job.WorkerID,
+		job.LockedUntil,
+	}
+}
+
+// jobUpdateArgs returns the values of jobUpdateColumns.
+func jobUpdateArgs(job *models.Job) []interface{} {
+	return []interface{}{
+		string(job.Status),
+		nullableUUID(job.ResultID),
+		job.Error,
+		job.Attempts,
+		job.UpdatedAt,
+		job.StartedAt,
+		job.FinishedAt,
+		job.WorkerID,
+		job.LockedUntil,
+	}
+}
+
+func scanJob(row rowScanner) (*models.Job, error) {
+	var job models.Job
+	var idStr, userIDStr, documentIDStr, jobType, status, payload string
+	var resultID sql.NullString
+	err := row.Scan(
+		&idStr,
+		&userIDStr,
+		&documentIDStr,
+		&jobType,
+		&status,
+		&payload,
+		&resultID,
+		&job.Error,
+		&job.Attempts,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+		&job.WorkerID,
+		&job.LockedUntil,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job.ID = uuid.MustParse(idStr)
+	job.UserID = uuid.MustParse(userIDStr)
+	job.DocumentID = uuid.MustParse(documentIDStr)
+	job.Type = models.JobType(jobType)
+	job.Status = models.JobStatus(status)
+	job.Payload = []byte(payload)
+	if resultID.Valid {
+		id := uuid.MustParse(resultID.String)
+		job.ResultID = &id
+	}
+	return &job, nil
+}
+
+// jobPayload stores an empty payload as an empty JSON object, since the column is JSONB.
+func jobPayload(payload []byte) string {
+	if len(payload) == 0 {
+		return "{}"
+	}
+	return string(payload)
+}
+
+func nullableUUID(id *uuid.UUID) interface{} {
+	if id == nil {
+		return nil
+	}
+	return id.String()
+}
@@ -0,0 +1,38 @@
+-- Baseline schema, mirroring the SQLite one. Child rows are removed with their parents, because unlike
+-- SQLite, Postgres enforces the foreign keys.
+
+CREATE TABLE IF NOT EXISTS users (
+	id UUID PRIMARY KEY,
+	email TEXT UNIQUE NOT NULL,
+	password TEXT NOT NULL,
+	name TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS documents (
+	id UUID PRIMARY KEY,
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	title TEXT NOT NULL,
+	content TEXT NOT NULL,
+	type TEXT NOT NULL,
+	size BIGINT NOT NULL,
+	uploaded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	processed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_documents_user_uploaded ON documents(user_id, uploaded_at);
+
+CREATE TABLE IF NOT EXISTS summaries (
+	id UUID PRIMARY KEY,
+	document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+	content TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'completed',
+	length TEXT NOT NULL DEFAULT '',
+	target_chars INTEGER NOT NULL DEFAULT 0,
+	target_words INTEGER NOT NULL DEFAULT 0,
+	style TEXT NOT NULL DEFAULT '',
+	language TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_summaries_document_created;
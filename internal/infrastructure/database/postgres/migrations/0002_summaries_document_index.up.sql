@@ -0,0 +1,2 @@
+-- Summaries are always listed per document, newest first.
+CREATE INDEX IF NOT EXISTS idx_summaries_document_created ON summaries(document_id, created_at);
@@ -0,0 +1,16 @@
+-- Embedded chunks for semantic search. Version 0003 is only used by SQLite.
+
+CREATE TABLE document_chunks (
+	id UUID PRIMARY KEY,
+	document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+	chunk_index INTEGER NOT NULL,
+	heading TEXT NOT NULL DEFAULT '',
+	start_offset INTEGER NOT NULL,
+	end_offset INTEGER NOT NULL,
+	content TEXT NOT NULL,
+	model TEXT NOT NULL,
+	embedding BYTEA NOT NULL, -- little-endian float32s, normalized to unit length
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_document_chunks_document ON document_chunks(document_id, chunk_index);
@@ -0,0 +1,23 @@
+-- Chat threads about a document and their messages.
+
+CREATE TABLE conversations (
+	id UUID PRIMARY KEY,
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+	title TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_conversations_document ON conversations(document_id, user_id, updated_at);
+
+CREATE TABLE messages (
+	id UUID PRIMARY KEY,
+	conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+	role TEXT NOT NULL,
+	content TEXT NOT NULL,
+	citations JSONB NOT NULL DEFAULT '[]', -- citations of assistant messages
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_messages_conversation ON messages(conversation_id, created_at);
@@ -0,0 +1,13 @@
+-- Slide decks generated from a document.
+
+CREATE TABLE decks (
+	id UUID PRIMARY KEY,
+	document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+	title TEXT NOT NULL,
+	slides JSONB NOT NULL,
+	slide_count INTEGER NOT NULL DEFAULT 0, -- requested number of slides
+	language TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_decks_document ON decks(document_id, created_at);
@@ -0,0 +1,15 @@
+-- Diagrams proposed for a document, kept as their Mermaid or PlantUML source.
+
+CREATE TABLE diagrams (
+	id UUID PRIMARY KEY,
+	document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+	kind TEXT NOT NULL, -- flowchart, sequence or mindmap
+	syntax TEXT NOT NULL, -- mermaid or plantuml
+	title TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	source TEXT NOT NULL,
+	language TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_diagrams_document ON diagrams(document_id, created_at);
@@ -0,0 +1,18 @@
+-- Files generated from a document. Their content is kept in the blob store under storage_key.
+
+CREATE TABLE artifacts (
+	id UUID PRIMARY KEY,
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+	kind TEXT NOT NULL, -- deck, diagram or image
+	name TEXT NOT NULL,
+	mime_type TEXT NOT NULL,
+	params JSONB NOT NULL DEFAULT '{}', -- generator parameters
+	size BIGINT NOT NULL,
+	checksum TEXT NOT NULL, -- hex SHA-256 of the content
+	storage_key TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_artifacts_user ON artifacts(user_id, created_at);
+CREATE INDEX idx_artifacts_document ON artifacts(document_id, created_at);
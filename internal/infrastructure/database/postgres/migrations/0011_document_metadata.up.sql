@@ -0,0 +1,3 @@
+-- What an uploaded file says about itself, such as the title and canonical URL of a web page: a JSON
+-- object of strings. Empty for documents whose files say nothing.
+ALTER TABLE documents ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
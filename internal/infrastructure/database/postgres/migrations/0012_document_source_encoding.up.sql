@@ -0,0 +1,3 @@
+-- The character encoding uploaded text was in before it was converted to UTF-8, such as Shift_JIS.
+-- Empty for documents uploaded before encodings were detected and for formats with their own encoding.
+ALTER TABLE documents ADD COLUMN source_encoding TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,20 @@
+-- Background jobs. IF NOT EXISTS lets databases from releases without the migration ledger,
+-- which created every table at start, adopt it.
+
+CREATE TABLE IF NOT EXISTS jobs (
+	id UUID PRIMARY KEY,
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+	type TEXT NOT NULL,
+	status TEXT NOT NULL,
+	payload JSONB NOT NULL DEFAULT '{}',
+	result_id UUID,
+	error TEXT NOT NULL DEFAULT '',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	started_at TIMESTAMPTZ,
+	finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_status_created ON jobs(status, created_at);
@@ -0,0 +1,2 @@
+ALTER TABLE jobs DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE jobs DROP COLUMN IF EXISTS worker_id;
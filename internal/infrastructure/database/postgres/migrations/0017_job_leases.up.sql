@@ -0,0 +1,4 @@
+-- The worker running a job and when its lease runs out. Workers renew the lease while they run a job, so
+-- a running job whose lease has run out was left by a process that stopped and can be queued again.
+ALTER TABLE jobs ADD COLUMN worker_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN locked_until TIMESTAMPTZ;
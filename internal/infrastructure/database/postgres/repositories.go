@@ -0,0 +1,20 @@
+package postgres
+
+import "github/k-tsurumaki/quilldeck/internal/domain/repository"
+
+func NewRepositories(db *DB) *repository.Repositories {
+	return &repository.Repositories{
+		Users:         NewUserRepository(db),
+		Documents:     NewDocumentRepository(db),
+		Summaries:     NewSummaryRepository(db),
+		Sessions:      NewSessionRepository(db),
+		APIKeys:       NewAPIKeyRepository(db),
+		Jobs:          NewJobRepository(db),
+		Search:        NewSearchRepository(db),
+		Embeddings:    NewEmbeddingRepository(db),
+		Conversations: NewConversationRepository(db),
+		Decks:         NewDeckRepository(db),
+		Diagrams:      NewDiagramRepository(db),
+		Artifacts:     NewArtifactRepository(db),
+	}
+}
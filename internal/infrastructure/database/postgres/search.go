@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+)
+
+// SearchRepository searches documents and summaries with ILIKE scans, ranking documents by how many
+// terms their title contains, like the SQLite backend built without FTS5.
+type SearchRepository struct {
+	db *DB
+}
+
+func NewSearchRepository(db *DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+func (r *SearchRepository) Search(ctx context.Context, userID uuid.UUID, opts repository.SearchOptions) ([]*models.SearchHit, error) {
+	var args []interface{}
+	// arg adds a query argument and returns its placeholder.
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	// contains requires every term to occur in one of columns.
+	contains := func(columns ...string) []string {
+		var conditions []string
+		for _, term := range opts.Terms {
+			pattern := arg("%" + escapeLike(term) + "%")
+			alternatives := make([]string, len(columns))
+			for i, column := range columns {
+				alternatives[i] = column + ` ILIKE ` + pattern + ` ESCAPE '\'`
+			}
+			conditions = append(conditions, "("+strings.Join(alternatives, " OR ")+")")
+		}
+		return conditions
+	}
+
+	titleMatches := make([]string, len(opts.Terms))
+	for i, term := range opts.Terms {
+		titleMatches[i] = `(CASE WHEN title ILIKE ` + arg("%"+escapeLike(term)+"%") + ` ESCAPE '\' THEN 1 ELSE 0 END)`
+	}
+	user := arg(userID.String())
+	documentConditions := append(contains("title", "content"), "user_id = "+user)
+	summaryConditions := append(contains("s.content"), "d.user_id = "+user)
+
+	query := fmt.Sprintf(`SELECT 'document' AS kind, id AS document_id, NULL::uuid AS summary_id, title, content AS text,
+			uploaded_at AS created_at, -(%s) AS rank
+		FROM documents
+		WHERE %s
+		UNION ALL
+		SELECT 'summary', d.id, s.id, d.title, s.content, s.created_at, 0
+		FROM summaries s JOIN documents d ON d.id = s.document_id
+		WHERE %s
+		ORDER BY rank, created_at DESC
+		LIMIT %s OFFSET %s`,
+		strings.Join(titleMatches, " + "),
+		strings.Join(documentConditions, " AND "),
+		strings.Join(summaryConditions, " AND "),
+		arg(opts.Limit), arg(opts.Offset))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []*models.SearchHit
+	for rows.Next() {
+		var hit models.SearchHit
+		var kind, docIDStr string
+		var summaryIDStr sql.NullString
+		var rank int
+		if err := rows.Scan(&kind, &docIDStr, &summaryIDStr, &hit.Title, &hit.Text, &hit.CreatedAt, &rank); err != nil {
+			return nil, err
+		}
+
+		hit.Kind = models.SearchHitKind(kind)
+		hit.DocumentID = uuid.MustParse(docIDStr)
+		if summaryIDStr.Valid {
+			summaryID := uuid.MustParse(summaryIDStr.String)
+			hit.SummaryID = &summaryID
+		}
+		hits = append(hits, &hit)
+	}
+	return hits, rows.Err()
+}
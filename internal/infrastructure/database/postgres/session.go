@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type SessionRepository struct {
+	db *DB
+}
+
+func NewSessionRepository(db *DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+const sessionColumns = `id, user_id, refresh_token_hash, user_agent, ip_address, created_at, last_used_at, expires_at, revoked_at`
+
+func (r *SessionRepository) Create(ctx context.Context, session *models.Session) error {
+	query := `
+		INSERT INTO sessions (` + sessionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		session.ID.String(),
+		session.UserID.String(),
+		session.RefreshTokenHash,
+		session.UserAgent,
+		session.IPAddress,
+		session.CreatedAt,
+		session.LastUsedAt,
+		session.ExpiresAt,
+		session.RevokedAt,
+	)
+	return err
+}
+
+func (r *SessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE id = $1`
+	return scanSession(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *SessionRepository) GetByRefreshTokenHash(ctx context.Context, hash string) (*models.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE refresh_token_hash = $1`
+	return scanSession(r.db.QueryRowContext(ctx, query, hash))
+}
+
+func (r *SessionRepository) GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_used_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID.String(), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*models.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *SessionRepository) Update(ctx context.Context, session *models.Session) error {
+	query := `UPDATE sessions SET refresh_token_hash = $1, last_used_at = $2, revoked_at = $3 WHERE id = $4`
+
+	_, err := r.db.ExecContext(ctx, query,
+		session.RefreshTokenHash,
+		session.LastUsedAt,
+		session.RevokedAt,
+		session.ID.String(),
+	)
+	return err
+}
+
+func (r *SessionRepository) Rotate(ctx context.Context, session *models.Session, previousHash string) (bool, error) {
+	query := `UPDATE sessions SET refresh_token_hash = $1, last_used_at = $2
+		WHERE id = $3 AND refresh_token_hash = $4 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query,
+		session.RefreshTokenHash,
+		session.LastUsedAt,
+		session.ID.String(),
+		previousHash,
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+func (r *SessionRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), userID.String())
+	return err
+}
+
+func scanSession(row rowScanner) (*models.Session, error) {
+	var session models.Session
+	var idStr, userIDStr string
+	err := row.Scan(
+		&idStr,
+		&userIDStr,
+		&session.RefreshTokenHash,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	session.ID = uuid.MustParse(idStr)
+	session.UserID = uuid.MustParse(userIDStr)
+	return &session, nil
+}
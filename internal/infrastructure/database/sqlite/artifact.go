@@ -0,0 +1,122 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+)
+
+type ArtifactRepository struct {
+	db *DB
+}
+
+func NewArtifactRepository(db *DB) *ArtifactRepository {
+	return &ArtifactRepository{db: db}
+}
+
+const artifactColumns = `id, user_id, document_id, kind, name, mime_type, params, size, checksum, storage_key, created_at`
+
+func (r *ArtifactRepository) Create(ctx context.Context, artifact *models.Artifact) error {
+	params, err := json.Marshal(artifact.Params)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO artifacts (` + artifactColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = r.db.ExecContext(ctx, query,
+		artifact.ID.String(),
+		artifact.UserID.String(),
+		artifact.DocumentID.String(),
+		string(artifact.Kind),
+		artifact.Name,
+		artifact.MIMEType,
+		string(params),
+		artifact.Size,
+		artifact.Checksum,
+		artifact.StorageKey,
+		artifact.CreatedAt,
+	)
+	return err
+}
+
+func (r *ArtifactRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Artifact, error) {
+	query := `SELECT ` + artifactColumns + ` FROM artifacts WHERE id = ?`
+	return scanArtifact(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *ArtifactRepository) GetByUserID(ctx context.Context, userID uuid.UUID, filter repository.ArtifactFilter) ([]*models.Artifact, error) {
+	conditions := []string{"user_id = ?"}
+	args := []interface{}{userID.String()}
+
+	if filter.DocumentID != uuid.Nil {
+		conditions = append(conditions, "document_id = ?")
+		args = append(args, filter.DocumentID.String())
+	}
+	if filter.Kind != "" {
+		conditions = append(conditions, "kind = ?")
+		args = append(args, string(filter.Kind))
+	}
+
+	query := `SELECT ` + artifactColumns + ` FROM artifacts WHERE ` + strings.Join(conditions, " AND ") +
+		` ORDER BY created_at DESC, rowid DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var artifacts []*models.Artifact
+	for rows.Next() {
+		artifact, err := scanArtifact(rows)
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, artifact)
+	}
+	return artifacts, rows.Err()
+}
+
+func (r *ArtifactRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM artifacts WHERE id = ?`, id.String())
+	return err
+}
+
+func scanArtifact(row rowScanner) (*models.Artifact, error) {
+	var artifact models.Artifact
+	var idStr, userIDStr, documentIDStr, kind, params string
+	err := row.Scan(
+		&idStr,
+		&userIDStr,
+		&documentIDStr,
+		&kind,
+		&artifact.Name,
+		&artifact.MIMEType,
+		&params,
+		&artifact.Size,
+		&artifact.Checksum,
+		&artifact.StorageKey,
+		&artifact.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(params), &artifact.Params); err != nil {
+		return nil, err
+	}
+
+	artifact.ID = uuid.MustParse(idStr)
+	artifact.UserID = uuid.MustParse(userIDStr)
+	artifact.DocumentID = uuid.MustParse(documentIDStr)
+	artifact.Kind = models.ArtifactKind(kind)
+	return &artifact, nil
+}
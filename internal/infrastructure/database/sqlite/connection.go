@@ -1,18 +1,73 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
+	"embed"
 	"fmt"
+	"io/fs"
+	"strings"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/database/migrate"
 )
 
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
 type DB struct {
 	*sql.DB
 }
 
+// timestampFormat is how times are stored: in UTC and with every digit of the fraction, so that comparing
+// timestamps as text, as indexes and WHERE clauses do, orders them by time. The driver would otherwise
+// store each time in its own zone and drop trailing zeros of the fraction.
+const timestampFormat = "2006-01-02 15:04:05.000000000-07:00"
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(timestampFormat)
+}
+
+// timestampArgs formats the times among query arguments as timestamp does.
+func timestampArgs(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			converted[i] = timestamp(v)
+		case *time.Time:
+			if v != nil {
+				converted[i] = timestamp(*v)
+			}
+		default:
+			converted[i] = arg
+		}
+	}
+	return converted
+}
+
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.DB.ExecContext(ctx, query, timestampArgs(args)...)
+}
+
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.DB.QueryContext(ctx, query, timestampArgs(args)...)
+}
+
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRowContext(ctx, query, timestampArgs(args)...)
+}
+
 func NewConnection(dbPath string) (*DB, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	// Background workers write concurrently with request handlers; wait for the lock instead of failing
+	// immediately with SQLITE_BUSY.
+	dsn := dbPath
+	if !strings.Contains(dsn, "?") {
+		dsn += "?_busy_timeout=5000"
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -24,51 +79,86 @@ func NewConnection(dbPath string) (*DB, error) {
 	return &DB{DB: db}, nil
 }
 
+// Migrator returns a migrator over the migrations embedded from the migrations directory, plus those of the
+// FTS5 search index, which only builds with the sqlite_fts5 tag run.
+func (db *DB) Migrator() (*migrate.Migrator, error) {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := migrate.Load(files)
+	if err != nil {
+		return nil, err
+	}
+	search, err := searchMigrations()
+	if err != nil {
+		return nil, err
+	}
+	migrations = append(migrations, search...)
+	return migrate.New(db.DB, migrate.SQLite, migrations, migrate.Options{Adopt: adoptLegacySchema, AfterUp: syncSearchIndex}), nil
+}
+
+// RunMigrations applies every pending migration.
 func (db *DB) RunMigrations() error {
-	migrations := []string{
-		createUsersTable,
-		createDocumentsTable,
-		createSummariesTable,
+	migrator, err := db.Migrator()
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
 	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	return nil
+}
+
+// legacyColumns were added with ALTER TABLE before the migration ledger existed. Databases from that
+// time may lack some of them even though the baseline migration's tables already exist.
+var legacyColumns = []struct {
+	table, name, definition string
+}{
+	{"summaries", "length", "TEXT NOT NULL DEFAULT ''"},
+	{"summaries", "target_chars", "INTEGER NOT NULL DEFAULT 0"},
+	{"summaries", "target_words", "INTEGER NOT NULL DEFAULT 0"},
+	{"summaries", "style", "TEXT NOT NULL DEFAULT ''"},
+	{"summaries", "language", "TEXT NOT NULL DEFAULT ''"},
+	{"summaries", "status", "TEXT NOT NULL DEFAULT 'completed'"},
+}
 
-	for _, migration := range migrations {
-		if _, err := db.Exec(migration); err != nil {
-			return fmt.Errorf("migration failed: %w", err)
+// adoptLegacySchema completes tables created by releases without the migration ledger so they match the
+// baseline migration.
+func adoptLegacySchema(ctx context.Context, tx *sql.Tx) error {
+	for _, column := range legacyColumns {
+		if err := addColumnIfMissing(ctx, tx, column.table, column.name, column.definition); err != nil {
+			return err
 		}
 	}
-
 	return nil
 }
 
-const createUsersTable = `
-CREATE TABLE IF NOT EXISTS users (
-	id TEXT PRIMARY KEY,
-	email TEXT UNIQUE NOT NULL,
-	password TEXT NOT NULL,
-	name TEXT NOT NULL,
-	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-);`
-
-const createDocumentsTable = `
-CREATE TABLE IF NOT EXISTS documents (
-	id TEXT PRIMARY KEY,
-	user_id TEXT NOT NULL,
-	title TEXT NOT NULL,
-	content TEXT NOT NULL,
-	type TEXT NOT NULL,
-	size INTEGER NOT NULL,
-	uploaded_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-	processed_at DATETIME,
-	FOREIGN KEY (user_id) REFERENCES users(id)
-);`
-
-const createSummariesTable = `
-CREATE TABLE IF NOT EXISTS summaries (
-	id TEXT PRIMARY KEY,
-	document_id TEXT NOT NULL,
-	content TEXT NOT NULL,
-	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-	FOREIGN KEY (document_id) REFERENCES documents(id)
-);`
\ No newline at end of file
+func addColumnIfMissing(ctx context.Context, tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, colType    string
+			defaultValue     sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
@@ -0,0 +1,164 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type ConversationRepository struct {
+	db *DB
+}
+
+func NewConversationRepository(db *DB) *ConversationRepository {
+	return &ConversationRepository{db: db}
+}
+
+const (
+	conversationColumns = `id, user_id, document_id, title, created_at, updated_at`
+	messageColumns      = `id, conversation_id, role, content, citations, created_at`
+)
+
+func (r *ConversationRepository) Create(ctx context.Context, conversation *models.Conversation) error {
+	query := `INSERT INTO conversations (` + conversationColumns + `) VALUES (?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		conversation.ID.String(),
+		conversation.UserID.String(),
+		conversation.DocumentID.String(),
+		conversation.Title,
+		conversation.CreatedAt,
+		conversation.UpdatedAt,
+	)
+	return err
+}
+
+func (r *ConversationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Conversation, error) {
+	query := `SELECT ` + conversationColumns + ` FROM conversations WHERE id = ?`
+	return scanConversation(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *ConversationRepository) ListByDocumentID(ctx context.Context, userID, documentID uuid.UUID) ([]*models.Conversation, error) {
+	query := `
+		SELECT ` + conversationColumns + ` FROM conversations
+		WHERE document_id = ? AND user_id = ?
+		ORDER BY updated_at DESC, id`
+
+	rows, err := r.db.QueryContext(ctx, query, documentID.String(), userID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conversations []*models.Conversation
+	for rows.Next() {
+		conversation, err := scanConversation(rows)
+		if err != nil {
+			return nil, err
+		}
+		conversations = append(conversations, conversation)
+	}
+	return conversations, rows.Err()
+}
+
+func (r *ConversationRepository) Update(ctx context.Context, conversation *models.Conversation) error {
+	query := `UPDATE conversations SET title = ?, updated_at = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, conversation.Title, conversation.UpdatedAt, conversation.ID.String())
+	return err
+}
+
+func (r *ConversationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	// The messages go with the conversation through the messages_conversation_delete trigger.
+	_, err := r.db.ExecContext(ctx, `DELETE FROM conversations WHERE id = ?`, id.String())
+	return err
+}
+
+func (r *ConversationRepository) CreateMessage(ctx context.Context, message *models.Message) error {
+	citations, err := encodeCitations(message.Citations)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO messages (` + messageColumns + `) VALUES (?, ?, ?, ?, ?, ?)`
+	_, err = r.db.ExecContext(ctx, query,
+		message.ID.String(),
+		message.ConversationID.String(),
+		message.Role,
+		message.Content,
+		citations,
+		message.CreatedAt,
+	)
+	return err
+}
+
+func (r *ConversationRepository) ListMessages(ctx context.Context, conversationID uuid.UUID) ([]*models.Message, error) {
+	query := `SELECT ` + messageColumns + ` FROM messages WHERE conversation_id = ? ORDER BY created_at, rowid`
+
+	rows, err := r.db.QueryContext(ctx, query, conversationID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*models.Message
+	for rows.Next() {
+		var message models.Message
+		var idStr, conversationIDStr, citations string
+		err := rows.Scan(
+			&idStr,
+			&conversationIDStr,
+			&message.Role,
+			&message.Content,
+			&citations,
+			&message.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(citations), &message.Citations); err != nil {
+			return nil, err
+		}
+
+		message.ID = uuid.MustParse(idStr)
+		message.ConversationID = uuid.MustParse(conversationIDStr)
+		messages = append(messages, &message)
+	}
+	return messages, rows.Err()
+}
+
+func scanConversation(row rowScanner) (*models.Conversation, error) {
+	var conversation models.Conversation
+	var idStr, userIDStr, documentIDStr string
+	err := row.Scan(
+		&idStr,
+		&userIDStr,
+		&documentIDStr,
+		&conversation.Title,
+		&conversation.CreatedAt,
+		&conversation.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	conversation.ID = uuid.MustParse(idStr)
+	conversation.UserID = uuid.MustParse(userIDStr)
+	conversation.DocumentID = uuid.MustParse(documentIDStr)
+	return &conversation, nil
+}
+
+// encodeCitations stores missing citations as an empty JSON array.
+func encodeCitations(citations []models.Citation) (string, error) {
+	if citations == nil {
+		citations = []models.Citation{}
+	}
+	encoded, err := json.Marshal(citations)
+	return string(encoded), err
+}
@@ -0,0 +1,94 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type DiagramRepository struct {
+	db *DB
+}
+
+func NewDiagramRepository(db *DB) *DiagramRepository {
+	return &DiagramRepository{db: db}
+}
+
+const diagramColumns = `id, document_id, kind, syntax, title, description, source, language, created_at`
+
+func (r *DiagramRepository) Create(ctx context.Context, diagram *models.Diagram) error {
+	query := `INSERT INTO diagrams (` + diagramColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query,
+		diagram.ID.String(),
+		diagram.DocumentID.String(),
+		string(diagram.Kind),
+		string(diagram.Syntax),
+		diagram.Title,
+		diagram.Description,
+		diagram.Source,
+		diagram.Language,
+		diagram.CreatedAt,
+	)
+	return err
+}
+
+func (r *DiagramRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Diagram, error) {
+	query := `SELECT ` + diagramColumns + ` FROM diagrams WHERE id = ?`
+	return scanDiagram(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+func (r *DiagramRepository) GetByDocumentID(ctx context.Context, documentID uuid.UUID) ([]*models.Diagram, error) {
+	query := `SELECT ` + diagramColumns + ` FROM diagrams WHERE document_id = ? ORDER BY created_at DESC, rowid DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, documentID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var diagrams []*models.Diagram
+	for rows.Next() {
+		diagram, err := scanDiagram(rows)
+		if err != nil {
+			return nil, err
+		}
+		diagrams = append(diagrams, diagram)
+	}
+	return diagrams, rows.Err()
+}
+
+func (r *DiagramRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM diagrams WHERE id = ?`, id.String())
+	return err
+}
+
+func scanDiagram(row rowScanner) (*models.Diagram, error) {
+	var diagram models.Diagram
+	var idStr, documentIDStr, kind, syntax string
+	err := row.Scan(
+		&idStr,
+		&documentIDStr,
+		&kind,
+		&syntax,
+		&diagram.Title,
+		&diagram.Description,
+		&diagram.Source,
+		&diagram.Language,
+		&diagram.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	diagram.ID = uuid.MustParse(idStr)
+	diagram.DocumentID = uuid.MustParse(documentIDStr)
+	diagram.Kind = models.DiagramKind(kind)
+	diagram.Syntax = models.DiagramSyntax(syntax)
+	return &diagram, nil
+}
@@ -3,9 +3,13 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
 
-	"github/k-tsurumaki/quilldeck/internal/domain/models"
 	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
 )
 
 type DocumentRepository struct {
@@ -18,10 +22,18 @@ func NewDocumentRepository(db *DB) *DocumentRepository {
 
 func (r *DocumentRepository) Create(ctx context.Context, document *models.Document) error {
 	query := `
-		INSERT INTO documents (id, user_id, title, content, type, size, uploaded_at, processed_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+		INSERT INTO documents (id, user_id, title, content, type, size, uploaded_at, processed_at, pages, original_key, metadata, source_encoding)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	_, err := r.db.ExecContext(ctx, query,
+	pages, err := marshalPages(document.Pages)
+	if err != nil {
+		return err
+	}
+	metadata, err := marshalMetadata(document.Metadata)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, query,
 		document.ID.String(),
 		document.UserID.String(),
 		document.Title,
@@ -30,15 +42,19 @@ func (r *DocumentRepository) Create(ctx context.Context, document *models.Docume
 		document.Size,
 		document.UploadedAt,
 		document.ProcessedAt,
+		pages,
+		document.OriginalKey,
+		metadata,
+		document.SourceEncoding,
 	)
 	return err
 }
 
 func (r *DocumentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Document, error) {
-	query := `SELECT id, user_id, title, content, type, size, uploaded_at, processed_at FROM documents WHERE id = ?`
+	query := `SELECT id, user_id, title, content, type, size, uploaded_at, processed_at, pages, original_key, metadata, source_encoding FROM documents WHERE id = ?`
 
 	var document models.Document
-	var idStr, userIDStr, typeStr string
+	var idStr, userIDStr, typeStr, pages, metadata string
 	err := r.db.QueryRowContext(ctx, query, id.String()).Scan(
 		&idStr,
 		&userIDStr,
@@ -48,6 +64,10 @@ func (r *DocumentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 		&document.Size,
 		&document.UploadedAt,
 		&document.ProcessedAt,
+		&pages,
+		&document.OriginalKey,
+		&metadata,
+		&document.SourceEncoding,
 	)
 
 	if err == sql.ErrNoRows {
@@ -60,11 +80,17 @@ func (r *DocumentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	document.ID = uuid.MustParse(idStr)
 	document.UserID = uuid.MustParse(userIDStr)
 	document.Type = models.DocumentType(typeStr)
+	if document.Pages, err = unmarshalPages(pages); err != nil {
+		return nil, err
+	}
+	if document.Metadata, err = unmarshalMetadata(metadata); err != nil {
+		return nil, err
+	}
 	return &document, nil
 }
 
 func (r *DocumentRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Document, error) {
-	query := `SELECT id, user_id, title, content, type, size, uploaded_at, processed_at FROM documents WHERE user_id = ?`
+	query := `SELECT id, user_id, title, content, type, size, uploaded_at, processed_at, pages, original_key, metadata, source_encoding FROM documents WHERE user_id = ?`
 
 	rows, err := r.db.QueryContext(ctx, query, userID.String())
 	if err != nil {
@@ -75,7 +101,7 @@ func (r *DocumentRepository) GetByUserID(ctx context.Context, userID uuid.UUID)
 	var documents []*models.Document
 	for rows.Next() {
 		var document models.Document
-		var idStr, userIDStr, typeStr string
+		var idStr, userIDStr, typeStr, pages, metadata string
 		err := rows.Scan(
 			&idStr,
 			&userIDStr,
@@ -85,6 +111,10 @@ func (r *DocumentRepository) GetByUserID(ctx context.Context, userID uuid.UUID)
 			&document.Size,
 			&document.UploadedAt,
 			&document.ProcessedAt,
+			&pages,
+			&document.OriginalKey,
+			&metadata,
+			&document.SourceEncoding,
 		)
 		if err != nil {
 			return nil, err
@@ -93,24 +123,182 @@ func (r *DocumentRepository) GetByUserID(ctx context.Context, userID uuid.UUID)
 		document.ID = uuid.MustParse(idStr)
 		document.UserID = uuid.MustParse(userIDStr)
 		document.Type = models.DocumentType(typeStr)
+		if document.Pages, err = unmarshalPages(pages); err != nil {
+			return nil, err
+		}
+		if document.Metadata, err = unmarshalMetadata(metadata); err != nil {
+			return nil, err
+		}
 		documents = append(documents, &document)
 	}
 
 	return documents, nil
 }
 
+func (r *DocumentRepository) ListByUserID(ctx context.Context, userID uuid.UUID, opts repository.DocumentListOptions) ([]*models.DocumentListItem, error) {
+	conditions := []string{"user_id = ?"}
+	args := []interface{}{userID.String()}
+
+	if opts.Type != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, string(opts.Type))
+	}
+	if opts.Processed != nil {
+		if *opts.Processed {
+			conditions = append(conditions, "processed_at IS NOT NULL")
+		} else {
+			conditions = append(conditions, "processed_at IS NULL")
+		}
+	}
+	if opts.UploadedFrom != nil {
+		conditions = append(conditions, "uploaded_at >= ?")
+		args = append(args, *opts.UploadedFrom)
+	}
+	if opts.UploadedTo != nil {
+		conditions = append(conditions, "uploaded_at < ?")
+		args = append(args, *opts.UploadedTo)
+	}
+	if opts.TitleContains != "" {
+		conditions = append(conditions, `title LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(opts.TitleContains)+"%")
+	}
+
+	column, cursorValue := documentSortColumn(opts.SortBy, opts.After)
+	direction, comparator := "DESC", "<"
+	if opts.SortOrder == repository.SortAsc {
+		direction, comparator = "ASC", ">"
+	}
+	if opts.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", column, comparator))
+		args = append(args, cursorValue, cursorValue, opts.After.ID.String())
+	}
+
+	query := fmt.Sprintf(`SELECT id, user_id, title, type, size, uploaded_at, processed_at FROM documents
+		WHERE %s
+		ORDER BY %s %s, id %s
+		LIMIT ?`, strings.Join(conditions, " AND "), column, direction, direction)
+	args = append(args, opts.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.DocumentListItem
+	for rows.Next() {
+		var item models.DocumentListItem
+		var idStr, userIDStr, typeStr string
+		err := rows.Scan(
+			&idStr,
+			&userIDStr,
+			&item.Title,
+			&typeStr,
+			&item.Size,
+			&item.UploadedAt,
+			&item.ProcessedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		item.ID = uuid.MustParse(idStr)
+		item.UserID = uuid.MustParse(userIDStr)
+		item.Type = models.DocumentType(typeStr)
+		items = append(items, &item)
+	}
+
+	return items, rows.Err()
+}
+
+// documentSortColumn maps a sort field to its column and the cursor value to compare it with.
+// Unknown fields fall back to the upload time.
+func documentSortColumn(field repository.DocumentSortField, cursor *repository.DocumentCursor) (string, interface{}) {
+	var value interface{}
+	switch field {
+	case repository.DocumentSortTitle:
+		if cursor != nil {
+			value = cursor.Title
+		}
+		return "title", value
+	case repository.DocumentSortSize:
+		if cursor != nil {
+			value = cursor.Size
+		}
+		return "size", value
+	default:
+		if cursor != nil {
+			value = cursor.UploadedAt
+		}
+		return "uploaded_at", value
+	}
+}
+
+func escapeLike(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(s)
+}
+
 func (r *DocumentRepository) Update(ctx context.Context, document *models.Document) error {
-	query := `UPDATE documents SET title = ?, content = ?, processed_at = ? WHERE id = ?`
+	query := `UPDATE documents SET title = ?, content = ?, size = ?, processed_at = ?, pages = ? WHERE id = ?`
 
-	_, err := r.db.ExecContext(ctx, query,
+	pages, err := marshalPages(document.Pages)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, query,
 		document.Title,
 		document.Content,
+		document.Size,
 		document.ProcessedAt,
+		pages,
 		document.ID.String(),
 	)
 	return err
 }
 
+// marshalPages encodes page spans for the pages column, which holds an empty array for documents without
+// pages.
+func marshalPages(pages []models.PageSpan) (string, error) {
+	if pages == nil {
+		pages = []models.PageSpan{}
+	}
+	data, err := json.Marshal(pages)
+	return string(data), err
+}
+
+func unmarshalPages(data string) ([]models.PageSpan, error) {
+	var pages []models.PageSpan
+	if err := json.Unmarshal([]byte(data), &pages); err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return nil, nil
+	}
+	return pages, nil
+}
+
+// marshalMetadata encodes metadata for the metadata column, which holds an empty object for documents
+// without metadata.
+func marshalMetadata(metadata map[string]string) (string, error) {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	data, err := json.Marshal(metadata)
+	return string(data), err
+}
+
+func unmarshalMetadata(data string) (map[string]string, error) {
+	var metadata map[string]string
+	if err := json.Unmarshal([]byte(data), &metadata); err != nil {
+		return nil, err
+	}
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	return metadata, nil
+}
+
 func (r *DocumentRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM documents WHERE id = ?`
 	_, err := r.db.ExecContext(ctx, query, id.String())
@@ -125,15 +313,23 @@ func NewSummaryRepository(db *DB) *SummaryRepository {
 	return &SummaryRepository{db: db}
 }
 
+const summaryColumns = `id, document_id, content, status, length, target_chars, target_words, style, language, created_at, updated_at`
+
 func (r *SummaryRepository) Create(ctx context.Context, summary *models.Summary) error {
 	query := `
-		INSERT INTO summaries (id, document_id, content, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?)`
+		INSERT INTO summaries (` + summaryColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	_, err := r.db.ExecContext(ctx, query,
 		summary.ID.String(),
 		summary.DocumentID.String(),
 		summary.Content,
+		string(summary.Status),
+		string(summary.Length),
+		summary.TargetChars,
+		summary.TargetWords,
+		string(summary.Style),
+		summary.Language,
 		summary.CreatedAt,
 		summary.UpdatedAt,
 	)
@@ -141,32 +337,20 @@ func (r *SummaryRepository) Create(ctx context.Context, summary *models.Summary)
 }
 
 func (r *SummaryRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Summary, error) {
-	query := `SELECT id, document_id, content, created_at, updated_at FROM summaries WHERE id = ?`
-
-	var summary models.Summary
-	var idStr, docIDStr string
-	err := r.db.QueryRowContext(ctx, query, id.String()).Scan(
-		&idStr,
-		&docIDStr,
-		&summary.Content,
-		&summary.CreatedAt,
-		&summary.UpdatedAt,
-	)
+	query := `SELECT ` + summaryColumns + ` FROM summaries WHERE id = ?`
 
+	summary, err := scanSummary(r.db.QueryRowContext(ctx, query, id.String()))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-
-	summary.ID = uuid.MustParse(idStr)
-	summary.DocumentID = uuid.MustParse(docIDStr)
-	return &summary, nil
+	return summary, nil
 }
 
 func (r *SummaryRepository) GetByDocumentID(ctx context.Context, documentID uuid.UUID) ([]*models.Summary, error) {
-	query := `SELECT id, document_id, content, created_at, updated_at FROM summaries WHERE document_id = ?`
+	query := `SELECT ` + summaryColumns + ` FROM summaries WHERE document_id = ? ORDER BY created_at DESC`
 
 	rows, err := r.db.QueryContext(ctx, query, documentID.String())
 	if err != nil {
@@ -176,25 +360,42 @@ func (r *SummaryRepository) GetByDocumentID(ctx context.Context, documentID uuid
 
 	var summaries []*models.Summary
 	for rows.Next() {
-		var summary models.Summary
-		var idStr, docIDStr string
-		err := rows.Scan(
-			&idStr,
-			&docIDStr,
-			&summary.Content,
-			&summary.CreatedAt,
-			&summary.UpdatedAt,
-		)
+		summary, err := scanSummary(rows)
 		if err != nil {
 			return nil, err
 		}
+		summaries = append(summaries, summary)
+	}
 
-		summary.ID = uuid.MustParse(idStr)
-		summary.DocumentID = uuid.MustParse(docIDStr)
-		summaries = append(summaries, &summary)
+	return summaries, rows.Err()
+}
+
+func scanSummary(row rowScanner) (*models.Summary, error) {
+	var summary models.Summary
+	var idStr, docIDStr, status, length, style string
+	err := row.Scan(
+		&idStr,
+		&docIDStr,
+		&summary.Content,
+		&status,
+		&length,
+		&summary.TargetChars,
+		&summary.TargetWords,
+		&style,
+		&summary.Language,
+		&summary.CreatedAt,
+		&summary.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	return summaries, nil
+	summary.ID = uuid.MustParse(idStr)
+	summary.DocumentID = uuid.MustParse(docIDStr)
+	summary.Status = models.SummaryStatus(status)
+	summary.Length = models.SummaryLength(length)
+	summary.Style = models.SummaryStyle(style)
+	return &summary, nil
 }
 
 func (r *SummaryRepository) Update(ctx context.Context, summary *models.Summary) error {
@@ -212,4 +413,10 @@ func (r *SummaryRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM summaries WHERE id = ?`
 	_, err := r.db.ExecContext(ctx, query, id.String())
 	return err
-}
\ No newline at end of file
+}
+
+func (r *SummaryRepository) DeleteByDocumentID(ctx context.Context, documentID uuid.UUID) error {
+	query := `DELETE FROM summaries WHERE document_id = ?`
+	_, err := r.db.ExecContext(ctx, query, documentID.String())
+	return err
+}
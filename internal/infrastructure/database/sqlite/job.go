@@ -0,0 +1,235 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type JobRepository struct {
+	db *DB
+}
+
+func NewJobRepository(db *DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+const jobColumns = `id, user_id, document_id, type, status, payload, result_id, error, attempts, created_at, updated_at, started_at, finished_at, worker_id, locked_until`
+
+func (r *JobRepository) Create(ctx context.Context, job *models.Job) error {
+	query := `
+		INSERT INTO jobs (` + jobColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query, jobInsertArgs(job)...)
+	return err
+}
+
+func (r *JobRepository) CreateIfNotQueued(ctx context.Context, job *models.Job) (bool, error) {
+	// The unique index on queued embedding jobs rejects a second one, even when both are inserted at once.
+	query := `
+		INSERT INTO jobs (` + jobColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING`
+
+	result, err := r.db.ExecContext(ctx, query, jobInsertArgs(job)...)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+func (r *JobRepository) HasQueued(ctx context.Context, documentID uuid.UUID, jobType models.JobType) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM jobs WHERE document_id = ? AND type = ? AND status = ?)`
+
+	var queued bool
+	err := r.db.QueryRowContext(ctx, query, documentID.String(), string(jobType), string(models.JobStatusQueued)).Scan(&queued)
+	return queued, err
+}
+
+func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE id = ?`
+	return scanJob(r.db.QueryRowContext(ctx, query, id.String()))
+}
+
+const jobUpdateColumns = `status = ?, result_id = ?, error = ?, attempts = ?, updated_at = ?, started_at = ?, finished_at = ?, worker_id = ?, locked_until = ?`
+
+func (r *JobRepository) Update(ctx context.Context, job *models.Job) error {
+	query := `UPDATE jobs SET ` + jobUpdateColumns + ` WHERE id = ?`
+
+	_, err := r.db.ExecContext(ctx, query, append(jobUpdateArgs(job), job.ID.String())...)
+	return err
+}
+
+func (r *JobRepository) ClaimNext(ctx context.Context, workerID string, lease time.Duration) (*models.Job, error) {
+	// SQLite serializes writers, so the sub-select and the update see the same queue state.
+	query := `
+		UPDATE jobs SET status = ?, attempts = attempts + 1, started_at = ?, updated_at = ?, worker_id = ?, locked_until = ?
+		WHERE id = (SELECT id FROM jobs WHERE status = ? ORDER BY created_at, id LIMIT 1) AND status = ?
+		RETURNING ` + jobColumns
+
+	now := time.Now()
+	return scanJob(r.db.QueryRowContext(ctx, query,
+		string(models.JobStatusRunning),
+		now,
+		now,
+		workerID,
+		now.Add(lease),
+		string(models.JobStatusQueued),
+		string(models.JobStatusQueued),
+	))
+}
+
+func (r *JobRepository) RenewLease(ctx context.Context, id uuid.UUID, workerID string, until time.Time) (bool, error) {
+	query := `UPDATE jobs SET locked_until = ? WHERE id = ? AND worker_id = ? AND status = ?`
+
+	result, err := r.db.ExecContext(ctx, query, until, id.String(), workerID, string(models.JobStatusRunning))
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+func (r *JobRepository) UpdateLeased(ctx context.Context, job *models.Job, workerID string) (bool, error) {
+	query := `UPDATE jobs SET ` + jobUpdateColumns + ` WHERE id = ? AND worker_id = ? AND status = ?`
+
+	args := append(jobUpdateArgs(job), job.ID.String(), workerID, string(models.JobStatusRunning))
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+func (r *JobRepository) GetByStatus(ctx context.Context, status models.JobStatus) ([]*models.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE status = ? ORDER BY created_at, id`
+	return r.queryJobs(ctx, query, string(status))
+}
+
+func (r *JobRepository) GetExpired(ctx context.Context, now time.Time) ([]*models.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE status = ? AND (locked_until IS NULL OR locked_until < ?) ORDER BY created_at, id`
+	return r.queryJobs(ctx, query, string(models.JobStatusRunning), now)
+}
+
+func (r *JobRepository) UpdateExpired(ctx context.Context, job *models.Job, now time.Time) (bool, error) {
+	query := `
+		UPDATE jobs SET ` + jobUpdateColumns + `
+		WHERE id = ? AND status = ? AND (locked_until IS NULL OR locked_until < ?)`
+
+	args := append(jobUpdateArgs(job), job.ID.String(), string(models.JobStatusRunning), now)
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+func (r *JobRepository) queryJobs(ctx context.Context, query string, args ...interface{}) ([]*models.Job, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*models.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// jobInsertArgs returns the values of jobColumns.
+func jobInsertArgs(job *models.Job) []interface{} {
+	return []interface{}{
+		job.ID.String(),
+		job.UserID.String(),
+		job.DocumentID.String(),
+		string(job.Type),
+		string(job.Status),
+		string(job.Payload),
+		nullableUUID(job.ResultID),
+		job.Error,
+		job.Attempts,
+		job.CreatedAt,
+		job.UpdatedAt,
+		job.StartedAt,
+		job.FinishedAt,
+		job.WorkerID,
+		job.LockedUntil,
+	}
+}
+
+// jobUpdateArgs returns the values of jobUpdateColumns.
+func jobUpdateArgs(job *models.Job) []interface{} {
+	return []interface{}{
+		string(job.Status),
+		nullableUUID(job.ResultID),
+		job.Error,
+		job.Attempts,
+		job.UpdatedAt,
+		job.StartedAt,
+		job.FinishedAt,
+		job.WorkerID,
+		job.LockedUntil,
+	}
+}
+
+func scanJob(row rowScanner) (*models.Job, error) {
+	var job models.Job
+	var idStr, userIDStr, documentIDStr, jobType, status, payload string
+	var resultID sql.NullString
+	err := row.Scan(
+		&idStr,
+		&userIDStr,
+		&documentIDStr,
+		&jobType,
+		&status,
+		&payload,
+		&resultID,
+		&job.Error,
+		&job.Attempts,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+		&job.WorkerID,
+		&job.LockedUntil,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job.ID = uuid.MustParse(idStr)
+	job.UserID = uuid.MustParse(userIDStr)
+	job.DocumentID = uuid.MustParse(documentIDStr)
+	job.Type = models.JobType(jobType)
+	job.Status = models.JobStatus(status)
+	job.Payload = []byte(payload)
+	if resultID.Valid {
+		id := uuid.MustParse(resultID.String)
+		job.ResultID = &id
+	}
+	return &job, nil
+}
+
+func nullableUUID(id *uuid.UUID) interface{} {
+	if id == nil {
+		return nil
+	}
+	return id.String()
+}
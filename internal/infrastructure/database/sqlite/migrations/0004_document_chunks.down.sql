@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS document_chunks_delete;
+DROP TABLE IF EXISTS document_chunks;
@@ -0,0 +1,22 @@
+-- Embedded chunks for semantic search. Version 0003 is the FTS5 search index of sqlite_fts5 builds.
+
+CREATE TABLE document_chunks (
+	id TEXT PRIMARY KEY,
+	document_id TEXT NOT NULL,
+	chunk_index INTEGER NOT NULL,
+	heading TEXT NOT NULL DEFAULT '',
+	start_offset INTEGER NOT NULL,
+	end_offset INTEGER NOT NULL,
+	content TEXT NOT NULL,
+	model TEXT NOT NULL,
+	embedding BLOB NOT NULL, -- little-endian float32s, normalized to unit length
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (document_id) REFERENCES documents(id)
+);
+
+CREATE INDEX idx_document_chunks_document ON document_chunks(document_id, chunk_index);
+
+-- Foreign keys are not enforced, so chunks are removed with their document here.
+CREATE TRIGGER document_chunks_delete AFTER DELETE ON documents BEGIN
+	DELETE FROM document_chunks WHERE document_id = old.id;
+END;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS messages_conversation_delete;
+DROP TRIGGER IF EXISTS conversations_document_delete;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS conversations;
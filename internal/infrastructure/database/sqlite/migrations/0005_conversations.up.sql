@@ -0,0 +1,36 @@
+-- Chat threads about a document and their messages.
+
+CREATE TABLE conversations (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL,
+	document_id TEXT NOT NULL,
+	title TEXT NOT NULL DEFAULT '',
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id),
+	FOREIGN KEY (document_id) REFERENCES documents(id)
+);
+
+CREATE INDEX idx_conversations_document ON conversations(document_id, user_id, updated_at);
+
+CREATE TABLE messages (
+	id TEXT PRIMARY KEY,
+	conversation_id TEXT NOT NULL,
+	role TEXT NOT NULL,
+	content TEXT NOT NULL,
+	citations TEXT NOT NULL DEFAULT '[]', -- JSON array of citations, for assistant messages
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (conversation_id) REFERENCES conversations(id)
+);
+
+CREATE INDEX idx_messages_conversation ON messages(conversation_id, created_at);
+
+-- Foreign keys are not enforced, so threads are removed with their document and messages with their
+-- thread here.
+CREATE TRIGGER conversations_document_delete AFTER DELETE ON documents BEGIN
+	DELETE FROM conversations WHERE document_id = old.id;
+END;
+
+CREATE TRIGGER messages_conversation_delete AFTER DELETE ON conversations BEGIN
+	DELETE FROM messages WHERE conversation_id = old.id;
+END;
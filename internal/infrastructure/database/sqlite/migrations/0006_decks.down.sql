@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS decks_document_delete;
+DROP TABLE IF EXISTS decks;
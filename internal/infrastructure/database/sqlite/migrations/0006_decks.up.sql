@@ -0,0 +1,19 @@
+-- Slide decks generated from a document.
+
+CREATE TABLE decks (
+	id TEXT PRIMARY KEY,
+	document_id TEXT NOT NULL,
+	title TEXT NOT NULL,
+	slides TEXT NOT NULL, -- JSON array of slides
+	slide_count INTEGER NOT NULL DEFAULT 0, -- requested number of slides
+	language TEXT NOT NULL DEFAULT '',
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (document_id) REFERENCES documents(id)
+);
+
+CREATE INDEX idx_decks_document ON decks(document_id, created_at);
+
+-- Foreign keys are not enforced, so decks are removed with their document here.
+CREATE TRIGGER decks_document_delete AFTER DELETE ON documents BEGIN
+	DELETE FROM decks WHERE document_id = old.id;
+END;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS diagrams_document_delete;
+DROP TABLE IF EXISTS diagrams;
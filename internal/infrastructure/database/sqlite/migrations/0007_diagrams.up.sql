@@ -0,0 +1,21 @@
+-- Diagrams proposed for a document, kept as their Mermaid or PlantUML source.
+
+CREATE TABLE diagrams (
+	id TEXT PRIMARY KEY,
+	document_id TEXT NOT NULL,
+	kind TEXT NOT NULL, -- flowchart, sequence or mindmap
+	syntax TEXT NOT NULL, -- mermaid or plantuml
+	title TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	source TEXT NOT NULL,
+	language TEXT NOT NULL DEFAULT '',
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (document_id) REFERENCES documents(id)
+);
+
+CREATE INDEX idx_diagrams_document ON diagrams(document_id, created_at);
+
+-- Foreign keys are not enforced, so diagrams are removed with their document here.
+CREATE TRIGGER diagrams_document_delete AFTER DELETE ON documents BEGIN
+	DELETE FROM diagrams WHERE document_id = old.id;
+END;
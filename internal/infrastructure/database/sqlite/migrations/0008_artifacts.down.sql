@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS artifacts_document_delete;
+DROP TABLE IF EXISTS artifacts;
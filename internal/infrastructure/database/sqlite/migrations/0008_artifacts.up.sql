@@ -0,0 +1,26 @@
+-- Files generated from a document. Their content is kept in the blob store under storage_key.
+
+CREATE TABLE artifacts (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL,
+	document_id TEXT NOT NULL,
+	kind TEXT NOT NULL, -- deck, diagram or image
+	name TEXT NOT NULL,
+	mime_type TEXT NOT NULL,
+	params TEXT NOT NULL DEFAULT '{}', -- JSON object of the generator parameters
+	size INTEGER NOT NULL,
+	checksum TEXT NOT NULL, -- hex SHA-256 of the content
+	storage_key TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id),
+	FOREIGN KEY (document_id) REFERENCES documents(id)
+);
+
+CREATE INDEX idx_artifacts_user ON artifacts(user_id, created_at);
+CREATE INDEX idx_artifacts_document ON artifacts(document_id, created_at);
+
+-- Foreign keys are not enforced, so artifacts are removed with their document here. Their blobs are
+-- removed by the application before the document is deleted.
+CREATE TRIGGER artifacts_document_delete AFTER DELETE ON documents BEGIN
+	DELETE FROM artifacts WHERE document_id = old.id;
+END;
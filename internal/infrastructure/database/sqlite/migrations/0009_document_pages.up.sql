@@ -0,0 +1,3 @@
+-- Where each page of a paged original, such as a PDF file, is in the content: a JSON array of
+-- {"number", "start", "end"} with rune offsets. Empty for documents without pages.
+ALTER TABLE documents ADD COLUMN pages TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,3 @@
+-- Where the uploaded file of a document converted from another format, such as PDF or DOCX, is kept in
+-- blob storage. Empty for documents uploaded as text.
+ALTER TABLE documents ADD COLUMN original_key TEXT NOT NULL DEFAULT '';
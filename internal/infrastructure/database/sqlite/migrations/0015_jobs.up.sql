@@ -0,0 +1,22 @@
+-- Background jobs. IF NOT EXISTS lets databases from releases without the migration ledger,
+-- which created every table at start, adopt it.
+
+CREATE TABLE IF NOT EXISTS jobs (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL,
+	document_id TEXT NOT NULL,
+	type TEXT NOT NULL,
+	status TEXT NOT NULL,
+	payload TEXT NOT NULL DEFAULT '{}',
+	result_id TEXT,
+	error TEXT NOT NULL DEFAULT '',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	started_at DATETIME,
+	finished_at DATETIME,
+	FOREIGN KEY (user_id) REFERENCES users(id),
+	FOREIGN KEY (document_id) REFERENCES documents(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_status_created ON jobs(status, created_at);
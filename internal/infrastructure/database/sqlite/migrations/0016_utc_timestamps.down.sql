@@ -0,0 +1,2 @@
+-- The rewritten times stay valid for the previous schema, so there is nothing to undo.
+SELECT 1;
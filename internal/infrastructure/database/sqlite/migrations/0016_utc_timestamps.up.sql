@@ -0,0 +1,41 @@
+-- Rewrites stored times in the one format the repositories now write: UTC, with nine digits of fraction.
+-- Times used to be stored in the zone of the server that wrote them, and comparing them as text, as the
+-- expiry checks and the document cursor do, put times of different zones out of order. Existing times keep
+-- milliseconds of their fraction; times SQLite cannot parse are left as they are.
+
+UPDATE users SET created_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) IS NOT NULL;
+UPDATE users SET updated_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', updated_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', updated_at) IS NOT NULL;
+
+UPDATE documents SET uploaded_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', uploaded_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', uploaded_at) IS NOT NULL;
+UPDATE documents SET processed_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', processed_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', processed_at) IS NOT NULL;
+
+UPDATE summaries SET created_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) IS NOT NULL;
+UPDATE summaries SET updated_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', updated_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', updated_at) IS NOT NULL;
+
+UPDATE document_chunks SET created_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) IS NOT NULL;
+
+UPDATE conversations SET created_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) IS NOT NULL;
+UPDATE conversations SET updated_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', updated_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', updated_at) IS NOT NULL;
+
+UPDATE messages SET created_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) IS NOT NULL;
+
+UPDATE decks SET created_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) IS NOT NULL;
+
+UPDATE diagrams SET created_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) IS NOT NULL;
+
+UPDATE artifacts SET created_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) IS NOT NULL;
+
+UPDATE sessions SET created_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) IS NOT NULL;
+UPDATE sessions SET last_used_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', last_used_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', last_used_at) IS NOT NULL;
+UPDATE sessions SET expires_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', expires_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', expires_at) IS NOT NULL;
+UPDATE sessions SET revoked_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', revoked_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', revoked_at) IS NOT NULL;
+
+UPDATE api_keys SET created_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) IS NOT NULL;
+UPDATE api_keys SET expires_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', expires_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', expires_at) IS NOT NULL;
+UPDATE api_keys SET last_used_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', last_used_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', last_used_at) IS NOT NULL;
+UPDATE api_keys SET revoked_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', revoked_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', revoked_at) IS NOT NULL;
+
+UPDATE jobs SET created_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', created_at) IS NOT NULL;
+UPDATE jobs SET updated_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', updated_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', updated_at) IS NOT NULL;
+UPDATE jobs SET started_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', started_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', started_at) IS NOT NULL;
+UPDATE jobs SET finished_at = strftime('%Y-%m-%d %H:%M:%f000000+00:00', finished_at) WHERE strftime('%Y-%m-%d %H:%M:%f000000+00:00', finished_at) IS NOT NULL;
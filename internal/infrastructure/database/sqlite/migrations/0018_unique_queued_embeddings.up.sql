@@ -0,0 +1,13 @@
+-- A document needs at most one queued embedding job, which embeds its latest content. The index keeps
+-- processes that queue embeddings at the same time, such as servers backfilling as they start, from queueing
+-- a document twice. Running jobs are left out so that a document edited while it is being embedded is
+-- queued again. Duplicates queued before the index existed are removed, keeping the oldest.
+
+DELETE FROM jobs
+WHERE type = 'embedding' AND status = 'queued' AND EXISTS (
+	SELECT 1 FROM jobs older
+	WHERE older.document_id = jobs.document_id AND older.type = 'embedding' AND older.status = 'queued'
+		AND (older.created_at < jobs.created_at OR (older.created_at = jobs.created_at AND older.id < jobs.id))
+);
+
+CREATE UNIQUE INDEX idx_jobs_queued_embedding ON jobs(document_id) WHERE type = 'embedding' AND status = 'queued';
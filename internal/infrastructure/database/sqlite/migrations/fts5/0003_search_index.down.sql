@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS summaries_fts_delete;
+DROP TRIGGER IF EXISTS summaries_fts_update;
+DROP TRIGGER IF EXISTS summaries_fts_insert;
+DROP TRIGGER IF EXISTS documents_fts_delete;
+DROP TRIGGER IF EXISTS documents_fts_update;
+DROP TRIGGER IF EXISTS documents_fts_insert;
+DROP TABLE IF EXISTS summaries_fts;
+DROP TABLE IF EXISTS documents_fts;
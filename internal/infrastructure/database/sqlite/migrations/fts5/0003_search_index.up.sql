@@ -0,0 +1,38 @@
+-- Full-text indexes for search. The trigram tokenizer matches any substring of three or more characters,
+-- which works for Japanese text without word segmentation. The indexes keep their own copy of the text,
+-- keyed by an unindexed id column, because the rowids of tables with TEXT primary keys can change on
+-- VACUUM.
+
+CREATE VIRTUAL TABLE documents_fts USING fts5(document_id UNINDEXED, title, content, tokenize = 'trigram');
+
+CREATE VIRTUAL TABLE summaries_fts USING fts5(summary_id UNINDEXED, content, tokenize = 'trigram');
+
+INSERT INTO documents_fts (document_id, title, content) SELECT id, title, content FROM documents;
+
+INSERT INTO summaries_fts (summary_id, content) SELECT id, content FROM summaries;
+
+CREATE TRIGGER documents_fts_insert AFTER INSERT ON documents BEGIN
+	INSERT INTO documents_fts (document_id, title, content) VALUES (new.id, new.title, new.content);
+END;
+
+CREATE TRIGGER documents_fts_update AFTER UPDATE OF title, content ON documents BEGIN
+	DELETE FROM documents_fts WHERE document_id = old.id;
+	INSERT INTO documents_fts (document_id, title, content) VALUES (new.id, new.title, new.content);
+END;
+
+CREATE TRIGGER documents_fts_delete AFTER DELETE ON documents BEGIN
+	DELETE FROM documents_fts WHERE document_id = old.id;
+END;
+
+CREATE TRIGGER summaries_fts_insert AFTER INSERT ON summaries BEGIN
+	INSERT INTO summaries_fts (summary_id, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER summaries_fts_update AFTER UPDATE OF content ON summaries BEGIN
+	DELETE FROM summaries_fts WHERE summary_id = old.id;
+	INSERT INTO summaries_fts (summary_id, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER summaries_fts_delete AFTER DELETE ON summaries BEGIN
+	DELETE FROM summaries_fts WHERE summary_id = old.id;
+END;
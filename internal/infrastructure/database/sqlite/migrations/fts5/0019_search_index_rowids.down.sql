@@ -0,0 +1,47 @@
+DROP TRIGGER IF EXISTS documents_fts_insert;
+DROP TRIGGER IF EXISTS documents_fts_update;
+DROP TRIGGER IF EXISTS documents_fts_delete;
+DROP TRIGGER IF EXISTS summaries_fts_insert;
+DROP TRIGGER IF EXISTS summaries_fts_update;
+DROP TRIGGER IF EXISTS summaries_fts_delete;
+
+DROP TABLE IF EXISTS documents_fts;
+DROP TABLE IF EXISTS summaries_fts;
+DROP TABLE IF EXISTS documents_fts_rows;
+DROP TABLE IF EXISTS summaries_fts_rows;
+
+-- The indexes as 0003_search_index created them.
+
+CREATE VIRTUAL TABLE documents_fts USING fts5(document_id UNINDEXED, title, content, tokenize = 'trigram');
+
+CREATE VIRTUAL TABLE summaries_fts USING fts5(summary_id UNINDEXED, content, tokenize = 'trigram');
+
+INSERT INTO documents_fts (document_id, title, content) SELECT id, title, content FROM documents;
+
+INSERT INTO summaries_fts (summary_id, content) SELECT id, content FROM summaries;
+
+CREATE TRIGGER documents_fts_insert AFTER INSERT ON documents BEGIN
+	INSERT INTO documents_fts (document_id, title, content) VALUES (new.id, new.title, new.content);
+END;
+
+CREATE TRIGGER documents_fts_update AFTER UPDATE OF title, content ON documents BEGIN
+	DELETE FROM documents_fts WHERE document_id = old.id;
+	INSERT INTO documents_fts (document_id, title, content) VALUES (new.id, new.title, new.content);
+END;
+
+CREATE TRIGGER documents_fts_delete AFTER DELETE ON documents BEGIN
+	DELETE FROM documents_fts WHERE document_id = old.id;
+END;
+
+CREATE TRIGGER summaries_fts_insert AFTER INSERT ON summaries BEGIN
+	INSERT INTO summaries_fts (summary_id, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER summaries_fts_update AFTER UPDATE OF content ON summaries BEGIN
+	DELETE FROM summaries_fts WHERE summary_id = old.id;
+	INSERT INTO summaries_fts (summary_id, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER summaries_fts_delete AFTER DELETE ON summaries BEGIN
+	DELETE FROM summaries_fts WHERE summary_id = old.id;
+END;
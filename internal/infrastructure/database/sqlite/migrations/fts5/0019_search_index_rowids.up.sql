@@ -0,0 +1,72 @@
+-- Key the full-text indexes by rowid. Looking rows up by an unindexed id column scans the whole index on
+-- every write. The rowids of documents and summaries can change on VACUUM because their primary keys are
+-- TEXT, so each index maps its rowids to ids in a table with an INTEGER PRIMARY KEY, which VACUUM keeps.
+-- The migration builds the indexes from scratch, so it also repairs an index that has gone missing.
+
+DROP TRIGGER IF EXISTS documents_fts_insert;
+DROP TRIGGER IF EXISTS documents_fts_update;
+DROP TRIGGER IF EXISTS documents_fts_delete;
+DROP TRIGGER IF EXISTS summaries_fts_insert;
+DROP TRIGGER IF EXISTS summaries_fts_update;
+DROP TRIGGER IF EXISTS summaries_fts_delete;
+
+DROP TABLE IF EXISTS documents_fts;
+DROP TABLE IF EXISTS summaries_fts;
+DROP TABLE IF EXISTS documents_fts_rows;
+DROP TABLE IF EXISTS summaries_fts_rows;
+
+CREATE TABLE documents_fts_rows (
+	fts_rowid INTEGER PRIMARY KEY,
+	document_id TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE summaries_fts_rows (
+	fts_rowid INTEGER PRIMARY KEY,
+	summary_id TEXT NOT NULL UNIQUE
+);
+
+CREATE VIRTUAL TABLE documents_fts USING fts5(title, content, tokenize = 'trigram');
+
+CREATE VIRTUAL TABLE summaries_fts USING fts5(content, tokenize = 'trigram');
+
+INSERT INTO documents_fts_rows (document_id) SELECT id FROM documents;
+
+INSERT INTO summaries_fts_rows (summary_id) SELECT id FROM summaries;
+
+INSERT INTO documents_fts (rowid, title, content)
+	SELECT r.fts_rowid, d.title, d.content FROM documents_fts_rows r JOIN documents d ON d.id = r.document_id;
+
+INSERT INTO summaries_fts (rowid, content)
+	SELECT r.fts_rowid, s.content FROM summaries_fts_rows r JOIN summaries s ON s.id = r.summary_id;
+
+CREATE TRIGGER documents_fts_insert AFTER INSERT ON documents BEGIN
+	INSERT INTO documents_fts_rows (document_id) VALUES (new.id);
+	INSERT INTO documents_fts (rowid, title, content)
+		VALUES ((SELECT fts_rowid FROM documents_fts_rows WHERE document_id = new.id), new.title, new.content);
+END;
+
+CREATE TRIGGER documents_fts_update AFTER UPDATE OF title, content ON documents BEGIN
+	UPDATE documents_fts SET title = new.title, content = new.content
+		WHERE rowid = (SELECT fts_rowid FROM documents_fts_rows WHERE document_id = new.id);
+END;
+
+CREATE TRIGGER documents_fts_delete AFTER DELETE ON documents BEGIN
+	DELETE FROM documents_fts WHERE rowid = (SELECT fts_rowid FROM documents_fts_rows WHERE document_id = old.id);
+	DELETE FROM documents_fts_rows WHERE document_id = old.id;
+END;
+
+CREATE TRIGGER summaries_fts_insert AFTER INSERT ON summaries BEGIN
+	INSERT INTO summaries_fts_rows (summary_id) VALUES (new.id);
+	INSERT INTO summaries_fts (rowid, content)
+		VALUES ((SELECT fts_rowid FROM summaries_fts_rows WHERE summary_id = new.id), new.content);
+END;
+
+CREATE TRIGGER summaries_fts_update AFTER UPDATE OF content ON summaries BEGIN
+	UPDATE summaries_fts SET content = new.content
+		WHERE rowid = (SELECT fts_rowid FROM summaries_fts_rows WHERE summary_id = new.id);
+END;
+
+CREATE TRIGGER summaries_fts_delete AFTER DELETE ON summaries BEGIN
+	DELETE FROM summaries_fts WHERE rowid = (SELECT fts_rowid FROM summaries_fts_rows WHERE summary_id = old.id);
+	DELETE FROM summaries_fts_rows WHERE summary_id = old.id;
+END;
@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+	"strings"
+
+	"github.com/google/uuid"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/database/migrate"
+)
+
+//go:embed migrations/fts5/*.sql
+var searchMigrationFiles embed.FS
+
+// searchTriggers keep the FTS5 indexes in step with the documents and summaries tables.
+var searchTriggers = []string{
+	"documents_fts_insert",
+	"documents_fts_update",
+	"documents_fts_delete",
+	"summaries_fts_insert",
+	"summaries_fts_update",
+	"summaries_fts_delete",
+}
+
+// searchMigrations returns the migrations of the FTS5 search index. Builds without FTS5 record them under
+// the same versions and checksums without running them, so that a database can move between builds;
+// syncSearchIndex then brings the index in line with the build.
+func searchMigrations() ([]migrate.Migration, error) {
+	files, err := fs.Sub(searchMigrationFiles, "migrations/fts5")
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := migrate.Load(files)
+	if err != nil {
+		return nil, err
+	}
+	if !searchIndexed {
+		for i := range migrations {
+			migrations[i].Up = "SELECT 1"
+			migrations[i].Down = "SELECT 1"
+		}
+	}
+	return migrations, nil
+}
+
+// SearchRepository searches documents and summaries. Builds with the sqlite_fts5 tag use FTS5 trigram
+// indexes ranked by bm25; other builds fall back to LIKE scans that rank title matches first.
+type SearchRepository struct {
+	db *DB
+}
+
+func NewSearchRepository(db *DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// Each arm of the search selects kind, document_id, summary_id, title, text, created_at and rank, where
+// a lower rank is a better match.
+func (r *SearchRepository) Search(ctx context.Context, userID uuid.UUID, opts repository.SearchOptions) ([]*models.SearchHit, error) {
+	documents, documentArgs := documentSearchQuery(userID, opts.Terms)
+	summaries, summaryArgs := summarySearchQuery(userID, opts.Terms)
+
+	query := documents + "\nUNION ALL\n" + summaries + "\nORDER BY rank, created_at DESC\nLIMIT ? OFFSET ?"
+	args := append(append(documentArgs, summaryArgs...), opts.Limit, opts.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []*models.SearchHit
+	for rows.Next() {
+		var hit models.SearchHit
+		var kind, docIDStr string
+		var summaryIDStr sql.NullString
+		var rank float64
+		if err := rows.Scan(&kind, &docIDStr, &summaryIDStr, &hit.Title, &hit.Text, &hit.CreatedAt, &rank); err != nil {
+			return nil, err
+		}
+
+		hit.Kind = models.SearchHitKind(kind)
+		hit.DocumentID = uuid.MustParse(docIDStr)
+		if summaryIDStr.Valid {
+			summaryID := uuid.MustParse(summaryIDStr.String)
+			hit.SummaryID = &summaryID
+		}
+		hits = append(hits, &hit)
+	}
+	return hits, rows.Err()
+}
+
+// likeConditions requires every term to occur in one of columns, and returns the matching arguments.
+func likeConditions(terms []string, columns ...string) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for _, term := range terms {
+		pattern := "%" + escapeLike(term) + "%"
+		alternatives := make([]string, len(columns))
+		for i, column := range columns {
+			alternatives[i] = column + ` LIKE ? ESCAPE '\'`
+			args = append(args, pattern)
+		}
+		conditions = append(conditions, "("+strings.Join(alternatives, " OR ")+")")
+	}
+	return conditions, args
+}
+
+// titleRank ranks documents by how many terms column contains, most first, and returns its arguments.
+func titleRank(terms []string, column string) (string, []interface{}) {
+	matches := make([]string, len(terms))
+	args := make([]interface{}, len(terms))
+	for i, term := range terms {
+		matches[i] = "(" + column + ` LIKE ? ESCAPE '\')`
+		args[i] = "%" + escapeLike(term) + "%"
+	}
+	return "-(" + strings.Join(matches, " + ") + ")", args
+}
@@ -0,0 +1,102 @@
+//go:build sqlite_fts5 || fts5
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// minTrigramChars is the shortest term the trigram index can look up. Shorter terms, such as two-letter
+// Japanese words, are matched with LIKE over the indexed text instead.
+const minTrigramChars = 3
+
+// searchIndexed is true: this build creates and queries the FTS5 indexes.
+const searchIndexed = true
+
+// syncSearchIndex rebuilds the search index when its triggers are missing, because a build without FTS5
+// recorded the search migrations without running them or dropped the triggers of an index that has gone
+// out of date since. The latest search migration builds the index from scratch.
+func syncSearchIndex(ctx context.Context, tx *sql.Tx) error {
+	query := `SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name IN (?` + strings.Repeat(", ?", len(searchTriggers)-1) + `)`
+	args := make([]interface{}, len(searchTriggers))
+	for i, trigger := range searchTriggers {
+		args[i] = trigger
+	}
+	var triggers int
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&triggers); err != nil {
+		return err
+	}
+	if triggers == len(searchTriggers) {
+		return nil
+	}
+
+	migrations, err := searchMigrations()
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, migrations[len(migrations)-1].Up)
+	return err
+}
+
+func documentSearchQuery(userID uuid.UUID, terms []string) (string, []interface{}) {
+	match, short := splitTrigramTerms(terms)
+	conditions, args := likeConditions(short, "f.title", "f.content")
+
+	// bm25 weighs titles over content and gives the unindexed id column no weight. It needs a MATCH, so
+	// queries of short terms only rank by title matches instead.
+	var rank string
+	var rankArgs []interface{}
+	if match != "" {
+		rank = "bm25(documents_fts, 10.0, 1.0)"
+		conditions = append([]string{"documents_fts MATCH ?"}, conditions...)
+		args = append([]interface{}{match}, args...)
+	} else {
+		rank, rankArgs = titleRank(short, "f.title")
+	}
+	conditions = append(conditions, "d.user_id = ?")
+	args = append(append(rankArgs, args...), userID.String())
+
+	return fmt.Sprintf(`SELECT 'document' AS kind, d.id AS document_id, NULL AS summary_id, d.title AS title,
+			d.content AS text, d.uploaded_at AS created_at, %s AS rank
+		FROM documents_fts f JOIN documents_fts_rows r ON r.fts_rowid = f.rowid JOIN documents d ON d.id = r.document_id
+		WHERE %s`, rank, strings.Join(conditions, " AND ")), args
+}
+
+func summarySearchQuery(userID uuid.UUID, terms []string) (string, []interface{}) {
+	match, short := splitTrigramTerms(terms)
+	conditions, args := likeConditions(short, "f.content")
+
+	rank := "0"
+	if match != "" {
+		rank = "bm25(summaries_fts)"
+		conditions = append([]string{"summaries_fts MATCH ?"}, conditions...)
+		args = append([]interface{}{match}, args...)
+	}
+	conditions = append(conditions, "d.user_id = ?")
+	args = append(args, userID.String())
+
+	return fmt.Sprintf(`SELECT 'summary', d.id, s.id, d.title, s.content, s.created_at, %s
+		FROM summaries_fts f JOIN summaries_fts_rows r ON r.fts_rowid = f.rowid JOIN summaries s ON s.id = r.summary_id
+			JOIN documents d ON d.id = s.document_id
+		WHERE %s`, rank, strings.Join(conditions, " AND ")), args
+}
+
+// splitTrigramTerms builds an FTS5 query requiring every term long enough for the trigram index, each as
+// a quoted phrase, and returns the remaining short terms.
+func splitTrigramTerms(terms []string) (string, []string) {
+	var phrases, short []string
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < minTrigramChars {
+			short = append(short, term)
+			continue
+		}
+		phrases = append(phrases, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+	}
+	return strings.Join(phrases, " "), short
+}
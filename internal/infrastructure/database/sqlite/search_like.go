@@ -0,0 +1,48 @@
+//go:build !(sqlite_fts5 || fts5)
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// searchIndexed is false: without FTS5 compiled in, search scans the tables directly.
+const searchIndexed = false
+
+// syncSearchIndex drops the triggers of a search index created by a build with FTS5, which would fail
+// every write to documents and summaries in this build. The index is rebuilt once such a build runs again.
+func syncSearchIndex(ctx context.Context, tx *sql.Tx) error {
+	for _, trigger := range searchTriggers {
+		if _, err := tx.ExecContext(ctx, "DROP TRIGGER IF EXISTS "+trigger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func documentSearchQuery(userID uuid.UUID, terms []string) (string, []interface{}) {
+	rank, args := titleRank(terms, "title")
+	conditions, conditionArgs := likeConditions(terms, "title", "content")
+	conditions = append(conditions, "user_id = ?")
+	args = append(append(args, conditionArgs...), userID.String())
+
+	return fmt.Sprintf(`SELECT 'document' AS kind, id AS document_id, NULL AS summary_id, title, content AS text,
+			uploaded_at AS created_at, %s AS rank
+		FROM documents
+		WHERE %s`, rank, strings.Join(conditions, " AND ")), args
+}
+
+func summarySearchQuery(userID uuid.UUID, terms []string) (string, []interface{}) {
+	conditions, args := likeConditions(terms, "s.content")
+	conditions = append(conditions, "d.user_id = ?")
+	args = append(args, userID.String())
+
+	return fmt.Sprintf(`SELECT 'summary', d.id, s.id, d.title, s.content, s.created_at, 0
+		FROM summaries s JOIN documents d ON d.id = s.document_id
+		WHERE %s`, strings.Join(conditions, " AND ")), args
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k-tsurumaki/fuselage"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+)
+
+type ArtifactHandler struct {
+	artifactService *service.ArtifactService
+}
+
+func NewArtifactHandler(artifactService *service.ArtifactService) *ArtifactHandler {
+	return &ArtifactHandler{artifactService: artifactService}
+}
+
+type ArtifactResponse struct {
+	ID         string         `json:"id"`
+	DocumentID string         `json:"document_id"`
+	Kind       string         `json:"kind"` // deck, diagram or image
+	Name       string         `json:"name"`
+	MIMEType   string         `json:"mime_type"`
+	Params     map[string]any `json:"params"`
+	Size       int64          `json:"size"`
+	Checksum   string         `json:"checksum"` // hex SHA-256
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// List lists the user's artifacts, newest first, optionally only those of document_id or of kind.
+func (h *ArtifactHandler) List(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	filter := repository.ArtifactFilter{Kind: models.ArtifactKind(c.Query("kind"))}
+	if value := c.Query("document_id"); value != "" {
+		documentID, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
+		}
+		filter.DocumentID = documentID
+	}
+	return h.list(c, user.ID, filter)
+}
+
+// ListDocument lists the artifacts of a document, newest first, optionally only those of kind.
+func (h *ArtifactHandler) ListDocument(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
+	}
+	return h.list(c, user.ID, repository.ArtifactFilter{DocumentID: documentID, Kind: models.ArtifactKind(c.Query("kind"))})
+}
+
+func (h *ArtifactHandler) list(c *fuselage.Context, userID uuid.UUID, filter repository.ArtifactFilter) error {
+	artifacts, err := h.artifactService.ListArtifacts(c.Request.Context(), userID, filter)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	response := make([]ArtifactResponse, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		response = append(response, newArtifactResponse(artifact))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *ArtifactHandler) Get(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	artifactID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid artifact ID"})
+	}
+
+	artifact, err := h.artifactService.GetArtifact(c.Request.Context(), user.ID, artifactID)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, newArtifactResponse(artifact))
+}
+
+// Preview serves a text or image artifact for display in the browser.
+func (h *ArtifactHandler) Preview(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	artifactID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid artifact ID"})
+	}
+
+	artifact, data, err := h.artifactService.PreviewArtifact(c.Request.Context(), user.ID, artifactID)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	// Generated content is untrusted: an SVG or HTML preview must not run scripts on this origin.
+	c.SetHeader("Content-Security-Policy", "sandbox")
+	return writeFile(c, "inline", artifact.Name, artifact.MIMEType, data)
+}
+
+func (h *ArtifactHandler) Download(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	artifactID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid artifact ID"})
+	}
+
+	artifact, data, err := h.artifactService.ReadArtifact(c.Request.Context(), user.ID, artifactID)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	return writeFile(c, "attachment", artifact.Name, artifact.MIMEType, data)
+}
+
+func (h *ArtifactHandler) Delete(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	artifactID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid artifact ID"})
+	}
+
+	if err := h.artifactService.DeleteArtifact(c.Request.Context(), user.ID, artifactID); err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Artifact deleted"})
+}
+
+func newArtifactResponse(artifact *models.Artifact) ArtifactResponse {
+	return ArtifactResponse{
+		ID:         artifact.ID.String(),
+		DocumentID: artifact.DocumentID.String(),
+		Kind:       string(artifact.Kind),
+		Name:       artifact.Name,
+		MIMEType:   artifact.MIMEType,
+		Params:     artifact.Params,
+		Size:       artifact.Size,
+		Checksum:   artifact.Checksum,
+		CreatedAt:  artifact.CreatedAt,
+	}
+}
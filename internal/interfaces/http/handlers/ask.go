@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/k-tsurumaki/fuselage"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+)
+
+type AskHandler struct {
+	askService *service.AskService
+}
+
+func NewAskHandler(askService *service.AskService) *AskHandler {
+	return &AskHandler{askService: askService}
+}
+
+type AskRequest struct {
+	Question string `json:"question"`
+}
+
+type CitationResponse struct {
+	Passage int    `json:"passage"` // the [n] marker used in the answer
+	Heading string `json:"heading,omitempty"`
+	Page    int    `json:"page,omitempty"` // for PDF documents
+	Start   int    `json:"start"`          // character offsets into the document content
+	End     int    `json:"end"`
+	Text    string `json:"text"`
+}
+
+type AskResponse struct {
+	DocumentID string             `json:"document_id"`
+	Question   string             `json:"question"`
+	Answer     string             `json:"answer"`
+	Citations  []CitationResponse `json:"citations"`
+}
+
+// Ask answers a question about a document, citing the passages the answer is based on.
+func (h *AskHandler) Ask(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
+	}
+
+	var req AskRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+	}
+
+	liftDeadlines(c.Response)
+	answer, err := h.askService.Ask(c.Request.Context(), user.ID, documentID, req.Question)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, AskResponse{
+		DocumentID: answer.DocumentID.String(),
+		Question:   answer.Question,
+		Answer:     answer.Content,
+		Citations:  newCitationResponses(answer.Citations),
+	})
+}
@@ -1,21 +1,26 @@
 package handlers
 
 import (
+	"net"
 	"net/http"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/k-tsurumaki/fuselage"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
 	"github/k-tsurumaki/quilldeck/internal/domain/service"
+	"github/k-tsurumaki/quilldeck/internal/interfaces/http/middleware"
 )
 
 type AuthHandler struct {
-	authService *service.AuthService
-	docService  *service.DocumentService
+	authService  *service.AuthService
+	tokenService *service.TokenService
 }
 
-func NewAuthHandler(authService *service.AuthService, docService *service.DocumentService) *AuthHandler {
+func NewAuthHandler(authService *service.AuthService, tokenService *service.TokenService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		docService:  docService,
+		authService:  authService,
+		tokenService: tokenService,
 	}
 }
 
@@ -30,9 +35,33 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
 type AuthResponse struct {
-	Message string `json:"message"`
-	UserID  string `json:"user_id,omitempty"`
+	Message          string     `json:"message"`
+	UserID           string     `json:"user_id,omitempty"`
+	AccessToken      string     `json:"access_token,omitempty"`
+	TokenType        string     `json:"token_type,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	RefreshToken     string     `json:"refresh_token,omitempty"`
+	RefreshExpiresAt *time.Time `json:"refresh_expires_at,omitempty"`
+}
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
 }
 
 func (h *AuthHandler) Register(c *fuselage.Context) error {
@@ -63,8 +92,129 @@ func (h *AuthHandler) Login(c *fuselage.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 	}
 
+	session, refreshToken, err := h.authService.StartSession(c.Request.Context(), user, c.Request.UserAgent(), clientIP(c.Request))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return h.issueTokens(c, "Login successful", user, session, refreshToken)
+}
+
+func (h *AuthHandler) Refresh(c *fuselage.Context) error {
+	var req RefreshRequest
+	if err := c.Bind(&req); err != nil || req.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "refresh_token is required"})
+	}
+
+	user, session, refreshToken, err := h.authService.RefreshSession(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return h.issueTokens(c, "Token refreshed", user, session, refreshToken)
+}
+
+func (h *AuthHandler) Logout(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+	sessionID, ok := middleware.SessionIDFromContext(c.Request.Context())
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "No active session"})
+	}
+
+	if err := h.authService.RevokeSession(c.Request.Context(), user.ID, sessionID); err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, AuthResponse{Message: "Logged out"})
+}
+
+func (h *AuthHandler) ListSessions(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+	currentSessionID, _ := middleware.SessionIDFromContext(c.Request.Context())
+
+	sessions, err := h.authService.ListSessions(c.Request.Context(), user.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	response := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, SessionResponse{
+			ID:         session.ID.String(),
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"sessions": response})
+}
+
+func (h *AuthHandler) RevokeSession(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid session ID"})
+	}
+
+	if err := h.authService.RevokeSession(c.Request.Context(), user.ID, sessionID); err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, AuthResponse{Message: "Session revoked"})
+}
+
+func (h *AuthHandler) ChangePassword(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	var req ChangePasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+	}
+
+	if err := h.authService.ChangePassword(c.Request.Context(), user.ID, req.CurrentPassword, req.NewPassword); err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, AuthResponse{Message: "Password changed; all sessions have been signed out"})
+}
+
+func (h *AuthHandler) issueTokens(c *fuselage.Context, message string, user *models.User, session *models.Session, refreshToken string) error {
+	accessToken, expiresAt, err := h.tokenService.IssueAccessToken(user, session.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
 	return c.JSON(http.StatusOK, AuthResponse{
-		Message: "Login successful",
-		UserID:  user.ID.String(),
+		Message:          message,
+		UserID:           user.ID.String(),
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        &expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: &session.ExpiresAt,
 	})
-}
\ No newline at end of file
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
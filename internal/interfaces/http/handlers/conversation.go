@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k-tsurumaki/fuselage"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+)
+
+type ConversationHandler struct {
+	conversationService *service.ConversationService
+}
+
+func NewConversationHandler(conversationService *service.ConversationService) *ConversationHandler {
+	return &ConversationHandler{conversationService: conversationService}
+}
+
+type CreateConversationRequest struct {
+	Title string `json:"title"` // optional; defaults to the first question
+}
+
+type PostMessageRequest struct {
+	Content string `json:"content"`
+}
+
+type ConversationResponse struct {
+	ID         string    `json:"id"`
+	DocumentID string    `json:"document_id"`
+	Title      string    `json:"title"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type MessageResponse struct {
+	ID        string             `json:"id"`
+	Role      string             `json:"role"` // "user" or "assistant"
+	Content   string             `json:"content"`
+	Citations []CitationResponse `json:"citations"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type ConversationDetailResponse struct {
+	ConversationResponse
+	Messages []MessageResponse `json:"messages"`
+}
+
+type PostMessageResponse struct {
+	Question MessageResponse `json:"question"`
+	Answer   MessageResponse `json:"answer"`
+}
+
+// Create starts a conversation about a document. The body is optional.
+func (h *ConversationHandler) Create(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
+	}
+
+	var req CreateConversationRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		}
+	}
+
+	conversation, err := h.conversationService.CreateConversation(c.Request.Context(), user.ID, documentID, req.Title)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, newConversationResponse(conversation))
+}
+
+// List lists the conversations about a document, most recently active first.
+func (h *ConversationHandler) List(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
+	}
+
+	conversations, err := h.conversationService.ListConversations(c.Request.Context(), user.ID, documentID)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	response := make([]ConversationResponse, 0, len(conversations))
+	for _, conversation := range conversations {
+		response = append(response, newConversationResponse(conversation))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// Get returns a conversation with its full history, oldest message first.
+func (h *ConversationHandler) Get(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	conversationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid conversation ID"})
+	}
+
+	conversation, err := h.conversationService.GetConversation(c.Request.Context(), user.ID, conversationID)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	messages, err := h.conversationService.ListMessages(c.Request.Context(), user.ID, conversationID)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	response := ConversationDetailResponse{
+		ConversationResponse: newConversationResponse(conversation),
+		Messages:             make([]MessageResponse, 0, len(messages)),
+	}
+	for _, message := range messages {
+		response.Messages = append(response.Messages, newMessageResponse(message))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// PostMessage asks a question in a conversation and returns it together with the answer.
+func (h *ConversationHandler) PostMessage(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	conversationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid conversation ID"})
+	}
+
+	var req PostMessageRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+	}
+
+	liftDeadlines(c.Response)
+	question, answer, err := h.conversationService.PostMessage(c.Request.Context(), user.ID, conversationID, req.Content)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, PostMessageResponse{
+		Question: newMessageResponse(question),
+		Answer:   newMessageResponse(answer),
+	})
+}
+
+func (h *ConversationHandler) Delete(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	conversationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid conversation ID"})
+	}
+
+	if err := h.conversationService.DeleteConversation(c.Request.Context(), user.ID, conversationID); err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Conversation deleted"})
+}
+
+func newConversationResponse(conversation *models.Conversation) ConversationResponse {
+	return ConversationResponse{
+		ID:         conversation.ID.String(),
+		DocumentID: conversation.DocumentID.String(),
+		Title:      conversation.Title,
+		CreatedAt:  conversation.CreatedAt,
+		UpdatedAt:  conversation.UpdatedAt,
+	}
+}
+
+func newMessageResponse(message *models.Message) MessageResponse {
+	return MessageResponse{
+		ID:        message.ID.String(),
+		Role:      message.Role,
+		Content:   message.Content,
+		Citations: newCitationResponses(message.Citations),
+		CreatedAt: message.CreatedAt,
+	}
+}
+
+func newCitationResponses(citations []models.Citation) []CitationResponse {
+	response := make([]CitationResponse, 0, len(citations))
+	for _, citation := range citations {
+		response = append(response, CitationResponse{
+			Passage: citation.Passage,
+			Heading: citation.Heading,
+			Page:    citation.Page,
+			Start:   citation.Start,
+			End:     citation.End,
+			Text:    citation.Text,
+		})
+	}
+	return response
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k-tsurumaki/fuselage"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+)
+
+type DeckHandler struct {
+	deckService *service.DeckService
+}
+
+func NewDeckHandler(deckService *service.DeckService) *DeckHandler {
+	return &DeckHandler{deckService: deckService}
+}
+
+type GenerateDeckRequest struct {
+	SlideCount int    `json:"slide_count"` // 4-20, default 8
+	Language   string `json:"language"`
+}
+
+type SlideResponse struct {
+	Kind     string   `json:"kind"` // title, agenda, points or actions
+	Title    string   `json:"title"`
+	Subtitle string   `json:"subtitle,omitempty"`
+	Bullets  []string `json:"bullets"`
+	Notes    string   `json:"notes,omitempty"`
+}
+
+type DeckResponse struct {
+	ID         string          `json:"id"`
+	DocumentID string          `json:"document_id"`
+	Title      string          `json:"title"`
+	Slides     []SlideResponse `json:"slides"`
+	SlideCount int             `json:"slide_count"`
+	Language   string          `json:"language"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// Generate makes a slide deck from a document. The body is optional.
+func (h *DeckHandler) Generate(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
+	}
+
+	var req GenerateDeckRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		}
+	}
+
+	liftDeadlines(c.Response)
+	deck, err := h.deckService.GenerateDeck(c.Request.Context(), user.ID, documentID, models.DeckOptions{
+		SlideCount: req.SlideCount,
+		Language:   req.Language,
+	})
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, newDeckResponse(deck))
+}
+
+// List lists the decks of a document, newest first.
+func (h *DeckHandler) List(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
+	}
+
+	decks, err := h.deckService.ListDecks(c.Request.Context(), user.ID, documentID)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	response := make([]DeckResponse, 0, len(decks))
+	for _, deck := range decks {
+		response = append(response, newDeckResponse(deck))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *DeckHandler) Get(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	deckID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid deck ID"})
+	}
+
+	deck, err := h.deckService.GetDeck(c.Request.Context(), user.ID, deckID)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, newDeckResponse(deck))
+}
+
+// Export downloads a deck as a PowerPoint file, or as Marp Markdown with format=marp.
+func (h *DeckHandler) Export(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	deckID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid deck ID"})
+	}
+	format := models.DeckFormat(c.Query("format"))
+	if format == "" {
+		format = models.DeckFormatPPTX
+	}
+
+	file, err := h.deckService.ExportDeck(c.Request.Context(), user.ID, deckID, format)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return writeFile(c, "attachment", file.Name, file.ContentType, file.Data)
+}
+
+func (h *DeckHandler) Delete(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	deckID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid deck ID"})
+	}
+
+	if err := h.deckService.DeleteDeck(c.Request.Context(), user.ID, deckID); err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Deck deleted"})
+}
+
+func newDeckResponse(deck *models.Deck) DeckResponse {
+	slides := make([]SlideResponse, 0, len(deck.Slides))
+	for _, slide := range deck.Slides {
+		bullets := slide.Bullets
+		if bullets == nil {
+			bullets = []string{}
+		}
+		slides = append(slides, SlideResponse{
+			Kind:     string(slide.Kind),
+			Title:    slide.Title,
+			Subtitle: slide.Subtitle,
+			Bullets:  bullets,
+			Notes:    slide.Notes,
+		})
+	}
+	return DeckResponse{
+		ID:         deck.ID.String(),
+		DocumentID: deck.DocumentID.String(),
+		Title:      deck.Title,
+		Slides:     slides,
+		SlideCount: deck.SlideCount,
+		Language:   deck.Language,
+		CreatedAt:  deck.CreatedAt,
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k-tsurumaki/fuselage"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+)
+
+type DiagramHandler struct {
+	diagramService *service.DiagramService
+}
+
+func NewDiagramHandler(diagramService *service.DiagramService) *DiagramHandler {
+	return &DiagramHandler{diagramService: diagramService}
+}
+
+type GenerateDiagramsRequest struct {
+	Kinds    []string `json:"kinds"`  // flowchart, sequence, mindmap; default all three
+	Syntax   string   `json:"syntax"` // mermaid (default) or plantuml
+	Language string   `json:"language"`
+}
+
+type RegenerateDiagramRequest struct {
+	Count int `json:"count"` // 1-3, default 2
+}
+
+type DiagramResponse struct {
+	ID          string    `json:"id"`
+	DocumentID  string    `json:"document_id"`
+	Kind        string    `json:"kind"`
+	Syntax      string    `json:"syntax"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Source      string    `json:"source"`
+	Language    string    `json:"language"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Generate proposes diagrams for a document, one per requested kind. The body is optional.
+func (h *DiagramHandler) Generate(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
+	}
+
+	var req GenerateDiagramsRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		}
+	}
+	kinds := make([]models.DiagramKind, len(req.Kinds))
+	for i, kind := range req.Kinds {
+		kinds[i] = models.DiagramKind(kind)
+	}
+
+	liftDeadlines(c.Response)
+	diagrams, err := h.diagramService.GenerateDiagrams(c.Request.Context(), user.ID, documentID, models.DiagramOptions{
+		Kinds:    kinds,
+		Syntax:   models.DiagramSyntax(req.Syntax),
+		Language: req.Language,
+	})
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, newDiagramResponses(diagrams))
+}
+
+// List lists the diagrams of a document, newest first.
+func (h *DiagramHandler) List(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
+	}
+
+	diagrams, err := h.diagramService.ListDiagrams(c.Request.Context(), user.ID, documentID)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, newDiagramResponses(diagrams))
+}
+
+func (h *DiagramHandler) Get(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	diagramID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid diagram ID"})
+	}
+
+	diagram, err := h.diagramService.GetDiagram(c.Request.Context(), user.ID, diagramID)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, newDiagramResponse(diagram))
+}
+
+// Regenerate proposes alternatives to a diagram. The body is optional.
+func (h *DiagramHandler) Regenerate(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	diagramID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid diagram ID"})
+	}
+
+	var req RegenerateDiagramRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+		}
+	}
+
+	liftDeadlines(c.Response)
+	diagrams, err := h.diagramService.RegenerateDiagram(c.Request.Context(), user.ID, diagramID, req.Count)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, newDiagramResponses(diagrams))
+}
+
+func (h *DiagramHandler) Delete(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	diagramID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid diagram ID"})
+	}
+
+	if err := h.diagramService.DeleteDiagram(c.Request.Context(), user.ID, diagramID); err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Diagram deleted"})
+}
+
+func newDiagramResponses(diagrams []*models.Diagram) []DiagramResponse {
+	response := make([]DiagramResponse, 0, len(diagrams))
+	for _, diagram := range diagrams {
+		response = append(response, newDiagramResponse(diagram))
+	}
+	return response
+}
+
+func newDiagramResponse(diagram *models.Diagram) DiagramResponse {
+	return DiagramResponse{
+		ID:          diagram.ID.String(),
+		DocumentID:  diagram.DocumentID.String(),
+		Kind:        string(diagram.Kind),
+		Syntax:      string(diagram.Syntax),
+		Title:       diagram.Title,
+		Description: diagram.Description,
+		Source:      diagram.Source,
+		Language:    diagram.Language,
+		CreatedAt:   diagram.CreatedAt,
+	}
+}
@@ -1,22 +1,39 @@
 package handlers
 
 import (
+	stderrors "errors"
+	"fmt"
 	"io"
+	"log"
+	"mime/multipart"
 	"net/http"
+	"path"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/k-tsurumaki/fuselage"
 	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
 	"github/k-tsurumaki/quilldeck/internal/domain/service"
-	"github.com/google/uuid"
 )
 
+// maxUploadSize bounds the request body of an upload, all of its files together.
+const maxUploadSize = 256 << 20
+
 type DocumentHandler struct {
-	docService *service.DocumentService
+	docService      *service.DocumentService
+	jobService      *service.JobService
+	artifactService *service.ArtifactService
 }
 
-func NewDocumentHandler(docService *service.DocumentService) *DocumentHandler {
-	return &DocumentHandler{docService: docService}
+func NewDocumentHandler(docService *service.DocumentService, jobService *service.JobService, artifactService *service.ArtifactService) *DocumentHandler {
+	return &DocumentHandler{
+		docService:      docService,
+		jobService:      jobService,
+		artifactService: artifactService,
+	}
 }
 
 type UploadResponse struct {
@@ -24,54 +41,141 @@ type UploadResponse struct {
 	DocumentID string `json:"document_id"`
 }
 
+// BatchUploadResponse answers uploads of several files or of zip archives, with a result for each file.
+type BatchUploadResponse struct {
+	Message  string         `json:"message"`
+	Created  int            `json:"created"`
+	Rejected int            `json:"rejected"`
+	Results  []UploadResult `json:"results"`
+}
+
+// UploadResult is the document created from one file of a batch upload, or the error it was rejected with.
+type UploadResult struct {
+	Filename string `json:"filename"`
+	// Archive is the zip archive the file came in, in which Filename is its path.
+	Archive    string `json:"archive,omitempty"`
+	DocumentID string `json:"document_id,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
 type SummaryRequest struct {
-	DocumentID string `json:"document_id"`
+	DocumentID  string `json:"document_id"`
+	Length      string `json:"length"` // short, medium or long
+	TargetChars int    `json:"target_chars"`
+	TargetWords int    `json:"target_words"`
+	Style       string `json:"style"` // business, bullets, executive or casual
+	Language    string `json:"language"`
 }
 
-type SummaryResponse struct {
+func (r SummaryRequest) options() models.SummaryOptions {
+	return models.SummaryOptions{
+		Length:      models.SummaryLength(r.Length),
+		TargetChars: r.TargetChars,
+		TargetWords: r.TargetWords,
+		Style:       models.SummaryStyle(r.Style),
+		Language:    r.Language,
+	}
+}
+
+type SummaryJobResponse struct {
 	Message   string `json:"message"`
-	SummaryID string `json:"summary_id"`
-	Content   string `json:"content"`
+	JobID     string `json:"job_id"`
+	Status    string `json:"status"`
+	StatusURL string `json:"status_url"`
+}
+
+type UpdateDocumentRequest struct {
+	Title   *string `json:"title"`
+	Content *string `json:"content"`
+}
+
+type DocumentResponse struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Type        string     `json:"type"`
+	Size        int64      `json:"size"`
+	UploadedAt  time.Time  `json:"uploaded_at"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+	Content     string     `json:"content,omitempty"`
+	// Pages locates the pages of a PDF document in Content.
+	Pages []models.PageSpan `json:"pages,omitempty"`
+	// HasOriginal is set for documents converted from another format, whose file can be downloaded.
+	HasOriginal bool `json:"has_original,omitempty"`
+	// Metadata is what the uploaded file said about itself, such as a web page's title and canonical URL.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// SourceEncoding is the character encoding an uploaded text file or web page was in, such as "Shift_JIS".
+	SourceEncoding string `json:"source_encoding,omitempty"`
 }
 
+type DocumentListResponse struct {
+	Documents  []DocumentResponse `json:"documents"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+type SummaryItemResponse struct {
+	ID      string `json:"id"`
+	Content string `json:"content"`
+	Status  string `json:"status"`
+	models.SummaryOptions
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
 
+// Upload creates documents from the files in the form's "file" fields. A single file is uploaded as
+// before, answering with its document's ID or an error; several files, or zip archives, are uploaded as a
+// batch, answering with what became of each file.
 func (h *DocumentHandler) Upload(c *fuselage.Context) error {
-	// TODO: Get user ID from authentication after implementing auth
-	userID := uuid.New()
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
 
+	// Large batches take longer to arrive than the server's read timeout allows, so the body is bounded by
+	// size instead.
+	c.Request.Body = http.MaxBytesReader(c.Response, c.Request.Body, maxUploadSize)
+	liftDeadlines(c.Response)
+	// Files past the first 10MB of the form are spooled to disk rather than held in memory.
 	err := c.Request.ParseMultipartForm(10 << 20) // 10MB
+	var tooLarge *http.MaxBytesError
+	if stderrors.As(err, &tooLarge) {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": fmt.Sprintf("Uploads are limited to %d MB", maxUploadSize>>20)})
+	}
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to parse form"})
 	}
+	defer c.Request.MultipartForm.RemoveAll()
 
-	file, header, err := c.Request.FormFile("file")
-	if err != nil {
+	headers := c.Request.MultipartForm.File["file"]
+	if len(headers) == 0 {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "No file uploaded"})
 	}
-	defer file.Close()
+	if len(headers) > 1 || strings.EqualFold(path.Ext(headers[0].Filename), ".zip") {
+		return h.uploadBatch(c, user.ID, headers)
+	}
 
 	// Check file extension
-	filename := header.Filename
-	var docType models.DocumentType
-	if strings.HasSuffix(strings.ToLower(filename), ".txt") {
-		docType = models.DocumentTypeTXT
-	} else if strings.HasSuffix(strings.ToLower(filename), ".md") {
-		docType = models.DocumentTypeMD
-	} else {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only .txt and .md files are supported"})
+	filename := headers[0].Filename
+	docType, ok := models.DocumentTypeForFilename(filename)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only .txt, .md, .pdf, .docx, .pptx, .xlsx, .html, .mhtml and .zip files are supported"})
+	}
+
+	if headers[0].Size > service.MaxFileSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": fmt.Sprintf("Files are limited to %d MB", service.MaxFileSize>>20)})
 	}
 
 	// Read file content
-	content, err := io.ReadAll(file)
+	content, err := readFormFile(headers[0])
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to read file"})
 	}
 
 	// Create document
-	document, err := h.docService.UploadDocument(c.Request.Context(), userID, filename, string(content), docType)
+	document, err := h.docService.UploadFile(c.Request.Context(), user.ID, filename, docType, content)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
 	}
+	h.queueEmbedding(c, user.ID, document.ID)
 
 	return c.JSON(http.StatusOK, UploadResponse{
 		Message:    "File uploaded successfully",
@@ -79,7 +183,102 @@ func (h *DocumentHandler) Upload(c *fuselage.Context) error {
 	})
 }
 
+// uploadBatch uploads each file in turn, reading one at a time, and zip archives one file at a time. It
+// answers 200 when any document was created and 400 when every file was rejected.
+func (h *DocumentHandler) uploadBatch(c *fuselage.Context, userID uuid.UUID, headers []*multipart.FileHeader) error {
+	response := BatchUploadResponse{Results: []UploadResult{}}
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			response.Results = append(response.Results, UploadResult{Filename: header.Filename, Error: "Failed to read file"})
+			response.Rejected++
+			continue
+		}
+
+		results := h.docService.UploadBatch(c.Request.Context(), userID, header.Filename, file, header.Size)
+		file.Close()
+		for _, result := range results {
+			item := UploadResult{Filename: result.Filename, Archive: result.Archive}
+			if result.Err != nil {
+				item.Error = result.Err.Error()
+				response.Rejected++
+			} else {
+				item.DocumentID = result.Document.ID.String()
+				h.queueEmbedding(c, userID, result.Document.ID)
+				response.Created++
+			}
+			response.Results = append(response.Results, item)
+		}
+	}
+
+	if response.Created == 0 {
+		response.Message = "No files were uploaded"
+		return c.JSON(http.StatusBadRequest, response)
+	}
+	response.Message = fmt.Sprintf("%d of %d files uploaded successfully", response.Created, response.Created+response.Rejected)
+	return c.JSON(http.StatusOK, response)
+}
+
+func readFormFile(header *multipart.FileHeader) ([]byte, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(io.LimitReader(file, service.MaxFileSize))
+}
+
 func (h *DocumentHandler) GenerateSummary(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	var req SummaryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+	}
+
+	documentID, err := uuid.Parse(req.DocumentID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
+	}
+
+	job, err := h.jobService.EnqueueSummary(c.Request.Context(), user.ID, documentID, req.options())
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	statusURL := "/api/jobs/" + job.ID.String()
+	c.SetHeader("Location", statusURL)
+	return c.JSON(http.StatusAccepted, SummaryJobResponse{
+		Message:   "Summary generation queued",
+		JobID:     job.ID.String(),
+		Status:    string(job.Status),
+		StatusURL: statusURL,
+	})
+}
+
+type SummaryDeltaEvent struct {
+	Text string `json:"text"`
+}
+
+type SummaryStreamErrorEvent struct {
+	Error     string `json:"error"`
+	SummaryID string `json:"summary_id,omitempty"`
+	Status    string `json:"status,omitempty"`
+}
+
+// StreamSummary generates a summary in the request and streams it as Server-Sent Events: "delta" events
+// carry text as the model produces it, then "done" carries the saved summary or "error" reports why the
+// stream stopped and which partial or failed summary was recorded. Problems found before the stream
+// starts are answered with an ordinary JSON error.
+func (h *DocumentHandler) StreamSummary(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
 	var req SummaryRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
@@ -90,14 +289,284 @@ func (h *DocumentHandler) GenerateSummary(c *fuselage.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
 	}
 
-	summary, err := h.docService.GenerateSummary(c.Request.Context(), documentID)
+	stream := newSSEWriter(c.Response)
+	summary, err := h.docService.StreamSummary(c.Request.Context(), user.ID, documentID, req.options(), func(delta string) error {
+		return stream.Event("delta", SummaryDeltaEvent{Text: delta})
+	})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		if !stream.Started() {
+			return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+		}
+		event := SummaryStreamErrorEvent{Error: err.Error()}
+		if summary != nil {
+			event.SummaryID = summary.ID.String()
+			event.Status = string(summary.Status)
+		}
+		// The client may be gone already, in which case there is nobody to tell.
+		_ = stream.Event("error", event)
+		return nil
 	}
 
-	return c.JSON(http.StatusOK, SummaryResponse{
-		Message:   "Summary generated successfully",
-		SummaryID: summary.ID.String(),
-		Content:   summary.Content,
+	return stream.Event("done", newSummaryItemResponse(summary))
+}
+
+// List returns one page of the user's documents. Query parameters: type, processed (true/false),
+// uploaded_from and uploaded_to (RFC 3339 or YYYY-MM-DD), q (title substring), sort (uploaded_at,
+// title, size), order (asc, desc), limit and cursor (next_cursor from the previous page).
+func (h *DocumentHandler) List(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	params, err := parseListDocumentsParams(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	page, err := h.docService.ListUserDocuments(c.Request.Context(), user.ID, params)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	response := make([]DocumentResponse, 0, len(page.Documents))
+	for _, item := range page.Documents {
+		response = append(response, DocumentResponse{
+			ID:          item.ID.String(),
+			Title:       item.Title,
+			Type:        string(item.Type),
+			Size:        item.Size,
+			UploadedAt:  item.UploadedAt,
+			ProcessedAt: item.ProcessedAt,
+		})
+	}
+	return c.JSON(http.StatusOK, DocumentListResponse{
+		Documents:  response,
+		NextCursor: page.NextCursor,
 	})
-}
\ No newline at end of file
+}
+
+func parseListDocumentsParams(c *fuselage.Context) (service.ListDocumentsParams, error) {
+	params := service.ListDocumentsParams{
+		Type:          models.DocumentType(c.Query("type")),
+		TitleContains: c.Query("q"),
+		SortBy:        repository.DocumentSortField(c.Query("sort")),
+		SortOrder:     repository.SortOrder(strings.ToLower(c.Query("order"))),
+		Cursor:        c.Query("cursor"),
+	}
+
+	if processed := c.Query("processed"); processed != "" {
+		value, err := strconv.ParseBool(processed)
+		if err != nil {
+			return params, fmt.Errorf("processed must be true or false")
+		}
+		params.Processed = &value
+	}
+	if from := c.Query("uploaded_from"); from != "" {
+		t, err := parseDateParam(from, false)
+		if err != nil {
+			return params, fmt.Errorf("uploaded_from: %w", err)
+		}
+		params.UploadedFrom = &t
+	}
+	if to := c.Query("uploaded_to"); to != "" {
+		t, err := parseDateParam(to, true)
+		if err != nil {
+			return params, fmt.Errorf("uploaded_to: %w", err)
+		}
+		params.UploadedTo = &t
+	}
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 {
+			return params, fmt.Errorf("limit must be a positive integer")
+		}
+		params.Limit = value
+	}
+	return params, nil
+}
+
+// parseDateParam accepts RFC 3339 timestamps or plain dates. A plain date used as an exclusive upper
+// bound is moved to the following midnight so that the whole day is included.
+func parseDateParam(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 timestamp or YYYY-MM-DD date")
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func (h *DocumentHandler) Get(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
+	}
+
+	document, err := h.docService.GetUserDocument(c.Request.Context(), user.ID, documentID)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, newDocumentResponse(document, true))
+}
+
+// originalTypes are the media types of the files documents are converted from.
+var originalTypes = map[models.DocumentType]string{
+	models.DocumentTypePDF:  "application/pdf",
+	models.DocumentTypeDOCX: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	models.DocumentTypePPTX: "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	models.DocumentTypeXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	models.DocumentTypeHTML: "text/html",
+}
+
+// GetOriginal downloads the file a document was converted from, as it was uploaded.
+func (h *DocumentHandler) GetOriginal(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
+	}
+
+	document, data, err := h.docService.GetOriginal(c.Request.Context(), user.ID, documentID)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	contentType, ok := originalTypes[document.Type]
+	if !ok {
+		contentType = "application/octet-stream"
+	}
+	// Web pages may have been saved as MHTML archives rather than HTML, as their file names tell.
+	if ext := strings.ToLower(path.Ext(document.Title)); document.Type == models.DocumentTypeHTML && (ext == ".mhtml" || ext == ".mht") {
+		contentType = "multipart/related"
+	}
+	return writeFile(c, "attachment", document.Title, contentType, data)
+}
+
+func (h *DocumentHandler) Update(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
+	}
+
+	var req UpdateDocumentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+	}
+	if req.Title == nil && req.Content == nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Nothing to update"})
+	}
+
+	document, err := h.docService.UpdateDocument(c.Request.Context(), user.ID, documentID, req.Title, req.Content)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	// The title is part of what is embedded, so either change makes the chunks stale.
+	h.queueEmbedding(c, user.ID, document.ID)
+
+	return c.JSON(http.StatusOK, newDocumentResponse(document, true))
+}
+
+// queueEmbedding refreshes the document's chunks for semantic search in the background. The document
+// itself is already saved, so a failure is only logged; documents never embedded are queued again on
+// the next start.
+func (h *DocumentHandler) queueEmbedding(c *fuselage.Context, userID, documentID uuid.UUID) {
+	if _, err := h.jobService.EnqueueEmbedding(c.Request.Context(), userID, documentID); err != nil {
+		log.Printf("failed to queue embedding of document %s: %v", documentID, err)
+	}
+}
+
+func (h *DocumentHandler) Delete(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
+	}
+
+	// The artifacts' files go first; their records would otherwise disappear with the document.
+	if err := h.artifactService.DeleteDocumentArtifacts(c.Request.Context(), user.ID, documentID); err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+	if err := h.docService.DeleteDocument(c.Request.Context(), user.ID, documentID); err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Document deleted"})
+}
+
+func (h *DocumentHandler) ListSummaries(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid document ID"})
+	}
+
+	summaries, err := h.docService.GetDocumentSummaries(c.Request.Context(), user.ID, documentID)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	response := make([]SummaryItemResponse, 0, len(summaries))
+	for _, summary := range summaries {
+		response = append(response, newSummaryItemResponse(summary))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"summaries": response})
+}
+
+func newSummaryItemResponse(summary *models.Summary) SummaryItemResponse {
+	return SummaryItemResponse{
+		ID:             summary.ID.String(),
+		Content:        summary.Content,
+		Status:         string(summary.Status),
+		SummaryOptions: summary.SummaryOptions,
+		CreatedAt:      summary.CreatedAt,
+		UpdatedAt:      summary.UpdatedAt,
+	}
+}
+
+func newDocumentResponse(document *models.Document, withContent bool) DocumentResponse {
+	response := DocumentResponse{
+		ID:             document.ID.String(),
+		Title:          document.Title,
+		Type:           string(document.Type),
+		Size:           document.Size,
+		UploadedAt:     document.UploadedAt,
+		ProcessedAt:    document.ProcessedAt,
+		HasOriginal:    document.OriginalKey != "",
+		Metadata:       document.Metadata,
+		SourceEncoding: document.SourceEncoding,
+	}
+	if withContent {
+		response.Content = document.Content
+		response.Pages = document.Pages
+	}
+	return response
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/k-tsurumaki/fuselage"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/interfaces/http/middleware"
+	"github/k-tsurumaki/quilldeck/internal/pkg/errors"
+)
+
+// currentUser returns the user injected by the auth middleware, writing a 401 response when absent.
+func currentUser(c *fuselage.Context) (*models.User, bool) {
+	user, ok := middleware.UserFromContext(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+		return nil, false
+	}
+	return user, true
+}
+
+// errorStatus maps application error codes to HTTP status codes, using fallback for anything else.
+func errorStatus(err error, fallback int) int {
+	var appErr *errors.AppError
+	if !stderrors.As(err, &appErr) {
+		return fallback
+	}
+	switch appErr.Code {
+	case errors.ErrCodeValidation:
+		return http.StatusBadRequest
+	case errors.ErrCodeNotFound:
+		return http.StatusNotFound
+	case errors.ErrCodeUnauthorized:
+		return http.StatusUnauthorized
+	case errors.ErrCodeUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return fallback
+	}
+}
+
+// writeFile sends data as a file named name, shown in the browser when disposition is "inline" and saved
+// when it is "attachment".
+func writeFile(c *fuselage.Context, disposition, name, contentType string, data []byte) error {
+	c.SetHeader("Content-Type", contentType)
+	c.SetHeader("Content-Length", strconv.Itoa(len(data)))
+	c.SetHeader("X-Content-Type-Options", "nosniff")
+	// FormatMediaType encodes non-ASCII names as filename*=utf-8''...
+	c.SetHeader("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": name}))
+	c.SetStatus(http.StatusOK)
+	_, err := c.Response.Write(data)
+	return err
+}
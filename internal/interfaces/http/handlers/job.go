@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k-tsurumaki/fuselage"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+)
+
+type JobHandler struct {
+	jobService *service.JobService
+	docService *service.DocumentService
+}
+
+func NewJobHandler(jobService *service.JobService, docService *service.DocumentService) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+		docService: docService,
+	}
+}
+
+type JobResponse struct {
+	ID         string               `json:"id"`
+	Type       string               `json:"type"`
+	Status     string               `json:"status"`
+	DocumentID string               `json:"document_id"`
+	ResultID   string               `json:"result_id,omitempty"`
+	Error      string               `json:"error,omitempty"`
+	Attempts   int                  `json:"attempts"`
+	CreatedAt  time.Time            `json:"created_at"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+	StartedAt  *time.Time           `json:"started_at,omitempty"`
+	FinishedAt *time.Time           `json:"finished_at,omitempty"`
+	Summary    *SummaryItemResponse `json:"summary,omitempty"`
+}
+
+// Get reports the state of a job. Once a summary job has succeeded the summary is embedded, so
+// clients polling this endpoint need no second request.
+func (h *JobHandler) Get(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid job ID"})
+	}
+
+	job, err := h.jobService.GetJob(c.Request.Context(), user.ID, jobID)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	response := JobResponse{
+		ID:         job.ID.String(),
+		Type:       string(job.Type),
+		Status:     string(job.Status),
+		DocumentID: job.DocumentID.String(),
+		Error:      job.Error,
+		Attempts:   job.Attempts,
+		CreatedAt:  job.CreatedAt,
+		UpdatedAt:  job.UpdatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if job.ResultID != nil {
+		response.ResultID = job.ResultID.String()
+		if job.Type == models.JobTypeSummary {
+			summary, err := h.docService.GetSummary(c.Request.Context(), user.ID, *job.ResultID)
+			if err != nil {
+				// The summary or its document may have been deleted since; the job record still stands.
+				log.Printf("failed to load result of job %s: %v", job.ID, err)
+			} else {
+				item := newSummaryItemResponse(summary)
+				response.Summary = &item
+			}
+		}
+	}
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/k-tsurumaki/fuselage"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+)
+
+type SearchHandler struct {
+	searchService    *service.SearchService
+	embeddingService *service.EmbeddingService
+}
+
+func NewSearchHandler(searchService *service.SearchService, embeddingService *service.EmbeddingService) *SearchHandler {
+	return &SearchHandler{
+		searchService:    searchService,
+		embeddingService: embeddingService,
+	}
+}
+
+type SearchResultResponse struct {
+	Type       string    `json:"type"` // document or summary
+	DocumentID string    `json:"document_id"`
+	SummaryID  string    `json:"summary_id,omitempty"`
+	Title      string    `json:"title"`
+	Snippet    string    `json:"snippet"` // HTML-escaped text with matches wrapped in <mark>
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type SearchResponse struct {
+	Query   string                 `json:"query"`
+	Results []SearchResultResponse `json:"results"`
+}
+
+type ChunkResultResponse struct {
+	DocumentID string  `json:"document_id"`
+	Title      string  `json:"title"`
+	ChunkIndex int     `json:"chunk_index"`
+	Heading    string  `json:"heading,omitempty"`
+	Content    string  `json:"content"`
+	Start      int     `json:"start"` // byte offsets of the chunk in the document content
+	End        int     `json:"end"`
+	Score      float64 `json:"score"` // cosine similarity to the query
+}
+
+type SemanticSearchResponse struct {
+	Query   string                `json:"query"`
+	Results []ChunkResultResponse `json:"results"`
+}
+
+// Search finds the user's documents and summaries containing every whitespace-separated term of q.
+// Query parameters: q, limit (default 20, at most 100) and offset.
+func (h *SearchHandler) Search(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	params := service.SearchParams{Query: c.Query("q")}
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be a positive integer"})
+		}
+		params.Limit = value
+	}
+	if offset := c.Query("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "offset must be a non-negative integer"})
+		}
+		params.Offset = value
+	}
+
+	hits, err := h.searchService.Search(c.Request.Context(), user.ID, params)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	results := make([]SearchResultResponse, 0, len(hits))
+	for _, hit := range hits {
+		result := SearchResultResponse{
+			Type:       string(hit.Kind),
+			DocumentID: hit.DocumentID.String(),
+			Title:      hit.Title,
+			Snippet:    hit.Snippet,
+			CreatedAt:  hit.CreatedAt,
+		}
+		if hit.SummaryID != nil {
+			result.SummaryID = hit.SummaryID.String()
+		}
+		results = append(results, result)
+	}
+	return c.JSON(http.StatusOK, SearchResponse{
+		Query:   params.Query,
+		Results: results,
+	})
+}
+
+// SemanticSearch finds the chunks of the user's documents closest in meaning to q, best first.
+// Query parameters: q and limit (default 10, at most 50). Responds 503 when no embedding model is
+// configured.
+func (h *SearchHandler) SemanticSearch(c *fuselage.Context) error {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil
+	}
+
+	query := c.Query("q")
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be a positive integer"})
+		}
+		limit = parsed
+	}
+
+	matches, err := h.embeddingService.SemanticSearch(c.Request.Context(), user.ID, query, limit)
+	if err != nil {
+		return c.JSON(errorStatus(err, http.StatusInternalServerError), map[string]string{"error": err.Error()})
+	}
+
+	results := make([]ChunkResultResponse, 0, len(matches))
+	for _, match := range matches {
+		results = append(results, ChunkResultResponse{
+			DocumentID: match.DocumentID.String(),
+			Title:      match.Title,
+			ChunkIndex: match.Index,
+			Heading:    match.Heading,
+			Content:    match.Content,
+			Start:      match.Start,
+			End:        match.End,
+			Score:      match.Score,
+		})
+	}
+	return c.JSON(http.StatusOK, SemanticSearchResponse{
+		Query:   query,
+		Results: results,
+	})
+}
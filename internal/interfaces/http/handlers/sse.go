@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// sseWriter writes Server-Sent Events. The event-stream headers go out with the first event, so until
+// then a handler can still answer with an ordinary JSON error.
+type sseWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	started bool
+}
+
+// newSSEWriter lifts the server's read and write timeouts from the connection, which would otherwise
+// cut off a stream that outlives them. Call it once the request body has been read.
+func newSSEWriter(w http.ResponseWriter) *sseWriter {
+	return &sseWriter{w: w, rc: liftDeadlines(w)}
+}
+
+// liftDeadlines removes the server's read and write timeouts from the connection, for responses that take
+// longer than they allow: streams, and answers that wait on a language model. Past the write timeout the
+// response would be thrown away after the work, and the tokens, had been paid for. Call it once the request
+// body has been read, or, for uploads, once the body's size is bounded; the request's context still ends
+// when the client goes away.
+func liftDeadlines(w http.ResponseWriter) *http.ResponseController {
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+	return rc
+}
+
+func (s *sseWriter) Started() bool {
+	return s.started
+}
+
+// Event sends one event with data encoded as JSON and flushes it to the client.
+func (s *sseWriter) Event(name string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if !s.started {
+		header := s.w.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		// Keep reverse proxies such as nginx from buffering the stream.
+		header.Set("X-Accel-Buffering", "no")
+		s.w.WriteHeader(http.StatusOK)
+		s.started = true
+	}
+
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", name, payload); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/k-tsurumaki/fuselage"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+)
+
+type contextKey string
+
+const (
+	userContextKey    contextKey = "user"
+	sessionContextKey contextKey = "session_id"
+	apiKeyContextKey  contextKey = "api_key"
+)
+
+// HeaderAPIKey is accepted as an alternative to "Authorization: Bearer qd_..." for scripts.
+const HeaderAPIKey = "X-API-Key"
+
+// Auth authenticates the request with either a bearer access token or a personal API key and injects
+// the authenticated user into the request context.
+func Auth(tokenService *service.TokenService, authService *service.AuthService, apiKeyService *service.APIKeyService) fuselage.MiddlewareFunc {
+	return func(next fuselage.HandlerFunc) fuselage.HandlerFunc {
+		return func(c *fuselage.Context) error {
+			if apiKey := c.Header(HeaderAPIKey); apiKey != "" {
+				return authenticateAPIKey(c, apiKeyService, apiKey, next)
+			}
+
+			token, ok := bearerToken(c.Header(fuselage.HeaderAuthorization))
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Missing bearer token"})
+			}
+			if service.IsAPIKey(token) {
+				return authenticateAPIKey(c, apiKeyService, token, next)
+			}
+
+			claims, err := tokenService.ParseAccessToken(token)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid or expired token"})
+			}
+
+			userID, err := claims.UserID()
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid or expired token"})
+			}
+			sessionID, err := claims.SessionUUID()
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid or expired token"})
+			}
+
+			// Access tokens die with their session, so a revoked device is locked out immediately.
+			if err := authService.ValidateSession(c.Request.Context(), userID, sessionID); err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Session has been revoked"})
+			}
+
+			user, err := authService.GetUser(c.Request.Context(), userID)
+			if err != nil || user == nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User no longer exists"})
+			}
+
+			ctx := WithUser(c.Request.Context(), user)
+			ctx = WithSessionID(ctx, sessionID)
+			c.Request = c.Request.WithContext(ctx)
+			return next(c)
+		}
+	}
+}
+
+func authenticateAPIKey(c *fuselage.Context, apiKeyService *service.APIKeyService, plaintext string, next fuselage.HandlerFunc) error {
+	user, key, err := apiKeyService.Authenticate(c.Request.Context(), plaintext)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid, expired or revoked API key"})
+	}
+
+	ctx := WithUser(c.Request.Context(), user)
+	ctx = WithAPIKey(ctx, key)
+	c.Request = c.Request.WithContext(ctx)
+	return next(c)
+}
+
+// RequireScope rejects API-key requests whose key lacks scope. Requests authenticated with a session
+// access token act with the user's full authority and always pass.
+func RequireScope(scope string) fuselage.MiddlewareFunc {
+	return func(next fuselage.HandlerFunc) fuselage.HandlerFunc {
+		return func(c *fuselage.Context) error {
+			if key, ok := APIKeyFromContext(c.Request.Context()); ok && !key.HasScope(scope) {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "API key is missing the " + scope + " scope"})
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireSession rejects requests not made with a session access token, keeping credential management
+// out of reach of API keys.
+func RequireSession() fuselage.MiddlewareFunc {
+	return func(next fuselage.HandlerFunc) fuselage.HandlerFunc {
+		return func(c *fuselage.Context) error {
+			if _, ok := SessionIDFromContext(c.Request.Context()); !ok {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "This endpoint requires an interactive login"})
+			}
+			return next(c)
+		}
+	}
+}
+
+// WithUser returns a copy of ctx carrying the authenticated user.
+func WithUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+// UserFromContext returns the authenticated user stored by Auth, if any.
+func UserFromContext(ctx context.Context) (*models.User, bool) {
+	user, ok := ctx.Value(userContextKey).(*models.User)
+	return user, ok && user != nil
+}
+
+// WithSessionID returns a copy of ctx carrying the session the request was authenticated with.
+func WithSessionID(ctx context.Context, sessionID uuid.UUID) context.Context {
+	return context.WithValue(ctx, sessionContextKey, sessionID)
+}
+
+// SessionIDFromContext returns the session stored by Auth, if any.
+func SessionIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	sessionID, ok := ctx.Value(sessionContextKey).(uuid.UUID)
+	return sessionID, ok
+}
+
+// WithAPIKey returns a copy of ctx carrying the API key the request was authenticated with.
+func WithAPIKey(ctx context.Context, key *models.APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey, key)
+}
+
+// APIKeyFromContext returns the API key stored by Auth, if the request used one.
+func APIKeyFromContext(ctx context.Context) (*models.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyContextKey).(*models.APIKey)
+	return key, ok && key != nil
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
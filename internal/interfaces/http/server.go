@@ -1,58 +1,248 @@
 package http
 
 import (
+	"context"
 	"net/http"
+	"time"
+
 	"github/k-tsurumaki/quilldeck/internal/config"
 
 	"github.com/k-tsurumaki/fuselage"
 	"github.com/k-tsurumaki/fuselage/middleware"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
 	"github/k-tsurumaki/quilldeck/internal/domain/service"
-	"github/k-tsurumaki/quilldeck/internal/infrastructure/database/sqlite"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/ai"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/storage"
 	"github/k-tsurumaki/quilldeck/internal/interfaces/http/handlers"
+	authmw "github/k-tsurumaki/quilldeck/internal/interfaces/http/middleware"
+	"github/k-tsurumaki/quilldeck/internal/pkg/crypto"
 )
 
+const shutdownTimeout = 10 * time.Second
+
 type Server struct {
-	router      *fuselage.Router
-	authHandler *handlers.AuthHandler
-	docHandler  *handlers.DocumentHandler
+	router          *fuselage.Router
+	requireAuth     fuselage.MiddlewareFunc
+	authHandler     *handlers.AuthHandler
+	keyHandler      *handlers.APIKeyHandler
+	docHandler      *handlers.DocumentHandler
+	jobHandler      *handlers.JobHandler
+	searchHandler   *handlers.SearchHandler
+	askHandler      *handlers.AskHandler
+	chatHandler     *handlers.ConversationHandler
+	deckHandler     *handlers.DeckHandler
+	diagramHandler  *handlers.DiagramHandler
+	artifactHandler *handlers.ArtifactHandler
+	jobService      *service.JobService
 }
 
-func NewServer(db *sqlite.DB, cfg *config.Config) *Server {
+func NewServer(repos *repository.Repositories, cfg *config.Config) (*Server, error) {
 	router := fuselage.New()
-	
+
 	// Add CORS middleware
 	router.Use(middleware.CORS())
-	
-	// Create repositories
-	userRepo := sqlite.NewUserRepository(db)
-	docRepo := sqlite.NewDocumentRepository(db)
-	summaryRepo := sqlite.NewSummaryRepository(db)
-	
+
+	hasher, err := crypto.NewPasswordHasher(cfg.Auth.Password.Algorithm, crypto.Argon2Params{
+		Memory:      uint32(cfg.Auth.Password.Argon2Memory),
+		Iterations:  uint32(cfg.Auth.Password.Argon2Iterations),
+		Parallelism: uint8(cfg.Auth.Password.Argon2Parallelism),
+	}, cfg.Auth.Password.BcryptCost)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := ai.NewLLM(cfg.LLM)
+	if err != nil {
+		return nil, err
+	}
+
+	embedder, err := ai.NewEmbedder(cfg.LLM)
+	if err != nil {
+		return nil, err
+	}
+
+	// Jobs call the model in the background, where no one is waiting on a response: each call may take as
+	// long as the whole job, which JOB_TIMEOUT bounds, rather than the LLM_TIMEOUT that requests allow.
+	jobLLMConfig := cfg.LLM
+	jobLLMConfig.LLM_TIMEOUT = cfg.Jobs.Timeout
+	jobModel, err := ai.NewLLM(jobLLMConfig)
+	if err != nil {
+		return nil, err
+	}
+	jobEmbedder, err := ai.NewEmbedder(jobLLMConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	blobs, err := storage.NewFileSystem(cfg.Storage.Dir)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create services
-	authService := service.NewAuthService(userRepo)
-	docService := service.NewDocumentService(docRepo, summaryRepo, cfg.LLM.LLM_API_KEY, cfg.LLM.LLM_BASE_URL, cfg.LLM.LLM_MODEL)
-	
-	return &Server{
-		router:      router,
-		authHandler: handlers.NewAuthHandler(authService, docService),
-		docHandler:  handlers.NewDocumentHandler(docService),
+	authService := service.NewAuthService(repos.Users, repos.Sessions, hasher, cfg.Auth.RefreshTokenTTL)
+	tokenService := service.NewTokenService(cfg.Auth.JWTSecret, cfg.Auth.AccessTokenTTL)
+	apiKeyService := service.NewAPIKeyService(repos.APIKeys, repos.Users)
+	summarizerOpts := service.SummarizerOptions{
+		MaxChunkChars: cfg.Summary.MaxChunkChars,
+		Concurrency:   cfg.Summary.Concurrency,
+	}
+	docService := service.NewDocumentService(repos.Documents, repos.Summaries, blobs, model, summarizerOpts)
+	searchService := service.NewSearchService(repos.Search)
+	embeddingOpts := service.EmbeddingOptions{
+		Model:      cfg.LLM.LLM_EMBEDDING_MODEL,
+		ChunkChars: cfg.Embedding.ChunkChars,
+		BatchSize:  cfg.Embedding.BatchSize,
 	}
+	embeddingService := service.NewEmbeddingService(repos.Embeddings, repos.Documents, embedder, embeddingOpts)
+	askService := service.NewAskService(docService, embeddingService, model, service.AskOptions{
+		PassageChars: cfg.Embedding.ChunkChars,
+		ContextChars: cfg.Chat.ContextChars,
+	})
+	conversationService := service.NewConversationService(repos.Conversations, askService)
+	artifactService := service.NewArtifactService(repos.Artifacts, blobs, docService)
+	deckService := service.NewDeckService(repos.Decks, docService, artifactService, model)
+	diagramService := service.NewDiagramService(repos.Diagrams, docService, artifactService, model)
+	jobDocService := service.NewDocumentService(repos.Documents, repos.Summaries, blobs, jobModel, summarizerOpts)
+	jobEmbeddingService := service.NewEmbeddingService(repos.Embeddings, repos.Documents, jobEmbedder, embeddingOpts)
+	jobService := service.NewJobService(repos.Jobs, jobDocService, jobEmbeddingService, service.JobOptions{
+		Workers:      cfg.Jobs.Workers,
+		PollInterval: cfg.Jobs.PollInterval,
+		Timeout:      cfg.Jobs.Timeout,
+		MaxAttempts:  cfg.Jobs.MaxAttempts,
+	})
+
+	return &Server{
+		router:          router,
+		requireAuth:     authmw.Auth(tokenService, authService, apiKeyService),
+		authHandler:     handlers.NewAuthHandler(authService, tokenService),
+		keyHandler:      handlers.NewAPIKeyHandler(apiKeyService),
+		docHandler:      handlers.NewDocumentHandler(docService, jobService, artifactService),
+		jobHandler:      handlers.NewJobHandler(jobService, docService),
+		searchHandler:   handlers.NewSearchHandler(searchService, embeddingService),
+		askHandler:      handlers.NewAskHandler(askService),
+		chatHandler:     handlers.NewConversationHandler(conversationService),
+		deckHandler:     handlers.NewDeckHandler(deckService),
+		diagramHandler:  handlers.NewDiagramHandler(diagramService),
+		artifactHandler: handlers.NewArtifactHandler(artifactService),
+		jobService:      jobService,
+	}, nil
 }
 
-func (s *Server) Start(port string) error {
+// Start runs the background job workers and serves HTTP until ctx is cancelled, then shuts both down
+// gracefully.
+func (s *Server) Start(ctx context.Context, port string) error {
 	// Health check endpoint
 	s.router.GET("/health", s.healthHandler)
-	
+
 	// Authentication endpoints
 	s.router.POST("/api/auth/register", s.authHandler.Register)
 	s.router.POST("/api/auth/login", s.authHandler.Login)
-	
-	// Document endpoints
-	s.router.POST("/api/documents/upload", s.docHandler.Upload)
-	s.router.POST("/api/documents/summary", s.docHandler.GenerateSummary)
+	s.router.POST("/api/auth/refresh", s.authHandler.Refresh)
+	s.router.POST("/api/auth/logout", s.sessionOnly(s.authHandler.Logout))
+	s.router.POST("/api/auth/password", s.sessionOnly(s.authHandler.ChangePassword))
+	s.router.GET("/api/auth/sessions", s.sessionOnly(s.authHandler.ListSessions))
+	s.router.DELETE("/api/auth/sessions/:id", s.sessionOnly(s.authHandler.RevokeSession))
 
-	server := fuselage.NewServer(":"+port, s.router)
-	return server.ListenAndServe()
+	// API key endpoints
+	s.router.POST("/api/auth/keys", s.sessionOnly(s.keyHandler.Create))
+	s.router.GET("/api/auth/keys", s.sessionOnly(s.keyHandler.List))
+	s.router.DELETE("/api/auth/keys/:id", s.sessionOnly(s.keyHandler.Revoke))
+
+	// Document endpoints (require an access token or an API key with the matching scope)
+	s.router.POST("/api/documents/upload", s.scoped(models.ScopeDocumentsWrite, s.docHandler.Upload))
+	s.router.POST("/api/documents/summary", s.scoped(models.ScopeSummariesGenerate, s.docHandler.GenerateSummary))
+	s.router.POST("/api/documents/summary/stream", s.scoped(models.ScopeSummariesGenerate, s.docHandler.StreamSummary))
+	s.router.GET("/api/documents", s.scoped(models.ScopeDocumentsRead, s.docHandler.List))
+	s.router.GET("/api/documents/:id", s.scoped(models.ScopeDocumentsRead, s.docHandler.Get))
+	s.router.GET("/api/documents/:id/original", s.scoped(models.ScopeDocumentsRead, s.docHandler.GetOriginal))
+	s.router.PUT("/api/documents/:id", s.scoped(models.ScopeDocumentsWrite, s.docHandler.Update))
+	s.router.DELETE("/api/documents/:id", s.scoped(models.ScopeDocumentsWrite, s.docHandler.Delete))
+	s.router.GET("/api/documents/:id/summaries", s.scoped(models.ScopeDocumentsRead, s.docHandler.ListSummaries))
+	s.router.POST("/api/documents/:id/ask", s.scoped(models.ScopeSummariesGenerate, s.askHandler.Ask))
+	s.router.POST("/api/documents/:id/conversations", s.scoped(models.ScopeDocumentsWrite, s.chatHandler.Create))
+	s.router.GET("/api/documents/:id/conversations", s.scoped(models.ScopeDocumentsRead, s.chatHandler.List))
+	s.router.POST("/api/documents/:id/decks", s.scoped(models.ScopeSummariesGenerate, s.deckHandler.Generate))
+	s.router.GET("/api/documents/:id/decks", s.scoped(models.ScopeDocumentsRead, s.deckHandler.List))
+	s.router.POST("/api/documents/:id/diagrams", s.scoped(models.ScopeSummariesGenerate, s.diagramHandler.Generate))
+	s.router.GET("/api/documents/:id/diagrams", s.scoped(models.ScopeDocumentsRead, s.diagramHandler.List))
+	s.router.GET("/api/documents/:id/artifacts", s.scoped(models.ScopeDocumentsRead, s.artifactHandler.ListDocument))
+
+	// Conversation endpoints
+	s.router.GET("/api/conversations/:id", s.scoped(models.ScopeDocumentsRead, s.chatHandler.Get))
+	s.router.POST("/api/conversations/:id/messages", s.scoped(models.ScopeSummariesGenerate, s.chatHandler.PostMessage))
+	s.router.DELETE("/api/conversations/:id", s.scoped(models.ScopeDocumentsWrite, s.chatHandler.Delete))
+
+	// Deck endpoints
+	s.router.GET("/api/decks/:id", s.scoped(models.ScopeDocumentsRead, s.deckHandler.Get))
+	s.router.GET("/api/decks/:id/export", s.scoped(models.ScopeDocumentsRead, s.deckHandler.Export))
+	s.router.DELETE("/api/decks/:id", s.scoped(models.ScopeDocumentsWrite, s.deckHandler.Delete))
+
+	// Diagram endpoints
+	s.router.GET("/api/diagrams/:id", s.scoped(models.ScopeDocumentsRead, s.diagramHandler.Get))
+	s.router.POST("/api/diagrams/:id/regenerate", s.scoped(models.ScopeSummariesGenerate, s.diagramHandler.Regenerate))
+	s.router.DELETE("/api/diagrams/:id", s.scoped(models.ScopeDocumentsWrite, s.diagramHandler.Delete))
+
+	// Artifact endpoints
+	s.router.GET("/api/artifacts", s.scoped(models.ScopeDocumentsRead, s.artifactHandler.List))
+	s.router.GET("/api/artifacts/:id", s.scoped(models.ScopeDocumentsRead, s.artifactHandler.Get))
+	s.router.GET("/api/artifacts/:id/preview", s.scoped(models.ScopeDocumentsRead, s.artifactHandler.Preview))
+	s.router.GET("/api/artifacts/:id/download", s.scoped(models.ScopeDocumentsRead, s.artifactHandler.Download))
+	s.router.DELETE("/api/artifacts/:id", s.scoped(models.ScopeDocumentsWrite, s.artifactHandler.Delete))
+
+	// Search endpoints
+	s.router.GET("/api/search", s.scoped(models.ScopeDocumentsRead, s.searchHandler.Search))
+	s.router.GET("/api/search/semantic", s.scoped(models.ScopeDocumentsRead, s.searchHandler.SemanticSearch))
+
+	// Job endpoints
+	s.router.GET("/api/jobs/:id", s.scoped(models.ScopeSummariesGenerate, s.jobHandler.Get))
+
+	jobCtx, stopJobs := context.WithCancel(ctx)
+	if err := s.jobService.Start(jobCtx); err != nil {
+		stopJobs()
+		return err
+	}
+	// Interrupted jobs are requeued and picked up again on the next start.
+	defer func() {
+		stopJobs()
+		s.jobService.Wait()
+	}()
+
+	server := fuselage.NewServer(":"+port, s)
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// ServeHTTP dispatches to the router. fuselage cannot register PATCH routes, so PATCH requests are
+// served by the PUT handler of the same path; every PUT handler here applies partial updates.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPatch {
+		r.Method = http.MethodPut
+	}
+	s.router.ServeHTTP(w, r)
+}
+
+// sessionOnly protects credential management: the caller must be logged in interactively, not via an API key.
+func (s *Server) sessionOnly(handler fuselage.HandlerFunc) fuselage.HandlerFunc {
+	return s.requireAuth(authmw.RequireSession()(handler))
+}
+
+// scoped protects a resource endpoint that API keys may call when granted scope.
+func (s *Server) scoped(scope string, handler fuselage.HandlerFunc) fuselage.HandlerFunc {
+	return s.requireAuth(authmw.RequireScope(scope)(handler))
 }
 
 func (s *Server) healthHandler(c *fuselage.Context) error {
@@ -61,4 +251,3 @@ func (s *Server) healthHandler(c *fuselage.Context) error {
 		"service": "quilldeck",
 	})
 }
-
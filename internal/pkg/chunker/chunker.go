@@ -0,0 +1,281 @@
+package chunker
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMaxChars is used when Split is called without a positive limit.
+const DefaultMaxChars = 12000
+
+// Chunk is a contiguous slice of the source text. Start and End are byte offsets into the original
+// content, so content[Start:End] == Text.
+type Chunk struct {
+	Index   int
+	Heading string // nearest Markdown heading above the chunk, if any
+	Start   int
+	End     int
+	Text    string
+}
+
+type span struct {
+	start, end int
+	heading    string
+}
+
+// Split cuts content into chunks of at most maxChars characters (runes). It prefers to break between
+// Markdown sections, then between paragraphs, then between lines, and only splits inside a line when a
+// single line is longer than the limit. Headings and paragraphs inside fenced code blocks are ignored.
+func Split(content string, maxChars int) []Chunk {
+	if maxChars <= 0 {
+		maxChars = DefaultMaxChars
+	}
+
+	s := &splitter{content: content, max: maxChars}
+	for _, section := range s.sections() {
+		s.split(section, levelParagraph)
+	}
+	return s.pack()
+}
+
+const (
+	levelParagraph = iota
+	levelLine
+	levelHard
+)
+
+type splitter struct {
+	content string
+	max     int
+	units   []span
+}
+
+func (s *splitter) size(sp span) int {
+	return utf8.RuneCountInString(s.content[sp.start:sp.end])
+}
+
+// split appends sp to the unit list, breaking it at progressively finer boundaries until every piece fits.
+func (s *splitter) split(sp span, level int) {
+	if s.size(sp) <= s.max {
+		s.units = append(s.units, sp)
+		return
+	}
+
+	var parts []span
+	switch level {
+	case levelParagraph:
+		parts = s.paragraphs(sp)
+	case levelLine:
+		parts = s.lines(sp)
+	default:
+		s.units = append(s.units, s.hardSplit(sp)...)
+		return
+	}
+
+	if len(parts) <= 1 {
+		s.split(sp, level+1)
+		return
+	}
+	for _, part := range parts {
+		s.split(part, level+1)
+	}
+}
+
+// pack greedily merges adjacent units into chunks that stay within the limit.
+func (s *splitter) pack() []Chunk {
+	var chunks []Chunk
+	var current []span
+	currentSize := 0
+
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		start, end := current[0].start, current[len(current)-1].end
+		text := s.content[start:end]
+		trimmedLeft := strings.TrimLeftFunc(text, unicode.IsSpace)
+		start += len(text) - len(trimmedLeft)
+		text = strings.TrimRightFunc(trimmedLeft, unicode.IsSpace)
+		end = start + len(text)
+		if text != "" {
+			chunks = append(chunks, Chunk{
+				Index:   len(chunks),
+				Heading: current[0].heading,
+				Start:   start,
+				End:     end,
+				Text:    text,
+			})
+		}
+		current = current[:0]
+		currentSize = 0
+	}
+
+	for _, unit := range s.units {
+		size := s.size(unit)
+		if currentSize+size > s.max {
+			flush()
+		}
+		current = append(current, unit)
+		currentSize += size
+	}
+	flush()
+	return chunks
+}
+
+// sections splits the content at Markdown ATX headings ("# Title") outside fenced code blocks.
+func (s *splitter) sections() []span {
+	var sections []span
+	current := span{start: 0}
+	inFence := false
+
+	for _, line := range s.lineSpans(span{start: 0, end: len(s.content)}) {
+		text := s.content[line.start:line.end]
+		if isFence(text) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if heading, ok := parseHeading(text); ok {
+			if line.start > current.start {
+				current.end = line.start
+				sections = append(sections, current)
+			}
+			current = span{start: line.start, heading: heading}
+		}
+	}
+	current.end = len(s.content)
+	if current.end > current.start {
+		sections = append(sections, current)
+	}
+	return sections
+}
+
+// paragraphs splits sp at blank lines. Blank lines stay attached to the preceding paragraph so the
+// returned spans are contiguous.
+func (s *splitter) paragraphs(sp span) []span {
+	var parts []span
+	start := sp.start
+	inFence := false
+	sawText := false
+
+	for _, line := range s.lineSpans(sp) {
+		text := s.content[line.start:line.end]
+		blank := strings.TrimSpace(text) == ""
+		if !inFence && !blank && sawText && s.isBlankLine(line.start) {
+			parts = append(parts, span{start: start, end: line.start, heading: sp.heading})
+			start = line.start
+		}
+		if isFence(text) {
+			inFence = !inFence
+		}
+		if !blank {
+			sawText = true
+		}
+	}
+	parts = append(parts, span{start: start, end: sp.end, heading: sp.heading})
+	return parts
+}
+
+// isBlankLine reports whether the line ending just before offset is blank.
+func (s *splitter) isBlankLine(offset int) bool {
+	prev := strings.TrimSuffix(s.content[:offset], "\n")
+	if i := strings.LastIndexByte(prev, '\n'); i >= 0 {
+		prev = prev[i+1:]
+	}
+	return strings.TrimSpace(prev) == ""
+}
+
+func (s *splitter) lines(sp span) []span {
+	parts := s.lineSpans(sp)
+	for i := range parts {
+		parts[i].heading = sp.heading
+	}
+	return parts
+}
+
+// lineSpans returns the lines of sp including their trailing newline.
+func (s *splitter) lineSpans(sp span) []span {
+	var lines []span
+	start := sp.start
+	for start < sp.end {
+		end := sp.end
+		if i := strings.IndexByte(s.content[start:sp.end], '\n'); i >= 0 {
+			end = start + i + 1
+		}
+		lines = append(lines, span{start: start, end: end})
+		start = end
+	}
+	return lines
+}
+
+// hardSplit cuts an over-long line into windows of at most max runes, preferring to end each window at a
+// sentence terminator or whitespace in its second half.
+func (s *splitter) hardSplit(sp span) []span {
+	var parts []span
+	start := sp.start
+	for start < sp.end {
+		end, runes := start, 0
+		for end < sp.end && runes < s.max {
+			_, width := utf8.DecodeRuneInString(s.content[end:])
+			end += width
+			runes++
+		}
+		if end < sp.end {
+			if cut := breakPoint(s.content[start:end]); cut > 0 {
+				end = start + cut
+			}
+		}
+		parts = append(parts, span{start: start, end: end, heading: sp.heading})
+		start = end
+	}
+	return parts
+}
+
+// breakPoint returns the byte offset just after the last sentence terminator, or failing that the last
+// whitespace, in the second half of window. It returns 0 when there is none.
+func breakPoint(window string) int {
+	half := len(window) / 2
+	sentence, space := 0, 0
+	for i, r := range window {
+		if i < half {
+			continue
+		}
+		switch {
+		case strings.ContainsRune("。．！？.!?", r):
+			sentence = i + utf8.RuneLen(r)
+		case unicode.IsSpace(r):
+			space = i + utf8.RuneLen(r)
+		}
+	}
+	if sentence > 0 {
+		return sentence
+	}
+	return space
+}
+
+func isFence(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
+
+// parseHeading recognizes ATX headings: up to three spaces, one to six '#', then a space or end of line.
+func parseHeading(line string) (string, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	indent := len(line) - len(strings.TrimLeft(line, " "))
+	if indent > 3 {
+		return "", false
+	}
+	rest := line[indent:]
+	level := len(rest) - len(strings.TrimLeft(rest, "#"))
+	if level == 0 || level > 6 {
+		return "", false
+	}
+	rest = rest[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(rest), "#")), true
+}
@@ -0,0 +1,67 @@
+// Package diagrams checks Mermaid and PlantUML diagram source before it is handed to users, so that a
+// generated diagram is known to render. Only the subset QuillDeck generates is supported: flowcharts,
+// sequence diagrams and mindmaps.
+package diagrams
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind is the type of diagram declared by a source.
+type Kind string
+
+const (
+	KindFlowchart Kind = "flowchart"
+	KindSequence  Kind = "sequence"
+	KindMindmap   Kind = "mindmap"
+)
+
+// SyntaxError reports the first problem found in a source. Line is 1-based.
+type SyntaxError struct {
+	Line    int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+func syntaxErrorf(line int, format string, args ...any) *SyntaxError {
+	return &SyntaxError{Line: line, Message: fmt.Sprintf(format, args...)}
+}
+
+// line is a source line without its line ending, with its 1-based number.
+type line struct {
+	number int
+	text   string
+}
+
+// sourceLines splits source into lines, leaving out blank lines and the lines isComment accepts.
+func sourceLines(source string, isComment func(trimmed string) bool) []line {
+	var lines []line
+	for i, text := range strings.Split(source, "\n") {
+		text = strings.TrimRight(text, "\r")
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || isComment(trimmed) {
+			continue
+		}
+		lines = append(lines, line{number: i + 1, text: text})
+	}
+	return lines
+}
+
+// block is an open block such as a loop or a subgraph, waiting for its end.
+type block struct {
+	keyword string
+	line    int
+}
+
+// firstWord splits s into its first whitespace-separated word and the trimmed rest.
+func firstWord(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		return s[:i], strings.TrimSpace(s[i:])
+	}
+	return s, ""
+}
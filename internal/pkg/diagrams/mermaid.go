@@ -0,0 +1,562 @@
+package diagrams
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ValidateMermaid parses source as a Mermaid flowchart, sequence diagram or mindmap and returns the kind
+// it declares. It is stricter than Mermaid: anything outside the supported subset is an error, so that
+// whatever passes is safe to render.
+func ValidateMermaid(source string) (Kind, error) {
+	lines := sourceLines(source, func(trimmed string) bool { return strings.HasPrefix(trimmed, "%%") })
+	if len(lines) == 0 {
+		return "", syntaxErrorf(1, "diagram is empty")
+	}
+
+	header, body := lines[0], lines[1:]
+	keyword, args := firstWord(strings.TrimSuffix(strings.TrimSpace(header.text), ";"))
+	var kind Kind
+	var err error
+	switch keyword {
+	case "flowchart", "graph":
+		if args != "" && !flowchartDirections[args] {
+			return "", syntaxErrorf(header.number, "unknown flowchart direction %q; use TB, TD, BT, RL or LR", args)
+		}
+		kind, err = KindFlowchart, validateFlowchart(body)
+	case "sequenceDiagram":
+		if args != "" {
+			return "", syntaxErrorf(header.number, "unexpected %q after sequenceDiagram", args)
+		}
+		kind, err = KindSequence, validateSequence(body)
+	case "mindmap":
+		if args != "" {
+			return "", syntaxErrorf(header.number, "unexpected %q after mindmap", args)
+		}
+		kind, err = KindMindmap, validateMindmap(header, body)
+	default:
+		return "", syntaxErrorf(header.number, "unsupported diagram type %q; use flowchart, sequenceDiagram or mindmap", keyword)
+	}
+	if err != nil {
+		return "", err
+	}
+	return kind, nil
+}
+
+var flowchartDirections = map[string]bool{"TB": true, "TD": true, "BT": true, "RL": true, "LR": true}
+
+func validateFlowchart(lines []line) error {
+	var subgraphs []block
+	for _, l := range lines {
+		for _, statement := range splitStatements(l.text) {
+			keyword, args := firstWord(statement)
+			switch keyword {
+			case "subgraph":
+				if args == "" {
+					return syntaxErrorf(l.number, "subgraph needs an id or a title")
+				}
+				// A subgraph is named like a node, id[title] or id [title], or just by a title.
+				if strings.ContainsAny(args, "[](){}") {
+					if err := parseSubgraph(args); err != nil {
+						return syntaxErrorf(l.number, "subgraph %s", err)
+					}
+				}
+				subgraphs = append(subgraphs, block{keyword: keyword, line: l.number})
+			case "end":
+				if args != "" {
+					return syntaxErrorf(l.number, "unexpected %q after end", args)
+				}
+				if len(subgraphs) == 0 {
+					return syntaxErrorf(l.number, "end without an open subgraph")
+				}
+				subgraphs = subgraphs[:len(subgraphs)-1]
+			case "direction":
+				if !flowchartDirections[args] {
+					return syntaxErrorf(l.number, "unknown direction %q; use TB, TD, BT, RL or LR", args)
+				}
+			case "classDef", "class", "style", "linkStyle", "click":
+				if args == "" {
+					return syntaxErrorf(l.number, "%s needs arguments", keyword)
+				}
+			default:
+				if err := parseChain(statement); err != nil {
+					return syntaxErrorf(l.number, "%s", err)
+				}
+			}
+		}
+	}
+	if len(subgraphs) > 0 {
+		return syntaxErrorf(subgraphs[len(subgraphs)-1].line, "subgraph is never closed with end")
+	}
+	return nil
+}
+
+// splitStatements splits a flowchart line at the semicolons that are not inside a label.
+func splitStatements(text string) []string {
+	var statements []string
+	depth, quoted, piped, start := 0, false, false, 0
+	for i, r := range text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '|':
+			piped = !piped
+		case piped:
+		case strings.ContainsRune("([{", r):
+			depth++
+		case strings.ContainsRune(")]}", r):
+			depth--
+		case r == ';' && depth <= 0:
+			statements = append(statements, text[start:i])
+			start = i + 1
+		}
+	}
+	statements = append(statements, text[start:])
+
+	nonEmpty := statements[:0]
+	for _, statement := range statements {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			nonEmpty = append(nonEmpty, statement)
+		}
+	}
+	return nonEmpty
+}
+
+// nodeShapes are the node shapes of flowcharts, longest opening first so that ([ wins over (.
+var nodeShapes = []struct {
+	open   string
+	closes []string
+}{
+	{"(((", []string{")))"}},
+	{"([", []string{"])"}},
+	{"[[", []string{"]]"}},
+	{"[(", []string{")]"}},
+	{"((", []string{"))"}},
+	{"{{", []string{"}}"}},
+	{"[/", []string{"/]", `\]`}},
+	{`[\`, []string{`\]`, "/]"}},
+	{"[", []string{"]"}},
+	{"(", []string{")"}},
+	{"{", []string{"}"}},
+	{">", []string{"]"}},
+}
+
+// chainParser reads a flowchart statement of nodes joined by links, such as A[Start] --> B & C.
+type chainParser struct {
+	s   string
+	pos int
+}
+
+func parseChain(statement string) error {
+	p := &chainParser{s: statement}
+	if err := p.nodeGroup(); err != nil {
+		return err
+	}
+	for {
+		p.skipSpaces()
+		if p.done() {
+			return nil
+		}
+		if err := p.link(); err != nil {
+			return err
+		}
+		p.skipSpaces()
+		if p.done() {
+			return fmt.Errorf("link has no target node")
+		}
+		if err := p.nodeGroup(); err != nil {
+			return err
+		}
+	}
+}
+
+// parseSubgraph checks the id and title of a subgraph.
+func parseSubgraph(s string) error {
+	p := &chainParser{s: s}
+	if p.identifier() == "" {
+		return fmt.Errorf("expected an id at %q", s)
+	}
+	p.skipSpaces()
+	if err := p.shape(); err != nil {
+		return err
+	}
+	if p.skipSpaces(); !p.done() {
+		return fmt.Errorf("unexpected %q", p.rest())
+	}
+	return nil
+}
+
+func (p *chainParser) done() bool       { return p.pos >= len(p.s) }
+func (p *chainParser) rest() string     { return p.s[p.pos:] }
+func (p *chainParser) peek() byte       { return p.s[p.pos] }
+func (p *chainParser) skipSpaces()      { p.pos += len(p.rest()) - len(strings.TrimLeft(p.rest(), " \t")) }
+func (p *chainParser) at(s string) bool { return strings.HasPrefix(p.rest(), s) }
+
+func (p *chainParser) consume(s string) bool {
+	if p.at(s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// nodeGroup reads nodes joined by &.
+func (p *chainParser) nodeGroup() error {
+	for {
+		if err := p.node(); err != nil {
+			return err
+		}
+		p.skipSpaces()
+		if !p.consume("&") {
+			return nil
+		}
+		p.skipSpaces()
+	}
+}
+
+// node reads an id, optionally followed by a shape with a label and a :::class.
+func (p *chainParser) node() error {
+	id := p.identifier()
+	if id == "" {
+		return fmt.Errorf("expected a node id at %q", p.rest())
+	}
+	if id == "end" {
+		return fmt.Errorf(`"end" cannot be a node id; capitalize it or use another id`)
+	}
+
+	if err := p.shape(); err != nil {
+		return err
+	}
+	if p.consume(":::") && p.identifier() == "" {
+		return fmt.Errorf("expected a class name after :::")
+	}
+	return nil
+}
+
+// shape reads a shape with its label, if one starts at the current position.
+func (p *chainParser) shape() error {
+	for _, shape := range nodeShapes {
+		if p.consume(shape.open) {
+			return p.label(shape.open, shape.closes)
+		}
+	}
+	return nil
+}
+
+func (p *chainParser) identifier() string {
+	start := p.pos
+	for !p.done() {
+		r, size := utf8.DecodeRuneInString(p.rest())
+		if (!unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-') || (r == '-' && p.atLink()) {
+			break
+		}
+		p.pos += size
+	}
+	return p.s[start:p.pos]
+}
+
+// atLink reports whether a link starts at the current position, for ids that contain hyphens.
+func (p *chainParser) atLink() bool {
+	return p.at("--") || p.at("-.") || p.at("->")
+}
+
+// label reads a node label up to one of closes. Unquoted labels must not contain brackets or quotes,
+// which Mermaid reads as the end of the label.
+func (p *chainParser) label(open string, closes []string) error {
+	if p.consume(`"`) {
+		end := strings.IndexByte(p.rest(), '"')
+		if end < 0 {
+			return fmt.Errorf("quoted label is never closed")
+		}
+		p.pos += end + 1
+		for _, close := range closes {
+			if p.consume(close) {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected %s after the quoted label", closes[0])
+	}
+
+	end, closeLen := -1, 0
+	for _, close := range closes {
+		if i := strings.Index(p.rest(), close); i >= 0 && (end < 0 || i < end) {
+			end, closeLen = i, len(close)
+		}
+	}
+	if end < 0 {
+		return fmt.Errorf("%s is never closed with %s", open, closes[0])
+	}
+	text := p.rest()[:end]
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("node label is empty")
+	}
+	if i := strings.IndexAny(text, `[](){}"`); i >= 0 {
+		return fmt.Errorf("label %q contains %q; wrap the label in double quotes", text, text[i:i+1])
+	}
+	p.pos += end + closeLen
+	return nil
+}
+
+// link reads a link such as -->, ---, -.->, ==>, <-->, --o or --x, with an optional label written
+// -- text --> or -->|text|.
+func (p *chainParser) link() error {
+	start := p.pos
+	if p.at("<") || (p.at("o") || p.at("x")) && len(p.rest()) > 1 && strings.ContainsRune("-=", rune(p.s[p.pos+1])) {
+		p.pos++
+	}
+
+	var err error
+	switch {
+	case p.at("-."):
+		err = p.dottedLink()
+	case p.at("--") || p.at("=="):
+		err = p.lineLink(p.peek())
+	default:
+		err = fmt.Errorf("expected a link at %q", p.s[start:])
+	}
+	if err != nil {
+		return err
+	}
+
+	p.skipSpaces()
+	if p.consume("|") {
+		end := strings.IndexByte(p.rest(), '|')
+		if end < 0 {
+			return fmt.Errorf("link label is never closed with |")
+		}
+		p.pos += end + 1
+	}
+	return nil
+}
+
+// lineLink reads a solid (-) or thick (=) link.
+func (p *chainParser) lineLink(c byte) error {
+	n := p.run(c)
+	if p.arrowhead() || n >= 3 {
+		return nil
+	}
+
+	// -- text --> or == text ==>
+	if p.done() || (p.peek() != ' ' && p.peek() != '\t') {
+		return fmt.Errorf("incomplete link %q", p.s[p.pos-n:])
+	}
+	closing := strings.Repeat(string(c), 2)
+	end := strings.Index(p.rest(), closing)
+	if end < 0 || strings.TrimSpace(p.rest()[:end]) == "" {
+		return fmt.Errorf("link text is never followed by %s", closing)
+	}
+	p.pos += end
+	if m := p.run(c); !p.arrowhead() && m < 3 {
+		return fmt.Errorf("incomplete link %q", p.s[p.pos-m:])
+	}
+	return nil
+}
+
+// dottedLink reads -.-, -.->, -..-> and -. text .->.
+func (p *chainParser) dottedLink() error {
+	p.pos++
+	dots := p.run('.')
+	if p.consume("-") {
+		p.arrowhead()
+		return nil
+	}
+	if dots != 1 || p.done() || (p.peek() != ' ' && p.peek() != '\t') {
+		return fmt.Errorf("incomplete dotted link at %q", p.rest())
+	}
+	end := strings.Index(p.rest(), ".-")
+	if end < 0 || strings.TrimSpace(p.rest()[:end]) == "" {
+		return fmt.Errorf("dotted link text is never followed by .-")
+	}
+	p.pos += end + 2
+	p.arrowhead()
+	return nil
+}
+
+func (p *chainParser) run(c byte) int {
+	n := 0
+	for !p.done() && p.peek() == c {
+		p.pos++
+		n++
+	}
+	return n
+}
+
+func (p *chainParser) arrowhead() bool {
+	return p.consume(">") || p.consume("o") || p.consume("x")
+}
+
+var (
+	sequenceMessage = regexp.MustCompile(`^(.+?)\s*(<<-->>|<<->>|-->>|->>|--x|-x|--\)|-\)|-->|->)\s*([+-]?)\s*([^:]*?)\s*:(.*)$`)
+	sequenceNote    = regexp.MustCompile(`(?i)^note\s+(left of|right of|over)\s+([^:]+):(.*)$`)
+)
+
+// sequenceBlocks are the blocks of a sequence diagram, with the keywords that may split each of them.
+var sequenceBlocks = map[string]string{
+	"loop":     "",
+	"alt":      "else",
+	"opt":      "",
+	"par":      "and",
+	"critical": "option",
+	"break":    "",
+	"rect":     "",
+	"box":      "",
+}
+
+func validateSequence(lines []line) error {
+	var blocks []block
+	for _, l := range lines {
+		text := strings.TrimSpace(l.text)
+		keyword, args := firstWord(text)
+
+		if _, ok := sequenceBlocks[keyword]; ok {
+			blocks = append(blocks, block{keyword: keyword, line: l.number})
+			continue
+		}
+		switch keyword {
+		case "end":
+			if len(blocks) == 0 {
+				return syntaxErrorf(l.number, "end without an open block")
+			}
+			blocks = blocks[:len(blocks)-1]
+			continue
+		case "else", "and", "option":
+			if len(blocks) == 0 || sequenceBlocks[blocks[len(blocks)-1].keyword] != keyword {
+				return syntaxErrorf(l.number, "%s is only allowed inside %s", keyword, splitBlock(keyword))
+			}
+			continue
+		case "participant", "actor":
+			name, _, _ := strings.Cut(args, " as ")
+			if strings.TrimSpace(name) == "" {
+				return syntaxErrorf(l.number, "%s needs a name", keyword)
+			}
+			continue
+		case "activate", "deactivate":
+			if args == "" {
+				return syntaxErrorf(l.number, "%s needs a participant", keyword)
+			}
+			continue
+		case "autonumber", "title", "title:":
+			continue
+		}
+
+		if match := sequenceNote.FindStringSubmatch(text); match != nil {
+			if err := checkMessageText(match[3]); err != nil {
+				return syntaxErrorf(l.number, "%s", err)
+			}
+			continue
+		}
+		match := sequenceMessage.FindStringSubmatch(text)
+		if match == nil {
+			return syntaxErrorf(l.number, "expected a message such as A->>B: text, got %q", text)
+		}
+		if match[4] == "" {
+			return syntaxErrorf(l.number, "message has no receiver")
+		}
+		if err := checkMessageText(match[5]); err != nil {
+			return syntaxErrorf(l.number, "%s", err)
+		}
+	}
+	if len(blocks) > 0 {
+		last := blocks[len(blocks)-1]
+		return syntaxErrorf(last.line, "%s is never closed with end", last.keyword)
+	}
+	return nil
+}
+
+func splitBlock(keyword string) string {
+	for block, split := range sequenceBlocks {
+		if split == keyword {
+			return block
+		}
+	}
+	return ""
+}
+
+// checkMessageText rejects semicolons, which end a statement in sequence diagrams.
+func checkMessageText(text string) error {
+	if strings.Contains(text, ";") {
+		return fmt.Errorf("text %q contains ;, which ends the statement; write #59; instead", strings.TrimSpace(text))
+	}
+	return nil
+}
+
+// mindmapShapes are the node shapes of mindmaps, longest opening first.
+var mindmapShapes = []struct{ open, close string }{
+	{"((", "))"},
+	{"))", "(("},
+	{"{{", "}}"},
+	{"(", ")"},
+	{")", "("},
+	{"[", "]"},
+}
+
+// validateMindmap checks that the nodes form a single tree: every node after the root is indented
+// deeper than the root.
+func validateMindmap(header line, lines []line) error {
+	rootIndent := -1
+	for _, l := range lines {
+		text := strings.TrimSpace(l.text)
+		if strings.HasPrefix(text, "::icon(") {
+			if !strings.HasSuffix(text, ")") {
+				return syntaxErrorf(l.number, "::icon( is never closed with )")
+			}
+			continue
+		}
+		if strings.HasPrefix(text, ":::") {
+			continue
+		}
+
+		indent := len(l.text) - len(strings.TrimLeft(l.text, " \t"))
+		if rootIndent < 0 {
+			rootIndent = indent
+		} else if indent <= rootIndent {
+			return syntaxErrorf(l.number, "a mindmap has a single root; indent %q under it", text)
+		}
+		if err := checkMindmapNode(text); err != nil {
+			return syntaxErrorf(l.number, "%s", err)
+		}
+	}
+	if rootIndent < 0 {
+		return syntaxErrorf(header.number, "mindmap has no nodes")
+	}
+	return nil
+}
+
+// checkMindmapNode accepts plain text, or an id followed by a shape that ends the line.
+func checkMindmapNode(text string) error {
+	i := strings.IndexAny(text, "()[]{}")
+	if i < 0 {
+		return nil
+	}
+	id, rest := text[:i], text[i:]
+	if strings.ContainsAny(id, " \t") {
+		return fmt.Errorf(`text %q contains brackets; write it as id["text"]`, text)
+	}
+	for _, shape := range mindmapShapes {
+		if !strings.HasPrefix(rest, shape.open) {
+			continue
+		}
+		inner := rest[len(shape.open):]
+		if !strings.HasSuffix(inner, shape.close) {
+			return fmt.Errorf("%s is never closed with %s at the end of the line", shape.open, shape.close)
+		}
+		label := strings.TrimSpace(inner[:len(inner)-len(shape.close)])
+		if len(label) >= 2 && strings.HasPrefix(label, `"`) && strings.HasSuffix(label, `"`) {
+			label = label[1 : len(label)-1]
+			if strings.Contains(label, `"`) {
+				return fmt.Errorf("label %q contains a quote", label)
+			}
+		} else if j := strings.IndexAny(label, `()[]{}"`); j >= 0 {
+			return fmt.Errorf("label %q contains %q; wrap the label in double quotes", label, label[j:j+1])
+		}
+		if strings.TrimSpace(label) == "" {
+			return fmt.Errorf("node label is empty")
+		}
+		return nil
+	}
+	return fmt.Errorf("unexpected %q in %q", rest[:1], text)
+}
@@ -0,0 +1,314 @@
+package diagrams
+
+import (
+	"strings"
+)
+
+// ValidatePlantUML checks that source is a single @startuml or @startmindmap diagram with balanced
+// blocks and returns its kind: mindmaps, activity diagrams (reported as flowcharts) and sequence diagrams
+// are supported.
+func ValidatePlantUML(source string) (Kind, error) {
+	lines := sourceLines(source, func(trimmed string) bool { return strings.HasPrefix(trimmed, "'") })
+	if len(lines) == 0 {
+		return "", syntaxErrorf(1, "diagram is empty")
+	}
+
+	first := lines[0]
+	start, _ := firstWord(first.text)
+	var end string
+	switch start {
+	case "@startuml":
+		end = "@enduml"
+	case "@startmindmap":
+		end = "@endmindmap"
+	default:
+		return "", syntaxErrorf(first.number, "diagram must start with @startuml or @startmindmap")
+	}
+	last := lines[len(lines)-1]
+	if len(lines) == 1 || strings.TrimSpace(last.text) != end {
+		return "", syntaxErrorf(last.number, "diagram must end with %s", end)
+	}
+
+	body := lines[1 : len(lines)-1]
+	for _, l := range body {
+		if text := strings.TrimSpace(l.text); strings.HasPrefix(text, "@start") || strings.HasPrefix(text, "@end") {
+			return "", syntaxErrorf(l.number, "a source holds a single diagram")
+		}
+	}
+	if len(body) == 0 {
+		return "", syntaxErrorf(first.number, "diagram is empty")
+	}
+
+	var kind Kind
+	var err error
+	switch {
+	case start == "@startmindmap":
+		kind, err = KindMindmap, validatePlantUMLMindmap(body)
+	case isActivityDiagram(body):
+		kind, err = KindFlowchart, validateActivityDiagram(body)
+	default:
+		kind, err = KindSequence, validatePlantUMLSequence(body)
+	}
+	if err != nil {
+		return "", err
+	}
+	return kind, nil
+}
+
+func isActivityDiagram(lines []line) bool {
+	for _, l := range lines {
+		text := strings.TrimSpace(l.text)
+		if text == "start" || strings.HasPrefix(text, ":") || strings.HasPrefix(text, "if (") {
+			return true
+		}
+	}
+	return false
+}
+
+// activityTerminators end an activity; ; is the usual one, the others give it an SDL shape.
+const activityTerminators = ";|<>/]}"
+
+// activityBlocks maps the keywords that open a block of an activity diagram to the keywords that close
+// it, and the keywords allowed between.
+var activityBlocks = map[string]struct{ ends, splits []string }{
+	"if":        {ends: []string{"endif", "end if"}, splits: []string{"else", "elseif", "else if"}},
+	"while":     {ends: []string{"endwhile", "end while"}},
+	"repeat":    {ends: []string{"repeat while", "repeatwhile"}},
+	"fork":      {ends: []string{"end fork", "end merge"}, splits: []string{"fork again"}},
+	"split":     {ends: []string{"end split"}, splits: []string{"split again"}},
+	"switch":    {ends: []string{"endswitch"}, splits: []string{"case"}},
+	"partition": {ends: []string{"}"}},
+}
+
+func validateActivityDiagram(lines []line) error {
+	var blocks []block
+	activity, note := 0, false
+	for _, l := range lines {
+		text := strings.TrimSpace(l.text)
+
+		// Activities and notes may span lines.
+		if activity > 0 {
+			if strings.ContainsRune(activityTerminators, rune(text[len(text)-1])) {
+				activity = 0
+			}
+			continue
+		}
+		if note {
+			note = text != "end note"
+			continue
+		}
+		if strings.HasPrefix(text, ":") {
+			if !strings.ContainsRune(activityTerminators, rune(text[len(text)-1])) || text == ":" {
+				activity = l.number
+			}
+			continue
+		}
+		if isMultilineNote(text) {
+			note = true
+			continue
+		}
+
+		if keyword := blockKeyword(text); keyword != "" {
+			if keyword == "partition" && !strings.HasSuffix(text, "{") {
+				return syntaxErrorf(l.number, "partition must open its block with {")
+			}
+			blocks = append(blocks, block{keyword: keyword, line: l.number})
+			continue
+		}
+		if open, ok := closedBlock(text, activityBlocks); ok {
+			if len(blocks) == 0 || blocks[len(blocks)-1].keyword != open {
+				return syntaxErrorf(l.number, "%q without an open %s", text, open)
+			}
+			blocks = blocks[:len(blocks)-1]
+			continue
+		}
+		if open, ok := splitBlockOf(text, activityBlocks); ok {
+			if len(blocks) == 0 || blocks[len(blocks)-1].keyword != open {
+				return syntaxErrorf(l.number, "%q outside of a %s block", text, open)
+			}
+		}
+	}
+	if activity > 0 {
+		return syntaxErrorf(activity, "activity is never closed with ;")
+	}
+	if note {
+		return syntaxErrorf(lines[len(lines)-1].number, "note is never closed with end note")
+	}
+	if len(blocks) > 0 {
+		last := blocks[len(blocks)-1]
+		return syntaxErrorf(last.line, "%s is never closed with %s", last.keyword, activityBlocks[last.keyword].ends[0])
+	}
+	return nil
+}
+
+// blockKeyword returns the activity block text opens, if any.
+func blockKeyword(text string) string {
+	keyword, _ := firstWord(strings.Replace(text, "(", " (", 1))
+	switch keyword {
+	case "if", "while", "switch", "partition":
+		return keyword
+	case "repeat", "fork", "split":
+		// repeat while, fork again and split again continue or close a block instead.
+		if rest := strings.TrimSpace(strings.TrimPrefix(text, keyword)); !strings.HasPrefix(rest, "while") && !strings.HasPrefix(rest, "again") {
+			return keyword
+		}
+	}
+	return ""
+}
+
+// closedBlock returns the block keyword whose end text is.
+func closedBlock(text string, blocks map[string]struct{ ends, splits []string }) (string, bool) {
+	for open, keywords := range blocks {
+		for _, end := range keywords.ends {
+			if text == end || strings.HasPrefix(text, end+" ") || strings.HasPrefix(text, end+"(") {
+				return open, true
+			}
+		}
+	}
+	return "", false
+}
+
+// splitBlockOf returns the block keyword that text splits, such as if for else.
+func splitBlockOf(text string, blocks map[string]struct{ ends, splits []string }) (string, bool) {
+	for open, keywords := range blocks {
+		for _, split := range keywords.splits {
+			if text == split || strings.HasPrefix(text, split+" ") || strings.HasPrefix(text, split+"(") {
+				return open, true
+			}
+		}
+	}
+	return "", false
+}
+
+// isMultilineNote reports whether text opens a note that runs until end note.
+func isMultilineNote(text string) bool {
+	keyword, _ := firstWord(text)
+	return (keyword == "note" || keyword == "hnote" || keyword == "rnote") && !strings.Contains(text, ":")
+}
+
+// sequenceGroups are the PlantUML sequence diagram blocks closed by end.
+var sequenceGroups = map[string]bool{"alt": true, "loop": true, "opt": true, "par": true, "break": true, "critical": true, "group": true}
+
+func validatePlantUMLSequence(lines []line) error {
+	var blocks []block
+	note := ""
+	for _, l := range lines {
+		text := strings.TrimSpace(l.text)
+		keyword, args := firstWord(text)
+
+		if note != "" {
+			if text == note {
+				note = ""
+			}
+			continue
+		}
+		if isMultilineNote(text) {
+			note = "end note"
+			continue
+		}
+		if keyword == "ref" && !strings.Contains(text, ":") {
+			note = "end ref"
+			continue
+		}
+
+		switch {
+		case sequenceGroups[keyword], keyword == "box":
+			blocks = append(blocks, block{keyword: keyword, line: l.number})
+		case keyword == "else":
+			if len(blocks) == 0 || blocks[len(blocks)-1].keyword == "box" {
+				return syntaxErrorf(l.number, "else outside of an alt block")
+			}
+		case keyword == "end":
+			if args == "box" {
+				if len(blocks) == 0 || blocks[len(blocks)-1].keyword != "box" {
+					return syntaxErrorf(l.number, "end box without an open box")
+				}
+			} else if args != "" {
+				return syntaxErrorf(l.number, "unexpected %q after end", args)
+			} else if len(blocks) == 0 || blocks[len(blocks)-1].keyword == "box" {
+				return syntaxErrorf(l.number, "end without an open block")
+			}
+			blocks = blocks[:len(blocks)-1]
+		}
+	}
+	if note != "" {
+		return syntaxErrorf(lines[len(lines)-1].number, "note is never closed with %s", note)
+	}
+	if len(blocks) > 0 {
+		last := blocks[len(blocks)-1]
+		return syntaxErrorf(last.line, "%s is never closed with end", last.keyword)
+	}
+	return nil
+}
+
+// mindmapSettings are the lines of a PlantUML mindmap that are not nodes.
+var mindmapSettings = []string{"title", "caption", "header", "footer", "legend", "left side", "right side", "skinparam"}
+
+// validatePlantUMLMindmap checks that the nodes form a single tree without skipped levels. Nodes are
+// written with *, or with + and - for the right and left sides.
+func validatePlantUMLMindmap(lines []line) error {
+	depth, multiline := 0, 0
+	for _, l := range lines {
+		text := strings.TrimSpace(l.text)
+		if multiline > 0 {
+			if strings.HasSuffix(text, ";") {
+				multiline = 0
+			}
+			continue
+		}
+		if isMindmapSetting(text) {
+			continue
+		}
+
+		marker := text[0]
+		if marker != '*' && marker != '+' && marker != '-' {
+			return syntaxErrorf(l.number, "expected a node starting with *, + or -, got %q", text)
+		}
+		level := len(text) - len(strings.TrimLeft(text, text[:1]))
+		switch {
+		case depth == 0 && level != 1:
+			return syntaxErrorf(l.number, "the first node must be the root, written with a single %c", marker)
+		case depth > 0 && level == 1:
+			return syntaxErrorf(l.number, "a mindmap has a single root; add a %c to %q", marker, text)
+		case level > depth+1:
+			return syntaxErrorf(l.number, "node skips a level; use at most %d %c", depth+1, marker)
+		}
+		depth = level
+
+		// The node text may follow a [#color] and a _ for a boxless node.
+		rest := text[level:]
+		if strings.HasPrefix(rest, "[") {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return syntaxErrorf(l.number, "[ is never closed with ]")
+			}
+			rest = rest[end+1:]
+		}
+		rest = strings.TrimPrefix(rest, "_")
+		if strings.HasPrefix(rest, ":") {
+			if !strings.HasSuffix(rest, ";") {
+				multiline = l.number
+			}
+			continue
+		}
+		if !strings.HasPrefix(rest, " ") || strings.TrimSpace(rest) == "" {
+			return syntaxErrorf(l.number, "node needs a space and text after %q", text[:level])
+		}
+	}
+	if multiline > 0 {
+		return syntaxErrorf(multiline, "multiline node is never closed with ;")
+	}
+	if depth == 0 {
+		return syntaxErrorf(lines[0].number, "mindmap has no nodes")
+	}
+	return nil
+}
+
+func isMindmapSetting(text string) bool {
+	for _, setting := range mindmapSettings {
+		if text == setting || strings.HasPrefix(text, setting+" ") {
+			return true
+		}
+	}
+	return false
+}
@@ -29,4 +29,5 @@ const (
 	ErrCodeNotFound     = "NOT_FOUND"
 	ErrCodeUnauthorized = "UNAUTHORIZED"
 	ErrCodeInternal     = "INTERNAL_ERROR"
-)
\ No newline at end of file
+	ErrCodeUnavailable  = "UNAVAILABLE"
+)
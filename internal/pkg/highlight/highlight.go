@@ -0,0 +1,135 @@
+package highlight
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// DefaultMaxChars is used when Snippet is called without a positive limit.
+	DefaultMaxChars = 160
+
+	openTag  = "<mark>"
+	closeTag = "</mark>"
+	ellipsis = "…"
+)
+
+type match struct {
+	start, end int // rune offsets
+}
+
+// Snippet cuts a window of about maxChars characters (runes) out of text around the first occurrence of
+// any term and wraps every occurrence inside it in <mark> tags. Matching is case-insensitive. Everything
+// outside the tags is HTML-escaped, so the result can be rendered as HTML as is. Runs of whitespace are
+// collapsed to one space and an ellipsis marks text cut off at either end. Without any occurrence the
+// snippet is the beginning of text.
+func Snippet(text string, terms []string, maxChars int) string {
+	if maxChars <= 0 {
+		maxChars = DefaultMaxChars
+	}
+
+	runes := []rune(text)
+	matches := findMatches(runes, terms)
+
+	start := 0
+	if len(matches) > 0 {
+		// Keep some context before the first match.
+		start = max(matches[0].start-maxChars/4, 0)
+	}
+	end := min(start+maxChars, len(runes))
+	if end-start < maxChars {
+		start = max(end-maxChars, 0)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(ellipsis)
+	}
+	var (
+		wrote, pendingSpace, marked bool
+		next                        int // first match that does not end before i
+	)
+	for i := start; i < end; i++ {
+		for next < len(matches) && matches[next].end <= i {
+			next++
+		}
+		inside := next < len(matches) && matches[next].start <= i
+
+		if unicode.IsSpace(runes[i]) && !inside {
+			pendingSpace = true
+			continue
+		}
+		if marked && !inside {
+			b.WriteString(closeTag)
+			marked = false
+		}
+		if pendingSpace && wrote {
+			b.WriteByte(' ')
+		}
+		pendingSpace = false
+		if inside && !marked {
+			b.WriteString(openTag)
+			marked = true
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+		wrote = true
+	}
+	if marked {
+		b.WriteString(closeTag)
+	}
+	if end < len(runes) {
+		b.WriteString(ellipsis)
+	}
+	return b.String()
+}
+
+// findMatches returns the non-overlapping occurrences of terms in runes, in order. Where occurrences
+// overlap they are merged into one.
+func findMatches(runes []rune, terms []string) []match {
+	folded := make([]rune, len(runes))
+	for i, r := range runes {
+		folded[i] = unicode.ToLower(r)
+	}
+
+	var found []match
+	for _, term := range terms {
+		needle := []rune(strings.TrimSpace(term))
+		for i, r := range needle {
+			needle[i] = unicode.ToLower(r)
+		}
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(folded); i++ {
+			if runesEqual(folded[i:i+len(needle)], needle) {
+				found = append(found, match{start: i, end: i + len(needle)})
+			}
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].start < found[j].start })
+	merged := found[:1]
+	for _, m := range found[1:] {
+		last := &merged[len(merged)-1]
+		if m.start <= last.end {
+			last.end = max(last.end, m.end)
+			continue
+		}
+		merged = append(merged, m)
+	}
+	return merged
+}
+
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
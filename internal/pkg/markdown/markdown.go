@@ -0,0 +1,144 @@
+// Package markdown writes the text of documents converted from other formats as normalized Markdown:
+// blocks separated by blank lines, white space collapsed, and tables on one line per row.
+package markdown
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Writer collects the blocks of a document. Blocks are separated by blank lines, except that the items of
+// a list follow each other directly. Blocks without text are not written.
+type Writer struct {
+	b        strings.Builder
+	lastItem bool
+}
+
+func (w *Writer) block(text string, item bool) {
+	if w.b.Len() > 0 {
+		if item && w.lastItem {
+			w.b.WriteString("\n")
+		} else {
+			w.b.WriteString("\n\n")
+		}
+	}
+	w.b.WriteString(text)
+	w.lastItem = item
+}
+
+// Heading writes a heading of level 1 to 6; other levels are brought within that range.
+func (w *Writer) Heading(level int, text string) {
+	if text = Inline(text); text == "" {
+		return
+	}
+	level = max(1, min(level, 6))
+	w.block(strings.Repeat("#", level)+" "+text, false)
+}
+
+func (w *Writer) Paragraph(text string) {
+	if text = Inline(text); text != "" {
+		w.block(text, false)
+	}
+}
+
+// ListItem writes an item of a bulleted or numbered list, indented by its level from 0. Numbered items
+// are all "1.", which Markdown renders in sequence.
+func (w *Writer) ListItem(level int, numbered bool, text string) {
+	if text = Inline(text); text == "" {
+		return
+	}
+	marker := "- "
+	if numbered {
+		marker = "1. "
+	}
+	w.block(strings.Repeat("  ", max(0, min(level, 8)))+marker+text, true)
+}
+
+// Table writes rows of cells as a table whose first row is the header. Trailing empty columns are
+// dropped, and a table without text is not written.
+func (w *Writer) Table(rows [][]string) {
+	width := 0
+	for _, row := range rows {
+		for i, cell := range row {
+			if strings.TrimSpace(cell) != "" {
+				width = max(width, i+1)
+			}
+		}
+	}
+	if width == 0 {
+		return
+	}
+
+	var b strings.Builder
+	for r, row := range rows {
+		b.WriteString("|")
+		for i := 0; i < width; i++ {
+			cell := ""
+			if i < len(row) {
+				cell = tableCell(row[i])
+			}
+			b.WriteString(" " + cell + " |")
+		}
+		if r == 0 {
+			b.WriteString("\n|" + strings.Repeat(" --- |", width))
+		}
+		if r < len(rows)-1 {
+			b.WriteString("\n")
+		}
+	}
+	w.block(b.String(), false)
+}
+
+// CodeBlock writes preformatted text as it is, fenced so that no line of it can close the fence.
+func (w *Writer) CodeBlock(text string) {
+	text = strings.Trim(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	w.block(fence+"\n"+text+"\n"+fence, false)
+}
+
+// Quote writes Markdown, such as that of another Writer, as a block quote.
+func (w *Writer) Quote(markdown string) {
+	markdown = strings.Trim(markdown, "\n")
+	if strings.TrimSpace(markdown) == "" {
+		return
+	}
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	w.block(strings.Join(lines, "\n"), false)
+}
+
+func (w *Writer) String() string {
+	return w.b.String()
+}
+
+// Inline normalizes the text of a paragraph: runs of white space become a single space, and line breaks
+// are kept without the spaces around them.
+func Inline(text string) string {
+	lines := strings.Split(text, "\n")
+	out := lines[:0]
+	for _, line := range lines {
+		if line = strings.Join(strings.FieldsFunc(line, isBlank), " "); line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// isBlank tells white space other than line breaks.
+func isBlank(r rune) bool {
+	return r != '\n' && unicode.IsSpace(r)
+}
+
+// tableCell fits text on one line of a table row.
+func tableCell(text string) string {
+	text = strings.ReplaceAll(Inline(text), "\n", "<br>")
+	return strings.ReplaceAll(text, "|", `\|`)
+}
@@ -0,0 +1,262 @@
+package ooxml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github/k-tsurumaki/quilldeck/internal/pkg/markdown"
+)
+
+// ExtractDOCX returns the body of a Word document as Markdown: headings from the heading styles, lists
+// from numbering, tables, and the text of text boxes after the paragraph they are anchored to. Headers,
+// footers, footnotes, comments and deleted revisions are left out.
+func ExtractDOCX(data []byte) (string, error) {
+	a, err := openArchive(data)
+	if err != nil {
+		return "", err
+	}
+	main, err := a.mainPart("word/document.xml")
+	if err != nil {
+		return "", err
+	}
+	doc, err := a.readXML(main)
+	if err != nil {
+		return "", err
+	}
+	body := doc.find("body")
+	if body == nil {
+		return "", fmt.Errorf("%w: the document has no body", ErrInvalid)
+	}
+
+	w := &wordReader{styles: make(map[string]*wordStyle), lists: make(map[string]map[int]bool)}
+	rels, err := a.relationships(main)
+	if err != nil {
+		return "", err
+	}
+	for _, rel := range rels {
+		switch {
+		case rel.isType("styles"):
+			if styles, err := a.readXML(rel.target); err == nil {
+				w.readStyles(styles)
+			}
+		case rel.isType("numbering"):
+			if numbering, err := a.readXML(rel.target); err == nil {
+				w.readNumbering(numbering)
+			}
+		}
+	}
+
+	w.blocks(body)
+	return w.md.String(), nil
+}
+
+type wordReader struct {
+	md     markdown.Writer
+	styles map[string]*wordStyle
+	// lists tells, by numbering ID and level, whether list items are numbered rather than bulleted.
+	lists map[string]map[int]bool
+}
+
+// wordStyle is what a paragraph style says about structure.
+type wordStyle struct {
+	basedOn string
+	// heading is the heading level from 1, or 0 when the style is not a heading.
+	heading int
+	numID   string
+	level   int
+}
+
+func (w *wordReader) readStyles(root *node) {
+	for _, s := range root.find("styles").children("style") {
+		if s.attr("type") != "paragraph" {
+			continue
+		}
+		style := &wordStyle{basedOn: s.at("basedOn").valueOr("")}
+		name := strings.ToLower(s.at("name").valueOr(""))
+		switch {
+		case name == "title":
+			style.heading = 1
+		case strings.HasPrefix(name, "heading "):
+			if n, err := strconv.Atoi(strings.TrimPrefix(name, "heading ")); err == nil {
+				style.heading = n
+			}
+		}
+		if style.heading == 0 {
+			if level, err := strconv.Atoi(s.at("pPr", "outlineLvl").valueOr("")); err == nil && level < 9 {
+				style.heading = level + 1
+			}
+		}
+		if numPr := s.at("pPr", "numPr"); numPr != nil {
+			style.numID = numPr.at("numId").valueOr("")
+			style.level, _ = strconv.Atoi(numPr.at("ilvl").valueOr("0"))
+		}
+		w.styles[s.attr("styleId")] = style
+	}
+}
+
+// readNumbering reads which list levels are numbered. Each numbering instance refers to an abstract
+// numbering, where the format of each level is defined.
+func (w *wordReader) readNumbering(root *node) {
+	numbering := root.find("numbering")
+	if numbering == nil {
+		return
+	}
+	abstract := make(map[string]map[int]bool)
+	for _, an := range numbering.children("abstractNum") {
+		levels := make(map[int]bool)
+		for _, lvl := range an.children("lvl") {
+			level, _ := strconv.Atoi(lvl.attr("ilvl"))
+			format := lvl.at("numFmt").valueOr("decimal")
+			levels[level] = format != "bullet" && format != "none"
+		}
+		abstract[an.attr("abstractNumId")] = levels
+	}
+	for _, num := range numbering.children("num") {
+		w.lists[num.attr("numId")] = abstract[num.at("abstractNumId").valueOr("")]
+	}
+}
+
+// style returns what a paragraph's style says, following the styles it is based on for what it leaves
+// unsaid.
+func (w *wordReader) style(id string) wordStyle {
+	var result wordStyle
+	for i := 0; id != "" && i < 16; i++ {
+		s, ok := w.styles[id]
+		if !ok {
+			break
+		}
+		if result.heading == 0 {
+			result.heading = s.heading
+		}
+		if result.numID == "" {
+			result.numID, result.level = s.numID, s.level
+		}
+		id = s.basedOn
+	}
+	return result
+}
+
+// blocks writes the paragraphs and tables in n.
+func (w *wordReader) blocks(n *node) {
+	for _, c := range n.nodes {
+		switch c.name {
+		case "p":
+			w.paragraph(c)
+		case "tbl":
+			w.table(c)
+		case "sdt", "sdtContent", "customXml", "ins", "moveTo":
+			w.blocks(c)
+		}
+	}
+}
+
+func (w *wordReader) paragraph(p *node) {
+	var text strings.Builder
+	var boxes []*node
+	collectRuns(p, &text, &boxes)
+
+	pPr := p.child("pPr")
+	style := w.style(pPr.at("pStyle").valueOr(""))
+	if level, err := strconv.Atoi(pPr.at("outlineLvl").valueOr("")); err == nil && level < 9 {
+		style.heading = level + 1
+	}
+	if numPr := pPr.at("numPr"); numPr != nil {
+		style.numID = numPr.at("numId").valueOr(style.numID)
+		style.level, _ = strconv.Atoi(numPr.at("ilvl").valueOr(strconv.Itoa(style.level)))
+	}
+
+	switch {
+	case style.heading > 0:
+		w.md.Heading(style.heading, text.String())
+	case style.numID != "" && style.numID != "0":
+		w.md.ListItem(style.level, w.lists[style.numID][style.level], text.String())
+	default:
+		w.md.Paragraph(text.String())
+	}
+	for _, box := range boxes {
+		w.blocks(box)
+	}
+}
+
+// collectRuns writes the text of the runs in n, and gathers the contents of the text boxes drawn in them.
+func collectRuns(n *node, text *strings.Builder, boxes *[]*node) {
+	for _, c := range n.nodes {
+		switch c.name {
+		case "t":
+			text.WriteString(c.text.String())
+		case "tab", "ptab":
+			text.WriteString("\t")
+		case "br", "cr":
+			text.WriteString("\n")
+		case "noBreakHyphen":
+			text.WriteString("-")
+		case "txbxContent":
+			*boxes = append(*boxes, c)
+		case "r":
+			if c.at("rPr", "vanish") == nil {
+				collectRuns(c, text, boxes)
+			}
+		case "pPr", "rPr", "del", "moveFrom", "Fallback":
+			// Properties, deleted text, and the copy of a drawing kept for older versions of Word.
+		default:
+			collectRuns(c, text, boxes)
+		}
+	}
+}
+
+// table writes a table with the text of each cell. Merged cells keep the columns in line: a cell spanning
+// columns is followed by empty ones, and the cells continuing a vertical merge are empty.
+func (w *wordReader) table(tbl *node) {
+	var rows [][]string
+	for _, tr := range contentChildren(tbl, "tr") {
+		var row []string
+		for _, tc := range contentChildren(tr, "tc") {
+			row = append(row, cellText(tc))
+			span, _ := strconv.Atoi(tc.at("tcPr", "gridSpan").valueOr("1"))
+			for i := 1; i < span && i < 64; i++ {
+				row = append(row, "")
+			}
+		}
+		rows = append(rows, row)
+	}
+	w.md.Table(rows)
+}
+
+// contentChildren returns the children of n with the given name, including those inside content
+// controls.
+func contentChildren(n *node, name string) []*node {
+	var out []*node
+	for _, c := range n.nodes {
+		switch c.name {
+		case name:
+			out = append(out, c)
+		case "sdt", "sdtContent", "customXml":
+			out = append(out, contentChildren(c, name)...)
+		}
+	}
+	return out
+}
+
+// cellText returns the paragraphs of a table cell, including those of nested tables, one per line.
+func cellText(tc *node) string {
+	if vMerge := tc.at("tcPr", "vMerge"); vMerge != nil && vMerge.attr("val") != "restart" {
+		return ""
+	}
+	var lines []string
+	var walk func(n *node)
+	walk = func(n *node) {
+		for _, c := range n.nodes {
+			if c.name == "p" {
+				var text strings.Builder
+				var boxes []*node
+				collectRuns(c, &text, &boxes)
+				lines = append(lines, text.String())
+				continue
+			}
+			walk(c)
+		}
+	}
+	walk(tc)
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,292 @@
+// Package ooxml extracts the text of Office Open XML files (Word, PowerPoint and Excel) as Markdown.
+//
+// The files are zip archives of XML parts; only the parts that hold text are read, so images, charts and
+// embedded objects are left out. Headings, lists and tables are kept as Markdown, while character
+// formatting is dropped.
+package ooxml
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+var (
+	// ErrEncrypted is returned for password-protected files, which Office saves as encrypted compound
+	// files rather than zip archives.
+	ErrEncrypted = errors.New("ooxml: file is encrypted")
+	// ErrInvalid is returned for data that is not a file of the expected kind.
+	ErrInvalid = errors.New("ooxml: not a valid Office Open XML file")
+)
+
+// maxPartSize bounds the uncompressed size of the parts read from an archive, so that a small file cannot
+// expand into gigabytes of XML.
+const maxPartSize = 64 << 20
+
+// compoundFileSignature starts the OLE compound files that encrypted documents are stored in.
+var compoundFileSignature = []byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1")
+
+// archive is an opened package: its parts by name, without the leading slash.
+type archive struct {
+	files map[string]*zip.File
+	read  int64
+}
+
+func openArchive(data []byte) (*archive, error) {
+	if bytes.HasPrefix(data, compoundFileSignature) {
+		return nil, ErrEncrypted
+	}
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	a := &archive{files: make(map[string]*zip.File)}
+	for _, f := range r.File {
+		a.files[strings.TrimPrefix(f.Name, "/")] = f
+	}
+	return a, nil
+}
+
+func (a *archive) has(name string) bool {
+	_, ok := a.files[name]
+	return ok
+}
+
+// readPart returns a part's content. The parts read from one archive share the size limit.
+func (a *archive) readPart(name string) ([]byte, error) {
+	f, ok := a.files[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing part %s", ErrInvalid, name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxPartSize-a.read+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalid, name, err)
+	}
+	a.read += int64(len(data))
+	if a.read > maxPartSize {
+		return nil, fmt.Errorf("%w: the content is too large", ErrInvalid)
+	}
+	return data, nil
+}
+
+// readXML reads and parses a part.
+func (a *archive) readXML(name string) (*node, error) {
+	data, err := a.readPart(name)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseXML(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalid, name, err)
+	}
+	return root, nil
+}
+
+// mainPart returns the name of the package's main part, as its relationships name it, or fallback for
+// packages that do not say.
+func (a *archive) mainPart(fallback string) (string, error) {
+	rels, err := a.relationships("")
+	if err != nil {
+		return "", err
+	}
+	for _, rel := range rels {
+		if rel.isType("officeDocument") && a.has(rel.target) {
+			return rel.target, nil
+		}
+	}
+	if !a.has(fallback) {
+		return "", fmt.Errorf("%w: missing part %s", ErrInvalid, fallback)
+	}
+	return fallback, nil
+}
+
+// relationships reads the relationships of a part, mapping their IDs to the names of the parts they
+// point to. A part without relationships has none.
+func (a *archive) relationships(part string) (map[string]relationship, error) {
+	dir, file := path.Split(part)
+	name := dir + "_rels/" + file + ".rels"
+	rels := make(map[string]relationship)
+	if !a.has(name) {
+		return rels, nil
+	}
+	root, err := a.readXML(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range root.at("Relationships").children("Relationship") {
+		if rel.attr("TargetMode") == "External" {
+			continue
+		}
+		target := rel.attr("Target")
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join(dir, target)
+		}
+		rels[rel.attr("Id")] = relationship{typ: rel.attr("Type"), target: target}
+	}
+	return rels, nil
+}
+
+type relationship struct {
+	typ    string
+	target string
+}
+
+// isType tells whether a relationship type URI names the given kind, such as "slide" or "notesSlide",
+// whatever version of the schemas it is from.
+func (r relationship) isType(kind string) bool {
+	return path.Base(r.typ) == kind
+}
+
+// node is an XML element, with its child elements in document order. Names are local, as each format
+// only uses one namespace for any given name that matters here.
+type node struct {
+	name  string
+	attrs []xml.Attr
+	nodes []*node
+	// text is the character data directly inside the element.
+	text strings.Builder
+}
+
+// maxDepth bounds the nesting of elements, which the functions walking the tree recurse through.
+const maxDepth = 256
+
+// parseXML returns a node holding the document element of data.
+func parseXML(data []byte) (*node, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+	root := &node{}
+	stack := []*node{root}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		top := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if len(stack) > maxDepth {
+				return nil, errors.New("elements are nested too deeply")
+			}
+			n := &node{name: t.Name.Local, attrs: t.Attr}
+			top.nodes = append(top.nodes, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			top.text.Write(t)
+		}
+	}
+	return root, nil
+}
+
+// attr returns the value of the attribute with the given local name and no namespace, or failing that any
+// namespace.
+func (n *node) attr(name string) string {
+	if n == nil {
+		return ""
+	}
+	value := ""
+	for _, a := range n.attrs {
+		if a.Name.Local != name {
+			continue
+		}
+		if a.Name.Space == "" {
+			return a.Value
+		}
+		if value == "" {
+			value = a.Value
+		}
+	}
+	return value
+}
+
+// relID returns the r:id attribute, which names a relationship of the part.
+func (n *node) relID() string {
+	for _, a := range n.attrs {
+		if a.Name.Local == "id" && strings.HasSuffix(a.Name.Space, "relationships") {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// valueOr returns the val attribute that many elements carry their setting in, or fallback when n is nil
+// or has none.
+func (n *node) valueOr(fallback string) string {
+	if n == nil {
+		return fallback
+	}
+	if v := n.attr("val"); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// child returns the first child element with the given name. The accessors treat a nil node as an
+// element without children.
+func (n *node) child(name string) *node {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.nodes {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (n *node) children(name string) []*node {
+	if n == nil {
+		return nil
+	}
+	var out []*node
+	for _, c := range n.nodes {
+		if c.name == name {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// find returns the first element with the given name at any depth below n.
+func (n *node) find(name string) *node {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.nodes {
+		if c.name == name {
+			return c
+		}
+		if found := c.find(name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// at follows a chain of child names, returning nil when any is missing.
+func (n *node) at(names ...string) *node {
+	for _, name := range names {
+		n = n.child(name)
+	}
+	return n
+}
@@ -0,0 +1,232 @@
+package ooxml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github/k-tsurumaki/quilldeck/internal/pkg/markdown"
+)
+
+// ExtractPPTX returns the text of a presentation as Markdown, one section per slide in presentation
+// order: the slide's title as the heading, its other text and tables, then its speaker notes.
+func ExtractPPTX(data []byte) (string, error) {
+	a, err := openArchive(data)
+	if err != nil {
+		return "", err
+	}
+	main, err := a.mainPart("ppt/presentation.xml")
+	if err != nil {
+		return "", err
+	}
+	pres, err := a.readXML(main)
+	if err != nil {
+		return "", err
+	}
+	rels, err := a.relationships(main)
+	if err != nil {
+		return "", err
+	}
+
+	p := &slideReader{}
+	number := 0
+	for _, id := range pres.find("sldIdLst").children("sldId") {
+		rel, ok := rels[id.relID()]
+		if !ok || !rel.isType("slide") {
+			continue
+		}
+		number++
+		if err := p.slide(a, number, rel.target); err != nil {
+			return "", err
+		}
+	}
+	if number == 0 {
+		return "", fmt.Errorf("%w: the presentation has no slides", ErrInvalid)
+	}
+	return p.md.String(), nil
+}
+
+type slideReader struct {
+	md markdown.Writer
+}
+
+func (p *slideReader) slide(a *archive, number int, part string) error {
+	slide, err := a.readXML(part)
+	if err != nil {
+		return err
+	}
+	tree := slide.find("spTree")
+	if tree == nil {
+		tree = &node{}
+	}
+
+	heading := "Slide " + strconv.Itoa(number)
+	title := findTitle(tree)
+	if title != nil {
+		if text := markdown.Inline(strings.ReplaceAll(shapeText(title), "\n", " ")); text != "" {
+			heading += ": " + text
+		}
+	}
+	p.md.Heading(2, heading)
+	p.shapes(tree, title)
+
+	rels, err := a.relationships(part)
+	if err != nil {
+		return err
+	}
+	for _, rel := range rels {
+		if !rel.isType("notesSlide") {
+			continue
+		}
+		notes, err := a.readXML(rel.target)
+		if err != nil {
+			return err
+		}
+		if tree := notes.find("spTree"); tree != nil {
+			p.notes(tree)
+		}
+	}
+	return nil
+}
+
+// findTitle returns the shape holding the slide's title placeholder.
+func findTitle(tree *node) *node {
+	for _, c := range tree.nodes {
+		switch c.name {
+		case "sp":
+			if t := placeholderType(c); t == "title" || t == "ctrTitle" {
+				return c
+			}
+		case "grpSp":
+			if title := findTitle(c); title != nil {
+				return title
+			}
+		}
+	}
+	return nil
+}
+
+// placeholderType returns the type of the placeholder a shape is, "none" if it is not one. Placeholders
+// without a type hold the slide's body.
+func placeholderType(sp *node) string {
+	ph := sp.at("nvSpPr", "nvPr", "ph")
+	if ph == nil {
+		return "none"
+	}
+	if t := ph.attr("type"); t != "" {
+		return t
+	}
+	return "body"
+}
+
+// shapes writes the text of the shapes in tree, in the order they are drawn, except for skip.
+func (p *slideReader) shapes(tree *node, skip *node) {
+	for _, c := range tree.nodes {
+		switch c.name {
+		case "sp":
+			if c == skip {
+				continue
+			}
+			switch t := placeholderType(c); t {
+			case "sldNum", "dt", "ftr", "hdr", "sldImg":
+				// Slide numbers, dates, footers and headers repeat on every slide.
+			default:
+				p.textBody(c.child("txBody"), t == "body" || t == "obj")
+			}
+		case "grpSp":
+			p.shapes(c, skip)
+		case "graphicFrame":
+			if tbl := c.find("tbl"); tbl != nil {
+				p.table(tbl)
+			}
+		case "AlternateContent":
+			if choice := c.child("Choice"); choice != nil {
+				p.shapes(choice, skip)
+			}
+		}
+	}
+}
+
+// textBody writes the paragraphs of a shape. Paragraphs in body placeholders are bulleted unless they say
+// otherwise; in other shapes, only when they ask for a bullet.
+func (p *slideReader) textBody(body *node, bulleted bool) {
+	for _, para := range body.children("p") {
+		text := paragraphText(para)
+		pPr := para.child("pPr")
+		level, _ := strconv.Atoi(pPr.attr("lvl"))
+		switch {
+		case pPr.child("buAutoNum") != nil:
+			p.md.ListItem(level, true, text)
+		case pPr.child("buChar") != nil || bulleted && pPr.child("buNone") == nil:
+			p.md.ListItem(level, false, text)
+		default:
+			p.md.Paragraph(text)
+		}
+	}
+}
+
+func (p *slideReader) table(tbl *node) {
+	var rows [][]string
+	for _, tr := range tbl.children("tr") {
+		var row []string
+		for _, tc := range tr.children("tc") {
+			row = append(row, shapeText(tc))
+		}
+		rows = append(rows, row)
+	}
+	p.md.Table(rows)
+}
+
+// notes writes the speaker notes of a slide, which are in the body placeholder of its notes page.
+func (p *slideReader) notes(tree *node) {
+	var paragraphs []string
+	var walk func(n *node)
+	walk = func(n *node) {
+		for _, c := range n.nodes {
+			switch c.name {
+			case "sp":
+				if placeholderType(c) == "body" {
+					for _, para := range c.child("txBody").children("p") {
+						paragraphs = append(paragraphs, paragraphText(para))
+					}
+				}
+			case "grpSp":
+				walk(c)
+			}
+		}
+	}
+	walk(tree)
+
+	if markdown.Inline(strings.Join(paragraphs, "\n")) == "" {
+		return
+	}
+	p.md.Heading(3, "Speaker notes")
+	for _, text := range paragraphs {
+		p.md.Paragraph(text)
+	}
+}
+
+// shapeText returns the paragraphs of a shape or table cell, one per line.
+func shapeText(n *node) string {
+	var lines []string
+	for _, para := range n.child("txBody").children("p") {
+		lines = append(lines, paragraphText(para))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// paragraphText returns the text of a DrawingML paragraph: its runs, fields and line breaks.
+func paragraphText(para *node) string {
+	var b strings.Builder
+	for _, c := range para.nodes {
+		switch c.name {
+		case "r", "fld":
+			if t := c.child("t"); t != nil {
+				b.WriteString(t.text.String())
+			}
+		case "br":
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,272 @@
+package ooxml
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github/k-tsurumaki/quilldeck/internal/pkg/markdown"
+)
+
+// maxTableCells bounds the cells written for one sheet. Rows past it are counted rather than written, so
+// that a sparse sheet with far-apart cells cannot turn into an enormous table.
+const maxTableCells = 1 << 20
+
+// ExtractXLSX returns the worksheets of a workbook as Markdown, each as a heading with its name and a
+// table of its cells as they are displayed: shared strings resolved, booleans as TRUE and FALSE, and
+// dates written out. Rows and columns without any value are left out, and the first remaining row is
+// the header. Empty worksheets and chart sheets are skipped.
+func ExtractXLSX(data []byte) (string, error) {
+	a, err := openArchive(data)
+	if err != nil {
+		return "", err
+	}
+	main, err := a.mainPart("xl/workbook.xml")
+	if err != nil {
+		return "", err
+	}
+	wb, err := a.readXML(main)
+	if err != nil {
+		return "", err
+	}
+	rels, err := a.relationships(main)
+	if err != nil {
+		return "", err
+	}
+
+	x := &workbookReader{date1904: isTrue(wb.find("workbookPr").attr("date1904"))}
+	for _, rel := range rels {
+		switch {
+		case rel.isType("sharedStrings"):
+			root, err := a.readXML(rel.target)
+			if err != nil {
+				return "", err
+			}
+			for _, si := range root.find("sst").children("si") {
+				x.shared = append(x.shared, stringItem(si))
+			}
+		case rel.isType("styles"):
+			if root, err := a.readXML(rel.target); err == nil {
+				x.readStyles(root)
+			}
+		}
+	}
+
+	sheets := wb.find("sheets").children("sheet")
+	if len(sheets) == 0 {
+		return "", fmt.Errorf("%w: the workbook has no sheets", ErrInvalid)
+	}
+	for _, sheet := range sheets {
+		rel, ok := rels[sheet.relID()]
+		if !ok || !rel.isType("worksheet") {
+			continue
+		}
+		root, err := a.readXML(rel.target)
+		if err != nil {
+			return "", err
+		}
+		x.sheet(sheet.attr("name"), root)
+	}
+	return x.md.String(), nil
+}
+
+type workbookReader struct {
+	md       markdown.Writer
+	shared   []string
+	date1904 bool
+	// dateStyles tells, by cell style index, which styles display numbers as dates or times.
+	dateStyles []bool
+}
+
+// stringItem returns the text of a shared or inline string, leaving out the phonetic guides (furigana)
+// that Japanese text may carry.
+func stringItem(si *node) string {
+	var b strings.Builder
+	for _, c := range si.nodes {
+		switch c.name {
+		case "t":
+			b.WriteString(c.text.String())
+		case "r":
+			if t := c.child("t"); t != nil {
+				b.WriteString(t.text.String())
+			}
+		}
+	}
+	return b.String()
+}
+
+func (x *workbookReader) readStyles(root *node) {
+	custom := make(map[int]string)
+	for _, f := range root.find("numFmts").children("numFmt") {
+		if id, err := strconv.Atoi(f.attr("numFmtId")); err == nil {
+			custom[id] = f.attr("formatCode")
+		}
+	}
+	for _, xf := range root.find("cellXfs").children("xf") {
+		id, _ := strconv.Atoi(xf.attr("numFmtId"))
+		format, isCustom := custom[id]
+		x.dateStyles = append(x.dateStyles, isCustom && isDateFormat(format) || !isCustom && isDateFormatID(id))
+	}
+}
+
+// isDateFormatID tells the built-in number formats that show dates or times, including those of East
+// Asian locales.
+func isDateFormatID(id int) bool {
+	return id >= 14 && id <= 22 || id >= 27 && id <= 36 || id >= 45 && id <= 47 || id >= 50 && id <= 58
+}
+
+// formatLiterals matches the parts of a number format that are shown as they are: quoted text, escaped
+// characters and bracketed colors and conditions.
+var formatLiterals = regexp.MustCompile(`"[^"]*"|\\.|\[[^\]]*\]`)
+
+// isDateFormat tells whether a custom number format shows dates or times, by the date and time codes
+// it uses outside of literals.
+func isDateFormat(format string) bool {
+	elapsed := strings.Contains(format, "[h]") || strings.Contains(format, "[m]") || strings.Contains(format, "[s]")
+	codes := strings.ToLower(formatLiterals.ReplaceAllString(format, ""))
+	return elapsed || strings.ContainsAny(codes, "ydhs") || strings.Contains(codes, "am/pm")
+}
+
+func (x *workbookReader) sheet(name string, root *node) {
+	cells := make(map[int]map[int]string)
+	usedColumns := make(map[int]bool)
+	row := 0
+	for _, r := range root.find("sheetData").children("row") {
+		if n, err := strconv.Atoi(r.attr("r")); err == nil {
+			row = n
+		} else {
+			row++
+		}
+		column := 0
+		for _, c := range r.children("c") {
+			if col, ok := columnIndex(c.attr("r")); ok {
+				column = col
+			} else {
+				column++
+			}
+			value := x.cellValue(c)
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			if cells[row] == nil {
+				cells[row] = make(map[int]string)
+			}
+			cells[row][column] = value
+			usedColumns[column] = true
+		}
+	}
+	if len(cells) == 0 {
+		return
+	}
+
+	rows := sortedKeys(cells)
+	columns := sortedKeys(usedColumns)
+	limit := max(1, maxTableCells/len(columns))
+	table := make([][]string, 0, min(len(rows), limit))
+	for _, r := range rows[:min(len(rows), limit)] {
+		line := make([]string, len(columns))
+		for i, c := range columns {
+			line[i] = cells[r][c]
+		}
+		table = append(table, line)
+	}
+
+	x.md.Heading(2, name)
+	x.md.Table(table)
+	if len(rows) > limit {
+		x.md.Paragraph(fmt.Sprintf("(%d more rows)", len(rows)-limit))
+	}
+}
+
+func (x *workbookReader) cellValue(c *node) string {
+	v := c.child("v")
+	value := ""
+	if v != nil {
+		value = v.text.String()
+	}
+	switch c.attr("t") {
+	case "s":
+		if i, err := strconv.Atoi(value); err == nil && i >= 0 && i < len(x.shared) {
+			return x.shared[i]
+		}
+		return ""
+	case "inlineStr":
+		return stringItem(c.child("is"))
+	case "b":
+		if value == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	case "str", "e", "d":
+		return value
+	}
+
+	style, _ := strconv.Atoi(c.attr("s"))
+	if style >= 0 && style < len(x.dateStyles) && x.dateStyles[style] {
+		if serial, err := strconv.ParseFloat(value, 64); err == nil {
+			return formatSerial(serial, x.date1904)
+		}
+	}
+	return value
+}
+
+// formatSerial writes a date serial number, the days since the workbook's epoch, as a date, a time of
+// day, or both.
+func formatSerial(serial float64, date1904 bool) string {
+	if serial < 0 || serial > 2958465 { // Excel's last date, 9999-12-31
+		return strconv.FormatFloat(serial, 'f', -1, 64)
+	}
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	switch {
+	case date1904:
+		epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	case serial < 61:
+		// Before the 29 February 1900 that Excel wrongly counts, for compatibility with Lotus 1-2-3.
+		epoch = epoch.AddDate(0, 0, 1)
+	}
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 86400)
+	t := epoch.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+
+	switch {
+	case seconds == 0:
+		return t.Format("2006-01-02")
+	case days == 0 && !date1904:
+		return t.Format("15:04:05")
+	default:
+		return t.Format("2006-01-02 15:04:05")
+	}
+}
+
+// columnIndex returns the column of a cell reference such as "B3", from 1.
+func columnIndex(ref string) (int, bool) {
+	column := 0
+	for _, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			column = column*26 + int(r-'A'+1)
+		} else {
+			break
+		}
+		if column > 1<<14 {
+			return 0, false
+		}
+	}
+	return column, column > 0
+}
+
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func isTrue(s string) bool {
+	return s == "1" || s == "true"
+}
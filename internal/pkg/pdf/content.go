@@ -0,0 +1,349 @@
+package pdf
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxFormDepth bounds the nesting of form XObjects, which may refer to each other.
+const maxFormDepth = 8
+
+// matrix is an affine transformation [a b c d e f], as PDF writes them.
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// mul returns m × n.
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2], m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2], m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4], m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+// textState is the part of the graphics state that text extraction follows.
+type textState struct {
+	ctm         matrix
+	font        *font
+	size        float64
+	charSpacing float64
+	wordSpacing float64
+	scale       float64
+	leading     float64
+}
+
+// extractor interprets content streams, writing the text they show. Breaks between lines and words are
+// not in the text itself: they are inferred from where each piece of text is drawn.
+type extractor struct {
+	doc   *document
+	out   strings.Builder
+	state textState
+	stack []textState
+	tm    matrix // text matrix
+	tlm   matrix // text line matrix
+
+	// Where the last piece of text ended, in device space, and its font size there.
+	drawn        bool
+	lastX, lastY float64
+	lastSize     float64
+}
+
+func (d *document) pageText(p page) string {
+	e := &extractor{doc: d, state: textState{ctm: identity, scale: 1}, tm: identity, tlm: identity}
+	e.run(d.contents(p), p.resources, 0)
+	return cleanText(e.out.String())
+}
+
+func (e *extractor) run(content []byte, resources dict, depth int) {
+	l := &lexer{data: content}
+	var operands []object
+	for {
+		obj, err := l.readObject()
+		if err != nil {
+			return
+		}
+		op, ok := obj.(keyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+		if op == "BI" {
+			skipInlineImage(l)
+		} else {
+			e.do(op, operands, resources, depth)
+		}
+		operands = operands[:0]
+	}
+}
+
+func (e *extractor) do(op keyword, args []object, resources dict, depth int) {
+	num := func(i int) float64 {
+		if i < len(args) {
+			return e.doc.number(args[i], 0)
+		}
+		return 0
+	}
+	mat := func() matrix {
+		return matrix{num(0), num(1), num(2), num(3), num(4), num(5)}
+	}
+
+	switch op {
+	case "q":
+		e.stack = append(e.stack, e.state)
+	case "Q":
+		if n := len(e.stack); n > 0 {
+			e.state = e.stack[n-1]
+			e.stack = e.stack[:n-1]
+		}
+	case "cm":
+		if len(args) == 6 {
+			e.state.ctm = mat().mul(e.state.ctm)
+		}
+	case "BT":
+		e.tm, e.tlm = identity, identity
+	case "Tf":
+		if len(args) == 2 {
+			if fontName, ok := args[0].(name); ok {
+				e.state.font = e.doc.fontByName(resources, fontName)
+			}
+			e.state.size = num(1)
+		}
+	case "Tc":
+		e.state.charSpacing = num(0)
+	case "Tw":
+		e.state.wordSpacing = num(0)
+	case "Tz":
+		e.state.scale = num(0) / 100
+	case "TL":
+		e.state.leading = num(0)
+	case "Td":
+		e.moveLine(num(0), num(1))
+	case "TD":
+		e.state.leading = -num(1)
+		e.moveLine(num(0), num(1))
+	case "Tm":
+		if len(args) == 6 {
+			e.tm, e.tlm = mat(), mat()
+		}
+	case "T*":
+		e.moveLine(0, -e.state.leading)
+	case "Tj":
+		if len(args) == 1 {
+			e.show(args[0])
+		}
+	case "'":
+		e.moveLine(0, -e.state.leading)
+		if len(args) == 1 {
+			e.show(args[0])
+		}
+	case "\"":
+		if len(args) == 3 {
+			e.state.wordSpacing, e.state.charSpacing = num(0), num(1)
+			e.moveLine(0, -e.state.leading)
+			e.show(args[2])
+		}
+	case "TJ":
+		if len(args) == 1 {
+			if items, ok := args[0].(array); ok {
+				for _, item := range items {
+					if s, ok := item.([]byte); ok {
+						e.show(s)
+					} else {
+						e.adjust(e.doc.number(item, 0))
+					}
+				}
+			}
+		}
+	case "Do":
+		if len(args) == 1 && depth < maxFormDepth {
+			if xname, ok := args[0].(name); ok {
+				e.form(resources, xname, depth)
+			}
+		}
+	}
+}
+
+func (e *extractor) moveLine(tx, ty float64) {
+	e.tlm = matrix{1, 0, 0, 1, tx, ty}.mul(e.tlm)
+	e.tm = e.tlm
+}
+
+// form runs the content of a form XObject, which is drawn like part of the page.
+func (e *extractor) form(resources dict, xname name, depth int) {
+	xobjects := e.doc.resolveDict(resources["XObject"])
+	if xobjects == nil {
+		return
+	}
+	s, ok := e.doc.resolve(xobjects[xname]).(*stream)
+	if !ok {
+		return
+	}
+	if subtype, _ := s.dict["Subtype"].(name); subtype != "Form" {
+		return
+	}
+	data, err := e.doc.decode(s)
+	if err != nil {
+		return
+	}
+	if res := e.doc.resolveDict(s.dict["Resources"]); res != nil {
+		resources = res
+	}
+
+	saved, savedTm, savedTlm := e.state, e.tm, e.tlm
+	if m, ok := e.doc.resolve(s.dict["Matrix"]).(array); ok && len(m) == 6 {
+		var fm matrix
+		for i := range fm {
+			fm[i] = e.doc.number(m[i], 0)
+		}
+		e.state.ctm = fm.mul(e.state.ctm)
+	}
+	e.run(data, resources, depth+1)
+	e.state, e.tm, e.tlm = saved, savedTm, savedTlm
+}
+
+// show writes a shown string, first breaking the line or the word if it is drawn away from where the
+// previous text ended.
+func (e *extractor) show(obj object) {
+	s, ok := obj.([]byte)
+	f := e.state.font
+	if !ok || f == nil {
+		return
+	}
+
+	trm := matrix{e.state.size * e.state.scale, 0, 0, e.state.size, 0, 0}.mul(e.tm).mul(e.state.ctm)
+	x, y := trm[4], trm[5]
+	size := math.Hypot(trm[2], trm[3])
+	if size == 0 {
+		size = 1
+	}
+	e.separate(x, y, size)
+
+	for _, g := range f.decode(s) {
+		e.out.WriteString(g.text)
+		advance := g.width*e.state.size + e.state.charSpacing
+		if g.bytes == 1 && g.code == ' ' {
+			advance += e.state.wordSpacing
+		}
+		e.tm = matrix{1, 0, 0, 1, advance * e.state.scale, 0}.mul(e.tm)
+	}
+
+	end := e.tm.mul(e.state.ctm)
+	e.drawn = true
+	e.lastX, e.lastY, e.lastSize = end[4], end[5], size
+}
+
+// adjust moves the text position by a TJ number, in thousandths of the font size. A large enough move is a
+// space between words.
+func (e *extractor) adjust(n float64) {
+	tx := -n / 1000 * e.state.size * e.state.scale
+	e.tm = matrix{1, 0, 0, 1, tx, 0}.mul(e.tm)
+	if -n > 200 {
+		e.space()
+	}
+	end := e.tm.mul(e.state.ctm)
+	e.lastX, e.lastY = end[4], end[5]
+}
+
+// separate decides how the text drawn at (x, y) relates to the text before it: on another line, after a
+// gap as a separate word, or run on.
+func (e *extractor) separate(x, y, size float64) {
+	if !e.drawn {
+		return
+	}
+	lineHeight := math.Max(size, e.lastSize)
+	dy := math.Abs(y - e.lastY)
+	switch {
+	case dy > 1.9*lineHeight:
+		// Further apart than line spacing: a new paragraph.
+		e.newline(2)
+	case dy > 0.5*lineHeight:
+		e.newline(1)
+	case x-e.lastX > 0.15*size:
+		e.space()
+	case x-e.lastX < -2*size:
+		// Back to the left on the same baseline, as in tables and multiple columns.
+		e.newline(1)
+	}
+}
+
+// space separates words, unless the text already ends with white space or between characters of
+// scripts written without spaces.
+func (e *extractor) space() {
+	last, _ := utf8.DecodeLastRuneInString(e.out.String())
+	if e.out.Len() == 0 || unicode.IsSpace(last) || isWide(last) {
+		return
+	}
+	e.out.WriteByte(' ')
+}
+
+func (e *extractor) newline(n int) {
+	if e.out.Len() == 0 {
+		return
+	}
+	text := e.out.String()
+	have := len(text) - len(strings.TrimRight(text, "\n"))
+	for ; have < n; have++ {
+		e.out.WriteByte('\n')
+	}
+}
+
+// isWide tells whether r belongs to the scripts written without spaces between words: Chinese, Japanese
+// and their full-width punctuation.
+func isWide(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) ||
+		r >= 0x3000 && r <= 0x303F || r >= 0xFF00 && r <= 0xFFEF
+}
+
+// skipInlineImage moves past the data of an inline image, which runs from the ID operator to EI.
+func skipInlineImage(l *lexer) {
+	for {
+		obj, err := l.readObject()
+		if err != nil {
+			return
+		}
+		if obj == keyword("ID") {
+			break
+		}
+	}
+	l.pos++ // the single white-space character after ID
+	for i := l.pos; i+2 <= len(l.data); i++ {
+		if l.data[i] == 'E' && l.data[i+1] == 'I' && i > 0 && isSpace(l.data[i-1]) &&
+			(i+2 == len(l.data) || isSpace(l.data[i+2])) {
+			l.pos = i + 2
+			return
+		}
+	}
+	l.pos = len(l.data)
+}
+
+// cleanText tidies the extracted text of a page: no trailing spaces, no control characters and no more
+// than one blank line in a row.
+func cleanText(text string) string {
+	lines := strings.Split(text, "\n")
+	var out []string
+	blank := 0
+	for _, line := range lines {
+		line = strings.Map(func(r rune) rune {
+			switch {
+			case r == '\t' || r == '\u00a0':
+				return ' '
+			case unicode.IsControl(r) || r == utf8.RuneError:
+				return -1
+			}
+			return r
+		}, line)
+		line = strings.TrimRight(line, " ")
+		if line == "" {
+			if blank++; blank > 1 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		out = append(out, line)
+	}
+	return strings.Trim(strings.Join(out, "\n"), "\n ")
+}
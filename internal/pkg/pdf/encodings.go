@@ -0,0 +1,107 @@
+package pdf
+
+import "strings"
+
+// The encodings of simple fonts, as the text of each code. Ligatures are spelt out, so that the text
+// reads and searches like what was typed.
+var (
+	standardEncoding = buildEncoding(map[int]string{
+		0x27: "’", 0x60: "‘",
+		0xA1: "¡", 0xA2: "¢", 0xA3: "£", 0xA4: "⁄", 0xA5: "¥", 0xA6: "ƒ", 0xA7: "§", 0xA8: "¤",
+		0xA9: "'", 0xAA: "“", 0xAB: "«", 0xAC: "‹", 0xAD: "›", 0xAE: "fi", 0xAF: "fl",
+		0xB1: "–", 0xB2: "†", 0xB3: "‡", 0xB4: "·", 0xB6: "¶", 0xB7: "•", 0xB8: "‚", 0xB9: "„",
+		0xBA: "”", 0xBB: "»", 0xBC: "…", 0xBD: "‰", 0xBF: "¿",
+		0xC1: "`", 0xC2: "´", 0xC3: "ˆ", 0xC4: "˜", 0xC5: "¯", 0xC6: "˘", 0xC7: "˙", 0xC8: "¨",
+		0xCA: "˚", 0xCB: "¸", 0xCD: "˝", 0xCE: "˛", 0xCF: "ˇ", 0xD0: "—",
+		0xE1: "Æ", 0xE3: "ª", 0xE8: "Ł", 0xE9: "Ø", 0xEA: "Œ", 0xEB: "º",
+		0xF1: "æ", 0xF5: "ı", 0xF8: "ł", 0xF9: "ø", 0xFA: "œ", 0xFB: "ß",
+	})
+
+	winAnsiEncoding = buildEncoding(winAnsiUpper())
+
+	macRomanEncoding = buildEncoding(upperHalf(
+		"ÄÅÇÉÑÖÜáàâäãåçéèêëíìîïñóòôöõúùûü†°¢£§•¶ß®©™´¨≠ÆØ∞±≤≥¥µ∂∑∏π∫ªºΩæø" +
+			"¿¡¬√ƒ≈∆«»…\u00a0ÀÃÕŒœ–—“”‘’÷◊ÿŸ⁄€‹›ﬁﬂ‡·‚„‰ÂÊÁËÈÍÎÏÌÓÔ\uF8FFÒÚÛÙıˆ˜¯˘˙˚¸˝˛ˇ"))
+)
+
+// buildEncoding returns printable ASCII with the given codes replaced.
+func buildEncoding(codes map[int]string) [256]string {
+	var enc [256]string
+	for c := 0x20; c < 0x7F; c++ {
+		enc[c] = string(rune(c))
+	}
+	for c, text := range codes {
+		enc[c] = ligatures.Replace(text)
+	}
+	return enc
+}
+
+var ligatures = strings.NewReplacer("ﬁ", "fi", "ﬂ", "fl")
+
+func upperHalf(chars string) map[int]string {
+	codes := make(map[int]string)
+	c := 0x80
+	for _, r := range chars {
+		codes[c] = string(r)
+		c++
+	}
+	return codes
+}
+
+// winAnsiUpper is Latin-1 from 0xA0, with the Windows-1252 punctuation below it.
+func winAnsiUpper() map[int]string {
+	codes := upperHalf("€\x00‚ƒ„…†‡ˆ‰Š‹Œ\x00Ž\x00\x00‘’“”•–—˜™š›œ\x00žŸ")
+	for c, text := range codes {
+		if text == "\x00" {
+			delete(codes, c)
+		}
+	}
+	for c := 0xA0; c <= 0xFF; c++ {
+		codes[c] = string(rune(c))
+	}
+	// The soft hyphen is drawn as a hyphen.
+	codes[0xAD] = "-"
+	return codes
+}
+
+// glyphNames maps the glyph names used in the encoding differences of simple fonts to their text. Single
+// letters, "uniXXXX" and "uXXXX" are handled by glyphText.
+var glyphNames = func() map[string]string {
+	names := make(map[string]string)
+	pairs := strings.Fields(`
+		space \x20 exclam ! quotedbl " numbersign # dollar $ percent % ampersand & quotesingle '
+		parenleft ( parenright ) asterisk * plus + comma , hyphen - period . slash /
+		zero 0 one 1 two 2 three 3 four 4 five 5 six 6 seven 7 eight 8 nine 9
+		colon : semicolon ; less < equal = greater > question ? at @
+		bracketleft [ backslash \ bracketright ] asciicircum ^ underscore _ grave ` + "`" + `
+		braceleft { bar | braceright } asciitilde ~
+		quoteleft ‘ quoteright ’ quotedblleft “ quotedblright ” quotesinglbase ‚ quotedblbase „
+		guillemotleft « guillemotright » guilsinglleft ‹ guilsinglright ›
+		endash – emdash — bullet • ellipsis … dagger † daggerdbl ‡ perthousand ‰ trademark ™
+		copyright © registered ® degree ° section § paragraph ¶ periodcentered · middot ·
+		minus − multiply × divide ÷ plusminus ± fraction ⁄ Euro € cent ¢ sterling £ yen ¥ currency ¤
+		florin ƒ exclamdown ¡ questiondown ¿ brokenbar ¦ dieresis ¨ macron ¯ acute ´ cedilla ¸
+		ordfeminine ª ordmasculine º logicalnot ¬ mu µ onesuperior ¹ twosuperior ² threesuperior ³
+		onequarter ¼ onehalf ½ threequarters ¾ circumflex ˆ tilde ˜ caron ˇ breve ˘ dotaccent ˙
+		ring ˚ ogonek ˛ hungarumlaut ˝ dotlessi ı
+		fi fi fl fl ff ff ffi ffi ffl ffl germandbls ß AE Æ ae æ OE Œ oe œ Oslash Ø oslash ø
+		Lslash Ł lslash ł Eth Ð eth ð Thorn Þ thorn þ
+		Agrave À Aacute Á Acircumflex Â Atilde Ã Adieresis Ä Aring Å Ccedilla Ç
+		Egrave È Eacute É Ecircumflex Ê Edieresis Ë Igrave Ì Iacute Í Icircumflex Î Idieresis Ï
+		Ntilde Ñ Ograve Ò Oacute Ó Ocircumflex Ô Otilde Õ Odieresis Ö
+		Ugrave Ù Uacute Ú Ucircumflex Û Udieresis Ü Yacute Ý Ydieresis Ÿ Scaron Š Zcaron Ž
+		agrave à aacute á acircumflex â atilde ã adieresis ä aring å ccedilla ç
+		egrave è eacute é ecircumflex ê edieresis ë igrave ì iacute í icircumflex î idieresis ï
+		ntilde ñ ograve ò oacute ó ocircumflex ô otilde õ odieresis ö
+		ugrave ù uacute ú ucircumflex û udieresis ü yacute ý ydieresis ÿ scaron š zcaron ž
+		nbspace \xa0 nonbreakingspace \xa0 sfthyphen - softhyphen -
+	`)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		text := pairs[i+1]
+		if text == `\x20` || text == `\xa0` {
+			text = " "
+		}
+		names[pairs[i]] = text
+	}
+	return names
+}()
@@ -0,0 +1,293 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// maxStreamSize bounds what a single stream may decode to, so that a small file cannot expand into
+// gigabytes.
+const maxStreamSize = 64 << 20
+
+var errStreamTooLarge = errors.New("pdf: stream decodes to more than 64 MiB")
+
+// decode returns the content of s with its filters undone.
+func (d *document) decode(s *stream) ([]byte, error) {
+	var filters, params array
+	switch f := d.resolve(s.dict["Filter"]).(type) {
+	case name:
+		filters = array{f}
+	case array:
+		filters = f
+	}
+	switch p := d.resolve(s.dict["DecodeParms"]).(type) {
+	case dict:
+		params = array{p}
+	case array:
+		params = p
+	}
+
+	data := s.raw
+	for i, f := range filters {
+		var param dict
+		if i < len(params) {
+			param, _ = d.resolve(params[i]).(dict)
+		}
+		filter, _ := d.resolve(f).(name)
+		var err error
+		switch filter {
+		case "FlateDecode", "Fl":
+			data, err = inflate(data)
+			if err == nil {
+				data, err = unpredict(data, d.integer(param["Predictor"], 1), d.integer(param["Columns"], 1),
+					d.integer(param["Colors"], 1), d.integer(param["BitsPerComponent"], 8))
+			}
+		case "LZWDecode", "LZW":
+			data, err = unlzw(data, d.integer(param["EarlyChange"], 1) == 1)
+			if err == nil {
+				data, err = unpredict(data, d.integer(param["Predictor"], 1), d.integer(param["Columns"], 1),
+					d.integer(param["Colors"], 1), d.integer(param["BitsPerComponent"], 8))
+			}
+		case "ASCIIHexDecode", "AHx":
+			data = unhex(data)
+		case "ASCII85Decode", "A85":
+			data, err = unascii85(data)
+		case "RunLengthDecode", "RL":
+			data, err = unrunlength(data)
+		default:
+			err = fmt.Errorf("pdf: unsupported filter %s", filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// inflate undoes FlateDecode. A truncated or corrupt stream yields what could be read of it, which is
+// usually all of the text that was there.
+func inflate(data []byte) ([]byte, error) {
+	var r io.ReadCloser
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		// Some writers leave out the zlib header.
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(io.LimitReader(r, maxStreamSize+1))
+	if len(out) > maxStreamSize {
+		return nil, errStreamTooLarge
+	}
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("pdf: inflate: %w", err)
+	}
+	return out, nil
+}
+
+// unpredict undoes the PNG predictors; TIFF predictors only appear with images, which are not read.
+func unpredict(data []byte, predictor, columns, colors, bits int) ([]byte, error) {
+	if predictor < 10 {
+		if predictor == 2 {
+			return nil, errors.New("pdf: unsupported TIFF predictor")
+		}
+		return data, nil
+	}
+	bpp := (colors*bits + 7) / 8
+	rowSize := (colors*bits*columns + 7) / 8
+	if bpp < 1 || rowSize < 1 {
+		return nil, errors.New("pdf: invalid predictor parameters")
+	}
+
+	var out []byte
+	prev := make([]byte, rowSize)
+	for len(data) > 0 {
+		kind := data[0]
+		data = data[1:]
+		row := make([]byte, rowSize)
+		n := copy(row, data)
+		data = data[n:]
+		for i := range row {
+			var left, up, upLeft byte
+			if i >= bpp {
+				left = row[i-bpp]
+				upLeft = prev[i-bpp]
+			}
+			up = prev[i]
+			switch kind {
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	default:
+		return c
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// unlzw undoes LZWDecode, whose codes grow from 9 to 12 bits one code earlier than plain LZW when
+// earlyChange is set, as it is by default.
+func unlzw(data []byte, earlyChange bool) ([]byte, error) {
+	const (
+		clear = 256
+		eod   = 257
+	)
+	var (
+		out     []byte
+		table   [][]byte
+		width   = 9
+		prev    []byte
+		acc     uint32
+		accBits int
+	)
+	reset := func() {
+		table = table[:0]
+		for i := 0; i < 256; i++ {
+			table = append(table, []byte{byte(i)})
+		}
+		table = append(table, nil, nil)
+		width = 9
+		prev = nil
+	}
+	reset()
+
+	early := 0
+	if earlyChange {
+		early = 1
+	}
+	for _, b := range data {
+		acc = acc<<8 | uint32(b)
+		accBits += 8
+		for accBits >= width {
+			code := int(acc >> (accBits - width) & (1<<width - 1))
+			accBits -= width
+			switch {
+			case code == clear:
+				reset()
+				continue
+			case code == eod:
+				return out, nil
+			}
+
+			var entry []byte
+			switch {
+			case code < len(table) && table[code] != nil:
+				entry = table[code]
+			case code == len(table) && prev != nil:
+				entry = append(append([]byte{}, prev...), prev[0])
+			default:
+				return nil, errors.New("pdf: invalid LZW code")
+			}
+			out = append(out, entry...)
+			if len(out) > maxStreamSize {
+				return nil, errStreamTooLarge
+			}
+			if prev != nil && len(table) < 4096 {
+				table = append(table, append(append([]byte{}, prev...), entry[0]))
+			}
+			prev = entry
+			if len(table)+early >= 1<<width && width < 12 {
+				width++
+			}
+		}
+	}
+	return out, nil
+}
+
+func unhex(data []byte) []byte {
+	var out []byte
+	var digits []byte
+	for _, c := range data {
+		if c == '>' {
+			break
+		}
+		if isSpace(c) {
+			continue
+		}
+		if digits = append(digits, c); len(digits) == 2 {
+			v, _ := strconv.ParseUint(string(digits), 16, 8)
+			out = append(out, byte(v))
+			digits = digits[:0]
+		}
+	}
+	if len(digits) == 1 {
+		v, _ := strconv.ParseUint(string(digits)+"0", 16, 8)
+		out = append(out, byte(v))
+	}
+	return out
+}
+
+func unascii85(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	data = bytes.TrimPrefix(data, []byte("<~"))
+	if end := bytes.Index(data, []byte("~>")); end >= 0 {
+		data = data[:end]
+	}
+	// Each "z" stands for four zero bytes.
+	out := make([]byte, 4*len(data)+4)
+	n, _, err := ascii85.Decode(out, data, true)
+	if err != nil {
+		return nil, fmt.Errorf("pdf: ASCII85: %w", err)
+	}
+	return out[:n], nil
+}
+
+func unrunlength(data []byte) ([]byte, error) {
+	var out []byte
+	for i := 0; i < len(data); {
+		n := int(data[i])
+		i++
+		switch {
+		case n == 128:
+			return out, nil
+		case n < 128:
+			end := i + n + 1
+			if end > len(data) {
+				end = len(data)
+			}
+			out = append(out, data[i:end]...)
+			i = end
+		default:
+			if i < len(data) {
+				out = append(out, bytes.Repeat(data[i:i+1], 257-n)...)
+				i++
+			}
+		}
+		if len(out) > maxStreamSize {
+			return nil, errStreamTooLarge
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,406 @@
+package pdf
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// font turns the bytes of a shown string into text and glyph widths.
+type font struct {
+	// composite fonts (Type0) have multi-byte codes, split by codespace; simple fonts have one byte per code.
+	composite bool
+	codespace []codespaceRange
+	// toUnicode maps codes, as their bytes, to text. It takes precedence over everything else.
+	toUnicode map[string]string
+	// ucs2 is set for composite fonts whose codes are UTF-16, as with the predefined Uni*-UCS2 and
+	// Uni*-UTF16 encodings.
+	ucs2 bool
+	// encoding maps the codes of a simple font to text.
+	encoding [256]string
+
+	// widths are in text space units per unit of font size, by code for simple fonts and by CID for
+	// composite ones.
+	widths       map[int]float64
+	defaultWidth float64
+	// identity is set for composite fonts whose codes are their CIDs, the only case where their widths
+	// can be looked up.
+	identity bool
+}
+
+type codespaceRange struct {
+	low, high []byte
+}
+
+// glyph is one code of a shown string.
+type glyph struct {
+	code  int
+	bytes int
+	text  string
+	width float64
+}
+
+// fontByName returns the font named by Tf in resources.
+func (d *document) fontByName(resources dict, fontName name) *font {
+	fonts := d.resolveDict(resources["Font"])
+	if fonts == nil {
+		return nil
+	}
+	obj := fonts[fontName]
+	r, isRef := obj.(ref)
+	if isRef {
+		if f, ok := d.fonts[r.num]; ok {
+			return f
+		}
+	}
+	fd := d.resolveDict(obj)
+	if fd == nil {
+		return nil
+	}
+	f := d.loadFont(fd)
+	if isRef {
+		d.fonts[r.num] = f
+	}
+	return f
+}
+
+func (d *document) loadFont(fd dict) *font {
+	f := &font{widths: make(map[int]float64)}
+	subtype, _ := d.resolve(fd["Subtype"]).(name)
+
+	if subtype == "Type0" {
+		f.composite = true
+		f.defaultWidth = 1
+		switch enc := d.resolve(fd["Encoding"]).(type) {
+		case name:
+			f.identity = enc == "Identity-H" || enc == "Identity-V"
+			f.ucs2 = strings.Contains(string(enc), "UCS2") || strings.Contains(string(enc), "UTF16")
+		case *stream:
+			if data, err := d.decode(enc); err == nil {
+				f.codespace = parseCMap(data).codespace
+			}
+		}
+		var descendant dict
+		if kids, ok := d.resolve(fd["DescendantFonts"]).(array); ok && len(kids) > 0 {
+			descendant = d.resolveDict(kids[0])
+		}
+		if descendant != nil {
+			f.defaultWidth = d.number(descendant["DW"], 1000) / 1000
+			d.readCIDWidths(f, d.resolve(descendant["W"]))
+		}
+	} else {
+		f.encoding = standardEncoding
+		scale := 0.001
+		if subtype == "Type3" {
+			if m, ok := d.resolve(fd["FontMatrix"]).(array); ok && len(m) > 0 {
+				scale = d.number(m[0], scale)
+			}
+		}
+		d.readSimpleEncoding(f, fd)
+		d.readSimpleWidths(f, fd, scale)
+	}
+
+	if s, ok := d.resolve(fd["ToUnicode"]).(*stream); ok {
+		if data, err := d.decode(s); err == nil {
+			cmap := parseCMap(data)
+			f.toUnicode = cmap.mappings
+			if f.composite && len(f.codespace) == 0 {
+				f.codespace = cmap.codespace
+			}
+		}
+	}
+	return f
+}
+
+func (d *document) readSimpleEncoding(f *font, fd dict) {
+	base := d.resolve(fd["Encoding"])
+	var differences array
+	if enc, ok := base.(dict); ok {
+		base = d.resolve(enc["BaseEncoding"])
+		differences, _ = d.resolve(enc["Differences"]).(array)
+	}
+	switch base {
+	case name("WinAnsiEncoding"):
+		f.encoding = winAnsiEncoding
+	case name("MacRomanEncoding"):
+		f.encoding = macRomanEncoding
+	case name("StandardEncoding"):
+		f.encoding = standardEncoding
+	}
+
+	code := 0
+	for _, item := range differences {
+		switch v := d.resolve(item).(type) {
+		case int64:
+			code = int(v)
+		case name:
+			if code >= 0 && code < 256 {
+				f.encoding[code] = glyphText(string(v))
+			}
+			code++
+		}
+	}
+}
+
+func (d *document) readSimpleWidths(f *font, fd dict, scale float64) {
+	first := d.integer(fd["FirstChar"], 0)
+	if widths, ok := d.resolve(fd["Widths"]).(array); ok {
+		for i, w := range widths {
+			f.widths[first+i] = d.number(w, 0) * scale
+		}
+	}
+	if desc := d.resolveDict(fd["FontDescriptor"]); desc != nil {
+		f.defaultWidth = d.number(desc["MissingWidth"], 0) * scale
+	}
+	if f.defaultWidth == 0 {
+		// Without widths, assume an average glyph so that gaps can still be told from advances.
+		f.defaultWidth = 0.5
+	}
+}
+
+// readCIDWidths reads a W array, whose entries are either "c [w1 w2 ...]" or "cfirst clast w".
+func (d *document) readCIDWidths(f *font, w object) {
+	items, _ := w.(array)
+	for i := 0; i < len(items); {
+		first := d.integer(items[i], -1)
+		if first < 0 || i+1 >= len(items) {
+			return
+		}
+		if list, ok := d.resolve(items[i+1]).(array); ok {
+			for j, width := range list {
+				f.widths[first+j] = d.number(width, 0) / 1000
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(items) {
+			return
+		}
+		last := d.integer(items[i+1], first)
+		width := d.number(items[i+2], 0) / 1000
+		for c := first; c <= last && c-first < 65536; c++ {
+			f.widths[c] = width
+		}
+		i += 3
+	}
+}
+
+// decode splits a shown string into glyphs.
+func (f *font) decode(s []byte) []glyph {
+	var glyphs []glyph
+	for len(s) > 0 {
+		n := f.codeLength(s)
+		code := 0
+		for _, b := range s[:n] {
+			code = code<<8 | int(b)
+		}
+		g := glyph{code: code, bytes: n, text: f.text(s[:n], code)}
+		g.width = f.width(code)
+		glyphs = append(glyphs, g)
+		s = s[n:]
+	}
+	return glyphs
+}
+
+func (f *font) codeLength(s []byte) int {
+	if !f.composite {
+		return 1
+	}
+	for _, r := range f.codespace {
+		n := len(r.low)
+		if n == 0 || n > len(s) {
+			continue
+		}
+		in := true
+		for i := 0; i < n; i++ {
+			if s[i] < r.low[i] || s[i] > r.high[i] {
+				in = false
+				break
+			}
+		}
+		if in {
+			return n
+		}
+	}
+	if len(s) < 2 {
+		return len(s)
+	}
+	return 2
+}
+
+func (f *font) text(code []byte, value int) string {
+	if t, ok := f.toUnicode[string(code)]; ok {
+		return t
+	}
+	switch {
+	case !f.composite:
+		return f.encoding[value]
+	case f.ucs2:
+		return utf16Text(code)
+	}
+	// A CID without a mapping to Unicode cannot be read.
+	return ""
+}
+
+func (f *font) width(code int) float64 {
+	if f.composite && !f.identity {
+		return f.defaultWidth
+	}
+	if w, ok := f.widths[code]; ok {
+		return w
+	}
+	return f.defaultWidth
+}
+
+// cmap is what text extraction needs from a CMap: its codespace and, for ToUnicode CMaps, what each code
+// stands for.
+type cmap struct {
+	codespace []codespaceRange
+	mappings  map[string]string
+}
+
+// maxRangeSize bounds a single bfrange, so that a hostile CMap cannot allocate without limit.
+const maxRangeSize = 1 << 16
+
+func parseCMap(data []byte) cmap {
+	c := cmap{mappings: make(map[string]string)}
+	l := &lexer{data: data}
+	var operands []object
+	for {
+		obj, err := l.readObject()
+		if err != nil {
+			break
+		}
+		op, ok := obj.(keyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch op {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				low, ok1 := operands[i].([]byte)
+				high, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 && len(low) == len(high) {
+					c.codespace = append(c.codespace, codespaceRange{low, high})
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok := operands[i].([]byte)
+				if !ok {
+					continue
+				}
+				switch dst := operands[i+1].(type) {
+				case []byte:
+					c.mappings[string(src)] = utf16Text(dst)
+				case name:
+					c.mappings[string(src)] = glyphText(string(dst))
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				low, ok1 := operands[i].([]byte)
+				high, ok2 := operands[i+1].([]byte)
+				if !ok1 || !ok2 || len(low) != len(high) || len(low) == 0 || len(low) > 4 {
+					continue
+				}
+				c.addRange(low, high, operands[i+2])
+			}
+		}
+		operands = operands[:0]
+	}
+	return c
+}
+
+// addRange maps the codes from low to high. A string destination is the text of low, the following codes
+// mapping to it with its last character incremented; an array lists the text of each code.
+func (c *cmap) addRange(low, high []byte, dst object) {
+	from, to := bytesValue(low), bytesValue(high)
+	if to < from || to-from >= maxRangeSize {
+		return
+	}
+	list, _ := dst.(array)
+	start, _ := dst.([]byte)
+	units := utf16.Decode(toUTF16(start))
+
+	for code := from; code <= to; code++ {
+		key := make([]byte, len(low))
+		for i, v := len(key)-1, code; i >= 0; i, v = i-1, v>>8 {
+			key[i] = byte(v)
+		}
+		offset := int(code - from)
+		switch {
+		case list != nil:
+			if offset < len(list) {
+				if b, ok := list[offset].([]byte); ok {
+					c.mappings[string(key)] = utf16Text(b)
+				}
+			}
+		case len(units) > 0:
+			text := append([]rune{}, units...)
+			text[len(text)-1] += rune(offset)
+			c.mappings[string(key)] = string(text)
+		}
+	}
+}
+
+func bytesValue(b []byte) uint32 {
+	var v uint32
+	for _, c := range b {
+		v = v<<8 | uint32(c)
+	}
+	return v
+}
+
+func toUTF16(b []byte) []uint16 {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return units
+}
+
+func utf16Text(b []byte) string {
+	return string(utf16.Decode(toUTF16(b)))
+}
+
+// glyphText returns the text a glyph name stands for, following the Adobe glyph naming conventions for
+// the names that are not in the list: "uniXXXX", "uXXXX" and single characters. Suffixes such as ".sc"
+// are dropped, and ligatures joined by '_' are spelt out.
+func glyphText(glyphName string) string {
+	if i := strings.IndexByte(glyphName, '.'); i > 0 {
+		glyphName = glyphName[:i]
+	}
+	if strings.Contains(glyphName, "_") {
+		var b strings.Builder
+		for _, part := range strings.Split(glyphName, "_") {
+			b.WriteString(glyphText(part))
+		}
+		return b.String()
+	}
+	if t, ok := glyphNames[glyphName]; ok {
+		return t
+	}
+	if strings.HasPrefix(glyphName, "uni") && len(glyphName) >= 7 && (len(glyphName)-3)%4 == 0 {
+		var units []uint16
+		for i := 3; i < len(glyphName); i += 4 {
+			v, err := strconv.ParseUint(glyphName[i:i+4], 16, 16)
+			if err != nil {
+				return ""
+			}
+			units = append(units, uint16(v))
+		}
+		return string(utf16.Decode(units))
+	}
+	if strings.HasPrefix(glyphName, "u") && len(glyphName) >= 5 && len(glyphName) <= 7 {
+		if v, err := strconv.ParseUint(glyphName[1:], 16, 32); err == nil {
+			return string(rune(v))
+		}
+	}
+	if len(glyphName) == 1 {
+		return glyphName
+	}
+	return ""
+}
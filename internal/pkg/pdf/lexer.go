@@ -0,0 +1,308 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// The PDF object types. Strings are kept as raw bytes, since their encoding depends on where they are
+// used; keywords are the bare words that are neither numbers nor true, false or null, such as content
+// stream operators.
+type (
+	object  interface{}
+	name    string
+	keyword string
+	array   []object
+	dict    map[name]object
+	ref     struct{ num, gen int }
+	stream  struct {
+		dict dict
+		raw  []byte
+	}
+)
+
+// lexer reads PDF objects from data. References ("12 0 R") are only recognized when refs is set, since
+// content streams have none and three numbers in a row there are just operands.
+type lexer struct {
+	data []byte
+	pos  int
+	refs bool
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func isRegular(c byte) bool {
+	return !isSpace(c) && !isDelimiter(c)
+}
+
+// skipSpace skips white space and comments.
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isSpace(c) {
+			l.pos++
+			continue
+		}
+		if c != '%' {
+			return
+		}
+		for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+			l.pos++
+		}
+	}
+}
+
+// readObject reads the next object, returning io.EOF at the end of the data. A stray closing bracket is
+// returned as a keyword so callers reading arrays and dictionaries can see it.
+func (l *lexer) readObject() (object, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, io.EOF
+	}
+
+	switch c := l.data[l.pos]; {
+	case c == '/':
+		return l.readName(), nil
+	case c == '(':
+		return l.readLiteralString(), nil
+	case c == '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return l.readDict()
+		}
+		return l.readHexString(), nil
+	case c == '[':
+		l.pos++
+		return l.readArray()
+	case c == '>' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '>':
+		l.pos += 2
+		return keyword(">>"), nil
+	case c == ']' || c == ')' || c == '>' || c == '{' || c == '}':
+		l.pos++
+		return keyword(c), nil
+	case c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.':
+		return l.readNumber(), nil
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && isRegular(l.data[l.pos]) {
+		l.pos++
+	}
+	switch word := string(l.data[start:l.pos]); word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	default:
+		return keyword(word), nil
+	}
+}
+
+func (l *lexer) readName() name {
+	l.pos++ // '/'
+	var b []byte
+	for l.pos < len(l.data) && isRegular(l.data[l.pos]) {
+		c := l.data[l.pos]
+		if c == '#' && l.pos+2 < len(l.data) {
+			if v, err := strconv.ParseUint(string(l.data[l.pos+1:l.pos+3]), 16, 8); err == nil {
+				b = append(b, byte(v))
+				l.pos += 3
+				continue
+			}
+		}
+		b = append(b, c)
+		l.pos++
+	}
+	return name(b)
+}
+
+func (l *lexer) readLiteralString() []byte {
+	l.pos++ // '('
+	var b []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return b
+			}
+		case '\r':
+			// An end of line in a string is a single newline, whichever form it has.
+			if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+				l.pos++
+			}
+			c = '\n'
+		case '\\':
+			if l.pos >= len(l.data) {
+				return b
+			}
+			c = l.data[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					v := int(c - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				}
+			}
+		}
+		b = append(b, c)
+	}
+	return b
+}
+
+func (l *lexer) readHexString() []byte {
+	l.pos++ // '<'
+	var b []byte
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; !isSpace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	if l.pos < len(l.data) {
+		l.pos++ // '>'
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	for i := 0; i+1 < len(digits); i += 2 {
+		if v, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8); err == nil {
+			b = append(b, byte(v))
+		}
+	}
+	return b
+}
+
+func (l *lexer) readArray() (array, error) {
+	var a array
+	for {
+		obj, err := l.readObject()
+		if err != nil {
+			return a, err
+		}
+		if obj == keyword("]") {
+			return a, nil
+		}
+		a = append(a, obj)
+	}
+}
+
+func (l *lexer) readDict() (dict, error) {
+	d := make(dict)
+	for {
+		key, err := l.readObject()
+		if err != nil {
+			return d, err
+		}
+		if key == keyword(">>") {
+			return d, nil
+		}
+		k, ok := key.(name)
+		if !ok {
+			return d, fmt.Errorf("dictionary key %v is not a name", key)
+		}
+		value, err := l.readObject()
+		if err != nil {
+			return d, err
+		}
+		if value == keyword(">>") {
+			return d, nil
+		}
+		d[k] = value
+	}
+}
+
+// readNumber reads an integer or a real. Malformed numbers, which some writers produce, read as 0.
+func (l *lexer) readNumber() object {
+	start := l.pos
+	for l.pos < len(l.data) && isRegular(l.data[l.pos]) {
+		l.pos++
+	}
+	text := string(l.data[start:l.pos])
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		if l.refs && n >= 0 {
+			if r, ok := l.readRef(int(n)); ok {
+				return r
+			}
+		}
+		return n
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f
+	}
+	return int64(0)
+}
+
+// readRef reads the rest of "num gen R" after num, leaving the position alone when it is not there.
+func (l *lexer) readRef(num int) (ref, bool) {
+	save := l.pos
+	l.skipSpace()
+	start := l.pos
+	for l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '9' {
+		l.pos++
+	}
+	if l.pos > start && l.pos < len(l.data) && isSpace(l.data[l.pos]) {
+		gen, _ := strconv.Atoi(string(l.data[start:l.pos]))
+		l.skipSpace()
+		if l.pos < len(l.data) && l.data[l.pos] == 'R' && (l.pos+1 == len(l.data) || !isRegular(l.data[l.pos+1])) {
+			l.pos++
+			return ref{num, gen}, true
+		}
+	}
+	l.pos = save
+	return ref{}, false
+}
+
+// hasKeyword tells whether word starts at the current position, as a whole token.
+func (l *lexer) hasKeyword(word string) bool {
+	if l.pos >= len(l.data) {
+		return false
+	}
+	rest := l.data[l.pos:]
+	return bytes.HasPrefix(rest, []byte(word)) && (len(rest) == len(word) || !isRegular(rest[len(word)]))
+}
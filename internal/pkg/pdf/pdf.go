@@ -0,0 +1,332 @@
+// Package pdf extracts the text of PDF files, page by page.
+//
+// It reads the objects of a file by scanning for them rather than through the cross-reference table,
+// which makes it tolerant of the damaged and incrementally updated files found in the wild, and it
+// interprets just enough of each page's content stream to recover the text in the order it is drawn.
+// Images are not read, so scanned pages come out empty.
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+var (
+	// ErrEncrypted is returned for encrypted files, whose text cannot be read without decrypting them.
+	ErrEncrypted = errors.New("pdf: file is encrypted")
+	// ErrInvalid is returned for data that is not a PDF file or has no pages.
+	ErrInvalid = errors.New("pdf: not a valid PDF file")
+)
+
+// maxPages bounds the page tree walk, which a malformed file could make loop through shared nodes.
+const maxPages = 10000
+
+// ExtractText returns the text of each page of the PDF file in data, in page order. Pages without text,
+// such as scanned ones, are empty strings.
+func ExtractText(data []byte) (texts []string, err error) {
+	// Files are untrusted, so a malformed one that trips up the parser is reported as invalid rather than
+	// taking the caller down with it.
+	defer func() {
+		if recover() != nil {
+			texts, err = nil, ErrInvalid
+		}
+	}()
+
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\n\f\r "), []byte("%PDF-")) {
+		return nil, ErrInvalid
+	}
+	d := parse(data)
+	if d.encrypted {
+		return nil, ErrEncrypted
+	}
+
+	pages := d.pages()
+	if len(pages) == 0 {
+		return nil, ErrInvalid
+	}
+	texts = make([]string, len(pages))
+	for i, p := range pages {
+		texts[i] = d.pageText(p)
+	}
+	return texts, nil
+}
+
+// document holds the objects of a file by object number. Where a number is defined more than once, as
+// incremental updates do, the last definition wins.
+type document struct {
+	objects   map[int]object
+	trailers  []dict
+	encrypted bool
+	fonts     map[int]*font
+}
+
+var objectPattern = regexp.MustCompile(`(\d+)[\t\n\f\r ]+(\d+)[\t\n\f\r ]+obj\b`)
+
+func parse(data []byte) *document {
+	d := &document{objects: make(map[int]object), fonts: make(map[int]*font)}
+
+	for pos := 0; pos < len(data); {
+		loc := objectPattern.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		start := pos + loc[0]
+		if start > 0 && isRegular(data[start-1]) {
+			// The number is the tail of a longer token.
+			pos = pos + loc[1]
+			continue
+		}
+		num, _ := strconv.Atoi(string(data[pos+loc[2] : pos+loc[3]]))
+		pos = d.readIndirect(data, num, pos+loc[1])
+	}
+	d.readTrailers(data)
+	d.expandObjectStreams()
+
+	for _, t := range d.trailers {
+		if _, ok := t["Encrypt"]; ok {
+			d.encrypted = true
+		}
+	}
+	return d
+}
+
+// readIndirect reads the body of object num starting at pos, returning where the object ends.
+func (d *document) readIndirect(data []byte, num, pos int) int {
+	l := &lexer{data: data, pos: pos, refs: true}
+	obj, err := l.readObject()
+	if err != nil && l.pos <= pos {
+		return pos + 1
+	}
+
+	l.skipSpace()
+	if head, ok := obj.(dict); ok && l.hasKeyword("stream") {
+		start := l.pos + len("stream")
+		if start < len(data) && data[start] == '\r' {
+			start++
+		}
+		if start < len(data) && data[start] == '\n' {
+			start++
+		}
+		raw, end := streamData(data, start, head["Length"])
+		s := &stream{dict: head, raw: raw}
+		d.objects[num] = s
+		if t, _ := head["Type"].(name); t == "XRef" {
+			d.trailers = append(d.trailers, head)
+		}
+		return end
+	}
+
+	d.objects[num] = obj
+	return l.pos
+}
+
+// streamData returns the data of a stream starting at start and where the stream ends. The Length entry
+// is used when it is a direct number that ends at "endstream"; otherwise the data runs up to the next
+// "endstream", less the end of line before it.
+func streamData(data []byte, start int, length object) ([]byte, int) {
+	endstream := []byte("endstream")
+	if n, ok := length.(int64); ok && n >= 0 && start+int(n) <= len(data) {
+		end := start + int(n)
+		rest := bytes.TrimLeft(data[end:], "\x00\t\n\f\r ")
+		if bytes.HasPrefix(rest, endstream) {
+			return data[start:end], len(data) - len(rest) + len(endstream)
+		}
+	}
+
+	i := bytes.Index(data[start:], endstream)
+	if i < 0 {
+		return data[start:], len(data)
+	}
+	raw := data[start : start+i]
+	raw = bytes.TrimSuffix(raw, []byte("\n"))
+	raw = bytes.TrimSuffix(raw, []byte("\r"))
+	return raw, start + i + len(endstream)
+}
+
+var trailerPattern = regexp.MustCompile(`trailer[\t\n\f\r ]*<<`)
+
+func (d *document) readTrailers(data []byte) {
+	for _, loc := range trailerPattern.FindAllIndex(data, -1) {
+		l := &lexer{data: data, pos: loc[1] - 2, refs: true}
+		if t, err := l.readObject(); err == nil {
+			if t, ok := t.(dict); ok {
+				d.trailers = append(d.trailers, t)
+			}
+		}
+	}
+}
+
+// expandObjectStreams adds the objects stored in object streams. Objects defined directly take
+// precedence, since the scan cannot tell which came later.
+func (d *document) expandObjectStreams() {
+	var streams []*stream
+	for _, obj := range d.objects {
+		if s, ok := obj.(*stream); ok {
+			if t, _ := s.dict["Type"].(name); t == "ObjStm" {
+				streams = append(streams, s)
+			}
+		}
+	}
+
+	for _, s := range streams {
+		data, err := d.decode(s)
+		if err != nil {
+			continue
+		}
+		n := d.integer(s.dict["N"], 0)
+		first := d.integer(s.dict["First"], 0)
+		if first < 0 || first > len(data) {
+			continue
+		}
+		header := &lexer{data: data[:first]}
+		for i := 0; i < n; i++ {
+			num, err1 := header.readObject()
+			offset, err2 := header.readObject()
+			if err1 != nil || err2 != nil {
+				break
+			}
+			num64, ok1 := num.(int64)
+			off64, ok2 := offset.(int64)
+			if !ok1 || !ok2 || first+int(off64) >= len(data) {
+				continue
+			}
+			if _, ok := d.objects[int(num64)]; ok {
+				continue
+			}
+			body := &lexer{data: data, pos: first + int(off64), refs: true}
+			if obj, err := body.readObject(); err == nil {
+				d.objects[int(num64)] = obj
+			}
+		}
+	}
+}
+
+// resolve follows references to the object they name; a reference to a missing object is null.
+func (d *document) resolve(obj object) object {
+	for i := 0; i < 32; i++ {
+		r, ok := obj.(ref)
+		if !ok {
+			return obj
+		}
+		obj = d.objects[r.num]
+	}
+	return nil
+}
+
+func (d *document) resolveDict(obj object) dict {
+	switch v := d.resolve(obj).(type) {
+	case dict:
+		return v
+	case *stream:
+		return v.dict
+	}
+	return nil
+}
+
+func (d *document) integer(obj object, fallback int) int {
+	switch v := d.resolve(obj).(type) {
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return fallback
+}
+
+func (d *document) number(obj object, fallback float64) float64 {
+	switch v := d.resolve(obj).(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return fallback
+}
+
+// root returns the document catalog, from the last trailer naming one or failing that from whichever
+// object says it is a catalog.
+func (d *document) root() dict {
+	for i := len(d.trailers) - 1; i >= 0; i-- {
+		if root := d.resolveDict(d.trailers[i]["Root"]); root != nil {
+			return root
+		}
+	}
+	for _, obj := range d.objects {
+		if c, ok := obj.(dict); ok {
+			if t, _ := c["Type"].(name); t == "Catalog" {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// page is a page dictionary with the resources it inherits from the page tree.
+type page struct {
+	dict      dict
+	resources dict
+}
+
+func (d *document) pages() []page {
+	root := d.root()
+	if root == nil {
+		return nil
+	}
+	var pages []page
+	visited := make(map[int]bool)
+	var walk func(node object, resources dict, depth int)
+	walk = func(node object, resources dict, depth int) {
+		if r, ok := node.(ref); ok {
+			if visited[r.num] {
+				return
+			}
+			visited[r.num] = true
+		}
+		n := d.resolveDict(node)
+		if n == nil || depth > 64 || len(pages) >= maxPages {
+			return
+		}
+		if res := d.resolveDict(n["Resources"]); res != nil {
+			resources = res
+		}
+
+		kids, isTree := d.resolve(n["Kids"]).(array)
+		if t, _ := n["Type"].(name); t == "Pages" || isTree && t != "Page" {
+			for _, kid := range kids {
+				walk(kid, resources, depth+1)
+			}
+			return
+		}
+		pages = append(pages, page{dict: n, resources: resources})
+	}
+	walk(root["Pages"], nil, 0)
+	return pages
+}
+
+// contents returns a page's content streams, decoded and joined.
+func (d *document) contents(p page) []byte {
+	var parts array
+	switch c := d.resolve(p.dict["Contents"]).(type) {
+	case *stream:
+		parts = array{c}
+	case array:
+		parts = c
+	}
+
+	var buf bytes.Buffer
+	for _, part := range parts {
+		s, ok := d.resolve(part).(*stream)
+		if !ok {
+			continue
+		}
+		data, err := d.decode(s)
+		if err != nil {
+			continue
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
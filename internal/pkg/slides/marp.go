@@ -0,0 +1,78 @@
+package slides
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+var (
+	// blockMarker matches the start of a line that Markdown would read as a heading, quote, list item or
+	// code fence rather than as text.
+	blockMarker = regexp.MustCompile(`^(#{1,6}(\s|$)|>|[-+*](\s|$)|` + "```" + `|~~~)`)
+	// orderedMarker matches the number that would start an ordered list.
+	orderedMarker = regexp.MustCompile(`^\d{1,9}([.)])(\s|$)`)
+)
+
+// RenderMarp returns the presentation as Markdown for Marp (https://marp.app). Slides are separated by
+// rules, cover slides use the centred "lead" class and speaker notes become HTML comments, which Marp
+// shows as presenter notes.
+func RenderMarp(p Presentation) string {
+	var b strings.Builder
+	b.WriteString("---\nmarp: true\ntheme: default\npaginate: true\n")
+	if p.Language != "" {
+		b.WriteString("lang: " + yamlString(p.Language) + "\n")
+	}
+	if p.Title != "" {
+		b.WriteString("title: " + yamlString(singleLine(p.Title)) + "\n")
+	}
+	b.WriteString("---\n")
+
+	for i, slide := range p.Slides {
+		if i > 0 {
+			b.WriteString("\n---\n")
+		}
+		b.WriteString("\n")
+		if slide.Cover {
+			b.WriteString("<!-- _class: lead -->\n<!-- _paginate: false -->\n\n")
+			b.WriteString("# " + markdownText(slide.Title) + "\n")
+			if subtitle := singleLine(slide.Subtitle); subtitle != "" {
+				b.WriteString("\n" + markdownText(subtitle) + "\n")
+			}
+		} else {
+			b.WriteString("## " + markdownText(slide.Title) + "\n")
+		}
+		if len(slide.Bullets) > 0 {
+			b.WriteString("\n")
+			for _, bullet := range slide.Bullets {
+				b.WriteString("- " + markdownText(bullet) + "\n")
+			}
+		}
+		if notes := strings.TrimSpace(slide.Notes); notes != "" {
+			// "-->" would end the comment early.
+			b.WriteString("\n<!--\n" + strings.ReplaceAll(notes, "-->", "-- >") + "\n-->\n")
+		}
+	}
+	return b.String()
+}
+
+// markdownText puts text on one line and escapes a leading marker that would change its meaning.
+func markdownText(text string) string {
+	text = singleLine(text)
+	if m := orderedMarker.FindStringSubmatchIndex(text); m != nil {
+		return text[:m[2]] + `\` + text[m[2]:]
+	}
+	if blockMarker.MatchString(text) {
+		return `\` + text
+	}
+	return text
+}
+
+// yamlString quotes s for the front matter; a JSON string is also a valid YAML string.
+func yamlString(s string) string {
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
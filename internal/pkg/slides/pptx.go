@@ -0,0 +1,386 @@
+package slides
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// A .pptx file is an Office Open XML package: a zip of XML parts tied together by relationship parts
+// (_rels/*.rels) and declared in [Content_Types].xml. WritePPTX writes the smallest package PowerPoint,
+// Keynote and LibreOffice open without repair: one slide master with a cover layout and a title and
+// content layout, a notes master, a theme shared in look by both masters, and one part per slide and
+// per speaker note.
+
+const (
+	xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+	nsA = `http://schemas.openxmlformats.org/drawingml/2006/main`
+	nsR = `http://schemas.openxmlformats.org/officeDocument/2006/relationships`
+	nsP = `http://schemas.openxmlformats.org/presentationml/2006/main`
+
+	pmlNamespaces = `xmlns:a="` + nsA + `" xmlns:r="` + nsR + `" xmlns:p="` + nsP + `"`
+
+	relTypeBase = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/`
+	contentBase = `application/vnd.openxmlformats-officedocument.`
+
+	// PPTXContentType is the MIME type of .pptx files.
+	PPTXContentType = contentBase + `presentationml.presentation`
+
+	// 16:9 slides, in EMU (914400 per inch).
+	slideWidth  = 12192000
+	slideHeight = 6858000
+)
+
+type part struct {
+	name string
+	body string
+}
+
+type relationship struct {
+	id, kind, target string
+}
+
+// WritePPTX writes the presentation as a PowerPoint file to w.
+func WritePPTX(w io.Writer, p Presentation) error {
+	created := p.Created
+	if created.IsZero() {
+		created = time.Now()
+	}
+	created = created.UTC().Truncate(time.Second)
+
+	parts := []part{
+		{"[Content_Types].xml", contentTypes(p)},
+		{"_rels/.rels", relationships(
+			relationship{"rId1", relTypeBase + "officeDocument", "ppt/presentation.xml"},
+			relationship{"rId2", "http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties", "docProps/core.xml"},
+			relationship{"rId3", relTypeBase + "extended-properties", "docProps/app.xml"},
+		)},
+		{"docProps/core.xml", coreProperties(p.Title, created)},
+		{"docProps/app.xml", appProperties(p)},
+		{"ppt/presentation.xml", presentation(p)},
+		{"ppt/_rels/presentation.xml.rels", presentationRelationships(p)},
+		{"ppt/presProps.xml", xmlHeader + `<p:presentationPr ` + pmlNamespaces + `/>`},
+		{"ppt/viewProps.xml", xmlHeader + `<p:viewPr ` + pmlNamespaces + `><p:normalViewPr><p:restoredLeft sz="15620"/>` +
+			`<p:restoredTop sz="94660"/></p:normalViewPr><p:gridSpacing cx="76200" cy="76200"/></p:viewPr>`},
+		{"ppt/tableStyles.xml", xmlHeader + `<a:tblStyleLst xmlns:a="` + nsA + `" def="{5C22544A-7EE6-4342-B048-85BDC9FD1C3A}"/>`},
+		{"ppt/theme/theme1.xml", theme},
+		{"ppt/theme/theme2.xml", theme},
+		{"ppt/slideMasters/slideMaster1.xml", slideMaster},
+		{"ppt/slideMasters/_rels/slideMaster1.xml.rels", relationships(
+			relationship{"rId1", relTypeBase + "slideLayout", "../slideLayouts/slideLayout1.xml"},
+			relationship{"rId2", relTypeBase + "slideLayout", "../slideLayouts/slideLayout2.xml"},
+			relationship{"rId3", relTypeBase + "theme", "../theme/theme1.xml"},
+		)},
+		{"ppt/slideLayouts/slideLayout1.xml", coverLayout},
+		{"ppt/slideLayouts/_rels/slideLayout1.xml.rels", relationships(
+			relationship{"rId1", relTypeBase + "slideMaster", "../slideMasters/slideMaster1.xml"},
+		)},
+		{"ppt/slideLayouts/slideLayout2.xml", contentLayout},
+		{"ppt/slideLayouts/_rels/slideLayout2.xml.rels", relationships(
+			relationship{"rId1", relTypeBase + "slideMaster", "../slideMasters/slideMaster1.xml"},
+		)},
+		{"ppt/notesMasters/notesMaster1.xml", notesMaster},
+		{"ppt/notesMasters/_rels/notesMaster1.xml.rels", relationships(
+			relationship{"rId1", relTypeBase + "theme", "../theme/theme2.xml"},
+		)},
+	}
+
+	for i, slide := range p.Slides {
+		n := i + 1
+		layout := "../slideLayouts/slideLayout2.xml"
+		if slide.Cover {
+			layout = "../slideLayouts/slideLayout1.xml"
+		}
+		slideRels := []relationship{{"rId1", relTypeBase + "slideLayout", layout}}
+		if hasNotes(slide) {
+			slideRels = append(slideRels, relationship{"rId2", relTypeBase + "notesSlide", fmt.Sprintf("../notesSlides/notesSlide%d.xml", n)})
+		}
+		parts = append(parts,
+			part{fmt.Sprintf("ppt/slides/slide%d.xml", n), slideXML(slide, p.Language)},
+			part{fmt.Sprintf("ppt/slides/_rels/slide%d.xml.rels", n), relationships(slideRels...)},
+		)
+		if hasNotes(slide) {
+			parts = append(parts,
+				part{fmt.Sprintf("ppt/notesSlides/notesSlide%d.xml", n), notesSlideXML(slide.Notes, p.Language)},
+				part{fmt.Sprintf("ppt/notesSlides/_rels/notesSlide%d.xml.rels", n), relationships(
+					relationship{"rId1", relTypeBase + "notesMaster", "../notesMasters/notesMaster1.xml"},
+					relationship{"rId2", relTypeBase + "slide", fmt.Sprintf("../slides/slide%d.xml", n)},
+				)},
+			)
+		}
+	}
+
+	zw := zip.NewWriter(w)
+	for _, part := range parts {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: part.name, Method: zip.Deflate, Modified: created})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, part.body); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func hasNotes(slide Slide) bool {
+	return strings.TrimSpace(slide.Notes) != ""
+}
+
+func contentTypes(p Presentation) string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	override := func(name, contentType string) {
+		fmt.Fprintf(&b, `<Override PartName="/%s" ContentType="%s"/>`, name, contentType)
+	}
+	override("ppt/presentation.xml", contentBase+"presentationml.presentation.main+xml")
+	override("ppt/presProps.xml", contentBase+"presentationml.presProps+xml")
+	override("ppt/viewProps.xml", contentBase+"presentationml.viewProps+xml")
+	override("ppt/tableStyles.xml", contentBase+"presentationml.tableStyles+xml")
+	override("ppt/theme/theme1.xml", contentBase+"theme+xml")
+	override("ppt/theme/theme2.xml", contentBase+"theme+xml")
+	override("ppt/slideMasters/slideMaster1.xml", contentBase+"presentationml.slideMaster+xml")
+	override("ppt/slideLayouts/slideLayout1.xml", contentBase+"presentationml.slideLayout+xml")
+	override("ppt/slideLayouts/slideLayout2.xml", contentBase+"presentationml.slideLayout+xml")
+	override("ppt/notesMasters/notesMaster1.xml", contentBase+"presentationml.notesMaster+xml")
+	for i, slide := range p.Slides {
+		override(fmt.Sprintf("ppt/slides/slide%d.xml", i+1), contentBase+"presentationml.slide+xml")
+		if hasNotes(slide) {
+			override(fmt.Sprintf("ppt/notesSlides/notesSlide%d.xml", i+1), contentBase+"presentationml.notesSlide+xml")
+		}
+	}
+	override("docProps/core.xml", "application/vnd.openxmlformats-package.core-properties+xml")
+	override("docProps/app.xml", contentBase+"extended-properties+xml")
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+func relationships(rels ...relationship) string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for _, rel := range rels {
+		fmt.Fprintf(&b, `<Relationship Id="%s" Type="%s" Target="%s"/>`, rel.id, rel.kind, rel.target)
+	}
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+func coreProperties(title string, created time.Time) string {
+	timestamp := created.Format(time.RFC3339)
+	return xmlHeader + `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" ` +
+		`xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" ` +
+		`xmlns:dcmitype="http://purl.org/dc/dcmitype/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">` +
+		`<dc:title>` + escape(singleLine(title)) + `</dc:title><dc:creator>QuillDeck</dc:creator>` +
+		`<dcterms:created xsi:type="dcterms:W3CDTF">` + timestamp + `</dcterms:created>` +
+		`<dcterms:modified xsi:type="dcterms:W3CDTF">` + timestamp + `</dcterms:modified>` +
+		`</cp:coreProperties>`
+}
+
+func appProperties(p Presentation) string {
+	notes := 0
+	for _, slide := range p.Slides {
+		if hasNotes(slide) {
+			notes++
+		}
+	}
+	return xmlHeader + `<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/extended-properties" ` +
+		`xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes">` +
+		fmt.Sprintf(`<Application>QuillDeck</Application><Slides>%d</Slides><Notes>%d</Notes>`, len(p.Slides), notes) +
+		`</Properties>`
+}
+
+func presentation(p Presentation) string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<p:presentation ` + pmlNamespaces + ` saveSubsetFonts="1">`)
+	b.WriteString(`<p:sldMasterIdLst><p:sldMasterId id="2147483648" r:id="rId1"/></p:sldMasterIdLst>`)
+	b.WriteString(`<p:notesMasterIdLst><p:notesMasterId r:id="rId2"/></p:notesMasterIdLst>`)
+	if len(p.Slides) > 0 {
+		b.WriteString(`<p:sldIdLst>`)
+		for i := range p.Slides {
+			// Slide IDs start at 256; relationship IDs leave room for the parts above.
+			fmt.Fprintf(&b, `<p:sldId id="%d" r:id="rId%d"/>`, 256+i, 10+i)
+		}
+		b.WriteString(`</p:sldIdLst>`)
+	}
+	fmt.Fprintf(&b, `<p:sldSz cx="%d" cy="%d"/><p:notesSz cx="6858000" cy="9144000"/>`, slideWidth, slideHeight)
+	b.WriteString(`</p:presentation>`)
+	return b.String()
+}
+
+func presentationRelationships(p Presentation) string {
+	rels := []relationship{
+		{"rId1", relTypeBase + "slideMaster", "slideMasters/slideMaster1.xml"},
+		{"rId2", relTypeBase + "notesMaster", "notesMasters/notesMaster1.xml"},
+		{"rId3", relTypeBase + "theme", "theme/theme1.xml"},
+		{"rId4", relTypeBase + "presProps", "presProps.xml"},
+		{"rId5", relTypeBase + "viewProps", "viewProps.xml"},
+		{"rId6", relTypeBase + "tableStyles", "tableStyles.xml"},
+	}
+	for i := range p.Slides {
+		rels = append(rels, relationship{fmt.Sprintf("rId%d", 10+i), relTypeBase + "slide", fmt.Sprintf("slides/slide%d.xml", i+1)})
+	}
+	return relationships(rels...)
+}
+
+func slideXML(slide Slide, language string) string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<p:sld ` + pmlNamespaces + `><p:cSld><p:spTree>` + groupProperties)
+	if slide.Cover {
+		b.WriteString(placeholder(2, "Title 1", `<p:ph type="ctrTitle"/>`, paragraphs([]string{singleLine(slide.Title)}, language)))
+		b.WriteString(placeholder(3, "Subtitle 2", `<p:ph type="subTitle" idx="1"/>`, paragraphs(lines(slide.Subtitle), language)))
+	} else {
+		b.WriteString(placeholder(2, "Title 1", `<p:ph type="title"/>`, paragraphs([]string{singleLine(slide.Title)}, language)))
+		bullets := make([]string, 0, len(slide.Bullets))
+		for _, bullet := range slide.Bullets {
+			if bullet = singleLine(bullet); bullet != "" {
+				bullets = append(bullets, bullet)
+			}
+		}
+		b.WriteString(placeholder(3, "Content 2", `<p:ph idx="1"/>`, paragraphs(bullets, language)))
+	}
+	b.WriteString(`</p:spTree></p:cSld><p:clrMapOvr><a:masterClrMapping/></p:clrMapOvr></p:sld>`)
+	return b.String()
+}
+
+func notesSlideXML(notes, language string) string {
+	return xmlHeader + `<p:notes ` + pmlNamespaces + `><p:cSld><p:spTree>` + groupProperties +
+		`<p:sp><p:nvSpPr><p:cNvPr id="2" name="Slide Image 1"/><p:cNvSpPr><a:spLocks noGrp="1" noRot="1" noChangeAspect="1"/></p:cNvSpPr>` +
+		`<p:nvPr><p:ph type="sldImg"/></p:nvPr></p:nvSpPr><p:spPr/></p:sp>` +
+		placeholder(3, "Notes 2", `<p:ph type="body" idx="1"/>`, paragraphs(lines(notes), language)) +
+		`</p:spTree></p:cSld><p:clrMapOvr><a:masterClrMapping/></p:clrMapOvr></p:notes>`
+}
+
+// placeholder is a text shape that takes its position and style from the layout placeholder ph.
+func placeholder(id int, name, ph, body string) string {
+	return fmt.Sprintf(`<p:sp><p:nvSpPr><p:cNvPr id="%d" name="%s"/><p:cNvSpPr><a:spLocks noGrp="1"/></p:cNvSpPr>`+
+		`<p:nvPr>%s</p:nvPr></p:nvSpPr><p:spPr/><p:txBody><a:bodyPr/><a:lstStyle/>%s</p:txBody></p:sp>`, id, name, ph, body)
+}
+
+// paragraphs returns one paragraph per text; a text body needs at least one, even if empty.
+func paragraphs(texts []string, language string) string {
+	lang := ""
+	if language != "" {
+		lang = ` lang="` + escape(language) + `"`
+	}
+	if len(texts) == 0 {
+		return `<a:p><a:endParaRPr` + lang + `/></a:p>`
+	}
+	var b strings.Builder
+	for _, text := range texts {
+		b.WriteString(`<a:p><a:r><a:rPr` + lang + ` dirty="0"/><a:t>` + escape(text) + `</a:t></a:r></a:p>`)
+	}
+	return b.String()
+}
+
+// lines splits text into its non-empty lines.
+func lines(text string) []string {
+	var result []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+func escape(s string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const groupProperties = `<p:nvGrpSpPr><p:cNvPr id="1" name=""/><p:cNvGrpSpPr/><p:nvPr/></p:nvGrpSpPr>` +
+	`<p:grpSpPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="0" cy="0"/><a:chOff x="0" y="0"/><a:chExt cx="0" cy="0"/></a:xfrm></p:grpSpPr>`
+
+const colorMap = `<p:clrMap bg1="lt1" tx1="dk1" bg2="lt2" tx2="dk2" accent1="accent1" accent2="accent2" accent3="accent3" ` +
+	`accent4="accent4" accent5="accent5" accent6="accent6" hlink="hlink" folHlink="folHlink"/>`
+
+// masterPlaceholder is a placeholder shape with a position, as masters and layouts define them.
+func masterPlaceholder(id int, name, ph string, x, y, cx, cy int, bodyPr, lstStyle string) string {
+	return fmt.Sprintf(`<p:sp><p:nvSpPr><p:cNvPr id="%d" name="%s"/><p:cNvSpPr><a:spLocks noGrp="1"/></p:cNvSpPr>`+
+		`<p:nvPr>%s</p:nvPr></p:nvSpPr><p:spPr><a:xfrm><a:off x="%d" y="%d"/><a:ext cx="%d" cy="%d"/></a:xfrm></p:spPr>`+
+		`<p:txBody>%s%s<a:p><a:endParaRPr lang="en-US"/></a:p></p:txBody></p:sp>`, id, name, ph, x, y, cx, cy, bodyPr, lstStyle)
+}
+
+var slideMaster = xmlHeader + `<p:sldMaster ` + pmlNamespaces + `><p:cSld>` +
+	`<p:bg><p:bgRef idx="1001"><a:schemeClr val="bg1"/></p:bgRef></p:bg><p:spTree>` + groupProperties +
+	masterPlaceholder(2, "Title Placeholder 1", `<p:ph type="title"/>`, 838200, 365125, 10515600, 1325563,
+		`<a:bodyPr anchor="ctr"><a:normAutofit/></a:bodyPr>`, `<a:lstStyle/>`) +
+	masterPlaceholder(3, "Text Placeholder 2", `<p:ph type="body" idx="1"/>`, 838200, 1825625, 10515600, 4351338,
+		`<a:bodyPr><a:normAutofit/></a:bodyPr>`, `<a:lstStyle/>`) +
+	`</p:spTree></p:cSld>` + colorMap +
+	`<p:sldLayoutIdLst><p:sldLayoutId id="2147483649" r:id="rId1"/><p:sldLayoutId id="2147483650" r:id="rId2"/></p:sldLayoutIdLst>` +
+	`<p:txStyles>` +
+	`<p:titleStyle><a:lvl1pPr algn="l"><a:defRPr sz="4000" b="1"><a:solidFill><a:schemeClr val="tx2"/></a:solidFill>` +
+	`<a:latin typeface="+mj-lt"/><a:ea typeface="+mj-ea"/><a:cs typeface="+mj-cs"/></a:defRPr></a:lvl1pPr></p:titleStyle>` +
+	`<p:bodyStyle><a:lvl1pPr marL="342900" indent="-342900"><a:spcBef><a:spcPts val="1000"/></a:spcBef>` +
+	`<a:buClr><a:schemeClr val="accent1"/></a:buClr><a:buFont typeface="Arial"/><a:buChar char="&#8226;"/>` +
+	`<a:defRPr sz="2400"><a:solidFill><a:schemeClr val="tx1"/></a:solidFill>` +
+	`<a:latin typeface="+mn-lt"/><a:ea typeface="+mn-ea"/><a:cs typeface="+mn-cs"/></a:defRPr></a:lvl1pPr></p:bodyStyle>` +
+	`<p:otherStyle><a:lvl1pPr><a:defRPr sz="1800"><a:solidFill><a:schemeClr val="tx1"/></a:solidFill></a:defRPr></a:lvl1pPr></p:otherStyle>` +
+	`</p:txStyles></p:sldMaster>`
+
+var coverLayout = xmlHeader + `<p:sldLayout ` + pmlNamespaces + ` type="title" preserve="1"><p:cSld name="Title Slide"><p:spTree>` +
+	groupProperties +
+	masterPlaceholder(2, "Title 1", `<p:ph type="ctrTitle"/>`, 1524000, 1122363, 9144000, 2387600,
+		`<a:bodyPr anchor="b"><a:normAutofit/></a:bodyPr>`, `<a:lstStyle><a:lvl1pPr algn="ctr"><a:defRPr sz="4800"/></a:lvl1pPr></a:lstStyle>`) +
+	masterPlaceholder(3, "Subtitle 2", `<p:ph type="subTitle" idx="1"/>`, 1524000, 3602038, 9144000, 1655762,
+		`<a:bodyPr><a:normAutofit/></a:bodyPr>`,
+		`<a:lstStyle><a:lvl1pPr marL="0" indent="0" algn="ctr"><a:buNone/><a:defRPr sz="2400"><a:solidFill><a:schemeClr val="tx2"/></a:solidFill></a:defRPr></a:lvl1pPr></a:lstStyle>`) +
+	`</p:spTree></p:cSld><p:clrMapOvr><a:masterClrMapping/></p:clrMapOvr></p:sldLayout>`
+
+var contentLayout = xmlHeader + `<p:sldLayout ` + pmlNamespaces + ` type="obj" preserve="1"><p:cSld name="Title and Content"><p:spTree>` +
+	groupProperties +
+	placeholder(2, "Title 1", `<p:ph type="title"/>`, `<a:p><a:endParaRPr lang="en-US"/></a:p>`) +
+	placeholder(3, "Content Placeholder 2", `<p:ph idx="1"/>`, `<a:p><a:endParaRPr lang="en-US"/></a:p>`) +
+	`</p:spTree></p:cSld><p:clrMapOvr><a:masterClrMapping/></p:clrMapOvr></p:sldLayout>`
+
+var notesMaster = xmlHeader + `<p:notesMaster ` + pmlNamespaces + `><p:cSld>` +
+	`<p:bg><p:bgRef idx="1001"><a:schemeClr val="bg1"/></p:bgRef></p:bg><p:spTree>` + groupProperties +
+	`<p:sp><p:nvSpPr><p:cNvPr id="2" name="Slide Image Placeholder 1"/><p:cNvSpPr><a:spLocks noGrp="1" noRot="1" noChangeAspect="1"/></p:cNvSpPr>` +
+	`<p:nvPr><p:ph type="sldImg" idx="2"/></p:nvPr></p:nvSpPr><p:spPr><a:xfrm><a:off x="685800" y="1143000"/><a:ext cx="5486400" cy="3086100"/></a:xfrm>` +
+	`<a:prstGeom prst="rect"><a:avLst/></a:prstGeom><a:noFill/><a:ln w="12700"><a:solidFill><a:prstClr val="black"/></a:solidFill></a:ln></p:spPr></p:sp>` +
+	masterPlaceholder(3, "Notes Placeholder 2", `<p:ph type="body" sz="quarter" idx="3"/>`, 685800, 4400550, 5486400, 3600450,
+		`<a:bodyPr/>`, `<a:lstStyle/>`) +
+	`</p:spTree></p:cSld>` + colorMap +
+	`<p:notesStyle><a:lvl1pPr marL="0" algn="l"><a:defRPr sz="1200"><a:solidFill><a:schemeClr val="tx1"/></a:solidFill>` +
+	`<a:latin typeface="+mn-lt"/><a:ea typeface="+mn-ea"/><a:cs typeface="+mn-cs"/></a:defRPr></a:lvl1pPr></p:notesStyle></p:notesMaster>`
+
+func solidFills(n int) string {
+	return strings.Repeat(`<a:solidFill><a:schemeClr val="phClr"/></a:solidFill>`, n)
+}
+
+var theme = xmlHeader + `<a:theme xmlns:a="` + nsA + `" name="QuillDeck"><a:themeElements>` +
+	`<a:clrScheme name="QuillDeck">` +
+	`<a:dk1><a:sysClr val="windowText" lastClr="000000"/></a:dk1><a:lt1><a:sysClr val="window" lastClr="FFFFFF"/></a:lt1>` +
+	`<a:dk2><a:srgbClr val="1F2937"/></a:dk2><a:lt2><a:srgbClr val="F3F4F6"/></a:lt2>` +
+	`<a:accent1><a:srgbClr val="4F46E5"/></a:accent1><a:accent2><a:srgbClr val="0EA5E9"/></a:accent2>` +
+	`<a:accent3><a:srgbClr val="10B981"/></a:accent3><a:accent4><a:srgbClr val="F59E0B"/></a:accent4>` +
+	`<a:accent5><a:srgbClr val="EF4444"/></a:accent5><a:accent6><a:srgbClr val="8B5CF6"/></a:accent6>` +
+	`<a:hlink><a:srgbClr val="4F46E5"/></a:hlink><a:folHlink><a:srgbClr val="7C3AED"/></a:folHlink>` +
+	`</a:clrScheme>` +
+	`<a:fontScheme name="QuillDeck">` +
+	`<a:majorFont><a:latin typeface="Calibri"/><a:ea typeface="Yu Gothic"/><a:cs typeface=""/></a:majorFont>` +
+	`<a:minorFont><a:latin typeface="Calibri"/><a:ea typeface="Yu Gothic"/><a:cs typeface=""/></a:minorFont>` +
+	`</a:fontScheme>` +
+	`<a:fmtScheme name="QuillDeck">` +
+	`<a:fillStyleLst>` + solidFills(3) + `</a:fillStyleLst>` +
+	`<a:lnStyleLst>` +
+	`<a:ln w="6350"><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:ln>` +
+	`<a:ln w="12700"><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:ln>` +
+	`<a:ln w="19050"><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:ln>` +
+	`</a:lnStyleLst>` +
+	`<a:effectStyleLst>` + strings.Repeat(`<a:effectStyle><a:effectLst/></a:effectStyle>`, 3) + `</a:effectStyleLst>` +
+	`<a:bgFillStyleLst>` + solidFills(3) + `</a:bgFillStyleLst>` +
+	`</a:fmtScheme></a:themeElements><a:objectDefaults/><a:extraClrSchemeLst/></a:theme>`
@@ -0,0 +1,30 @@
+// Package slides renders a simple presentation, made of title slides and bulleted slides with speaker
+// notes, as Marp Markdown or as a PowerPoint (.pptx) file.
+package slides
+
+import (
+	"strings"
+	"time"
+)
+
+// Presentation is the content of a slide deck, independent of its file format.
+type Presentation struct {
+	Title    string
+	Language string    // BCP 47 tag of the text, e.g. "ja"; optional
+	Created  time.Time // recorded in the file's properties; optional
+	Slides   []Slide
+}
+
+// Slide is either a cover slide, showing Title and Subtitle centred, or a title with bullets.
+type Slide struct {
+	Cover    bool
+	Title    string
+	Subtitle string
+	Bullets  []string
+	Notes    string
+}
+
+// singleLine joins the lines of text with spaces, since a title or bullet is one line in both formats.
+func singleLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,178 @@
+// Package textenc decodes uploaded text to normalized UTF-8, detecting the encoding it was written in:
+// Unicode with or without a byte order mark, or the legacy encodings of Japanese systems.
+package textenc
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Names of the encodings text is detected in. Other encodings that text declares itself in are named as
+// in the WHATWG Encoding Standard.
+const (
+	UTF8        = "UTF-8"
+	UTF16LE     = "UTF-16LE"
+	UTF16BE     = "UTF-16BE"
+	ShiftJIS    = "Shift_JIS"
+	EUCJP       = "EUC-JP"
+	ISO2022JP   = "ISO-2022-JP"
+	Windows1252 = "windows-1252"
+)
+
+// candidates are the encodings that text without a byte order mark may be in, in the order they are
+// preferred when they read equally well.
+var candidates = []struct {
+	name     string
+	encoding encoding.Encoding
+}{
+	{UTF8, unicode.UTF8},
+	{ShiftJIS, japanese.ShiftJIS},
+	{EUCJP, japanese.EUCJP},
+	{UTF16LE, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)},
+	{UTF16BE, unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)},
+	{Windows1252, charmap.Windows1252},
+}
+
+// sampleSize bounds the bytes read to tell the encoding of text.
+const sampleSize = 64 << 10
+
+// Decode returns data as normalized UTF-8 text, with the name of the encoding it was in. A byte order
+// mark settles the encoding, and so does data being valid UTF-8. Otherwise declared, the charset the data
+// claims to be in, such as that of an HTML page, is followed when it is known; without it, the encoding
+// that reads most like text, Japanese text in particular, is chosen. Bytes that are not valid in the
+// encoding become U+FFFD.
+func Decode(data []byte, declared string) (text, name string) {
+	var enc encoding.Encoding
+	switch {
+	case bytes.HasPrefix(data, []byte("\xef\xbb\xbf")):
+		name, enc = UTF8, unicode.UTF8
+	case bytes.HasPrefix(data, []byte("\xff\xfe")):
+		name, enc = UTF16LE, unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+	case bytes.HasPrefix(data, []byte("\xfe\xff")):
+		name, enc = UTF16BE, unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+	case isISO2022JP(data):
+		name, enc = ISO2022JP, japanese.ISO2022JP
+	case bytes.IndexByte(data, 0) < 0 && utf8.Valid(data):
+		name, enc = UTF8, unicode.UTF8
+	default:
+		if enc, name = declaredEncoding(declared); enc == nil {
+			name, enc = detect(data[:min(len(data), sampleSize)])
+		}
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		// The decoders replace what they cannot decode, so this does not happen; keep the bytes valid anyway.
+		decoded = bytes.ToValidUTF8(data, []byte("\uFFFD"))
+	}
+	return Normalize(string(decoded)), name
+}
+
+// declaredEncoding returns the encoding a charset label names, or nil for labels that name none.
+func declaredEncoding(label string) (encoding.Encoding, string) {
+	if strings.TrimSpace(label) == "" {
+		return nil, ""
+	}
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		return nil, ""
+	}
+	name, err := htmlindex.Name(enc)
+	if err != nil {
+		return nil, ""
+	}
+	if name == "utf-8" {
+		// Declared UTF-8 that is not valid UTF-8 says nothing about what it really is.
+		return nil, ""
+	}
+	return enc, canonicalName(name)
+}
+
+// canonicalName spells the encodings this package detects as it names them.
+func canonicalName(name string) string {
+	for _, c := range candidates {
+		if strings.EqualFold(c.name, name) {
+			return c.name
+		}
+	}
+	if strings.EqualFold(name, ISO2022JP) {
+		return ISO2022JP
+	}
+	return name
+}
+
+// isISO2022JP tells 7-bit text that switches to JIS X 0208 with escape sequences, as Japanese email does.
+func isISO2022JP(data []byte) bool {
+	sample := data[:min(len(data), sampleSize)]
+	for _, b := range sample {
+		if b >= 0x80 {
+			return false
+		}
+	}
+	return bytes.Contains(sample, []byte("\x1b$B")) || bytes.Contains(sample, []byte("\x1b$@"))
+}
+
+// detect returns the candidate encoding in which sample reads best.
+func detect(sample []byte) (string, encoding.Encoding) {
+	best, bestScore := candidates[len(candidates)-1], -1e9
+	for _, c := range candidates {
+		if (c.name == UTF16LE || c.name == UTF16BE) && len(sample)%2 != 0 {
+			continue
+		}
+		decoded, err := c.encoding.NewDecoder().Bytes(sample)
+		if err != nil {
+			continue
+		}
+		if score := readability(string(decoded)); score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best.name, best.encoding
+}
+
+// readability scores how much decoded text reads like text, per character: Japanese characters count
+// the most, kana above all, and undecodable bytes and control characters count against it.
+func readability(text string) float64 {
+	score, count := 0.0, 0
+	for _, r := range text {
+		count++
+		switch {
+		case r == utf8.RuneError:
+			score -= 10
+		case r == '\t' || r == '\n' || r == '\r' || r >= 0x20 && r < 0x7f:
+			score++
+		case r < 0x20 || r >= 0x7f && r < 0xa0:
+			score -= 2
+		case r >= 0x3040 && r <= 0x30ff: // hiragana and katakana
+			score += 2
+		case r >= 0x4e00 && r <= 0x9fff, r >= 0x3000 && r <= 0x303f, r >= 0xff01 && r <= 0xff5e:
+			// CJK ideographs, CJK punctuation, and full-width ASCII.
+			score++
+		case r >= 0xa0 && r <= 0xff, r >= 0xff61 && r <= 0xff9f:
+			// Latin-1 letters and symbols, and half-width katakana, which other encodings' bytes often
+			// read as.
+			score += 0.5
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return score / float64(count)
+}
+
+// Normalize puts text in the form documents are stored in: without a byte order mark, with "\n" line
+// breaks, and in Unicode Normalization Form C, so that the same characters are always the same bytes.
+func Normalize(text string) string {
+	text = strings.TrimPrefix(text, "\uFEFF")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	return norm.NFC.String(text)
+}
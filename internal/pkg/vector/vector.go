@@ -0,0 +1,113 @@
+package vector
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Encode packs v as little-endian float32s, four bytes per dimension.
+func Encode(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(x))
+	}
+	return buf
+}
+
+// Decode unpacks a vector written by Encode.
+func Decode(buf []byte) ([]float32, error) {
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("vector has %d bytes, not a multiple of 4", len(buf))
+	}
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v, nil
+}
+
+// Normalize scales v to unit length in place, so that the dot product of two normalized vectors is their
+// cosine similarity. The zero vector is left as is.
+func Normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
+
+// Dot returns the dot product of two vectors of the same length.
+func Dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// Scored is a value with its similarity score.
+type Scored[T any] struct {
+	Value T
+	Score float32
+}
+
+// TopK keeps the k highest-scoring values pushed into it, for brute-force nearest-neighbour search
+// without holding every candidate in memory.
+type TopK[T any] struct {
+	k     int
+	items scoredHeap[T]
+}
+
+func NewTopK[T any](k int) *TopK[T] {
+	return &TopK[T]{k: k}
+}
+
+func (t *TopK[T]) Push(value T, score float32) {
+	if t.k <= 0 {
+		return
+	}
+	if len(t.items) < t.k {
+		heap.Push(&t.items, Scored[T]{Value: value, Score: score})
+		return
+	}
+	if score > t.items[0].Score {
+		t.items[0] = Scored[T]{Value: value, Score: score}
+		heap.Fix(&t.items, 0)
+	}
+}
+
+// Results returns the kept values, best first.
+func (t *TopK[T]) Results() []Scored[T] {
+	items := make(scoredHeap[T], len(t.items))
+	copy(items, t.items)
+	results := make([]Scored[T], len(items))
+	for i := len(results) - 1; i >= 0; i-- {
+		results[i] = heap.Pop(&items).(Scored[T])
+	}
+	return results
+}
+
+// scoredHeap is a min-heap on Score, so the worst kept value is evicted first.
+type scoredHeap[T any] []Scored[T]
+
+func (h scoredHeap[T]) Len() int           { return len(h) }
+func (h scoredHeap[T]) Less(i, j int) bool { return h[i].Score < h[j].Score }
+func (h scoredHeap[T]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *scoredHeap[T]) Push(x any) { *h = append(*h, x.(Scored[T])) }
+
+func (h *scoredHeap[T]) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
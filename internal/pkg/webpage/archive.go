@@ -0,0 +1,101 @@
+package webpage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+// archivedPage is the page a web archive was saved from.
+type archivedPage struct {
+	source []byte
+	// location is the URL the page was saved from.
+	location string
+	// charset is the character encoding the archive gives for the page, empty when it gives none.
+	charset string
+}
+
+// readArchive reads an MHTML web archive: a MIME message whose root part is the page's HTML, with its
+// images and styles in the other parts. It returns nil, with no error, when data is not a MIME message,
+// as for HTML files.
+func readArchive(data []byte) (*archivedPage, error) {
+	trimmed := bytes.TrimLeft(data, " \t\r\n\ufeff")
+	if len(trimmed) == 0 || trimmed[0] == '<' {
+		return nil, nil
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(trimmed))
+	if err != nil {
+		return nil, nil
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") && mediaType != "text/html" {
+		return nil, nil
+	}
+
+	// Chrome records the page's URL for the whole archive; Internet Explorer only on its parts.
+	location := msg.Header.Get("Snapshot-Content-Location")
+	if mediaType == "text/html" {
+		return readPage(msg.Body, textproto.MIMEHeader(msg.Header), location)
+	}
+
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, fmt.Errorf("%w: the archive has no part boundary", ErrInvalid)
+	}
+	// The root part is the one named by the start parameter, or else the first HTML part.
+	start := params["start"]
+	parts := multipart.NewReader(msg.Body, boundary)
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: the archive has no HTML page", ErrInvalid)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if start != "" && part.Header.Get("Content-ID") != start || start == "" && partType != "text/html" {
+			continue
+		}
+		return readPage(part, part.Header, location)
+	}
+}
+
+// readPage reads the page in the body of a MIME part, falling back to the part's own location for the
+// URL it was saved from.
+func readPage(body io.Reader, header textproto.MIMEHeader, location string) (*archivedPage, error) {
+	source, err := readPart(body, header)
+	if err != nil {
+		return nil, err
+	}
+	if location == "" {
+		location = header.Get("Content-Location")
+	}
+	_, params, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	return &archivedPage{source: source, location: location, charset: params["charset"]}, nil
+}
+
+// readPart reads the body of a MIME part, decoding its transfer encoding.
+func readPart(body io.Reader, header textproto.MIMEHeader) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxPageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if len(data) > maxPageSize {
+		return nil, fmt.Errorf("%w: the page is larger than %d bytes", ErrInvalid, maxPageSize)
+	}
+	return data, nil
+}
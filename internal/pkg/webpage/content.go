@@ -0,0 +1,304 @@
+package webpage
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// The main content is found the way reader modes find it: after removing what is never content, each
+// paragraph of text adds to the score of the elements around it, and the element with the best score,
+// discounted by how much of its text is links, is the content. Pages that mark their content as the one
+// <article> or <main> are taken at their word.
+
+// removedTags are left out with everything in them: scripts, styles, media and form controls, which
+// have no text to read, and the parts of a page around its content.
+var removedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Iframe: true, atom.Object: true, atom.Embed: true, atom.Svg: true, atom.Math: true,
+	atom.Canvas: true, atom.Video: true, atom.Audio: true, atom.Img: true, atom.Picture: true,
+	atom.Input: true, atom.Button: true, atom.Select: true, atom.Textarea: true,
+	atom.Nav: true, atom.Aside: true, atom.Footer: true, atom.Dialog: true, atom.Menu: true,
+}
+
+// removedRoles are the ARIA roles of the parts of a page around its content.
+var removedRoles = map[string]bool{
+	"navigation": true, "banner": true, "contentinfo": true, "complementary": true, "search": true,
+	"menu": true, "menubar": true, "toolbar": true, "dialog": true, "alertdialog": true,
+}
+
+var (
+	// unlikelyNames match the classes and IDs of boilerplate, unless likelyNames match them too.
+	unlikelyNames = regexp.MustCompile(`(?i)\b(?:ads?|advert\w*|banner|breadcrumbs?|comments?|cookies?|footer|header|masthead|menu|modal|nav\w*|newsletter|pager|pagination|popup|promo\w*|related|share|sharing|sidebar|skip|social|sponsor\w*|subscribe|widget)\b`)
+	likelyNames   = regexp.MustCompile(`(?i)\b(?:article|blog|body|content|entry|main|post|story|text)\b`)
+	hiddenStyle   = regexp.MustCompile(`(?i)display\s*:\s*none|visibility\s*:\s*hidden`)
+)
+
+// prune removes what is never content from n. Headers are kept inside articles, where they hold the
+// article's title rather than the site's.
+func prune(n *html.Node, inArticle bool) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch {
+		case c.Type == html.CommentNode:
+			n.RemoveChild(c)
+		case c.Type != html.ElementNode:
+		case isBoilerplate(c) || c.DataAtom == atom.Header && !inArticle:
+			n.RemoveChild(c)
+		default:
+			prune(c, inArticle || c.DataAtom == atom.Article || c.DataAtom == atom.Main)
+		}
+		c = next
+	}
+}
+
+func isBoilerplate(n *html.Node) bool {
+	if removedTags[n.DataAtom] || n.Namespace != "" {
+		return true
+	}
+	if _, hidden := attr(n, "hidden"); hidden {
+		return true
+	}
+	if v, _ := attr(n, "aria-hidden"); v == "true" {
+		return true
+	}
+	if v, _ := attr(n, "style"); hiddenStyle.MatchString(v) {
+		return true
+	}
+	if v, _ := attr(n, "role"); removedRoles[strings.ToLower(strings.TrimSpace(v))] {
+		return true
+	}
+	switch n.DataAtom {
+	case atom.Body, atom.Article, atom.Main, atom.Table, atom.Tbody, atom.Tr, atom.Td, atom.Th:
+		return false
+	}
+	names := className(n)
+	return unlikelyNames.MatchString(names) && !likelyNames.MatchString(names)
+}
+
+// className returns the classes and ID of an element, for matching against names.
+func className(n *html.Node) string {
+	class, _ := attr(n, "class")
+	id, _ := attr(n, "id")
+	return class + " " + id
+}
+
+// mainContent returns the elements holding the main content of body, in document order.
+func mainContent(body *html.Node) []*html.Node {
+	prune(body, false)
+	if marked := markedContent(body); marked != nil {
+		clean(marked)
+		return []*html.Node{marked}
+	}
+
+	scores := scoreParagraphs(body)
+	var top *html.Node
+	walk(body.Parent, func(n *html.Node) bool {
+		if score, ok := scores[n]; ok {
+			scores[n] = score * (1 - linkDensity(n))
+			if top == nil || scores[n] > scores[top] {
+				top = n
+			}
+		}
+		return true
+	})
+	if top == nil {
+		return []*html.Node{body}
+	}
+
+	// Content split into several blocks, such as sections without a common container of their own, is
+	// gathered from the siblings of the best one that score well enough too, with the headings before them.
+	threshold := max(10, scores[top]*0.2)
+	var content []*html.Node
+	for c := top.Parent.FirstChild; c != nil; c = c.NextSibling {
+		if c == top || scores[c] >= threshold || isParagraph(c) && isReadable(c) {
+			content = append(content, c)
+		} else if isHeading(c) {
+			if next := nextElement(c); next != nil && (next == top || scores[next] >= threshold || isParagraph(next) && isReadable(next)) {
+				content = append(content, c)
+			}
+		}
+	}
+	for _, n := range content {
+		clean(n)
+	}
+	return content
+}
+
+// markedContent returns the element a page marks as its content: its only article, or else its only
+// main element, when either has text.
+func markedContent(body *html.Node) *html.Node {
+	var articles, mains []*html.Node
+	walk(body, func(n *html.Node) bool {
+		role, _ := attr(n, "role")
+		switch {
+		case n.DataAtom == atom.Article || role == "article":
+			articles = append(articles, n)
+		case n.DataAtom == atom.Main || role == "main":
+			mains = append(mains, n)
+		}
+		return true
+	})
+	for _, candidates := range [][]*html.Node{articles, mains} {
+		if len(candidates) == 1 && textLength(candidates[0]) >= minParagraphLength {
+			return candidates[0]
+		}
+	}
+	return nil
+}
+
+// minParagraphLength is the length of text, in characters, below which a paragraph does not count.
+const minParagraphLength = 25
+
+// scoreParagraphs returns the scores of the elements around paragraphs: each paragraph adds points for
+// its length and commas to its parent, half of them to its grandparent, and less to the elements further
+// out.
+func scoreParagraphs(body *html.Node) map[*html.Node]float64 {
+	scores := make(map[*html.Node]float64)
+	walk(body, func(n *html.Node) bool {
+		if !isParagraph(n) {
+			return true
+		}
+		text := strings.Join(strings.Fields(textContent(n)), " ")
+		length := utf8.RuneCountInString(text)
+		if length < minParagraphLength {
+			return true
+		}
+		score := 1 + float64(strings.Count(text, ",")+strings.Count(text, "、")+strings.Count(text, "，"))
+		score += float64(min(length/100, 3))
+
+		ancestor := n.Parent
+		for level := 0; level < 5 && ancestor != nil && ancestor.DataAtom != atom.Html; level++ {
+			if _, ok := scores[ancestor]; !ok {
+				scores[ancestor] = initialScore(ancestor)
+			}
+			switch level {
+			case 0:
+				scores[ancestor] += score
+			case 1:
+				scores[ancestor] += score / 2
+			default:
+				scores[ancestor] += score / float64(level*3)
+			}
+			ancestor = ancestor.Parent
+		}
+		return true
+	})
+	return scores
+}
+
+// initialScore is what an element scores before its paragraphs: a little for the elements that usually
+// hold prose, less for those that usually do not, and more or less by what its classes and ID say.
+func initialScore(n *html.Node) float64 {
+	score := 0.0
+	switch n.DataAtom {
+	case atom.Div, atom.Article, atom.Section, atom.Main:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form, atom.Address:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+	names := className(n)
+	if unlikelyNames.MatchString(names) {
+		score -= 25
+	}
+	if likelyNames.MatchString(names) {
+		score += 25
+	}
+	return score
+}
+
+// isParagraph tells the elements whose text is scored as a paragraph: paragraphs, preformatted text,
+// table cells, and divisions that hold text without other blocks.
+func isParagraph(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	switch n.DataAtom {
+	case atom.P, atom.Pre, atom.Td:
+		return true
+	case atom.Div, atom.Section:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && blockTags[c.DataAtom] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// isReadable tells whether a paragraph has enough text, few enough links, to be worth reading on its own.
+func isReadable(n *html.Node) bool {
+	return textLength(n) >= 80 && linkDensity(n) < 0.25
+}
+
+func isHeading(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		return n.Type == html.ElementNode
+	}
+	return false
+}
+
+func nextElement(n *html.Node) *html.Node {
+	for c := n.NextSibling; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			return c
+		}
+	}
+	return nil
+}
+
+// clean removes the blocks inside content that are mostly links, such as lists of related pages, unless
+// they hold most of its text, as a page that is an index of links does.
+func clean(content *html.Node) {
+	total := textLength(content)
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; {
+			next := c.NextSibling
+			if c.Type == html.ElementNode {
+				switch c.DataAtom {
+				case atom.Div, atom.Section, atom.Ul, atom.Ol, atom.Dl, atom.Table, atom.Form:
+					if textLength(c)*2 < total && linkDensity(c) > 0.5 {
+						n.RemoveChild(c)
+						c = next
+						continue
+					}
+				}
+				visit(c)
+			}
+			c = next
+		}
+	}
+	visit(content)
+}
+
+// textLength returns the number of characters in the text of n, with white space collapsed.
+func textLength(n *html.Node) int {
+	return utf8.RuneCountInString(strings.Join(strings.Fields(textContent(n)), " "))
+}
+
+// linkDensity returns the share of the text in n that is the text of links.
+func linkDensity(n *html.Node) float64 {
+	total := textLength(n)
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	walk(n, func(c *html.Node) bool {
+		if c.DataAtom == atom.A {
+			links += textLength(c)
+			return false
+		}
+		return true
+	})
+	return float64(links) / float64(total)
+}
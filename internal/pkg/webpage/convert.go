@@ -0,0 +1,283 @@
+package webpage
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github/k-tsurumaki/quilldeck/internal/pkg/markdown"
+)
+
+// blockTags are the elements that start a block of their own rather than running on with the text
+// around them.
+var blockTags = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true, atom.Blockquote: true, atom.Body: true,
+	atom.Caption: true, atom.Center: true, atom.Dd: true, atom.Details: true, atom.Dialog: true,
+	atom.Dir: true, atom.Div: true, atom.Dl: true, atom.Dt: true, atom.Fieldset: true,
+	atom.Figcaption: true, atom.Figure: true, atom.Footer: true, atom.Form: true, atom.H1: true,
+	atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true, atom.Header: true,
+	atom.Hgroup: true, atom.Hr: true, atom.Legend: true, atom.Li: true, atom.Main: true, atom.Menu: true,
+	atom.Nav: true, atom.Ol: true, atom.P: true, atom.Pre: true, atom.Section: true, atom.Summary: true,
+	atom.Table: true, atom.Tbody: true, atom.Td: true, atom.Tfoot: true, atom.Th: true, atom.Thead: true,
+	atom.Tr: true, atom.Ul: true,
+}
+
+// convert writes the content in nodes as Markdown: headings, lists, tables, preformatted text and
+// quotes as such, and other text as paragraphs. Links and emphasis are written as their text.
+func convert(nodes []*html.Node) string {
+	c := &converter{}
+	for _, n := range nodes {
+		c.node(n)
+	}
+	c.flush()
+	return c.md.String()
+}
+
+type converter struct {
+	md markdown.Writer
+	// text is the paragraph being gathered from the inline content of blocks.
+	text strings.Builder
+}
+
+// flush writes the paragraph gathered so far.
+func (c *converter) flush() {
+	c.md.Paragraph(c.text.String())
+	c.text.Reset()
+}
+
+func (c *converter) children(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.node(child)
+	}
+}
+
+func (c *converter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		c.text.WriteString(spaces(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		c.flush()
+		level, _ := strconv.Atoi(n.Data[1:])
+		c.md.Heading(level, strings.ReplaceAll(text(n), "\n", " "))
+	case atom.Br:
+		c.text.WriteString("\n")
+	case atom.Ul, atom.Ol:
+		c.flush()
+		c.list(n, 0)
+	case atom.Table:
+		c.flush()
+		if rows, ok := tableRows(n); ok {
+			c.md.Table(rows)
+		} else {
+			c.children(n)
+			c.flush()
+		}
+	case atom.Pre:
+		c.flush()
+		c.md.CodeBlock(preformatted(n))
+	case atom.Blockquote:
+		c.flush()
+		quote := &converter{}
+		quote.children(n)
+		quote.flush()
+		c.md.Quote(quote.md.String())
+	case atom.Head, atom.Title:
+	default:
+		if !blockTags[n.DataAtom] {
+			c.children(n)
+			return
+		}
+		c.flush()
+		c.children(n)
+		c.flush()
+	}
+}
+
+// list writes the items of a list and of the lists nested in them, a level further in.
+func (c *converter) list(n *html.Node, level int) {
+	numbered := n.DataAtom == atom.Ol
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode {
+			continue
+		}
+		switch child.DataAtom {
+		case atom.Li:
+			c.item(child, level, numbered)
+		case atom.Ul, atom.Ol:
+			c.list(child, level+1)
+		}
+	}
+}
+
+// item writes a list item as one line of text, followed by the lists nested in it.
+func (c *converter) item(li *html.Node, level int, numbered bool) {
+	var b strings.Builder
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			switch {
+			case child.Type == html.TextNode:
+				b.WriteString(spaces(child.Data))
+			case child.Type != html.ElementNode:
+			case child.DataAtom == atom.Ul || child.DataAtom == atom.Ol:
+				c.md.ListItem(level, numbered, b.String())
+				b.Reset()
+				c.list(child, level+1)
+			case child.DataAtom == atom.Br || blockTags[child.DataAtom]:
+				b.WriteString(" ")
+				visit(child)
+				b.WriteString(" ")
+			default:
+				visit(child)
+			}
+		}
+	}
+	visit(li)
+	c.md.ListItem(level, numbered, b.String())
+}
+
+// The most columns and rows a table cell is taken to span. Rows are bounded as browsers bound them;
+// columns closer, since every row of the table is written as wide as its widest.
+const (
+	maxColspan = 100
+	maxRowspan = 65534
+)
+
+// tableRows returns the text of a table's cells by row, with empty cells under those spanning several
+// columns or rows so that the columns stay in line. It returns false for tables used for layout, which
+// hold other tables or a single column.
+func tableRows(table *html.Node) ([][]string, bool) {
+	var rows [][]string
+	width := 0
+	nested := false
+	// covered holds, by column, how many more rows a cell spanning rows covers.
+	covered := make(map[int]int)
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			switch child.DataAtom {
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				visit(child)
+			case atom.Tr:
+				row := tableRow(child, covered)
+				width = max(width, len(row))
+				rows = append(rows, row)
+			}
+		}
+	}
+	visit(table)
+	walk(table, func(n *html.Node) bool {
+		nested = nested || n.DataAtom == atom.Table
+		return !nested
+	})
+	return rows, !nested && width > 1
+}
+
+func tableRow(tr *html.Node, covered map[int]int) []string {
+	var row []string
+	skipCovered := func() {
+		for covered[len(row)] > 0 {
+			covered[len(row)]--
+			row = append(row, "")
+		}
+	}
+	for cell := tr.FirstChild; cell != nil; cell = cell.NextSibling {
+		if cell.Type != html.ElementNode || cell.DataAtom != atom.Td && cell.DataAtom != atom.Th {
+			continue
+		}
+		skipCovered()
+		colspan := span(cell, "colspan", maxColspan)
+		rowspan := span(cell, "rowspan", maxRowspan)
+		for i := 0; i < colspan; i++ {
+			if i == 0 {
+				row = append(row, text(cell))
+			} else {
+				row = append(row, "")
+			}
+			if rowspan > 1 {
+				covered[len(row)-1] = rowspan - 1
+			}
+		}
+	}
+	skipCovered()
+	return row
+}
+
+// span returns the columns or rows a table cell spans, by its colspan or rowspan attribute.
+func span(cell *html.Node, key string, limit int) int {
+	v, _ := attr(cell, key)
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return min(n, limit)
+}
+
+// text returns the text in n as it reads: white space collapsed, with line breaks for <br> and around
+// blocks.
+func text(n *html.Node) string {
+	var b strings.Builder
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			switch {
+			case child.Type == html.TextNode:
+				b.WriteString(spaces(child.Data))
+			case child.Type != html.ElementNode:
+			case child.DataAtom == atom.Br:
+				b.WriteString("\n")
+			case blockTags[child.DataAtom]:
+				b.WriteString("\n")
+				visit(child)
+				b.WriteString("\n")
+			default:
+				visit(child)
+			}
+		}
+	}
+	visit(n)
+	return markdown.Inline(b.String())
+}
+
+// preformatted returns the text of a <pre> as it is, with line breaks for <br>.
+func preformatted(n *html.Node) string {
+	var b strings.Builder
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			switch {
+			case child.Type == html.TextNode:
+				b.WriteString(child.Data)
+			case child.Type == html.ElementNode && child.DataAtom == atom.Br:
+				b.WriteString("\n")
+			default:
+				visit(child)
+			}
+		}
+	}
+	visit(n)
+	return b.String()
+}
+
+// spaces turns the line breaks and tabs in HTML text into spaces, as browsers show them.
+func spaces(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '\n', '\r', '\t', '\f':
+			return ' '
+		}
+		return r
+	}, s)
+}
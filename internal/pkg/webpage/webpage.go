@@ -0,0 +1,204 @@
+// Package webpage extracts the main content of web pages saved as HTML files or as MHTML web archives,
+// as Markdown, along with the title and canonical URL the pages give themselves.
+package webpage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github/k-tsurumaki/quilldeck/internal/pkg/textenc"
+)
+
+// ErrInvalid is returned for web archives that cannot be read.
+var ErrInvalid = errors.New("webpage: not a readable web page")
+
+// maxPageSize bounds the HTML read from a web archive, whose parts may be compressed by their encoding.
+const maxPageSize = 64 << 20
+
+// Page is what is extracted from a saved web page.
+type Page struct {
+	// Title is the page's <title>, empty when it has none.
+	Title string
+	// CanonicalURL is the absolute URL the page names as canonical, or else the one a web archive was
+	// saved from. It is empty when neither is known.
+	CanonicalURL string
+	// Content is the main content of the page as Markdown, without the navigation, headers, footers and
+	// sidebars around it. It is empty for pages without text.
+	Content string
+	// Encoding is the character encoding the page was in, as textenc names it.
+	Encoding string
+}
+
+// Extract reads a web page saved as HTML or as an MHTML web archive, which it tells apart by content
+// rather than by file name. Pages not in UTF-8 are decoded from the charset they declare, in the
+// archive or in a <meta> tag, or else from the one they are detected in.
+func Extract(data []byte) (*Page, error) {
+	source, location, charset := data, "", ""
+	archived, err := readArchive(data)
+	if err != nil {
+		return nil, err
+	}
+	if archived != nil {
+		source, location, charset = archived.source, archived.location, archived.charset
+	}
+	if charset == "" {
+		charset = declaredCharset(source)
+	}
+	text, encoding := textenc.Decode(source, charset)
+	doc, err := html.Parse(strings.NewReader(text))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	page := &Page{Encoding: encoding}
+	base, err := url.Parse(location)
+	if err != nil {
+		base = &url.URL{}
+	}
+	var body *html.Node
+	var canonical, ogURL string
+	hasBase := false
+	walk(doc, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Title:
+			if page.Title == "" {
+				page.Title = strings.Join(strings.Fields(textContent(n)), " ")
+			}
+		case atom.Base:
+			// Only the first <base> with an href counts.
+			if href, ok := attr(n, "href"); ok && !hasBase {
+				if u, err := base.Parse(strings.TrimSpace(href)); err == nil {
+					base = u
+				}
+				hasBase = true
+			}
+		case atom.Link:
+			if rel, _ := attr(n, "rel"); canonical == "" && hasToken(rel, "canonical") {
+				canonical, _ = attr(n, "href")
+			}
+		case atom.Meta:
+			if property, _ := attr(n, "property"); ogURL == "" && strings.EqualFold(property, "og:url") {
+				ogURL, _ = attr(n, "content")
+			}
+		case atom.Body:
+			if body == nil {
+				body = n
+			}
+			return false
+		}
+		return true
+	})
+
+	for _, ref := range []string{canonical, ogURL, location} {
+		if page.CanonicalURL = absoluteURL(base, ref); page.CanonicalURL != "" {
+			break
+		}
+	}
+	if body != nil {
+		page.Content = convert(mainContent(body))
+	}
+	return page, nil
+}
+
+// prescanSize is how far into a page browsers look for the <meta> tag declaring its charset.
+const prescanSize = 1024
+
+// declaredCharset returns the charset an HTML page declares with <meta charset> or <meta http-equiv>
+// near its start, or "" when it declares none.
+func declaredCharset(data []byte) string {
+	z := html.NewTokenizer(bytes.NewReader(data[:min(len(data), prescanSize)]))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) != "meta" || !hasAttr {
+				continue
+			}
+			var charset, httpEquiv, content string
+			for more := true; more; {
+				var key, val []byte
+				key, val, more = z.TagAttr()
+				switch string(key) {
+				case "charset":
+					charset = string(val)
+				case "http-equiv":
+					httpEquiv = string(val)
+				case "content":
+					content = string(val)
+				}
+			}
+			if charset != "" {
+				return charset
+			}
+			if strings.EqualFold(httpEquiv, "content-type") {
+				if _, params, err := mime.ParseMediaType(content); err == nil && params["charset"] != "" {
+					return params["charset"]
+				}
+			}
+		}
+	}
+}
+
+// absoluteURL resolves ref against base, returning "" when it does not make an absolute URL.
+func absoluteURL(base *url.URL, ref string) string {
+	if ref = strings.TrimSpace(ref); ref == "" {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil || !u.IsAbs() || u.Host == "" && u.Scheme != "file" {
+		return ""
+	}
+	return u.String()
+}
+
+// walk calls visit for the elements in n in document order, going into an element's children only when
+// visit returns true.
+func walk(n *html.Node, visit func(*html.Node) bool) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && !visit(c) {
+			continue
+		}
+		walk(c, visit)
+	}
+}
+
+// attr returns the value of an element's attribute, and whether it has it.
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// hasToken tells whether a space-separated list of tokens, such as rel or class, holds token.
+func hasToken(list, token string) bool {
+	for _, t := range strings.Fields(list) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// textContent returns all the text in n as it is in the source.
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
@@ -0,0 +1,177 @@
+// Package ziparchive reads the files in an uploaded zip archive, within limits that keep a small archive
+// from expanding into more files or bytes than an upload could hold.
+package ziparchive
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github/k-tsurumaki/quilldeck/internal/pkg/textenc"
+)
+
+// Limits on the archives read. Archives past them are rejected as a whole rather than read in part.
+const (
+	MaxFiles     = 1000
+	MaxTotalSize = 256 << 20
+)
+
+// MaxFileSize bounds each file read, which is held in memory whole. Larger files are left out one by one.
+const MaxFileSize = 32 << 20
+
+var (
+	// ErrInvalid is returned for data that is not a zip archive.
+	ErrInvalid = errors.New("ziparchive: not a valid zip archive")
+	// ErrTooManyFiles is returned for archives holding more than MaxFiles files.
+	ErrTooManyFiles = errors.New("ziparchive: too many files")
+	// ErrTooLarge is returned for archives whose files add up to more than MaxTotalSize bytes.
+	ErrTooLarge = errors.New("ziparchive: the content is too large")
+	// ErrFileTooLarge is set on files larger than MaxFileSize.
+	ErrFileTooLarge = errors.New("ziparchive: the file is too large")
+	// ErrUnsafePath is set on files whose path leads out of the archive, such as "../../etc/passwd", and on
+	// symbolic links.
+	ErrUnsafePath = errors.New("ziparchive: unsafe path")
+)
+
+// File is a file in an archive.
+type File struct {
+	// Name is the file's slash-separated path in the archive.
+	Name string
+	Data []byte
+	// Err is set, and Data left out, for files that are not read because their path is unsafe or they are
+	// too large.
+	Err error
+}
+
+// Read returns the files in a zip archive in the order they are stored, leaving out directories and the
+// hidden files that operating systems add, such as ".DS_Store" and "__MACOSX/". Names that are not UTF-8,
+// as Windows writes them in Japanese, are decoded.
+func Read(data []byte) ([]File, error) {
+	var files []File
+	err := Walk(bytes.NewReader(data), int64(len(data)), func(f File) error {
+		files = append(files, f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Walk calls fn with each of the files Read would return, in turn, inflating a file only once fn has
+// returned for the one before, so that no more than one file is held in memory. Archives past the limits
+// their directory declares are rejected before fn is called; archives whose files turn out larger than
+// declared fail part way through. Walk stops at the first error fn returns and returns it.
+func Walk(r io.ReaderAt, size int64, fn func(File) error) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	entries := make([]*zip.File, 0, len(zr.File))
+	var declared uint64
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || isHidden(f.Name) {
+			continue
+		}
+		if len(entries) == MaxFiles {
+			return fmt.Errorf("%w: more than %d", ErrTooManyFiles, MaxFiles)
+		}
+		// The sizes an archive declares may be false, so they only reject archives early; reading is
+		// limited by what is actually inflated.
+		if f.UncompressedSize64 > MaxTotalSize-declared {
+			return ErrTooLarge
+		}
+		declared += f.UncompressedSize64
+		entries = append(entries, f)
+	}
+
+	var read int64
+	for _, f := range entries {
+		name, ok := safeName(f.Name)
+		if !ok || f.Mode()&fs.ModeSymlink != 0 {
+			if err := fn(File{Name: decodeName(f.Name), Err: ErrUnsafePath}); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.UncompressedSize64 > MaxFileSize {
+			if err := fn(File{Name: name, Err: ErrFileTooLarge}); err != nil {
+				return err
+			}
+			continue
+		}
+		content, err := readFile(f, min(MaxFileSize, MaxTotalSize-read))
+		if err != nil {
+			return err
+		}
+		read += int64(len(content))
+		if err := fn(File{Name: name, Data: content}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readFile inflates a file, failing once more than limit bytes come out of it.
+func readFile(f *zip.File, limit int64) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalid, f.Name, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalid, f.Name, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
+
+// safeName returns the cleaned path of a file in an archive, or false for paths that are absolute or climb
+// out of the archive with "..". Windows' backslashes are taken as separators, once the name is decoded:
+// in Shift_JIS, the byte of a backslash is also the second byte of characters such as "表".
+func safeName(name string) (string, bool) {
+	name = strings.ReplaceAll(decodeName(name), `\`, "/")
+	if strings.HasPrefix(name, "/") || len(name) >= 2 && name[1] == ':' {
+		return "", false
+	}
+	for _, element := range strings.Split(name, "/") {
+		if element == ".." {
+			return "", false
+		}
+	}
+	name = path.Clean(name)
+	if name == "." {
+		return "", false
+	}
+	return name, true
+}
+
+// decodeName turns a file name that is not UTF-8 into UTF-8, detecting its encoding as for text.
+func decodeName(name string) string {
+	if utf8.ValidString(name) {
+		return textenc.Normalize(name)
+	}
+	decoded, _ := textenc.Decode([]byte(name), "")
+	return decoded
+}
+
+// isHidden tells the files that archivers and file managers add without users seeing them.
+func isHidden(name string) bool {
+	name = strings.ReplaceAll(decodeName(name), `\`, "/")
+	if strings.HasPrefix(name, "__MACOSX/") {
+		return true
+	}
+	base := path.Base(name)
+	return strings.HasPrefix(base, ".") && base != ".."
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+func TestNewArtifact(t *testing.T) {
+	artifact := models.NewArtifact(uuid.New(), uuid.New(), models.ArtifactKindDiagram, "flow.mmd", "text/plain", []byte("abc"))
+
+	assert.Equal(t, int64(3), artifact.Size)
+	// SHA-256 of "abc"
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", artifact.Checksum)
+	assert.NotNil(t, artifact.Params)
+
+	artifact.StorageKey = "artifacts/" + artifact.ID.String()
+	assert.NoError(t, artifact.Validate())
+}
+
+func TestArtifact_Validate(t *testing.T) {
+	newArtifact := func(change func(a *models.Artifact)) *models.Artifact {
+		artifact := models.NewArtifact(uuid.New(), uuid.New(), models.ArtifactKindDeck, "deck.pptx", "application/octet-stream", []byte("PK"))
+		artifact.StorageKey = "artifacts/deck"
+		change(artifact)
+		return artifact
+	}
+
+	tests := []struct {
+		name     string
+		artifact *models.Artifact
+		errMsg   string
+	}{
+		{"empty user_id", newArtifact(func(a *models.Artifact) { a.UserID = uuid.Nil }), "user_id is required"},
+		{"empty document_id", newArtifact(func(a *models.Artifact) { a.DocumentID = uuid.Nil }), "document_id is required"},
+		{"unknown kind", newArtifact(func(a *models.Artifact) { a.Kind = "video" }), "kind must be"},
+		{"empty name", newArtifact(func(a *models.Artifact) { a.Name = "" }), "name is required"},
+		{"empty mime_type", newArtifact(func(a *models.Artifact) { a.MIMEType = "" }), "mime_type is required"},
+		{"empty storage_key", newArtifact(func(a *models.Artifact) { a.StorageKey = "" }), "storage_key is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.artifact.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestArtifact_Previewable(t *testing.T) {
+	for mimeType, want := range map[string]bool{
+		"text/plain; charset=utf-8": true,
+		"text/markdown":             true,
+		"image/png":                 true,
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation": false,
+		"application/pdf": false,
+	} {
+		artifact := &models.Artifact{MIMEType: mimeType}
+		assert.Equal(t, want, artifact.Previewable(), mimeType)
+	}
+}
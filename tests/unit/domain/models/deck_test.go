@@ -0,0 +1,49 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+func TestNewDeck(t *testing.T) {
+	documentID := uuid.New()
+	slides := []models.Slide{{Kind: models.SlideKindTitle, Title: "Report"}}
+
+	deck := models.NewDeck(documentID, "Report", slides)
+
+	assert.Equal(t, documentID, deck.DocumentID)
+	assert.Equal(t, slides, deck.Slides)
+	assert.Equal(t, models.DefaultDeckSlides, deck.SlideCount)
+	assert.Equal(t, models.DefaultSummaryLanguage, deck.Language)
+	assert.False(t, deck.CreatedAt.IsZero())
+	assert.NoError(t, deck.Validate())
+}
+
+func TestDeck_Validate(t *testing.T) {
+	documentID := uuid.New()
+	valid := []models.Slide{{Kind: models.SlideKindTitle, Title: "Report"}}
+
+	tests := []struct {
+		name   string
+		deck   *models.Deck
+		errMsg string
+	}{
+		{"empty document_id", &models.Deck{Title: "Report", Slides: valid}, "document_id is required"},
+		{"empty title", &models.Deck{DocumentID: documentID, Slides: valid}, "title is required"},
+		{"no slides", &models.Deck{DocumentID: documentID, Title: "Report"}, "at least one slide"},
+		{"unknown kind", &models.Deck{DocumentID: documentID, Title: "Report", Slides: []models.Slide{{Kind: "chart", Title: "x"}}}, "slide kind"},
+		{"untitled slide", &models.Deck{DocumentID: documentID, Title: "Report", Slides: []models.Slide{{Kind: models.SlideKindPoints}}}, "needs a title"},
+		{"too many slides requested", &models.Deck{DocumentID: documentID, Title: "Report", Slides: valid, DeckOptions: models.DeckOptions{SlideCount: 21}}, "slide_count"},
+		{"invalid language", &models.Deck{DocumentID: documentID, Title: "Report", Slides: valid, DeckOptions: models.DeckOptions{Language: "日本語"}}, "language"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.deck.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
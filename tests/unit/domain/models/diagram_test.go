@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+func TestDiagramOptions_WithDefaults(t *testing.T) {
+	opts := models.DiagramOptions{}.WithDefaults()
+
+	assert.Equal(t, models.DiagramKinds, opts.Kinds)
+	assert.Equal(t, models.DiagramSyntaxMermaid, opts.Syntax)
+	assert.Equal(t, models.DefaultSummaryLanguage, opts.Language)
+	assert.NoError(t, opts.Validate())
+}
+
+func TestDiagram_Validate(t *testing.T) {
+	documentID := uuid.New()
+
+	assert.NoError(t, models.NewDiagram(documentID, models.DiagramKindMindmap, models.DiagramSyntaxPlantUML, "Plan", "@startmindmap\n* Plan\n@endmindmap").Validate())
+
+	tests := []struct {
+		name    string
+		diagram *models.Diagram
+		errMsg  string
+	}{
+		{"empty document_id", models.NewDiagram(uuid.Nil, models.DiagramKindFlowchart, models.DiagramSyntaxMermaid, "Flow", "flowchart TD"), "document_id is required"},
+		{"unknown kind", models.NewDiagram(documentID, "gantt", models.DiagramSyntaxMermaid, "Flow", "gantt"), "kind must be"},
+		{"unknown syntax", models.NewDiagram(documentID, models.DiagramKindFlowchart, "dot", "Flow", "digraph {}"), "syntax must be"},
+		{"empty title", models.NewDiagram(documentID, models.DiagramKindFlowchart, models.DiagramSyntaxMermaid, "", "flowchart TD"), "title is required"},
+		{"empty source", models.NewDiagram(documentID, models.DiagramKindFlowchart, models.DiagramSyntaxMermaid, "Flow", ""), "source is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.diagram.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
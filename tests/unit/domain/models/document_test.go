@@ -75,6 +75,17 @@ func TestDocument_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "content is required",
 		},
+		{
+			name:    "pdf with pages",
+			doc:     &models.Document{UserID: userID, Title: "a.pdf", Content: "one\n\ntwo", Type: models.DocumentTypePDF, Pages: []models.PageSpan{{Number: 1, Start: 0, End: 3}, {Number: 2, Start: 5, End: 8}}},
+			wantErr: false,
+		},
+		{
+			name:    "pages out of order",
+			doc:     &models.Document{UserID: userID, Title: "a.pdf", Content: "one\n\ntwo", Type: models.DocumentTypePDF, Pages: []models.PageSpan{{Number: 2, Start: 5, End: 8}, {Number: 1, Start: 0, End: 3}}},
+			wantErr: true,
+			errMsg:  "pages must be numbered from 1 and in order",
+		},
 		{
 			name: "invalid type",
 			doc: &models.Document{
@@ -112,4 +123,57 @@ func TestDocument_MarkProcessed(t *testing.T) {
 	assert.True(t, doc.IsProcessed())
 	assert.NotNil(t, doc.ProcessedAt)
 	assert.False(t, doc.ProcessedAt.IsZero())
-}
\ No newline at end of file
+}
+
+func TestJoinPages(t *testing.T) {
+	content, pages := models.JoinPages([]string{"第一章", "", "Second page"})
+
+	assert.Equal(t, "第一章\n\n\n\nSecond page", content)
+	assert.Equal(t, []models.PageSpan{
+		{Number: 1, Start: 0, End: 3},
+		{Number: 2, Start: 5, End: 5},
+		{Number: 3, Start: 7, End: 18},
+	}, pages)
+
+	doc := models.NewDocument(uuid.New(), "book.pdf", content, models.DocumentTypePDF, int64(len(content)))
+	doc.Pages = pages
+	assert.NoError(t, doc.Validate())
+	assert.Equal(t, 1, doc.PageAt(0))
+	assert.Equal(t, 1, doc.PageAt(4), "the break between pages counts as the earlier page")
+	assert.Equal(t, 3, doc.PageAt(10))
+	assert.Equal(t, "[Page 1]\n第一章\n\n[Page 2]\n\n\n[Page 3]\nSecond page", doc.PagedContent())
+
+	doc.UpdateContent("edited")
+	assert.Nil(t, doc.Pages)
+	assert.Equal(t, 0, doc.PageAt(0))
+	assert.Equal(t, "edited", doc.PagedContent())
+}
+
+func TestDocumentTypeForFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     models.DocumentType
+		ok       bool
+	}{
+		{"notes.txt", models.DocumentTypeTXT, true},
+		{"README.MD", models.DocumentTypeMD, true},
+		{"議事録.docx", models.DocumentTypeDOCX, true},
+		{"plan.v2.pptx", models.DocumentTypePPTX, true},
+		{"budget.xlsx", models.DocumentTypeXLSX, true},
+		{"wiki.html", models.DocumentTypeHTML, true},
+		{"index.HTM", models.DocumentTypeHTML, true},
+		{"portal.mhtml", models.DocumentTypeHTML, true},
+		{"saved.mht", models.DocumentTypeHTML, true},
+		{"legacy.doc", "", false},
+		{"docx", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			docType, ok := models.DocumentTypeForFilename(tt.filename)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.want, docType)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ func TestNewSummary(t *testing.T) {
 	assert.NotNil(t, summary)
 	assert.Equal(t, documentID, summary.DocumentID)
 	assert.Equal(t, content, summary.Content)
+	assert.Equal(t, models.SummaryStatusCompleted, summary.Status)
 	assert.NotEqual(t, "", summary.ID.String())
 	assert.False(t, summary.CreatedAt.IsZero())
 	assert.False(t, summary.UpdatedAt.IsZero())
@@ -54,6 +55,24 @@ func TestSummary_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "content is required",
 		},
+		{
+			name: "failed summary without content",
+			summary: &models.Summary{
+				DocumentID: documentID,
+				Status:     models.SummaryStatusFailed,
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown status",
+			summary: &models.Summary{
+				DocumentID: documentID,
+				Content:    "Content",
+				Status:     "done",
+			},
+			wantErr: true,
+			errMsg:  "status must be one of",
+		},
 	}
 
 	for _, tt := range tests {
@@ -78,4 +97,48 @@ func TestSummary_UpdateContent(t *testing.T) {
 
 	assert.Equal(t, newContent, summary.Content)
 	assert.True(t, summary.UpdatedAt.After(originalUpdatedAt))
-}
\ No newline at end of file
+}
+
+func TestSummaryOptions_WithDefaults(t *testing.T) {
+	opts := models.SummaryOptions{}.WithDefaults()
+	assert.Equal(t, models.SummaryLengthMedium, opts.Length)
+	assert.Equal(t, models.SummaryStyleBusiness, opts.Style)
+	assert.Equal(t, "ja", opts.Language)
+
+	// An explicit target replaces the length preset instead of being combined with it.
+	opts = models.SummaryOptions{TargetWords: 80}.WithDefaults()
+	assert.Empty(t, opts.Length)
+	length, words := opts.TargetLength()
+	assert.Equal(t, 80, length)
+	assert.True(t, words)
+}
+
+func TestSummaryOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   models.SummaryOptions
+		errMsg string
+	}{
+		{name: "zero value", opts: models.SummaryOptions{}},
+		{name: "preset", opts: models.SummaryOptions{Length: models.SummaryLengthShort, Style: models.SummaryStyleBullets, Language: "en-US"}},
+		{name: "target chars", opts: models.SummaryOptions{TargetChars: 400}},
+		{name: "unknown preset", opts: models.SummaryOptions{Length: "huge"}, errMsg: "length must be one of"},
+		{name: "preset and target", opts: models.SummaryOptions{Length: models.SummaryLengthShort, TargetChars: 100}, errMsg: "specify only one of"},
+		{name: "target chars too small", opts: models.SummaryOptions{TargetChars: 5}, errMsg: "target_chars must be between"},
+		{name: "target words too large", opts: models.SummaryOptions{TargetWords: 5000}, errMsg: "target_words must be between"},
+		{name: "unknown style", opts: models.SummaryOptions{Style: "poem"}, errMsg: "style must be one of"},
+		{name: "invalid language", opts: models.SummaryOptions{Language: "Japanese please"}, errMsg: "language must be a language code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.errMsg != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+	"github/k-tsurumaki/quilldeck/tests/unit/domain/service/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyService_CreateKey(t *testing.T) {
+	userID := uuid.New()
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		keyName   string
+		scopes    []string
+		expiresAt *time.Time
+		wantErr   bool
+	}{
+		{
+			name:    "valid key",
+			keyName: "ci",
+			scopes:  []string{models.ScopeDocumentsWrite, models.ScopeSummariesGenerate, models.ScopeDocumentsWrite},
+		},
+		{
+			name:    "unknown scope",
+			keyName: "ci",
+			scopes:  []string{"admin:everything"},
+			wantErr: true,
+		},
+		{
+			name:    "no scopes",
+			keyName: "ci",
+			wantErr: true,
+		},
+		{
+			name:      "expiry in the past",
+			keyName:   "ci",
+			scopes:    []string{models.ScopeDocumentsRead},
+			expiresAt: &past,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyRepo := new(mocks.MockAPIKeyRepository)
+			if !tt.wantErr {
+				keyRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.APIKey")).Return(nil)
+			}
+
+			apiKeyService := service.NewAPIKeyService(keyRepo, new(mocks.MockUserRepository))
+			key, plaintext, err := apiKeyService.CreateKey(context.Background(), userID, tt.keyName, tt.scopes, tt.expiresAt)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "VALIDATION_ERROR")
+				assert.Nil(t, key)
+			} else {
+				require.NoError(t, err)
+				assert.True(t, strings.HasPrefix(plaintext, key.Prefix+"_"))
+				assert.Len(t, key.Prefix, len(service.APIKeyPrefix)+16)
+				assert.True(t, service.IsAPIKey(plaintext))
+				assert.NotContains(t, key.KeyHash, plaintext)
+				assert.Equal(t, []string{models.ScopeDocumentsWrite, models.ScopeSummariesGenerate}, key.Scopes)
+			}
+
+			keyRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Email: "ci@example.com", Name: "CI"}
+
+	// Create a real key through the service so the stored hash matches the plaintext.
+	keyRepo := new(mocks.MockAPIKeyRepository)
+	keyRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.APIKey")).Return(nil)
+	stored, plaintext, err := service.NewAPIKeyService(keyRepo, new(mocks.MockUserRepository)).
+		CreateKey(context.Background(), user.ID, "ci", []string{models.ScopeDocumentsWrite}, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		plaintext string
+		key       func() *models.APIKey
+		wantErr   bool
+	}{
+		{
+			name:      "valid key",
+			plaintext: plaintext,
+			key:       func() *models.APIKey { k := *stored; return &k },
+		},
+		{
+			name:      "wrong secret",
+			plaintext: stored.Prefix + "_not-the-secret",
+			key:       func() *models.APIKey { k := *stored; return &k },
+			wantErr:   true,
+		},
+		{
+			name:      "revoked key",
+			plaintext: plaintext,
+			key: func() *models.APIKey {
+				k := *stored
+				k.Revoke()
+				return &k
+			},
+			wantErr: true,
+		},
+		{
+			name:      "expired key",
+			plaintext: plaintext,
+			key: func() *models.APIKey {
+				k := *stored
+				expired := time.Now().Add(-time.Minute)
+				k.ExpiresAt = &expired
+				return &k
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyRepo := new(mocks.MockAPIKeyRepository)
+			userRepo := new(mocks.MockUserRepository)
+			keyRepo.On("GetByPrefix", mock.Anything, stored.Prefix).Return(tt.key(), nil)
+			if !tt.wantErr {
+				userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+				keyRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.APIKey")).Return(nil)
+			}
+
+			apiKeyService := service.NewAPIKeyService(keyRepo, userRepo)
+			gotUser, key, err := apiKeyService.Authenticate(context.Background(), tt.plaintext)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "UNAUTHORIZED")
+				assert.Nil(t, gotUser)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, user.ID, gotUser.ID)
+				assert.True(t, key.HasScope(models.ScopeDocumentsWrite))
+				assert.False(t, key.HasScope(models.ScopeSummariesGenerate))
+				assert.NotNil(t, key.LastUsedAt)
+			}
+
+			keyRepo.AssertExpectations(t)
+			userRepo.AssertExpectations(t)
+		})
+	}
+}
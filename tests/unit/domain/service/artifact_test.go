@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+	"github/k-tsurumaki/quilldeck/tests/unit/domain/service/mocks"
+)
+
+// memoryBlobs is a blob store in a map.
+type memoryBlobs struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func newMemoryBlobs() *memoryBlobs {
+	return &memoryBlobs{blobs: make(map[string][]byte)}
+}
+
+func (b *memoryBlobs) Put(ctx context.Context, key string, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.blobs[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (b *memoryBlobs) Get(ctx context.Context, key string) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.blobs[key], nil
+}
+
+func (b *memoryBlobs) Delete(ctx context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.blobs, key)
+	return nil
+}
+
+func newArtifactService(document *models.Document, artifactRepo *mocks.MockArtifactRepository, blobs *memoryBlobs) *service.ArtifactService {
+	docRepo := new(mocks.MockDocumentRepository)
+	docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+	docService := service.NewDocumentService(docRepo, new(mocks.MockSummaryRepository), newMemoryBlobs(), &fakeLLM{}, service.SummarizerOptions{})
+	return service.NewArtifactService(artifactRepo, blobs, docService)
+}
+
+func TestArtifactService_SaveAndRead(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	artifactRepo := new(mocks.MockArtifactRepository)
+	artifactRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	blobs := newMemoryBlobs()
+	svc := newArtifactService(document, artifactRepo, blobs)
+
+	artifact, err := svc.SaveArtifact(context.Background(), document, models.ArtifactKindDiagram, "flow.mmd", "text/plain; charset=utf-8",
+		map[string]any{"syntax": "mermaid"}, []byte("flowchart TD\n  A --> B"))
+
+	require.NoError(t, err)
+	assert.Equal(t, userID, artifact.UserID)
+	assert.Equal(t, document.ID, artifact.DocumentID)
+	assert.Equal(t, int64(22), artifact.Size)
+	assert.Equal(t, models.Checksum([]byte("flowchart TD\n  A --> B")), artifact.Checksum)
+	assert.Equal(t, "artifacts/"+artifact.ID.String()[:2]+"/"+artifact.ID.String(), artifact.StorageKey)
+	assert.Equal(t, []byte("flowchart TD\n  A --> B"), blobs.blobs[artifact.StorageKey])
+	artifactRepo.AssertCalled(t, "Create", mock.Anything, artifact)
+
+	artifactRepo.On("GetByID", mock.Anything, artifact.ID).Return(artifact, nil)
+	read, data, err := svc.ReadArtifact(context.Background(), userID, artifact.ID)
+	require.NoError(t, err)
+	assert.Equal(t, artifact, read)
+	assert.Equal(t, "flowchart TD\n  A --> B", string(data))
+
+	_, data, err = svc.PreviewArtifact(context.Background(), userID, artifact.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "flowchart TD\n  A --> B", string(data))
+
+	// Someone else's artifact does not exist for them.
+	_, _, err = svc.ReadArtifact(context.Background(), uuid.New(), artifact.ID)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "NOT_FOUND")
+
+	// Content changed behind the store's back is refused.
+	blobs.blobs[artifact.StorageKey] = []byte("flowchart TD\n  A --> C")
+	_, _, err = svc.ReadArtifact(context.Background(), userID, artifact.ID)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum")
+}
+
+func TestArtifactService_SaveArtifact_RemovesContentWhenRecordFails(t *testing.T) {
+	document := models.NewDocument(uuid.New(), "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	artifactRepo := new(mocks.MockArtifactRepository)
+	artifactRepo.On("Create", mock.Anything, mock.Anything).Return(fmt.Errorf("disk full"))
+	blobs := newMemoryBlobs()
+
+	_, err := newArtifactService(document, artifactRepo, blobs).
+		SaveArtifact(context.Background(), document, models.ArtifactKindDeck, "deck.md", "text/markdown", nil, []byte("# deck"))
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "INTERNAL")
+	assert.Empty(t, blobs.blobs)
+}
+
+func TestArtifactService_PreviewArtifact_RejectsOfficeFiles(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	artifact := models.NewArtifact(userID, document.ID, models.ArtifactKindDeck, "deck.pptx",
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation", []byte("PK"))
+	artifactRepo := new(mocks.MockArtifactRepository)
+	artifactRepo.On("GetByID", mock.Anything, artifact.ID).Return(artifact, nil)
+
+	_, _, err := newArtifactService(document, artifactRepo, newMemoryBlobs()).
+		PreviewArtifact(context.Background(), userID, artifact.ID)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "VALIDATION")
+}
+
+func TestArtifactService_ListArtifacts(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	artifact := models.NewArtifact(userID, document.ID, models.ArtifactKindDiagram, "flow.mmd", "text/plain", []byte("graph LR"))
+	artifactRepo := new(mocks.MockArtifactRepository)
+	artifactRepo.On("GetByUserID", mock.Anything, userID, mock.Anything).Return([]*models.Artifact{artifact}, nil)
+	svc := newArtifactService(document, artifactRepo, newMemoryBlobs())
+
+	filter := repository.ArtifactFilter{DocumentID: document.ID, Kind: models.ArtifactKindDiagram}
+	artifacts, err := svc.ListArtifacts(context.Background(), userID, filter)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.Artifact{artifact}, artifacts)
+	artifactRepo.AssertCalled(t, "GetByUserID", mock.Anything, userID, filter)
+
+	_, err = svc.ListArtifacts(context.Background(), uuid.New(), repository.ArtifactFilter{DocumentID: document.ID})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "NOT_FOUND")
+
+	_, err = svc.ListArtifacts(context.Background(), userID, repository.ArtifactFilter{Kind: "video"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "VALIDATION")
+}
+
+func TestArtifactService_DeleteDocumentArtifacts(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	artifactRepo := new(mocks.MockArtifactRepository)
+	artifactRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	artifactRepo.On("Delete", mock.Anything, mock.Anything).Return(nil)
+	blobs := newMemoryBlobs()
+	svc := newArtifactService(document, artifactRepo, blobs)
+
+	first, err := svc.SaveArtifact(context.Background(), document, models.ArtifactKindDeck, "deck.md", "text/markdown", nil, []byte("# one"))
+	require.NoError(t, err)
+	second, err := svc.SaveArtifact(context.Background(), document, models.ArtifactKindDeck, "deck.md", "text/markdown", nil, []byte("# two"))
+	require.NoError(t, err)
+	artifactRepo.On("GetByUserID", mock.Anything, userID, repository.ArtifactFilter{DocumentID: document.ID}).
+		Return([]*models.Artifact{second, first}, nil)
+
+	require.NoError(t, svc.DeleteDocumentArtifacts(context.Background(), userID, document.ID))
+
+	artifactRepo.AssertCalled(t, "Delete", mock.Anything, first.ID)
+	artifactRepo.AssertCalled(t, "Delete", mock.Anything, second.ID)
+	assert.Empty(t, blobs.blobs)
+}
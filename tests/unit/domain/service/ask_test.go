@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+	"github/k-tsurumaki/quilldeck/tests/unit/domain/service/mocks"
+)
+
+// answerLLM replies with a fixed answer and records the last request.
+type answerLLM struct {
+	answer  string
+	request llm.Request
+}
+
+func (f *answerLLM) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	f.request = req
+	return &llm.Response{Content: f.answer}, nil
+}
+
+const askContent = "# 概要\n\nこの計画は社内向けです。\n\n# 予算\n\n来期の予算は300万円で、広告費を削減します。\n\n# 日程\n\n締め切りは3月末です。"
+
+func newAskService(t *testing.T, document *models.Document, model llm.LLM, embeddingService *service.EmbeddingService) *service.AskService {
+	t.Helper()
+	docRepo := new(mocks.MockDocumentRepository)
+	docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+	docService := service.NewDocumentService(docRepo, new(mocks.MockSummaryRepository), newMemoryBlobs(), model, service.SummarizerOptions{})
+	return service.NewAskService(docService, embeddingService, model, service.AskOptions{PassageChars: 30, MaxPassages: 2})
+}
+
+func TestAskService_Ask_CitesPassagesByCharacterOffsets(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	model := &answerLLM{answer: "予算は300万円です [2]。広告費を削減します [2, 9]。"}
+
+	answer, err := newAskService(t, document, model, nil).Ask(context.Background(), userID, document.ID, " 来期の予算はいくら？ ")
+
+	require.NoError(t, err)
+	assert.Equal(t, "来期の予算はいくら？", answer.Question)
+	assert.Equal(t, model.answer, answer.Content)
+
+	// The two passages sharing the most words with the question are numbered in document order.
+	prompt := model.request.Messages[len(model.request.Messages)-1].Content
+	assert.Contains(t, prompt, "[2] (section \"予算\")\n来期の予算は300万円")
+	assert.NotContains(t, prompt, "締め切り")
+	assert.True(t, strings.HasSuffix(prompt, "Question: 来期の予算はいくら？"))
+
+	// [9] names no passage and the repeated [2] is cited once.
+	require.Len(t, answer.Citations, 1)
+	citation := answer.Citations[0]
+	assert.Equal(t, 2, citation.Passage)
+	assert.Equal(t, "予算", citation.Heading)
+	assert.Equal(t, citation.Text, string([]rune(askContent)[citation.Start:citation.End]))
+	assert.Contains(t, citation.Text, "300万円")
+}
+
+func TestAskService_Ask_CitesPagesOfPDFDocuments(t *testing.T) {
+	userID := uuid.New()
+	content, pages := models.JoinPages([]string{"この計画は社内向けの資料で、目的を説明します。", "来期の予算は300万円で、広告費を削減します。"})
+	document := models.NewDocument(userID, "計画.pdf", content, models.DocumentTypePDF, int64(len(content)))
+	document.Pages = pages
+	model := &answerLLM{answer: "300万円です [2]。"}
+
+	answer, err := newAskService(t, document, model, nil).Ask(context.Background(), userID, document.ID, "来期の予算は？")
+
+	require.NoError(t, err)
+	prompt := model.request.Messages[len(model.request.Messages)-1].Content
+	assert.Contains(t, prompt, "[1] (page 1)\nこの計画は社内向けの資料")
+	assert.Contains(t, prompt, "[2] (page 2)\n来期の予算は300万円")
+	require.Len(t, answer.Citations, 1)
+	assert.Equal(t, 2, answer.Citations[0].Page)
+	assert.Equal(t, pages[1].Start, answer.Citations[0].Start)
+}
+
+func TestAskService_Ask_PrefersEmbeddedChunks(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	start := strings.Index(askContent, "# 日程")
+	stored := []*models.DocumentChunk{
+		{Index: 0, Start: 0, End: start, Content: askContent[:start], Embedding: []float32{1, 0}},
+		{Index: 1, Heading: "日程", Start: start, End: len(askContent), Content: askContent[start:], Embedding: []float32{0, 1}},
+	}
+	embeddingRepo := new(mocks.MockEmbeddingRepository)
+	embeddingRepo.On("ListByDocumentID", mock.Anything, document.ID, "m").Return(stored, nil)
+	embedder := &fakeEmbedder{}
+	embeddingService := service.NewEmbeddingService(embeddingRepo, new(mocks.MockDocumentRepository), embedder, service.EmbeddingOptions{Model: "m"})
+	model := &answerLLM{answer: "締め切りは3月末です [2]。"}
+
+	answer, err := newAskService(t, document, model, embeddingService).Ask(context.Background(), userID, document.ID, "いつまで？")
+
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"いつまで？"}}, embedder.batches)
+	// Both chunks fit in MaxPassages and are numbered in document order.
+	require.Len(t, answer.Citations, 1)
+	assert.Equal(t, 2, answer.Citations[0].Passage)
+	assert.Equal(t, "日程", answer.Citations[0].Heading)
+	assert.Equal(t, len([]rune(askContent[:start])), answer.Citations[0].Start)
+	assert.Equal(t, len([]rune(askContent)), answer.Citations[0].End)
+}
+
+func TestAskService_Ask_IgnoresStaleChunks(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	embeddingRepo := new(mocks.MockEmbeddingRepository)
+	embeddingRepo.On("ListByDocumentID", mock.Anything, document.ID, "m").
+		Return([]*models.DocumentChunk{{Start: 0, End: 6, Content: "old te", Embedding: []float32{1, 0}}}, nil)
+	embedder := &fakeEmbedder{}
+	embeddingService := service.NewEmbeddingService(embeddingRepo, new(mocks.MockDocumentRepository), embedder, service.EmbeddingOptions{Model: "m"})
+	model := &answerLLM{answer: "300万円です [2]。"}
+
+	answer, err := newAskService(t, document, model, embeddingService).Ask(context.Background(), userID, document.ID, "予算は？")
+
+	require.NoError(t, err)
+	assert.Empty(t, embedder.batches)
+	require.Len(t, answer.Citations, 1)
+	assert.Contains(t, answer.Citations[0].Text, "300万円")
+}
+
+func TestAskService_Ask_Validation(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	empty := models.NewDocument(userID, "empty.md", " \n", models.DocumentTypeMD, 2)
+
+	tests := []struct {
+		name     string
+		document *models.Document
+		userID   uuid.UUID
+		question string
+		wantCode string
+	}{
+		{"empty question", document, userID, "  ", "VALIDATION"},
+		{"long question", document, userID, strings.Repeat("あ", 2001), "VALIDATION"},
+		{"empty document", empty, userID, "何？", "VALIDATION"},
+		{"foreign document", document, uuid.New(), "何？", "NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &answerLLM{}
+
+			_, err := newAskService(t, tt.document, model, nil).Ask(context.Background(), tt.userID, tt.document.ID, tt.question)
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantCode)
+			assert.Empty(t, model.request.Messages)
+		})
+	}
+}
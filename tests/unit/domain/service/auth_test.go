@@ -3,16 +3,30 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github/k-tsurumaki/quilldeck/internal/domain/models"
 	"github/k-tsurumaki/quilldeck/internal/domain/service"
+	"github/k-tsurumaki/quilldeck/internal/pkg/crypto"
 	"github/k-tsurumaki/quilldeck/tests/unit/domain/service/mocks"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+// testArgon2Params keeps hashing cheap so the table tests stay fast.
+var testArgon2Params = crypto.Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func newTestHasher(t *testing.T) crypto.PasswordHasher {
+	hasher, err := crypto.NewPasswordHasher(crypto.AlgorithmArgon2id, testArgon2Params, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hasher
+}
+
 func TestAuthService_Register(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -64,7 +78,7 @@ func TestAuthService_Register(t *testing.T) {
 			mockRepo := new(mocks.MockUserRepository)
 			tt.setup(mockRepo)
 
-			authService := service.NewAuthService(mockRepo)
+			authService := service.NewAuthService(mockRepo, new(mocks.MockSessionRepository), newTestHasher(t), time.Hour)
 			user, err := authService.Register(context.Background(), tt.email, tt.password, tt.userName)
 
 			if tt.wantErr {
@@ -78,6 +92,7 @@ func TestAuthService_Register(t *testing.T) {
 				assert.NotNil(t, user)
 				assert.Equal(t, tt.email, user.Email)
 				assert.Equal(t, tt.userName, user.Name)
+				assert.Contains(t, user.Password, "$argon2id$")
 			}
 
 			mockRepo.AssertExpectations(t)
@@ -86,7 +101,10 @@ func TestAuthService_Register(t *testing.T) {
 }
 
 func TestAuthService_Login(t *testing.T) {
-	hashedPassword := "ef92b778bafe771e89245b89ecbc08a44a4e166c06659911881f383d4473e94f" // sha256 of "password123"
+	hashedPassword, err := newTestHasher(t).Hash("password123")
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	tests := []struct {
 		name     string
@@ -144,7 +162,7 @@ func TestAuthService_Login(t *testing.T) {
 			mockRepo := new(mocks.MockUserRepository)
 			tt.setup(mockRepo)
 
-			authService := service.NewAuthService(mockRepo)
+			authService := service.NewAuthService(mockRepo, new(mocks.MockSessionRepository), newTestHasher(t), time.Hour)
 			user, err := authService.Login(context.Background(), tt.email, tt.password)
 
 			if tt.wantErr {
@@ -162,4 +180,198 @@ func TestAuthService_Login(t *testing.T) {
 			mockRepo.AssertExpectations(t)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestAuthService_Login_RehashesLegacyPassword(t *testing.T) {
+	legacyHash := "ef92b778bafe771e89245b89ecbc08a44a4e166c06659911881f383d4473e94f" // sha256 of "password123"
+
+	tests := []struct {
+		name     string
+		password string
+		setup    func(*mocks.MockUserRepository, *models.User)
+		wantErr  bool
+	}{
+		{
+			name:     "legacy hash is upgraded on successful login",
+			password: "password123",
+			setup: func(repo *mocks.MockUserRepository, user *models.User) {
+				repo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+				repo.On("Update", mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+					return strings.HasPrefix(u.Password, "$argon2id$")
+				})).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:     "login still succeeds when the upgrade cannot be stored",
+			password: "password123",
+			setup: func(repo *mocks.MockUserRepository, user *models.User) {
+				repo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+				repo.On("Update", mock.Anything, mock.AnythingOfType("*models.User")).Return(errors.New("database is locked"))
+			},
+			wantErr: false,
+		},
+		{
+			name:     "legacy hash is left alone on failed login",
+			password: "wrongpassword",
+			setup: func(repo *mocks.MockUserRepository, user *models.User) {
+				repo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &models.User{
+				ID:       uuid.New(),
+				Email:    "legacy@example.com",
+				Password: legacyHash,
+				Name:     "Legacy User",
+			}
+			mockRepo := new(mocks.MockUserRepository)
+			tt.setup(mockRepo, user)
+
+			authService := service.NewAuthService(mockRepo, new(mocks.MockSessionRepository), newTestHasher(t), time.Hour)
+			loggedIn, err := authService.Login(context.Background(), user.Email, tt.password)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, loggedIn)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, loggedIn)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthService_RefreshSession(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Email: "test@example.com", Name: "Test User"}
+	refreshToken := "refresh-token"
+	tokenHash := crypto.HashToken(refreshToken)
+
+	tests := []struct {
+		name    string
+		session func() *models.Session
+		setup   func(*mocks.MockUserRepository, *mocks.MockSessionRepository, *models.Session)
+		wantErr bool
+	}{
+		{
+			name: "active session is rotated",
+			session: func() *models.Session {
+				return models.NewSession(user.ID, tokenHash, "test-agent", "127.0.0.1", time.Hour)
+			},
+			setup: func(users *mocks.MockUserRepository, sessions *mocks.MockSessionRepository, session *models.Session) {
+				sessions.On("GetByRefreshTokenHash", mock.Anything, tokenHash).Return(session, nil)
+				users.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+				sessions.On("Rotate", mock.Anything, mock.MatchedBy(func(s *models.Session) bool {
+					return s.RefreshTokenHash != tokenHash
+				}), tokenHash).Return(true, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "token rotated by a concurrent refresh",
+			session: func() *models.Session {
+				return models.NewSession(user.ID, tokenHash, "test-agent", "127.0.0.1", time.Hour)
+			},
+			setup: func(users *mocks.MockUserRepository, sessions *mocks.MockSessionRepository, session *models.Session) {
+				sessions.On("GetByRefreshTokenHash", mock.Anything, tokenHash).Return(session, nil)
+				users.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+				sessions.On("Rotate", mock.Anything, mock.AnythingOfType("*models.Session"), tokenHash).Return(false, nil)
+				sessions.On("RevokeAllByUserID", mock.Anything, user.ID).Return(nil)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unknown token",
+			session: func() *models.Session { return nil },
+			setup: func(users *mocks.MockUserRepository, sessions *mocks.MockSessionRepository, session *models.Session) {
+				sessions.On("GetByRefreshTokenHash", mock.Anything, tokenHash).Return(nil, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired session",
+			session: func() *models.Session {
+				return models.NewSession(user.ID, tokenHash, "test-agent", "127.0.0.1", -time.Hour)
+			},
+			setup: func(users *mocks.MockUserRepository, sessions *mocks.MockSessionRepository, session *models.Session) {
+				sessions.On("GetByRefreshTokenHash", mock.Anything, tokenHash).Return(session, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "reused revoked token revokes every session",
+			session: func() *models.Session {
+				session := models.NewSession(user.ID, tokenHash, "test-agent", "127.0.0.1", time.Hour)
+				session.Revoke()
+				return session
+			},
+			setup: func(users *mocks.MockUserRepository, sessions *mocks.MockSessionRepository, session *models.Session) {
+				sessions.On("GetByRefreshTokenHash", mock.Anything, tokenHash).Return(session, nil)
+				sessions.On("RevokeAllByUserID", mock.Anything, user.ID).Return(nil)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(mocks.MockUserRepository)
+			sessionRepo := new(mocks.MockSessionRepository)
+			tt.setup(userRepo, sessionRepo, tt.session())
+
+			authService := service.NewAuthService(userRepo, sessionRepo, newTestHasher(t), time.Hour)
+			gotUser, session, newToken, err := authService.RefreshSession(context.Background(), refreshToken)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "UNAUTHORIZED")
+				assert.Nil(t, session)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, user.ID, gotUser.ID)
+				assert.NotEmpty(t, newToken)
+				assert.NotEqual(t, refreshToken, newToken)
+				assert.Equal(t, crypto.HashToken(newToken), session.RefreshTokenHash)
+			}
+
+			userRepo.AssertExpectations(t)
+			sessionRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthService_ChangePassword_RevokesAllSessions(t *testing.T) {
+	hasher := newTestHasher(t)
+	hashedPassword, err := hasher.Hash("password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{ID: uuid.New(), Email: "test@example.com", Password: hashedPassword, Name: "Test User"}
+
+	userRepo := new(mocks.MockUserRepository)
+	sessionRepo := new(mocks.MockSessionRepository)
+	userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	userRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.User")).Return(nil)
+	sessionRepo.On("RevokeAllByUserID", mock.Anything, user.ID).Return(nil)
+
+	authService := service.NewAuthService(userRepo, sessionRepo, hasher, time.Hour)
+
+	err = authService.ChangePassword(context.Background(), user.ID, "wrongpassword", "newpassword456")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "UNAUTHORIZED")
+
+	err = authService.ChangePassword(context.Background(), user.ID, "password123", "newpassword456")
+	assert.NoError(t, err)
+	ok, err := hasher.Verify("newpassword456", user.Password)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	userRepo.AssertExpectations(t)
+	sessionRepo.AssertNumberOfCalls(t, "RevokeAllByUserID", 1)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+	"github/k-tsurumaki/quilldeck/tests/unit/domain/service/mocks"
+)
+
+func newConversationService(t *testing.T, document *models.Document, model llm.LLM, conversationRepo *mocks.MockConversationRepository, contextChars int) *service.ConversationService {
+	t.Helper()
+	docRepo := new(mocks.MockDocumentRepository)
+	docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+	docService := service.NewDocumentService(docRepo, new(mocks.MockSummaryRepository), newMemoryBlobs(), model, service.SummarizerOptions{})
+	askService := service.NewAskService(docService, nil, model, service.AskOptions{PassageChars: 30, MaxPassages: 2, ContextChars: contextChars})
+	return service.NewConversationService(conversationRepo, askService)
+}
+
+func TestConversationService_PostMessage_SendsRecentTurns(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	conversation := models.NewConversation(userID, document.ID, "")
+	history := []*models.Message{
+		models.NewMessage(conversation.ID, llm.RoleUser, strings.Repeat("長い質問", 500)),
+		models.NewMessage(conversation.ID, llm.RoleAssistant, "古い回答です [1]。"),
+		models.NewMessage(conversation.ID, llm.RoleUser, "来期の予算はいくら？"),
+		models.NewMessage(conversation.ID, llm.RoleAssistant, "300万円です [2]。"),
+	}
+	conversationRepo := new(mocks.MockConversationRepository)
+	conversationRepo.On("GetByID", mock.Anything, conversation.ID).Return(conversation, nil)
+	conversationRepo.On("ListMessages", mock.Anything, conversation.ID).Return(history, nil)
+	conversationRepo.On("CreateMessage", mock.Anything, mock.Anything).Return(nil)
+	conversationRepo.On("Update", mock.Anything, conversation).Return(nil)
+	model := &answerLLM{answer: "広告費を削減します [1]。"}
+
+	question, answer, err := newConversationService(t, document, model, conversationRepo, 1500).
+		PostMessage(context.Background(), userID, conversation.ID, " 何を削減する？ ")
+
+	require.NoError(t, err)
+	assert.Equal(t, llm.RoleUser, question.Role)
+	assert.Equal(t, "何を削減する？", question.Content)
+	assert.Equal(t, llm.RoleAssistant, answer.Role)
+	assert.Equal(t, model.answer, answer.Content)
+	require.Len(t, answer.Citations, 1)
+	assert.True(t, answer.CreatedAt.After(question.CreatedAt))
+
+	// The long first question does not fit the budget, and its answer is not sent without it. Citation
+	// markers of earlier answers are dropped since their passages are not sent again.
+	messages := model.request.Messages
+	require.Len(t, messages, 4)
+	assert.Equal(t, llm.RoleSystem, messages[0].Role)
+	assert.Equal(t, llm.Message{Role: llm.RoleUser, Content: "来期の予算はいくら？"}, messages[1])
+	assert.Equal(t, llm.Message{Role: llm.RoleAssistant, Content: "300万円です。"}, messages[2])
+	assert.Equal(t, llm.RoleUser, messages[3].Role)
+	assert.True(t, strings.HasSuffix(messages[3].Content, "Question: 何を削減する？"))
+
+	conversationRepo.AssertNumberOfCalls(t, "CreateMessage", 2)
+	assert.Equal(t, "何を削減する？", conversation.Title)
+}
+
+func TestConversationService_PostMessage_SendsWholeHistoryWithinBudget(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	conversation := models.NewConversation(userID, document.ID, "予算")
+	history := []*models.Message{
+		models.NewMessage(conversation.ID, llm.RoleUser, "概要は？"),
+		models.NewMessage(conversation.ID, llm.RoleAssistant, "社内向けの計画です。"),
+	}
+	conversationRepo := new(mocks.MockConversationRepository)
+	conversationRepo.On("GetByID", mock.Anything, conversation.ID).Return(conversation, nil)
+	conversationRepo.On("ListMessages", mock.Anything, conversation.ID).Return(history, nil)
+	conversationRepo.On("CreateMessage", mock.Anything, mock.Anything).Return(nil)
+	conversationRepo.On("Update", mock.Anything, conversation).Return(nil)
+	model := &answerLLM{answer: "3月末です [1]。"}
+
+	_, _, err := newConversationService(t, document, model, conversationRepo, 0).
+		PostMessage(context.Background(), userID, conversation.ID, "締め切りは？")
+
+	require.NoError(t, err)
+	require.Len(t, model.request.Messages, 4)
+	assert.Equal(t, "概要は？", model.request.Messages[1].Content)
+	assert.Equal(t, "予算", conversation.Title)
+}
+
+func TestConversationService_PostMessage_Errors(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	own := models.NewConversation(userID, document.ID, "")
+	foreign := models.NewConversation(uuid.New(), document.ID, "")
+
+	tests := []struct {
+		name           string
+		conversationID uuid.UUID
+		content        string
+		answer         string
+		wantCode       string
+	}{
+		{"empty question", own.ID, " ", "回答", "VALIDATION"},
+		{"foreign conversation", foreign.ID, "何？", "回答", "NOT_FOUND"},
+		{"missing conversation", uuid.New(), "何？", "回答", "NOT_FOUND"},
+		{"empty answer", own.ID, "何？", "", "INTERNAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversationRepo := new(mocks.MockConversationRepository)
+			conversationRepo.On("GetByID", mock.Anything, own.ID).Return(own, nil)
+			conversationRepo.On("GetByID", mock.Anything, foreign.ID).Return(foreign, nil)
+			conversationRepo.On("GetByID", mock.Anything, mock.Anything).Return(nil, nil)
+			conversationRepo.On("ListMessages", mock.Anything, own.ID).Return([]*models.Message{}, nil)
+			model := &answerLLM{answer: tt.answer}
+
+			_, _, err := newConversationService(t, document, model, conversationRepo, 0).
+				PostMessage(context.Background(), userID, tt.conversationID, tt.content)
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantCode)
+			conversationRepo.AssertNotCalled(t, "CreateMessage", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestConversationService_CreateConversation_ForeignDocument(t *testing.T) {
+	document := models.NewDocument(uuid.New(), "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	conversationRepo := new(mocks.MockConversationRepository)
+
+	_, err := newConversationService(t, document, &answerLLM{}, conversationRepo, 0).
+		CreateConversation(context.Background(), uuid.New(), document.ID, "")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "NOT_FOUND")
+	conversationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestConversationService_DeleteConversation(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	conversation := models.NewConversation(userID, document.ID, "")
+	conversationRepo := new(mocks.MockConversationRepository)
+	conversationRepo.On("GetByID", mock.Anything, conversation.ID).Return(conversation, nil)
+	conversationRepo.On("Delete", mock.Anything, conversation.ID).Return(nil)
+	svc := newConversationService(t, document, &answerLLM{}, conversationRepo, 0)
+
+	err := svc.DeleteConversation(context.Background(), uuid.New(), conversation.ID)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "NOT_FOUND")
+	conversationRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+
+	require.NoError(t, svc.DeleteConversation(context.Background(), userID, conversation.ID))
+	conversationRepo.AssertCalled(t, "Delete", mock.Anything, conversation.ID)
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+	"github/k-tsurumaki/quilldeck/tests/unit/domain/service/mocks"
+)
+
+// deckLLM replies to the outline prompt with outline and summarizes everything else.
+type deckLLM struct {
+	mu      sync.Mutex
+	outline string
+	prompts []string
+}
+
+func (f *deckLLM) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	prompt := req.Messages[len(req.Messages)-1].Content
+	f.mu.Lock()
+	f.prompts = append(f.prompts, prompt)
+	f.mu.Unlock()
+	if strings.Contains(prompt, "outline of a slide deck") {
+		return &llm.Response{Content: f.outline}, nil
+	}
+	return &llm.Response{Content: "condensed"}, nil
+}
+
+const deckOutline = "Here is the outline:\n```json\n" + `{
+  "title": "来期計画の報告",
+  "subtitle": "経営会議向け",
+  "agenda_title": "アジェンダ",
+  "actions_title": "次のアクション",
+  "sections": [
+    {"title": "予算", "bullets": ["300万円", " ", "広告費を削減", "3", "4", "5", "6", "7"], "notes": "内訳を説明"},
+    {"title": "", "bullets": ["no title"]},
+    {"title": "日程", "bullets": ["3月末締め切り"]},
+    {"title": "余分", "bullets": ["one too many"]}
+  ],
+  "next_actions": ["予算案を承認する", ""]
+}` + "\n```"
+
+func newDeckService(t *testing.T, document *models.Document, model llm.LLM, deckRepo *mocks.MockDeckRepository, chunkChars int) (*service.DeckService, *mocks.MockArtifactRepository) {
+	t.Helper()
+	docRepo := new(mocks.MockDocumentRepository)
+	docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+	docService := service.NewDocumentService(docRepo, new(mocks.MockSummaryRepository), newMemoryBlobs(), model, service.SummarizerOptions{MaxChunkChars: chunkChars})
+	artifactRepo := new(mocks.MockArtifactRepository)
+	artifactRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	artifacts := service.NewArtifactService(artifactRepo, newMemoryBlobs(), docService)
+	return service.NewDeckService(deckRepo, docService, artifacts, model), artifactRepo
+}
+
+func TestDeckService_GenerateDeck(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	deckRepo := new(mocks.MockDeckRepository)
+	deckRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	model := &deckLLM{outline: deckOutline}
+
+	svc, artifactRepo := newDeckService(t, document, model, deckRepo, 0)
+	deck, err := svc.GenerateDeck(context.Background(), userID, document.ID, models.DeckOptions{SlideCount: 5, Language: "ja"})
+
+	require.NoError(t, err)
+	// The whole document fits in one prompt, which asks for the slides between the fixed ones.
+	require.Len(t, model.prompts, 1)
+	assert.Contains(t, model.prompts[0], "Write exactly 2 sections")
+	assert.Contains(t, model.prompts[0], "in Japanese")
+	assert.Contains(t, model.prompts[0], "締め切りは3月末です。")
+
+	assert.Equal(t, "来期計画の報告", deck.Title)
+	assert.Equal(t, 5, deck.SlideCount)
+	assert.Equal(t, []models.Slide{
+		{Kind: models.SlideKindTitle, Title: "来期計画の報告", Subtitle: "経営会議向け"},
+		{Kind: models.SlideKindAgenda, Title: "アジェンダ", Bullets: []string{"予算", "日程"}},
+		{Kind: models.SlideKindPoints, Title: "予算", Bullets: []string{"300万円", "広告費を削減", "3", "4", "5", "6"}, Notes: "内訳を説明"},
+		{Kind: models.SlideKindPoints, Title: "日程", Bullets: []string{"3月末締め切り"}},
+		{Kind: models.SlideKindActions, Title: "次のアクション", Bullets: []string{"予算案を承認する"}},
+	}, deck.Slides)
+	deckRepo.AssertCalled(t, "Create", mock.Anything, deck)
+
+	// Both files of the deck are kept as artifacts.
+	require.Len(t, artifactRepo.Calls, 2)
+	for i, name := range []string{"来期計画の報告.pptx", "来期計画の報告.md"} {
+		artifact := artifactRepo.Calls[i].Arguments.Get(1).(*models.Artifact)
+		assert.Equal(t, models.ArtifactKindDeck, artifact.Kind)
+		assert.Equal(t, name, artifact.Name)
+		assert.Equal(t, userID, artifact.UserID)
+		assert.Equal(t, deck.ID.String(), artifact.Params["deck_id"])
+	}
+}
+
+func TestDeckService_GenerateDeck_SummarizesLongDocuments(t *testing.T) {
+	userID := uuid.New()
+	content := strings.Repeat("# 節\n\n長い本文です。\n\n", 20)
+	document := models.NewDocument(userID, "long.md", content, models.DocumentTypeMD, int64(len(content)))
+	deckRepo := new(mocks.MockDeckRepository)
+	deckRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	model := &deckLLM{outline: deckOutline}
+
+	svc, _ := newDeckService(t, document, model, deckRepo, 100)
+	_, err := svc.GenerateDeck(context.Background(), userID, document.ID, models.DeckOptions{})
+
+	require.NoError(t, err)
+	outlinePrompt := model.prompts[len(model.prompts)-1]
+	assert.Greater(t, len(model.prompts), 1)
+	assert.Contains(t, outlinePrompt, "Write exactly 5 sections")
+	assert.True(t, strings.HasSuffix(outlinePrompt, "condensed"))
+	assert.NotContains(t, outlinePrompt, "長い本文です。")
+}
+
+func TestDeckService_GenerateDeck_Errors(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+
+	tests := []struct {
+		name     string
+		userID   uuid.UUID
+		opts     models.DeckOptions
+		outline  string
+		wantCode string
+	}{
+		{"too few slides", userID, models.DeckOptions{SlideCount: 3}, deckOutline, "VALIDATION"},
+		{"foreign document", uuid.New(), models.DeckOptions{}, deckOutline, "NOT_FOUND"},
+		{"not JSON", userID, models.DeckOptions{}, "I cannot do that.", "INTERNAL"},
+		{"no sections", userID, models.DeckOptions{}, `{"title": "x", "sections": []}`, "INTERNAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deckRepo := new(mocks.MockDeckRepository)
+
+			svc, _ := newDeckService(t, document, &deckLLM{outline: tt.outline}, deckRepo, 0)
+			_, err := svc.GenerateDeck(context.Background(), tt.userID, document.ID, tt.opts)
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantCode)
+			deckRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestDeckService_ExportDeck(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	deck := models.NewDeck(document.ID, "来期/計画", []models.Slide{
+		{Kind: models.SlideKindTitle, Title: "来期/計画", Subtitle: "経営会議向け"},
+		{Kind: models.SlideKindPoints, Title: "予算", Bullets: []string{"300万円"}, Notes: "内訳を説明"},
+	})
+	deckRepo := new(mocks.MockDeckRepository)
+	deckRepo.On("GetByID", mock.Anything, deck.ID).Return(deck, nil)
+	svc, _ := newDeckService(t, document, &deckLLM{}, deckRepo, 0)
+
+	marp, err := svc.ExportDeck(context.Background(), userID, deck.ID, models.DeckFormatMarp)
+	require.NoError(t, err)
+	assert.Equal(t, "来期_計画.md", marp.Name)
+	assert.Equal(t, "text/markdown; charset=utf-8", marp.ContentType)
+	assert.Contains(t, string(marp.Data), "# 来期/計画\n\n経営会議向け\n")
+	assert.Contains(t, string(marp.Data), "## 予算\n\n- 300万円\n\n<!--\n内訳を説明\n-->\n")
+
+	pptx, err := svc.ExportDeck(context.Background(), userID, deck.ID, models.DeckFormatPPTX)
+	require.NoError(t, err)
+	assert.Equal(t, "来期_計画.pptx", pptx.Name)
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.presentationml.presentation", pptx.ContentType)
+	reader, err := zip.NewReader(bytes.NewReader(pptx.Data), int64(len(pptx.Data)))
+	require.NoError(t, err)
+	var names []string
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	assert.Contains(t, names, "ppt/slides/slide2.xml")
+	assert.Contains(t, names, "ppt/notesSlides/notesSlide2.xml")
+
+	_, err = svc.ExportDeck(context.Background(), userID, deck.ID, "pdf")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "VALIDATION")
+
+	_, err = svc.ExportDeck(context.Background(), uuid.New(), deck.ID, models.DeckFormatPPTX)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "NOT_FOUND")
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+	"github/k-tsurumaki/quilldeck/tests/unit/domain/service/mocks"
+)
+
+// scriptedLLM gives its replies in order and records the requests.
+type scriptedLLM struct {
+	replies  []string
+	requests []llm.Request
+}
+
+func (f *scriptedLLM) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	f.requests = append(f.requests, req)
+	reply := f.replies[0]
+	if len(f.replies) > 1 {
+		f.replies = f.replies[1:]
+	}
+	return &llm.Response{Content: reply}, nil
+}
+
+const (
+	flowchartReply = `{"kind": "flowchart", "title": "承認の流れ", "description": "申請から承認まで", "source": "` +
+		"```mermaid\\nflowchart TD\\n  A[申請] --> B{承認?}\\n  B -->|はい| C[完了]\\n```" + `"}`
+	mindmapReply = `{"kind": "mindmap", "title": "来期計画", "source": "mindmap\n  root((来期計画))\n    予算\n    日程"}`
+)
+
+func newDiagramService(t *testing.T, document *models.Document, model llm.LLM, diagramRepo *mocks.MockDiagramRepository) (*service.DiagramService, *mocks.MockArtifactRepository) {
+	t.Helper()
+	docRepo := new(mocks.MockDocumentRepository)
+	docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+	docService := service.NewDocumentService(docRepo, new(mocks.MockSummaryRepository), newMemoryBlobs(), model, service.SummarizerOptions{})
+	artifactRepo := new(mocks.MockArtifactRepository)
+	artifactRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	artifacts := service.NewArtifactService(artifactRepo, newMemoryBlobs(), docService)
+	return service.NewDiagramService(diagramRepo, docService, artifacts, model), artifactRepo
+}
+
+func TestDiagramService_GenerateDiagrams_RepairsInvalidDiagrams(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	diagramRepo := new(mocks.MockDiagramRepository)
+	diagramRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	model := &scriptedLLM{replies: []string{
+		`{"diagrams": [` + flowchartReply + `,
+			{"kind": "sequence", "title": "やり取り", "source": "sequenceDiagram\n  A->>B: 申請; 確認"},
+			` + mindmapReply + `]}`,
+		`{"diagrams": [{"kind": "sequence", "title": "やり取り", "source": "sequenceDiagram\n  A->>B: 申請と確認"}]}`,
+	}}
+
+	svc, artifactRepo := newDiagramService(t, document, model, diagramRepo)
+	diagrams, err := svc.GenerateDiagrams(context.Background(), userID, document.ID, models.DiagramOptions{})
+
+	require.NoError(t, err)
+	require.Len(t, diagrams, 3)
+	assert.Equal(t, models.DiagramKindFlowchart, diagrams[0].Kind)
+	assert.Equal(t, "flowchart TD\n  A[申請] --> B{承認?}\n  B -->|はい| C[完了]", diagrams[0].Source)
+	assert.Equal(t, "申請から承認まで", diagrams[0].Description)
+	assert.Equal(t, models.DiagramKindMindmap, diagrams[1].Kind)
+	assert.Equal(t, models.DiagramKindSequence, diagrams[2].Kind)
+	require.Len(t, artifactRepo.Calls, 3)
+	for i, diagram := range diagrams {
+		assert.Equal(t, models.DiagramSyntaxMermaid, diagram.Syntax)
+		assert.Equal(t, "ja", diagram.Language)
+		diagramRepo.AssertCalled(t, "Create", mock.Anything, diagram)
+
+		// Each source is also kept as an artifact.
+		artifact := artifactRepo.Calls[i].Arguments.Get(1).(*models.Artifact)
+		assert.Equal(t, models.ArtifactKindDiagram, artifact.Kind)
+		assert.Equal(t, diagram.Title+".mmd", artifact.Name)
+		assert.Equal(t, int64(len(diagram.Source)), artifact.Size)
+		assert.Equal(t, diagram.ID.String(), artifact.Params["diagram_id"])
+	}
+
+	// The second request carries the first reply and what was wrong with it.
+	require.Len(t, model.requests, 2)
+	assert.Contains(t, model.requests[0].Messages[1].Content, "締め切りは3月末です。")
+	repair := model.requests[1].Messages
+	require.Len(t, repair, 4)
+	assert.Equal(t, llm.RoleAssistant, repair[2].Role)
+	assert.Contains(t, repair[3].Content, "#59;")
+	assert.Contains(t, repair[3].Content, "only these diagrams: sequence.")
+}
+
+func TestDiagramService_GenerateDiagrams_KeepsWhatIsValid(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	diagramRepo := new(mocks.MockDiagramRepository)
+	diagramRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	model := &scriptedLLM{replies: []string{
+		"Sorry, here are the diagrams.",
+		// The mindmap's source is a flowchart, and the sequence diagram was not asked for.
+		`{"diagrams": [` + flowchartReply + `,
+			{"kind": "mindmap", "title": "x", "source": "flowchart TD\n  A --> B"},
+			{"kind": "sequence", "title": "y", "source": "sequenceDiagram\n  A->>B: hi"}]}`,
+	}}
+
+	svc, _ := newDiagramService(t, document, model, diagramRepo)
+	diagrams, err := svc.GenerateDiagrams(context.Background(), userID, document.ID, models.DiagramOptions{
+		Kinds: []models.DiagramKind{models.DiagramKindFlowchart, models.DiagramKindMindmap},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, diagrams, 1)
+	assert.Equal(t, models.DiagramKindFlowchart, diagrams[0].Kind)
+	require.Len(t, model.requests, 2)
+	assert.Contains(t, model.requests[1].Messages[3].Content, "not the JSON object asked for")
+	diagramRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestDiagramService_GenerateDiagrams_PlantUML(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	diagramRepo := new(mocks.MockDiagramRepository)
+	diagramRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	model := &scriptedLLM{replies: []string{
+		`{"diagrams": [{"kind": "mindmap", "title": "Plan", "source": "@startmindmap\n* Plan\n** Budget\n@endmindmap"}]}`,
+	}}
+
+	svc, _ := newDiagramService(t, document, model, diagramRepo)
+	diagrams, err := svc.GenerateDiagrams(context.Background(), userID, document.ID, models.DiagramOptions{
+		Kinds:    []models.DiagramKind{models.DiagramKindMindmap},
+		Syntax:   models.DiagramSyntaxPlantUML,
+		Language: "en",
+	})
+
+	require.NoError(t, err)
+	require.Len(t, diagrams, 1)
+	assert.Equal(t, models.DiagramSyntaxPlantUML, diagrams[0].Syntax)
+	assert.Equal(t, "en", diagrams[0].Language)
+	assert.Contains(t, model.requests[0].Messages[1].Content, "@startmindmap")
+	assert.Contains(t, model.requests[0].Messages[1].Content, "in English")
+}
+
+func TestDiagramService_GenerateDiagrams_Errors(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+
+	tests := []struct {
+		name     string
+		userID   uuid.UUID
+		opts     models.DiagramOptions
+		wantCode string
+	}{
+		{"unknown kind", userID, models.DiagramOptions{Kinds: []models.DiagramKind{"gantt"}}, "VALIDATION"},
+		{"repeated kind", userID, models.DiagramOptions{Kinds: []models.DiagramKind{"mindmap", "mindmap"}}, "VALIDATION"},
+		{"unknown syntax", userID, models.DiagramOptions{Syntax: "graphviz"}, "VALIDATION"},
+		{"foreign document", uuid.New(), models.DiagramOptions{}, "NOT_FOUND"},
+		{"never valid", userID, models.DiagramOptions{}, "INTERNAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagramRepo := new(mocks.MockDiagramRepository)
+			model := &scriptedLLM{replies: []string{`{"diagrams": [{"kind": "flowchart", "title": "x", "source": "flowchart TD\n  A[a (b)] --> B"}]}`}}
+
+			svc, _ := newDiagramService(t, document, model, diagramRepo)
+			_, err := svc.GenerateDiagrams(context.Background(), tt.userID, document.ID, tt.opts)
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantCode)
+			diagramRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestDiagramService_RegenerateDiagram(t *testing.T) {
+	userID := uuid.New()
+	document := models.NewDocument(userID, "計画.md", askContent, models.DocumentTypeMD, int64(len(askContent)))
+	original := models.NewDiagram(document.ID, models.DiagramKindSequence, models.DiagramSyntaxMermaid, "やり取り", "sequenceDiagram\n  A->>B: original")
+	original.Language = "en"
+	diagramRepo := new(mocks.MockDiagramRepository)
+	diagramRepo.On("GetByID", mock.Anything, original.ID).Return(original, nil)
+	diagramRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	model := &scriptedLLM{replies: []string{`{"diagrams": [
+		{"kind": "sequence", "title": "one", "source": "sequenceDiagram\n  A->>C: first"},
+		{"kind": "sequence", "title": "two", "source": "sequenceDiagram\n  C->>B: second"},
+		{"kind": "sequence", "title": "three", "source": "sequenceDiagram\n  B->>A: third"}]}`}}
+	svc, _ := newDiagramService(t, document, model, diagramRepo)
+
+	alternatives, err := svc.RegenerateDiagram(context.Background(), userID, original.ID, 0)
+
+	require.NoError(t, err)
+	require.Len(t, alternatives, models.DefaultDiagramAlternatives)
+	for _, diagram := range alternatives {
+		assert.NotEqual(t, original.ID, diagram.ID)
+		assert.Equal(t, models.DiagramKindSequence, diagram.Kind)
+		assert.Equal(t, "en", diagram.Language)
+	}
+	assert.Contains(t, model.requests[0].Messages[1].Content, "A->>B: original")
+	diagramRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+
+	_, err = svc.RegenerateDiagram(context.Background(), userID, original.ID, models.MaxDiagramAlternatives+1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "VALIDATION")
+
+	_, err = svc.RegenerateDiagram(context.Background(), uuid.New(), original.ID, 1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "NOT_FOUND")
+}
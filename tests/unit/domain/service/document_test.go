@@ -0,0 +1,708 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+	"github/k-tsurumaki/quilldeck/tests/unit/domain/service/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func newTestDocumentService(docRepo *mocks.MockDocumentRepository, summaryRepo *mocks.MockSummaryRepository) *service.DocumentService {
+	return service.NewDocumentService(docRepo, summaryRepo, newMemoryBlobs(), &fakeLLM{}, service.SummarizerOptions{})
+}
+
+// textPDF returns a PDF file with one line of text on each page; an empty string makes a page without text.
+func textPDF(pages ...string) []byte {
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>", ""}
+	var kids []string
+	for _, text := range pages {
+		content := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+		if text == "" {
+			content = "0 0 100 100 re f"
+		}
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Contents %d 0 R /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >> >>", len(objects)+2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+		kids = append(kids, fmt.Sprintf("%d 0 R", len(objects)-1))
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var b strings.Builder
+	b.WriteString("%PDF-1.4\n")
+	for i, obj := range objects {
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\n%%%%EOF\n", len(objects)+1)
+	return []byte(b.String())
+}
+
+func TestDocumentService_UploadPDF(t *testing.T) {
+	owner := uuid.New()
+
+	t.Run("keeps the text with its pages and the file", func(t *testing.T) {
+		docRepo := new(mocks.MockDocumentRepository)
+		docRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Document")).Return(nil)
+		blobs := newMemoryBlobs()
+		data := textPDF("Overview", "", "Results")
+
+		docService := service.NewDocumentService(docRepo, new(mocks.MockSummaryRepository), blobs, &fakeLLM{}, service.SummarizerOptions{})
+		document, err := docService.UploadPDF(context.Background(), owner, "report.pdf", data)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.DocumentTypePDF, document.Type)
+		assert.Equal(t, "Overview\n\n\n\nResults", document.Content)
+		assert.Equal(t, []models.PageSpan{{Number: 1, Start: 0, End: 8}, {Number: 2, Start: 10, End: 10}, {Number: 3, Start: 12, End: 19}}, document.Pages)
+		assert.Equal(t, "originals/"+document.ID.String()[:2]+"/"+document.ID.String(), document.OriginalKey)
+		assert.Equal(t, data, blobs.blobs[document.OriginalKey])
+		docRepo.AssertCalled(t, "Create", mock.Anything, document)
+	})
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"scanned pages", textPDF("", ""), "scanned PDFs are not supported"},
+		{"not a PDF", []byte("Overview"), "not a readable PDF"},
+		{"encrypted", append(textPDF("Secret"), "trailer\n<< /Root 1 0 R /Encrypt << /Filter /Standard >> >>\n"...), "encrypted PDF files are not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docRepo := new(mocks.MockDocumentRepository)
+
+			docService := newTestDocumentService(docRepo, new(mocks.MockSummaryRepository))
+			_, err := docService.UploadPDF(context.Background(), owner, "report.pdf", tt.data)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "VALIDATION_ERROR")
+			assert.Contains(t, err.Error(), tt.wantErr)
+			docRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// wordFile returns a Word document whose body is the given WordprocessingML.
+func wordFile(body string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, _ := w.Create("word/document.xml")
+	fmt.Fprintf(f, `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>%s</w:body></w:document>`, body)
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestDocumentService_UploadFile(t *testing.T) {
+	owner := uuid.New()
+	minutes := wordFile(`<w:p><w:pPr><w:outlineLvl w:val="0"/></w:pPr><w:r><w:t>Minutes</w:t></w:r></w:p><w:p><w:r><w:t>Budget approved.</w:t></w:r></w:p>`)
+
+	t.Run("converts Office files to Markdown and keeps the file", func(t *testing.T) {
+		docRepo := new(mocks.MockDocumentRepository)
+		docRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Document")).Return(nil)
+		blobs := newMemoryBlobs()
+
+		docService := service.NewDocumentService(docRepo, new(mocks.MockSummaryRepository), blobs, &fakeLLM{}, service.SummarizerOptions{})
+		document, err := docService.UploadFile(context.Background(), owner, "minutes.docx", models.DocumentTypeDOCX, minutes)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.DocumentTypeDOCX, document.Type)
+		assert.Equal(t, "# Minutes\n\nBudget approved.", document.Content)
+		assert.Equal(t, minutes, blobs.blobs[document.OriginalKey])
+	})
+
+	t.Run("keeps text files as they are", func(t *testing.T) {
+		docRepo := new(mocks.MockDocumentRepository)
+		docRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Document")).Return(nil)
+		blobs := newMemoryBlobs()
+
+		docService := service.NewDocumentService(docRepo, new(mocks.MockSummaryRepository), blobs, &fakeLLM{}, service.SummarizerOptions{})
+		document, err := docService.UploadFile(context.Background(), owner, "notes.md", models.DocumentTypeMD, []byte("# Notes"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "# Notes", document.Content)
+		assert.Equal(t, "UTF-8", document.SourceEncoding)
+		assert.Empty(t, document.OriginalKey)
+		assert.Empty(t, blobs.blobs)
+	})
+
+	t.Run("decodes text files in legacy encodings", func(t *testing.T) {
+		docRepo := new(mocks.MockDocumentRepository)
+		docRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Document")).Return(nil)
+		sjis, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte("議事録\r\n予算は承認されました。\r\n"))
+		require.NoError(t, err)
+
+		docService := newTestDocumentService(docRepo, new(mocks.MockSummaryRepository))
+		document, err := docService.UploadFile(context.Background(), owner, "minutes.txt", models.DocumentTypeTXT, sjis)
+
+		require.NoError(t, err)
+		assert.Equal(t, "議事録\n予算は承認されました。\n", document.Content)
+		assert.Equal(t, "Shift_JIS", document.SourceEncoding)
+		assert.Equal(t, int64(len(document.Content)), document.Size)
+	})
+
+	t.Run("converts web pages to Markdown with their title and URL", func(t *testing.T) {
+		docRepo := new(mocks.MockDocumentRepository)
+		docRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Document")).Return(nil)
+		blobs := newMemoryBlobs()
+		page := []byte(`<html><head><title>Holidays</title><link rel="canonical" href="https://intranet.example.com/holidays"></head>` +
+			`<body><nav><a href="/">Home</a></nav><main><h1>Holidays</h1><p>The office is closed from December 29 to January 3.</p></main></body></html>`)
+
+		docService := service.NewDocumentService(docRepo, new(mocks.MockSummaryRepository), blobs, &fakeLLM{}, service.SummarizerOptions{})
+		document, err := docService.UploadFile(context.Background(), owner, "holidays.html", models.DocumentTypeHTML, page)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.DocumentTypeHTML, document.Type)
+		assert.Equal(t, "# Holidays\n\nThe office is closed from December 29 to January 3.", document.Content)
+		assert.Equal(t, map[string]string{
+			models.MetadataTitle:        "Holidays",
+			models.MetadataCanonicalURL: "https://intranet.example.com/holidays",
+		}, document.Metadata)
+		assert.Equal(t, "UTF-8", document.SourceEncoding)
+		assert.Equal(t, page, blobs.blobs[document.OriginalKey])
+	})
+
+	t.Run("removes the file when the document cannot be saved", func(t *testing.T) {
+		docRepo := new(mocks.MockDocumentRepository)
+		docRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Document")).Return(fmt.Errorf("disk full"))
+		blobs := newMemoryBlobs()
+
+		docService := service.NewDocumentService(docRepo, new(mocks.MockSummaryRepository), blobs, &fakeLLM{}, service.SummarizerOptions{})
+		_, err := docService.UploadFile(context.Background(), owner, "minutes.docx", models.DocumentTypeDOCX, minutes)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "INTERNAL_ERROR")
+		assert.Empty(t, blobs.blobs)
+	})
+
+	tests := []struct {
+		name    string
+		docType models.DocumentType
+		data    []byte
+		wantErr string
+	}{
+		{"no text", models.DocumentTypeDOCX, wordFile(`<w:p/>`), "has no text"},
+		{"not an Office file", models.DocumentTypeXLSX, []byte("a,b,c"), "not a readable .xlsx file"},
+		{"password-protected", models.DocumentTypePPTX, []byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"), "password-protected"},
+		{"page without text", models.DocumentTypeHTML, []byte(`<html><body><nav>Menu</nav></body></html>`), "has no text"},
+		{"broken web archive", models.DocumentTypeHTML, []byte("Content-Type: multipart/related\r\n\r\n"), "not a readable web page"},
+		{"unsupported type", models.DocumentType("exe"), []byte("MZ"), "unsupported document type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docRepo := new(mocks.MockDocumentRepository)
+
+			docService := newTestDocumentService(docRepo, new(mocks.MockSummaryRepository))
+			_, err := docService.UploadFile(context.Background(), owner, "file", tt.docType, tt.data)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "VALIDATION_ERROR")
+			assert.Contains(t, err.Error(), tt.wantErr)
+			docRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// zipFile returns a zip archive of the given files, in the order given as name and content pairs.
+func zipFile(files ...string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for i := 0; i+1 < len(files); i += 2 {
+		f, _ := w.Create(files[i])
+		f.Write([]byte(files[i+1]))
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestDocumentService_UploadBatch(t *testing.T) {
+	owner := uuid.New()
+
+	t.Run("uploads each file in an archive", func(t *testing.T) {
+		docRepo := new(mocks.MockDocumentRepository)
+		docRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Document")).Return(nil)
+		notes := zipFile(
+			"notes/2024-04.md", "# April",
+			"notes/2024-05.txt", "May",
+			"notes/budget.docx", "not a docx",
+			"notes/tool.exe", "MZ",
+			"notes/old.zip", string(zipFile("a.md", "# A")),
+			"../secret.md", "# Secret",
+		)
+
+		docService := newTestDocumentService(docRepo, new(mocks.MockSummaryRepository))
+		results := docService.UploadBatch(context.Background(), owner, "notes.zip", bytes.NewReader(notes), int64(len(notes)))
+
+		require.Len(t, results, 6)
+		for _, result := range results {
+			assert.Equal(t, "notes.zip", result.Archive)
+		}
+		assert.Equal(t, "notes/2024-04.md", results[0].Filename)
+		require.NoError(t, results[0].Err)
+		assert.Equal(t, "notes/2024-04.md", results[0].Document.Title)
+		assert.Equal(t, "# April", results[0].Document.Content)
+		require.NoError(t, results[1].Err)
+		assert.Equal(t, models.DocumentTypeTXT, results[1].Document.Type)
+
+		for i, wantErr := range map[int]string{
+			2: "not a readable .docx file",
+			3: "unsupported file type",
+			4: "archives inside archives",
+			5: "leads out of the archive",
+		} {
+			assert.Nil(t, results[i].Document, results[i].Filename)
+			require.Error(t, results[i].Err, results[i].Filename)
+			assert.Contains(t, results[i].Err.Error(), wantErr)
+		}
+		docRepo.AssertNumberOfCalls(t, "Create", 2)
+	})
+
+	t.Run("uploads other files as they are", func(t *testing.T) {
+		docRepo := new(mocks.MockDocumentRepository)
+		docRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Document")).Return(nil)
+
+		docService := newTestDocumentService(docRepo, new(mocks.MockSummaryRepository))
+		results := docService.UploadBatch(context.Background(), owner, "minutes.md", strings.NewReader("# Minutes"), int64(len("# Minutes")))
+
+		require.Len(t, results, 1)
+		require.NoError(t, results[0].Err)
+		assert.Equal(t, "minutes.md", results[0].Filename)
+		assert.Empty(t, results[0].Archive)
+		assert.Equal(t, "# Minutes", results[0].Document.Content)
+	})
+
+	t.Run("stops at a file that expands to more than declared", func(t *testing.T) {
+		var compressed bytes.Buffer
+		fw, _ := flate.NewWriter(&compressed, flate.BestCompression)
+		fw.Write(make([]byte, 1<<20))
+		fw.Close()
+		var buf bytes.Buffer
+		w := zip.NewWriter(&buf)
+		f, _ := w.Create("a.md")
+		f.Write([]byte("# A"))
+		f, _ = w.CreateRaw(&zip.FileHeader{Name: "bomb.md", Method: zip.Deflate, UncompressedSize64: 10})
+		f.Write(compressed.Bytes())
+		f, _ = w.Create("c.md")
+		f.Write([]byte("# C"))
+		w.Close()
+
+		docRepo := new(mocks.MockDocumentRepository)
+		docRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Document")).Return(nil)
+
+		docService := newTestDocumentService(docRepo, new(mocks.MockSummaryRepository))
+		results := docService.UploadBatch(context.Background(), owner, "notes.zip", bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+
+		require.Len(t, results, 2)
+		assert.Equal(t, "a.md", results[0].Filename)
+		require.NoError(t, results[0].Err)
+		assert.Equal(t, "notes.zip", results[1].Filename)
+		assert.ErrorContains(t, results[1].Err, "not a readable zip archive")
+		docRepo.AssertNumberOfCalls(t, "Create", 1)
+	})
+
+	t.Run("rejects files past the size limit one by one", func(t *testing.T) {
+		docRepo := new(mocks.MockDocumentRepository)
+
+		docService := newTestDocumentService(docRepo, new(mocks.MockSummaryRepository))
+		results := docService.UploadBatch(context.Background(), owner, "scan.pdf", strings.NewReader("%PDF-"), service.MaxFileSize+1)
+
+		require.Len(t, results, 1)
+		assert.ErrorContains(t, results[0].Err, "the file is larger than 32 MB")
+		docRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects broken archives whole", func(t *testing.T) {
+		docRepo := new(mocks.MockDocumentRepository)
+
+		docService := newTestDocumentService(docRepo, new(mocks.MockSummaryRepository))
+		results := docService.UploadBatch(context.Background(), owner, "NOTES.ZIP", strings.NewReader("# not a zip"), int64(len("# not a zip")))
+
+		require.Len(t, results, 1)
+		assert.Equal(t, "NOTES.ZIP", results[0].Filename)
+		require.Error(t, results[0].Err)
+		assert.Contains(t, results[0].Err.Error(), "VALIDATION_ERROR")
+		assert.Contains(t, results[0].Err.Error(), "not a readable zip archive")
+		docRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDocumentService_GetOriginal(t *testing.T) {
+	owner := uuid.New()
+	converted := models.NewDocument(owner, "plan.pptx", "## Slide 1", models.DocumentTypePPTX, 10)
+	converted.OriginalKey = "originals/ab/plan"
+	text := models.NewDocument(owner, "notes.md", "# Notes", models.DocumentTypeMD, 7)
+	docRepo := new(mocks.MockDocumentRepository)
+	docRepo.On("GetByID", mock.Anything, converted.ID).Return(converted, nil)
+	docRepo.On("GetByID", mock.Anything, text.ID).Return(text, nil)
+	blobs := newMemoryBlobs()
+	blobs.blobs[converted.OriginalKey] = []byte("PK")
+	docService := service.NewDocumentService(docRepo, new(mocks.MockSummaryRepository), blobs, &fakeLLM{}, service.SummarizerOptions{})
+
+	document, data, err := docService.GetOriginal(context.Background(), owner, converted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, converted, document)
+	assert.Equal(t, []byte("PK"), data)
+
+	_, _, err = docService.GetOriginal(context.Background(), uuid.New(), converted.ID)
+	assert.ErrorContains(t, err, "NOT_FOUND")
+
+	_, _, err = docService.GetOriginal(context.Background(), owner, text.ID)
+	assert.ErrorContains(t, err, "NOT_FOUND")
+}
+
+func TestDocumentService_GetUserDocument_Ownership(t *testing.T) {
+	owner := uuid.New()
+	document := models.NewDocument(owner, "notes.md", "# Notes", models.DocumentTypeMD, 7)
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		stored  *models.Document
+		wantErr string
+	}{
+		{name: "owner can read", userID: owner, stored: document},
+		{name: "other user gets not found", userID: uuid.New(), stored: document, wantErr: "NOT_FOUND"},
+		{name: "missing document", userID: owner, stored: nil, wantErr: "NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docRepo := new(mocks.MockDocumentRepository)
+			if tt.stored == nil {
+				docRepo.On("GetByID", mock.Anything, document.ID).Return(nil, nil)
+			} else {
+				docRepo.On("GetByID", mock.Anything, document.ID).Return(tt.stored, nil)
+			}
+
+			docService := newTestDocumentService(docRepo, new(mocks.MockSummaryRepository))
+			got, err := docService.GetUserDocument(context.Background(), tt.userID, document.ID)
+
+			if tt.wantErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, document.ID, got.ID)
+			}
+		})
+	}
+}
+
+func TestDocumentService_UpdateDocument(t *testing.T) {
+	owner := uuid.New()
+	document := models.NewDocument(owner, "notes.md", "old", models.DocumentTypeMD, 3)
+	document.MarkProcessed()
+
+	docRepo := new(mocks.MockDocumentRepository)
+	docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+	docRepo.On("Update", mock.Anything, document).Return(nil)
+
+	title := "renamed.md"
+	content := "new content"
+	docService := newTestDocumentService(docRepo, new(mocks.MockSummaryRepository))
+	updated, err := docService.UpdateDocument(context.Background(), owner, document.ID, &title, &content)
+
+	assert.NoError(t, err)
+	assert.Equal(t, title, updated.Title)
+	assert.Equal(t, content, updated.Content)
+	assert.Equal(t, int64(len(content)), updated.Size)
+	assert.False(t, updated.IsProcessed(), "content changes invalidate the processed state")
+
+	empty := ""
+	_, err = docService.UpdateDocument(context.Background(), owner, document.ID, &empty, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "VALIDATION_ERROR")
+}
+
+func TestDocumentService_DeleteDocument(t *testing.T) {
+	owner := uuid.New()
+	document := models.NewDocument(owner, "notes.md", "content", models.DocumentTypeMD, 7)
+
+	t.Run("owner deletes document and summaries", func(t *testing.T) {
+		docRepo := new(mocks.MockDocumentRepository)
+		summaryRepo := new(mocks.MockSummaryRepository)
+		docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+		summaryRepo.On("DeleteByDocumentID", mock.Anything, document.ID).Return(nil)
+		docRepo.On("Delete", mock.Anything, document.ID).Return(nil)
+
+		err := newTestDocumentService(docRepo, summaryRepo).DeleteDocument(context.Background(), owner, document.ID)
+
+		assert.NoError(t, err)
+		docRepo.AssertExpectations(t)
+		summaryRepo.AssertExpectations(t)
+	})
+
+	t.Run("removes the original file", func(t *testing.T) {
+		converted := models.NewDocument(owner, "minutes.docx", "# Minutes", models.DocumentTypeDOCX, 9)
+		converted.OriginalKey = "originals/ab/minutes"
+		docRepo := new(mocks.MockDocumentRepository)
+		summaryRepo := new(mocks.MockSummaryRepository)
+		docRepo.On("GetByID", mock.Anything, converted.ID).Return(converted, nil)
+		summaryRepo.On("DeleteByDocumentID", mock.Anything, converted.ID).Return(nil)
+		docRepo.On("Delete", mock.Anything, converted.ID).Return(nil)
+		blobs := newMemoryBlobs()
+		blobs.blobs[converted.OriginalKey] = []byte("PK")
+
+		docService := service.NewDocumentService(docRepo, summaryRepo, blobs, &fakeLLM{}, service.SummarizerOptions{})
+		err := docService.DeleteDocument(context.Background(), owner, converted.ID)
+
+		assert.NoError(t, err)
+		assert.Empty(t, blobs.blobs)
+	})
+
+	t.Run("other user cannot delete", func(t *testing.T) {
+		docRepo := new(mocks.MockDocumentRepository)
+		summaryRepo := new(mocks.MockSummaryRepository)
+		docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+
+		err := newTestDocumentService(docRepo, summaryRepo).DeleteDocument(context.Background(), uuid.New(), document.ID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "NOT_FOUND")
+		docRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+		summaryRepo.AssertNotCalled(t, "DeleteByDocumentID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDocumentService_ListUserDocuments_Pagination(t *testing.T) {
+	userID := uuid.New()
+	base := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	items := make([]*models.DocumentListItem, 3)
+	for i := range items {
+		items[i] = &models.DocumentListItem{
+			ID:         uuid.New(),
+			UserID:     userID,
+			Title:      "doc",
+			Type:       models.DocumentTypeMD,
+			UploadedAt: base.Add(-time.Duration(i) * time.Hour),
+		}
+	}
+
+	docRepo := new(mocks.MockDocumentRepository)
+	// First page: limit 2 is requested from the repository as 3 to detect a following page.
+	docRepo.On("ListByUserID", mock.Anything, userID, mock.MatchedBy(func(opts repository.DocumentListOptions) bool {
+		return opts.After == nil && opts.Limit == 3 &&
+			opts.SortBy == repository.DocumentSortUploadedAt && opts.SortOrder == repository.SortDesc
+	})).Return(items, nil).Once()
+	// Second page resumes after the last item of the first one.
+	docRepo.On("ListByUserID", mock.Anything, userID, mock.MatchedBy(func(opts repository.DocumentListOptions) bool {
+		return opts.After != nil && opts.After.ID == items[1].ID && opts.After.UploadedAt.Equal(items[1].UploadedAt)
+	})).Return(items[2:], nil).Once()
+
+	docService := newTestDocumentService(docRepo, new(mocks.MockSummaryRepository))
+
+	first, err := docService.ListUserDocuments(context.Background(), userID, service.ListDocumentsParams{Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, first.Documents, 2)
+	assert.NotEmpty(t, first.NextCursor)
+
+	second, err := docService.ListUserDocuments(context.Background(), userID, service.ListDocumentsParams{Limit: 2, Cursor: first.NextCursor})
+	assert.NoError(t, err)
+	assert.Len(t, second.Documents, 1)
+	assert.Empty(t, second.NextCursor)
+
+	// A cursor cannot be replayed against a different sort.
+	_, err = docService.ListUserDocuments(context.Background(), userID, service.ListDocumentsParams{
+		Limit:  2,
+		Cursor: first.NextCursor,
+		SortBy: repository.DocumentSortTitle,
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "VALIDATION_ERROR")
+
+	docRepo.AssertExpectations(t)
+}
+
+func TestDocumentService_ListUserDocuments_InvalidParams(t *testing.T) {
+	from := time.Now()
+	to := from.Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		params service.ListDocumentsParams
+	}{
+		{name: "unknown sort", params: service.ListDocumentsParams{SortBy: "content"}},
+		{name: "unknown order", params: service.ListDocumentsParams{SortOrder: "sideways"}},
+		{name: "unknown type", params: service.ListDocumentsParams{Type: "exe"}},
+		{name: "inverted date range", params: service.ListDocumentsParams{UploadedFrom: &from, UploadedTo: &to}},
+		{name: "garbage cursor", params: service.ListDocumentsParams{Cursor: "!!!"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docRepo := new(mocks.MockDocumentRepository)
+			docService := newTestDocumentService(docRepo, new(mocks.MockSummaryRepository))
+
+			_, err := docService.ListUserDocuments(context.Background(), uuid.New(), tt.params)
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "VALIDATION_ERROR")
+			docRepo.AssertNotCalled(t, "ListByUserID", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestDocumentService_GenerateSummary(t *testing.T) {
+	owner := uuid.New()
+
+	t.Run("summarizes with the injected model and records options", func(t *testing.T) {
+		document := models.NewDocument(owner, "notes.md", "# Notes\n\nQuarterly results.", models.DocumentTypeMD, 28)
+		docRepo := new(mocks.MockDocumentRepository)
+		summaryRepo := new(mocks.MockSummaryRepository)
+		docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+		summaryRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Summary")).Return(nil)
+		docRepo.On("Update", mock.Anything, document).Return(nil)
+		model := &fakeLLM{}
+
+		docService := service.NewDocumentService(docRepo, summaryRepo, newMemoryBlobs(), model, service.SummarizerOptions{})
+		summary, err := docService.GenerateSummary(context.Background(), owner, document.ID, models.SummaryOptions{
+			Style:    models.SummaryStyleBullets,
+			Language: "en",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "summary 1", summary.Content)
+		assert.Equal(t, models.SummaryLengthMedium, summary.Length)
+		assert.Equal(t, models.SummaryStyleBullets, summary.Style)
+		assert.Equal(t, "en", summary.Language)
+		assert.True(t, document.IsProcessed())
+		assert.Len(t, model.prompts, 1)
+		summaryRepo.AssertExpectations(t)
+		docRepo.AssertExpectations(t)
+	})
+
+	t.Run("marks the pages of PDF documents", func(t *testing.T) {
+		content, pages := models.JoinPages([]string{"Overview", "Results"})
+		document := models.NewDocument(owner, "report.pdf", content, models.DocumentTypePDF, int64(len(content)))
+		document.Pages = pages
+		docRepo := new(mocks.MockDocumentRepository)
+		summaryRepo := new(mocks.MockSummaryRepository)
+		docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+		summaryRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Summary")).Return(nil)
+		docRepo.On("Update", mock.Anything, document).Return(nil)
+		model := &fakeLLM{}
+
+		docService := service.NewDocumentService(docRepo, summaryRepo, newMemoryBlobs(), model, service.SummarizerOptions{})
+		_, err := docService.GenerateSummary(context.Background(), owner, document.ID, models.SummaryOptions{})
+
+		assert.NoError(t, err)
+		require.Len(t, model.prompts, 1)
+		assert.Contains(t, model.prompts[0], "[Page 1]\nOverview\n\n[Page 2]\nResults")
+	})
+
+	t.Run("invalid options are rejected before calling the model", func(t *testing.T) {
+		model := &fakeLLM{}
+		docService := service.NewDocumentService(new(mocks.MockDocumentRepository), new(mocks.MockSummaryRepository), newMemoryBlobs(), model, service.SummarizerOptions{})
+
+		_, err := docService.GenerateSummary(context.Background(), owner, uuid.New(), models.SummaryOptions{Style: "poem"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "VALIDATION_ERROR")
+		assert.Empty(t, model.prompts)
+	})
+}
+
+func TestDocumentService_StreamSummary(t *testing.T) {
+	owner := uuid.New()
+	newDocument := func() *models.Document {
+		return models.NewDocument(owner, "notes.md", "# Notes\n\nQuarterly results.", models.DocumentTypeMD, 28)
+	}
+
+	t.Run("saves the assembled text once the stream completes", func(t *testing.T) {
+		document := newDocument()
+		docRepo := new(mocks.MockDocumentRepository)
+		summaryRepo := new(mocks.MockSummaryRepository)
+		docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+		summaryRepo.On("Create", mock.Anything, mock.MatchedBy(func(s *models.Summary) bool {
+			return s.Content == "short summary" && s.Status == models.SummaryStatusCompleted
+		})).Return(nil)
+		docRepo.On("Update", mock.Anything, document).Return(nil)
+
+		docService := service.NewDocumentService(docRepo, summaryRepo, newMemoryBlobs(), &streamingLLM{deltas: []string{"short ", "summary"}}, service.SummarizerOptions{})
+		var deltas []string
+		summary, err := docService.StreamSummary(context.Background(), owner, document.ID, models.SummaryOptions{}, func(delta string) error {
+			deltas = append(deltas, delta)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"short ", "summary"}, deltas)
+		assert.Equal(t, models.SummaryStatusCompleted, summary.Status)
+		assert.True(t, document.IsProcessed())
+		summaryRepo.AssertExpectations(t)
+		docRepo.AssertExpectations(t)
+	})
+
+	t.Run("records a partial summary when the client goes away", func(t *testing.T) {
+		document := newDocument()
+		docRepo := new(mocks.MockDocumentRepository)
+		summaryRepo := new(mocks.MockSummaryRepository)
+		docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+		summaryRepo.On("Create", mock.Anything, mock.MatchedBy(func(s *models.Summary) bool {
+			// Everything the model produced is kept, including the delta that could not be sent.
+			return s.Content == "short summ" && s.Status == models.SummaryStatusPartial
+		})).Return(nil)
+
+		docService := service.NewDocumentService(docRepo, summaryRepo, newMemoryBlobs(), &streamingLLM{deltas: []string{"short ", "summ", "ary"}}, service.SummarizerOptions{})
+		sent := 0
+		summary, err := docService.StreamSummary(context.Background(), owner, document.ID, models.SummaryOptions{}, func(delta string) error {
+			if sent == 1 {
+				return fmt.Errorf("broken pipe")
+			}
+			sent++
+			return nil
+		})
+
+		assert.Error(t, err)
+		assert.Equal(t, models.SummaryStatusPartial, summary.Status)
+		assert.False(t, document.IsProcessed())
+		summaryRepo.AssertExpectations(t)
+		docRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("records a failed summary when no text arrived", func(t *testing.T) {
+		document := newDocument()
+		docRepo := new(mocks.MockDocumentRepository)
+		summaryRepo := new(mocks.MockSummaryRepository)
+		docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+		summaryRepo.On("Create", mock.Anything, mock.MatchedBy(func(s *models.Summary) bool {
+			return s.Content == "" && s.Status == models.SummaryStatusFailed
+		})).Return(nil)
+
+		docService := service.NewDocumentService(docRepo, summaryRepo, newMemoryBlobs(), &streamingLLM{err: fmt.Errorf("provider error")}, service.SummarizerOptions{})
+		summary, err := docService.StreamSummary(context.Background(), owner, document.ID, models.SummaryOptions{}, func(string) error { return nil })
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "provider error")
+		assert.Equal(t, models.SummaryStatusFailed, summary.Status)
+		summaryRepo.AssertExpectations(t)
+	})
+
+	t.Run("foreign documents fail before streaming", func(t *testing.T) {
+		document := newDocument()
+		docRepo := new(mocks.MockDocumentRepository)
+		docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+		model := &streamingLLM{deltas: []string{"text"}}
+
+		docService := service.NewDocumentService(docRepo, new(mocks.MockSummaryRepository), newMemoryBlobs(), model, service.SummarizerOptions{})
+		summary, err := docService.StreamSummary(context.Background(), uuid.New(), document.ID, models.SummaryOptions{}, func(string) error { return nil })
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "NOT_FOUND")
+		assert.Nil(t, summary)
+		assert.Empty(t, model.prompts)
+	})
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+	"github/k-tsurumaki/quilldeck/tests/unit/domain/service/mocks"
+)
+
+// fakeEmbedder returns a fixed two-dimensional vector per input and records the batches it was sent.
+type fakeEmbedder struct {
+	batches [][]string
+	fail    bool
+}
+
+func (f *fakeEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	f.batches = append(f.batches, texts)
+	if f.fail {
+		return nil, fmt.Errorf("provider error")
+	}
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = []float32{3, 4}
+	}
+	return vectors, nil
+}
+
+func TestEmbeddingService_IndexDocument(t *testing.T) {
+	document := models.NewDocument(uuid.New(), "notes.md", "# Plan\n\n"+strings.Repeat("alpha ", 30)+"\n\n# Budget\n\n"+strings.Repeat("beta ", 30), models.DocumentTypeMD, 400)
+
+	t.Run("embeds chunks in batches and replaces the stored ones", func(t *testing.T) {
+		docRepo := new(mocks.MockDocumentRepository)
+		docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+		embeddingRepo := new(mocks.MockEmbeddingRepository)
+		var saved []*models.DocumentChunk
+		embeddingRepo.On("ReplaceDocumentChunks", mock.Anything, document.ID, mock.Anything).
+			Run(func(args mock.Arguments) { saved = args.Get(2).([]*models.DocumentChunk) }).
+			Return(nil)
+		embedder := &fakeEmbedder{}
+
+		svc := service.NewEmbeddingService(embeddingRepo, docRepo, embedder, service.EmbeddingOptions{Model: "m", ChunkChars: 200, BatchSize: 1})
+		require.NoError(t, svc.IndexDocument(context.Background(), document.ID))
+
+		require.Len(t, saved, 2)
+		assert.Len(t, embedder.batches, 2)
+		// The title and heading give each chunk its context.
+		assert.True(t, strings.HasPrefix(embedder.batches[1][0], "notes.md > Budget\n\n"))
+		for i, chunk := range saved {
+			assert.Equal(t, i, chunk.Index)
+			assert.Equal(t, "m", chunk.Model)
+			assert.Equal(t, document.Content[chunk.Start:chunk.End], chunk.Content)
+			assert.InDeltaSlice(t, []float32{0.6, 0.8}, chunk.Embedding, 1e-6)
+		}
+		assert.Equal(t, "Budget", saved[1].Heading)
+	})
+
+	t.Run("keeps the old chunks when the provider fails", func(t *testing.T) {
+		docRepo := new(mocks.MockDocumentRepository)
+		docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+		embeddingRepo := new(mocks.MockEmbeddingRepository)
+
+		svc := service.NewEmbeddingService(embeddingRepo, docRepo, &fakeEmbedder{fail: true}, service.EmbeddingOptions{Model: "m"})
+		err := svc.IndexDocument(context.Background(), document.ID)
+
+		assert.Error(t, err)
+		embeddingRepo.AssertNotCalled(t, "ReplaceDocumentChunks", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestEmbeddingService_SemanticSearch(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("embeds the query and returns the nearest chunks", func(t *testing.T) {
+		embeddingRepo := new(mocks.MockEmbeddingRepository)
+		matches := []*models.ChunkMatch{{Title: "notes.md", Score: 0.9}}
+		embeddingRepo.On("Nearest", mock.Anything, userID, "m", []float32{0.6, 0.8}, 50).Return(matches, nil)
+		embedder := &fakeEmbedder{}
+
+		svc := service.NewEmbeddingService(embeddingRepo, new(mocks.MockDocumentRepository), embedder, service.EmbeddingOptions{Model: "m"})
+		got, err := svc.SemanticSearch(context.Background(), userID, "  budget cuts ", 500)
+
+		require.NoError(t, err)
+		assert.Equal(t, matches, got)
+		assert.Equal(t, [][]string{{"budget cuts"}}, embedder.batches)
+		embeddingRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects an empty query", func(t *testing.T) {
+		embedder := &fakeEmbedder{}
+		svc := service.NewEmbeddingService(new(mocks.MockEmbeddingRepository), new(mocks.MockDocumentRepository), embedder, service.EmbeddingOptions{Model: "m"})
+
+		_, err := svc.SemanticSearch(context.Background(), userID, "   ", 0)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "VALIDATION")
+		assert.Empty(t, embedder.batches)
+	})
+
+	t.Run("is unavailable without an embedder", func(t *testing.T) {
+		svc := service.NewEmbeddingService(new(mocks.MockEmbeddingRepository), new(mocks.MockDocumentRepository), nil, service.EmbeddingOptions{})
+
+		_, err := svc.SemanticSearch(context.Background(), userID, "budget", 0)
+
+		assert.False(t, svc.Enabled())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "UNAVAILABLE")
+	})
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+	"github/k-tsurumaki/quilldeck/tests/unit/domain/service/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobService_EnqueueSummary(t *testing.T) {
+	owner := uuid.New()
+	document := models.NewDocument(owner, "notes.md", "# Notes", models.DocumentTypeMD, 7)
+
+	t.Run("queues a job with the resolved options", func(t *testing.T) {
+		docRepo := new(mocks.MockDocumentRepository)
+		jobRepo := new(mocks.MockJobRepository)
+		docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+		jobRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Job")).Return(nil)
+
+		jobService := service.NewJobService(jobRepo, newTestDocumentService(docRepo, new(mocks.MockSummaryRepository)), nil, service.JobOptions{})
+		job, err := jobService.EnqueueSummary(context.Background(), owner, document.ID, models.SummaryOptions{Language: "en"})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.JobStatusQueued, job.Status)
+		assert.Equal(t, models.JobTypeSummary, job.Type)
+		var opts models.SummaryOptions
+		require.NoError(t, json.Unmarshal(job.Payload, &opts))
+		assert.Equal(t, models.SummaryOptions{Length: models.SummaryLengthMedium, Style: models.SummaryStyleBusiness, Language: "en"}, opts)
+		jobRepo.AssertExpectations(t)
+	})
+
+	t.Run("other user's document is not queued", func(t *testing.T) {
+		docRepo := new(mocks.MockDocumentRepository)
+		jobRepo := new(mocks.MockJobRepository)
+		docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+
+		jobService := service.NewJobService(jobRepo, newTestDocumentService(docRepo, new(mocks.MockSummaryRepository)), nil, service.JobOptions{})
+		_, err := jobService.EnqueueSummary(context.Background(), uuid.New(), document.ID, models.SummaryOptions{})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "NOT_FOUND")
+		jobRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestJobService_EnqueueEmbedding_OncePerDocument(t *testing.T) {
+	owner, documentID := uuid.New(), uuid.New()
+	jobRepo := new(mocks.MockJobRepository)
+	jobRepo.On("CreateIfNotQueued", mock.Anything, mock.AnythingOfType("*models.Job")).Return(true, nil).Once()
+	jobRepo.On("CreateIfNotQueued", mock.Anything, mock.AnythingOfType("*models.Job")).Return(false, nil)
+	embeddingService := service.NewEmbeddingService(new(mocks.MockEmbeddingRepository), new(mocks.MockDocumentRepository), &fakeEmbedder{}, service.EmbeddingOptions{Model: "m"})
+	jobService := service.NewJobService(jobRepo, newTestDocumentService(new(mocks.MockDocumentRepository), new(mocks.MockSummaryRepository)), embeddingService, service.JobOptions{})
+
+	job, err := jobService.EnqueueEmbedding(context.Background(), owner, documentID)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, models.JobTypeEmbedding, job.Type)
+
+	job, err = jobService.EnqueueEmbedding(context.Background(), owner, documentID)
+	assert.NoError(t, err)
+	assert.Nil(t, job, "already queued")
+}
+
+func TestJobService_GetJob_Ownership(t *testing.T) {
+	owner := uuid.New()
+	job := models.NewJob(owner, uuid.New(), models.JobTypeSummary, json.RawMessage(`{}`))
+	jobRepo := new(mocks.MockJobRepository)
+	jobRepo.On("GetByID", mock.Anything, job.ID).Return(job, nil)
+	jobService := service.NewJobService(jobRepo, newTestDocumentService(new(mocks.MockDocumentRepository), new(mocks.MockSummaryRepository)), nil, service.JobOptions{})
+
+	got, err := jobService.GetJob(context.Background(), owner, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, job, got)
+
+	_, err = jobService.GetJob(context.Background(), uuid.New(), job.ID)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "NOT_FOUND")
+}
+
+func TestJobService_StartRecoversInterruptedJobs(t *testing.T) {
+	retry := models.NewJob(uuid.New(), uuid.New(), models.JobTypeSummary, json.RawMessage(`{}`))
+	retry.Start()
+	exhausted := models.NewJob(uuid.New(), uuid.New(), models.JobTypeSummary, json.RawMessage(`{}`))
+	for i := 0; i < 3; i++ {
+		exhausted.Start()
+	}
+
+	jobRepo := new(mocks.MockJobRepository)
+	recoveredElsewhere := models.NewJob(uuid.New(), uuid.New(), models.JobTypeSummary, json.RawMessage(`{}`))
+	recoveredElsewhere.Start()
+	superseded := models.NewJob(uuid.New(), uuid.New(), models.JobTypeEmbedding, json.RawMessage(`{}`))
+	superseded.Start()
+	jobRepo.On("HasQueued", mock.Anything, superseded.DocumentID, models.JobTypeEmbedding).Return(true, nil)
+	jobRepo.On("GetExpired", mock.Anything, mock.Anything).Return([]*models.Job{retry, exhausted, recoveredElsewhere, superseded}, nil).Once()
+	jobRepo.On("GetExpired", mock.Anything, mock.Anything).Return([]*models.Job{}, nil)
+	jobRepo.On("UpdateExpired", mock.Anything, recoveredElsewhere, mock.Anything).Return(false, nil)
+	jobRepo.On("UpdateExpired", mock.Anything, mock.AnythingOfType("*models.Job"), mock.Anything).Return(true, nil)
+	jobRepo.On("ClaimNext", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	jobService := service.NewJobService(jobRepo, newTestDocumentService(new(mocks.MockDocumentRepository), new(mocks.MockSummaryRepository)), nil, service.JobOptions{MaxAttempts: 3})
+	require.NoError(t, jobService.Start(ctx))
+	cancel()
+	jobService.Wait()
+
+	assert.Equal(t, models.JobStatusQueued, retry.Status)
+	assert.Equal(t, models.JobStatusFailed, exhausted.Status)
+	assert.Contains(t, exhausted.Error, "interrupted 3 times")
+	assert.Equal(t, models.JobStatusFailed, superseded.Status, "the document is queued for embedding again")
+	jobRepo.AssertCalled(t, "UpdateExpired", mock.Anything, recoveredElsewhere, mock.Anything)
+}
+
+func TestJobService_WorkerRunsSummaryJob(t *testing.T) {
+	owner := uuid.New()
+	document := models.NewDocument(owner, "notes.md", "# Notes\n\nBody.", models.DocumentTypeMD, 15)
+	job := models.NewJob(owner, document.ID, models.JobTypeSummary, json.RawMessage(`{"length":"short","style":"casual","language":"ja"}`))
+	job.Start()
+
+	docRepo := new(mocks.MockDocumentRepository)
+	summaryRepo := new(mocks.MockSummaryRepository)
+	jobRepo := new(mocks.MockJobRepository)
+	docRepo.On("GetByID", mock.Anything, document.ID).Return(document, nil)
+	docRepo.On("Update", mock.Anything, document).Return(nil)
+	summaryRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Summary")).Return(nil)
+	jobRepo.On("GetExpired", mock.Anything, mock.Anything).Return([]*models.Job{}, nil)
+	jobRepo.On("ClaimNext", mock.Anything, mock.Anything, mock.Anything).Return(job, nil).Once()
+	jobRepo.On("ClaimNext", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	finished := make(chan struct{})
+	jobRepo.On("UpdateLeased", mock.Anything, job, mock.Anything).Return(true, nil).Run(func(mock.Arguments) { close(finished) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobService := service.NewJobService(jobRepo, newTestDocumentService(docRepo, summaryRepo), nil, service.JobOptions{Workers: 1})
+	require.NoError(t, jobService.Start(ctx))
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job was not processed")
+	}
+	cancel()
+	jobService.Wait()
+
+	assert.Equal(t, models.JobStatusSucceeded, job.Status)
+	require.NotNil(t, job.ResultID)
+	assert.NotNil(t, job.FinishedAt)
+	summaryRepo.AssertExpectations(t)
+}
+
+func TestJobService_WorkerRecordsFailure(t *testing.T) {
+	owner := uuid.New()
+	job := models.NewJob(owner, uuid.New(), models.JobTypeSummary, json.RawMessage(`{}`))
+	job.Start()
+
+	docRepo := new(mocks.MockDocumentRepository)
+	jobRepo := new(mocks.MockJobRepository)
+	docRepo.On("GetByID", mock.Anything, job.DocumentID).Return(nil, nil)
+	jobRepo.On("GetExpired", mock.Anything, mock.Anything).Return([]*models.Job{}, nil)
+	jobRepo.On("ClaimNext", mock.Anything, mock.Anything, mock.Anything).Return(job, nil).Once()
+	jobRepo.On("ClaimNext", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	finished := make(chan struct{})
+	jobRepo.On("UpdateLeased", mock.Anything, job, mock.Anything).Return(true, nil).Run(func(mock.Arguments) { close(finished) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobService := service.NewJobService(jobRepo, newTestDocumentService(docRepo, new(mocks.MockSummaryRepository)), nil, service.JobOptions{Workers: 1})
+	require.NoError(t, jobService.Start(ctx))
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job was not processed")
+	}
+	cancel()
+	jobService.Wait()
+
+	assert.Equal(t, models.JobStatusFailed, job.Status)
+	assert.Contains(t, job.Error, "document not found")
+}
+
+func TestJobService_WorkerStopsJobWhoseLeaseWasLost(t *testing.T) {
+	job := models.NewJob(uuid.New(), uuid.New(), models.JobTypeSummary, json.RawMessage(`{}`))
+	job.Start()
+
+	docRepo := new(mocks.MockDocumentRepository)
+	jobRepo := new(mocks.MockJobRepository)
+	stopped := make(chan struct{})
+	docRepo.On("GetByID", mock.Anything, job.DocumentID).Return(nil, context.Canceled).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+		close(stopped)
+	})
+	jobRepo.On("GetExpired", mock.Anything, mock.Anything).Return([]*models.Job{}, nil)
+	jobRepo.On("ClaimNext", mock.Anything, mock.Anything, 30*time.Millisecond).Return(job, nil).Once()
+	jobRepo.On("ClaimNext", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	// Another process found the lease expired and queued the job again.
+	jobRepo.On("RenewLease", mock.Anything, job.ID, mock.Anything, mock.Anything).Return(false, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobService := service.NewJobService(jobRepo, newTestDocumentService(docRepo, new(mocks.MockSummaryRepository)), nil, service.JobOptions{Workers: 1, Lease: 30 * time.Millisecond})
+	require.NoError(t, jobService.Start(ctx))
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job was not stopped")
+	}
+	cancel()
+	jobService.Wait()
+
+	jobRepo.AssertNotCalled(t, "UpdateLeased", mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, models.JobStatusRunning, job.Status)
+}
@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+)
+
+type MockArtifactRepository struct {
+	mock.Mock
+}
+
+func (m *MockArtifactRepository) Create(ctx context.Context, artifact *models.Artifact) error {
+	args := m.Called(ctx, artifact)
+	return args.Error(0)
+}
+
+func (m *MockArtifactRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Artifact, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Artifact), args.Error(1)
+}
+
+func (m *MockArtifactRepository) GetByUserID(ctx context.Context, userID uuid.UUID, filter repository.ArtifactFilter) ([]*models.Artifact, error) {
+	args := m.Called(ctx, userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Artifact), args.Error(1)
+}
+
+func (m *MockArtifactRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
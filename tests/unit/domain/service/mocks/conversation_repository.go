@@ -0,0 +1,57 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type MockConversationRepository struct {
+	mock.Mock
+}
+
+func (m *MockConversationRepository) Create(ctx context.Context, conversation *models.Conversation) error {
+	args := m.Called(ctx, conversation)
+	return args.Error(0)
+}
+
+func (m *MockConversationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Conversation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) ListByDocumentID(ctx context.Context, userID, documentID uuid.UUID) ([]*models.Conversation, error) {
+	args := m.Called(ctx, userID, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) Update(ctx context.Context, conversation *models.Conversation) error {
+	args := m.Called(ctx, conversation)
+	return args.Error(0)
+}
+
+func (m *MockConversationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockConversationRepository) CreateMessage(ctx context.Context, message *models.Message) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockConversationRepository) ListMessages(ctx context.Context, conversationID uuid.UUID) ([]*models.Message, error) {
+	args := m.Called(ctx, conversationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Message), args.Error(1)
+}
@@ -0,0 +1,39 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type MockDeckRepository struct {
+	mock.Mock
+}
+
+func (m *MockDeckRepository) Create(ctx context.Context, deck *models.Deck) error {
+	args := m.Called(ctx, deck)
+	return args.Error(0)
+}
+
+func (m *MockDeckRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Deck, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Deck), args.Error(1)
+}
+
+func (m *MockDeckRepository) GetByDocumentID(ctx context.Context, documentID uuid.UUID) ([]*models.Deck, error) {
+	args := m.Called(ctx, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Deck), args.Error(1)
+}
+
+func (m *MockDeckRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
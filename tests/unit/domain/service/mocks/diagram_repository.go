@@ -0,0 +1,39 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+)
+
+type MockDiagramRepository struct {
+	mock.Mock
+}
+
+func (m *MockDiagramRepository) Create(ctx context.Context, diagram *models.Diagram) error {
+	args := m.Called(ctx, diagram)
+	return args.Error(0)
+}
+
+func (m *MockDiagramRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Diagram, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Diagram), args.Error(1)
+}
+
+func (m *MockDiagramRepository) GetByDocumentID(ctx context.Context, documentID uuid.UUID) ([]*models.Diagram, error) {
+	args := m.Called(ctx, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Diagram), args.Error(1)
+}
+
+func (m *MockDiagramRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
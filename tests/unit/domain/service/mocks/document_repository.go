@@ -0,0 +1,93 @@
+package mocks
+
+import (
+	"context"
+
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDocumentRepository struct {
+	mock.Mock
+}
+
+func (m *MockDocumentRepository) Create(ctx context.Context, document *models.Document) error {
+	args := m.Called(ctx, document)
+	return args.Error(0)
+}
+
+func (m *MockDocumentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Document, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Document), args.Error(1)
+}
+
+func (m *MockDocumentRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Document, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Document), args.Error(1)
+}
+
+func (m *MockDocumentRepository) ListByUserID(ctx context.Context, userID uuid.UUID, opts repository.DocumentListOptions) ([]*models.DocumentListItem, error) {
+	args := m.Called(ctx, userID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DocumentListItem), args.Error(1)
+}
+
+func (m *MockDocumentRepository) Update(ctx context.Context, document *models.Document) error {
+	args := m.Called(ctx, document)
+	return args.Error(0)
+}
+
+func (m *MockDocumentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type MockSummaryRepository struct {
+	mock.Mock
+}
+
+func (m *MockSummaryRepository) Create(ctx context.Context, summary *models.Summary) error {
+	args := m.Called(ctx, summary)
+	return args.Error(0)
+}
+
+func (m *MockSummaryRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Summary, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Summary), args.Error(1)
+}
+
+func (m *MockSummaryRepository) GetByDocumentID(ctx context.Context, documentID uuid.UUID) ([]*models.Summary, error) {
+	args := m.Called(ctx, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Summary), args.Error(1)
+}
+
+func (m *MockSummaryRepository) Update(ctx context.Context, summary *models.Summary) error {
+	args := m.Called(ctx, summary)
+	return args.Error(0)
+}
+
+func (m *MockSummaryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockSummaryRepository) DeleteByDocumentID(ctx context.Context, documentID uuid.UUID) error {
+	args := m.Called(ctx, documentID)
+	return args.Error(0)
+}
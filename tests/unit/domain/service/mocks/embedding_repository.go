@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+)
+
+type MockEmbeddingRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmbeddingRepository) ReplaceDocumentChunks(ctx context.Context, documentID uuid.UUID, chunks []*models.DocumentChunk) error {
+	args := m.Called(ctx, documentID, chunks)
+	return args.Error(0)
+}
+
+func (m *MockEmbeddingRepository) DeleteByDocumentID(ctx context.Context, documentID uuid.UUID) error {
+	args := m.Called(ctx, documentID)
+	return args.Error(0)
+}
+
+func (m *MockEmbeddingRepository) Nearest(ctx context.Context, userID uuid.UUID, model string, query []float32, limit int) ([]*models.ChunkMatch, error) {
+	args := m.Called(ctx, userID, model, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ChunkMatch), args.Error(1)
+}
+
+func (m *MockEmbeddingRepository) ListUnembedded(ctx context.Context, model string, limit int) ([]repository.UnembeddedDocument, error) {
+	args := m.Called(ctx, model, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.UnembeddedDocument), args.Error(1)
+}
+
+func (m *MockEmbeddingRepository) ListByDocumentID(ctx context.Context, documentID uuid.UUID, model string) ([]*models.DocumentChunk, error) {
+	args := m.Called(ctx, documentID, model)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DocumentChunk), args.Error(1)
+}
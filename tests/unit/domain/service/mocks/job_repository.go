@@ -0,0 +1,81 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockJobRepository) Create(ctx context.Context, job *models.Job) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) CreateIfNotQueued(ctx context.Context, job *models.Job) (bool, error) {
+	args := m.Called(ctx, job)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) HasQueued(ctx context.Context, documentID uuid.UUID, jobType models.JobType) (bool, error) {
+	args := m.Called(ctx, documentID, jobType)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) Update(ctx context.Context, job *models.Job) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) ClaimNext(ctx context.Context, workerID string, lease time.Duration) (*models.Job, error) {
+	args := m.Called(ctx, workerID, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) GetByStatus(ctx context.Context, status models.JobStatus) ([]*models.Job, error) {
+	args := m.Called(ctx, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) RenewLease(ctx context.Context, id uuid.UUID, workerID string, until time.Time) (bool, error) {
+	args := m.Called(ctx, id, workerID, until)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) UpdateLeased(ctx context.Context, job *models.Job, workerID string) (bool, error) {
+	args := m.Called(ctx, job, workerID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) GetExpired(ctx context.Context, now time.Time) ([]*models.Job, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) UpdateExpired(ctx context.Context, job *models.Job, now time.Time) (bool, error) {
+	args := m.Called(ctx, job, now)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+	"github/k-tsurumaki/quilldeck/tests/unit/domain/service/mocks"
+)
+
+func TestSearchService_Search(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("passes distinct terms and highlights the hits", func(t *testing.T) {
+		searchRepo := new(mocks.MockSearchRepository)
+		searchRepo.On("Search", mock.Anything, userID, repository.SearchOptions{
+			Terms: []string{"予算", "Budget"},
+			Limit: 20,
+		}).Return([]*models.SearchHit{{
+			Kind:       models.SearchHitDocument,
+			DocumentID: uuid.New(),
+			Title:      "議事録.md",
+			Text:       "予算の見直し。budget approved.",
+		}}, nil)
+
+		hits, err := service.NewSearchService(searchRepo).Search(context.Background(), userID, service.SearchParams{Query: " 予算  Budget budget "})
+
+		require.NoError(t, err)
+		require.Len(t, hits, 1)
+		assert.Equal(t, "<mark>予算</mark>の見直し。<mark>budget</mark> approved.", hits[0].Snippet)
+		searchRepo.AssertExpectations(t)
+	})
+
+	t.Run("caps the limit", func(t *testing.T) {
+		searchRepo := new(mocks.MockSearchRepository)
+		searchRepo.On("Search", mock.Anything, userID, repository.SearchOptions{Terms: []string{"x"}, Limit: 100, Offset: 40}).
+			Return([]*models.SearchHit{}, nil)
+
+		_, err := service.NewSearchService(searchRepo).Search(context.Background(), userID, service.SearchParams{Query: "x", Limit: 500, Offset: 40})
+
+		require.NoError(t, err)
+		searchRepo.AssertExpectations(t)
+	})
+
+	invalid := []struct {
+		name   string
+		params service.SearchParams
+	}{
+		{"empty query", service.SearchParams{Query: "   "}},
+		{"too many terms", service.SearchParams{Query: "a b c d e f g h i"}},
+		{"negative offset", service.SearchParams{Query: "x", Offset: -1}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			searchRepo := new(mocks.MockSearchRepository)
+
+			_, err := service.NewSearchService(searchRepo).Search(context.Background(), userID, tt.params)
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "VALIDATION")
+			searchRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+)
+
+// fakeLLM records prompts and tracks how many calls run at once.
+type fakeLLM struct {
+	mu       sync.Mutex
+	prompts  []string
+	inFlight int32
+	peak     int32
+	fail     string
+}
+
+func (f *fakeLLM) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	prompt := req.Messages[len(req.Messages)-1].Content
+	n := atomic.AddInt32(&f.inFlight, 1)
+	defer atomic.AddInt32(&f.inFlight, -1)
+	for {
+		peak := atomic.LoadInt32(&f.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(&f.peak, peak, n) {
+			break
+		}
+	}
+
+	f.mu.Lock()
+	f.prompts = append(f.prompts, prompt)
+	calls := len(f.prompts)
+	f.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+	if f.fail != "" && strings.Contains(prompt, f.fail) {
+		return nil, fmt.Errorf("provider error")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &llm.Response{Content: fmt.Sprintf("summary %d", calls)}, nil
+}
+
+// streamingLLM streams deltas one by one, failing with err after them when err is set.
+type streamingLLM struct {
+	fakeLLM
+	deltas []string
+	err    error
+}
+
+func (f *streamingLLM) Stream(ctx context.Context, req llm.Request, onDelta func(delta string) error) (*llm.Response, error) {
+	f.mu.Lock()
+	f.prompts = append(f.prompts, req.Messages[len(req.Messages)-1].Content)
+	f.mu.Unlock()
+
+	var content strings.Builder
+	for _, delta := range f.deltas {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		content.WriteString(delta)
+		if err := onDelta(delta); err != nil {
+			return nil, err
+		}
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &llm.Response{Content: content.String()}, nil
+}
+
+var defaultSummaryOptions = models.SummaryOptions{}.WithDefaults()
+
+func sectionedDocument(sections int) string {
+	var b strings.Builder
+	for i := 0; i < sections; i++ {
+		fmt.Fprintf(&b, "## Section %d\n\n%s\n\n", i, strings.Repeat("content ", 18))
+	}
+	return b.String()
+}
+
+func TestSummarizer_SmallDocumentUsesSinglePrompt(t *testing.T) {
+	model := &fakeLLM{}
+	summarizer := service.NewSummarizer(model, service.SummarizerOptions{MaxChunkChars: 1000})
+
+	summary, err := summarizer.Summarize(context.Background(), "# Notes\n\nShort document.", defaultSummaryOptions)
+
+	require.NoError(t, err)
+	assert.Equal(t, "summary 1", summary)
+	require.Len(t, model.prompts, 1)
+	assert.Contains(t, model.prompts[0], "Short document.")
+	assert.Contains(t, model.prompts[0], "in Japanese, in a formal business-report style, within about 200 characters")
+}
+
+func TestSummarizer_PromptFollowsOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts models.SummaryOptions
+		want string
+	}{
+		{
+			name: "length preset",
+			opts: models.SummaryOptions{Length: models.SummaryLengthLong, Style: models.SummaryStyleCasual, Language: "ja"},
+			want: "in Japanese, in a casual, conversational tone, within about 600 characters",
+		},
+		{
+			name: "target words",
+			opts: models.SummaryOptions{TargetWords: 50, Style: models.SummaryStyleBullets, Language: "en-US"},
+			want: "in English, as a bulleted list of the key points, within about 50 words",
+		},
+		{
+			name: "target characters and unknown language",
+			opts: models.SummaryOptions{TargetChars: 300, Style: models.SummaryStyleExecutive, Language: "sv"},
+			want: "in the language identified by \"sv\", as an executive brief",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &fakeLLM{}
+			summarizer := service.NewSummarizer(model, service.SummarizerOptions{MaxChunkChars: 200, Concurrency: 2})
+
+			_, err := summarizer.Summarize(context.Background(), sectionedDocument(3), tt.opts)
+
+			require.NoError(t, err)
+			// The requested shape applies to the final reduce prompt.
+			assert.Contains(t, model.prompts[len(model.prompts)-1], tt.want)
+		})
+	}
+}
+
+func TestSummarizer_MapReduceWithBoundedConcurrency(t *testing.T) {
+	model := &fakeLLM{}
+	summarizer := service.NewSummarizer(model, service.SummarizerOptions{MaxChunkChars: 200, Concurrency: 2})
+
+	_, err := summarizer.Summarize(context.Background(), sectionedDocument(8), defaultSummaryOptions)
+
+	require.NoError(t, err)
+	// Eight chunk prompts followed by one reduce prompt.
+	require.Len(t, model.prompts, 9)
+	assert.LessOrEqual(t, model.peak, int32(2))
+	last := model.prompts[len(model.prompts)-1]
+	assert.Contains(t, last, "Combine them into a single summary")
+	for i := 1; i <= 8; i++ {
+		assert.Contains(t, last, fmt.Sprintf("## Part %d", i))
+	}
+}
+
+func TestSummarizer_ReducesHierarchicallyWhenPartialsDoNotFit(t *testing.T) {
+	model := &fakeLLM{}
+	summarizer := service.NewSummarizer(model, service.SummarizerOptions{MaxChunkChars: 200, Concurrency: 4})
+
+	_, err := summarizer.Summarize(context.Background(), sectionedDocument(20), defaultSummaryOptions)
+
+	require.NoError(t, err)
+	// 20 chunk prompts, at least one intermediate round, then the final reduce.
+	assert.Greater(t, len(model.prompts), 21)
+	assert.Contains(t, model.prompts[len(model.prompts)-1], "Combine them into a single summary")
+}
+
+func TestSummarizer_ChunkFailureFailsSummary(t *testing.T) {
+	model := &fakeLLM{fail: "Section 3"}
+	summarizer := service.NewSummarizer(model, service.SummarizerOptions{MaxChunkChars: 200, Concurrency: 2})
+
+	summary, err := summarizer.Summarize(context.Background(), sectionedDocument(8), defaultSummaryOptions)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "provider error")
+	assert.Empty(t, summary)
+}
+
+func TestSummarizer_StreamStreamsOnlyTheFinalPrompt(t *testing.T) {
+	model := &streamingLLM{deltas: []string{"final ", "summary"}}
+	summarizer := service.NewSummarizer(model, service.SummarizerOptions{MaxChunkChars: 200, Concurrency: 2})
+
+	var deltas []string
+	summary, err := summarizer.SummarizeStream(context.Background(), sectionedDocument(3), defaultSummaryOptions, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "final summary", summary)
+	assert.Equal(t, []string{"final ", "summary"}, deltas)
+	// Three chunk prompts through Complete, then the streamed reduce prompt.
+	require.Len(t, model.prompts, 4)
+	assert.Contains(t, model.prompts[3], "Combine them into a single summary")
+}
+
+func TestSummarizer_StreamFallsBackToComplete(t *testing.T) {
+	model := &fakeLLM{}
+	summarizer := service.NewSummarizer(model, service.SummarizerOptions{MaxChunkChars: 1000})
+
+	var deltas []string
+	summary, err := summarizer.SummarizeStream(context.Background(), "Short document.", defaultSummaryOptions, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "summary 1", summary)
+	assert.Equal(t, []string{"summary 1"}, deltas)
+}
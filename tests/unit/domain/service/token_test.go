@@ -0,0 +1,81 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/service"
+)
+
+func TestTokenService_IssueAndParse(t *testing.T) {
+	tokenService := service.NewTokenService("test-secret", 15*time.Minute)
+	user := &models.User{ID: uuid.New(), Email: "test@example.com", Name: "Test User"}
+
+	sessionID := uuid.New()
+	token, expiresAt, err := tokenService.IssueAccessToken(user, sessionID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), expiresAt, 5*time.Second)
+
+	claims, err := tokenService.ParseAccessToken(token)
+	require.NoError(t, err)
+	userID, err := claims.UserID()
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, userID)
+	assert.Equal(t, user.Email, claims.Email)
+	gotSessionID, err := claims.SessionUUID()
+	require.NoError(t, err)
+	assert.Equal(t, sessionID, gotSessionID)
+}
+
+func TestTokenService_ParseAccessToken_Rejects(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Email: "test@example.com", Name: "Test User"}
+
+	tests := []struct {
+		name  string
+		token func(t *testing.T) string
+	}{
+		{
+			name: "signed with another secret",
+			token: func(t *testing.T) string {
+				token, _, err := service.NewTokenService("other-secret", time.Minute).IssueAccessToken(user, uuid.New())
+				require.NoError(t, err)
+				return token
+			},
+		},
+		{
+			name: "expired",
+			token: func(t *testing.T) string {
+				token, _, err := service.NewTokenService("test-secret", -time.Minute).IssueAccessToken(user, uuid.New())
+				require.NoError(t, err)
+				return token
+			},
+		},
+		{
+			name:  "malformed",
+			token: func(t *testing.T) string { return "not-a-jwt" },
+		},
+	}
+
+	tokenService := service.NewTokenService("test-secret", time.Minute)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tokenService.ParseAccessToken(tt.token(t))
+			assert.Error(t, err)
+			assert.Nil(t, claims)
+			assert.Contains(t, err.Error(), "UNAUTHORIZED")
+		})
+	}
+}
+
+func TestTokenService_RequiresSecret(t *testing.T) {
+	tokenService := service.NewTokenService("", time.Minute)
+	user := &models.User{ID: uuid.New(), Email: "test@example.com", Name: "Test User"}
+
+	_, _, err := tokenService.IssueAccessToken(user, uuid.New())
+	assert.Error(t, err)
+}
@@ -0,0 +1,347 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/config"
+	"github/k-tsurumaki/quilldeck/internal/domain/llm"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/ai"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/ai/anthropic"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/ai/ollama"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/ai/openai"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/ai/transport"
+)
+
+var testRequest = llm.Request{
+	Messages: []llm.Message{
+		{Role: llm.RoleSystem, Content: "Be brief."},
+		{Role: llm.RoleUser, Content: "Summarize this."},
+	},
+	MaxTokens: 256,
+}
+
+// captureServer answers every request with response and records the path, headers and decoded body.
+func captureServer(t *testing.T, response string) (*httptest.Server, *http.Request, map[string]interface{}) {
+	t.Helper()
+	var captured http.Request
+	body := map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured = *r
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, &captured, body
+}
+
+func TestOpenAI_Complete(t *testing.T) {
+	server, req, body := captureServer(t, `{"model":"gpt-test","choices":[{"index":0,"finish_reason":"stop",
+		"message":{"role":"assistant","content":"short summary"}}],"usage":{"prompt_tokens":12,"completion_tokens":3}}`)
+
+	client := openai.New("sk-test", server.URL+"/v1/", "gpt-test", time.Second)
+	resp, err := client.Complete(context.Background(), testRequest)
+
+	require.NoError(t, err)
+	assert.Equal(t, "/v1/chat/completions", req.URL.Path)
+	assert.Equal(t, "Bearer sk-test", req.Header.Get("Authorization"))
+	assert.Equal(t, "gpt-test", body["model"])
+	assert.Len(t, body["messages"], 2)
+	assert.Equal(t, "short summary", resp.Content)
+	assert.Equal(t, "stop", resp.FinishReason)
+	assert.Equal(t, llm.Usage{PromptTokens: 12, CompletionTokens: 3}, resp.Usage)
+}
+
+func TestAnthropic_Complete(t *testing.T) {
+	server, req, body := captureServer(t, `{"id":"msg_1","model":"claude-test","content":[{"type":"text","text":"short "},
+		{"type":"text","text":"summary"}],"stop_reason":"end_turn","usage":{"input_tokens":20,"output_tokens":4}}`)
+
+	client := anthropic.New("ak-test", server.URL, "claude-test", time.Second)
+	resp, err := client.Complete(context.Background(), testRequest)
+
+	require.NoError(t, err)
+	assert.Equal(t, "/messages", req.URL.Path)
+	assert.Equal(t, "ak-test", req.Header.Get("x-api-key"))
+	assert.NotEmpty(t, req.Header.Get("anthropic-version"))
+	// System messages move to the top-level system field.
+	assert.Equal(t, "Be brief.", body["system"])
+	assert.Len(t, body["messages"], 1)
+	assert.EqualValues(t, 256, body["max_tokens"])
+	assert.Equal(t, "short summary", resp.Content)
+	assert.Equal(t, "end_turn", resp.FinishReason)
+	assert.Equal(t, llm.Usage{PromptTokens: 20, CompletionTokens: 4}, resp.Usage)
+}
+
+func TestOllama_Complete(t *testing.T) {
+	server, req, body := captureServer(t, `{"model":"llama3","message":{"role":"assistant","content":"short summary"},
+		"done":true,"done_reason":"stop","prompt_eval_count":15,"eval_count":5}`)
+
+	client := ollama.New(server.URL, "llama3", time.Second)
+	resp, err := client.Complete(context.Background(), testRequest)
+
+	require.NoError(t, err)
+	assert.Equal(t, "/api/chat", req.URL.Path)
+	assert.Equal(t, false, body["stream"])
+	assert.EqualValues(t, 256, body["options"].(map[string]interface{})["num_predict"])
+	assert.Equal(t, "short summary", resp.Content)
+	assert.Equal(t, llm.Usage{PromptTokens: 15, CompletionTokens: 5}, resp.Usage)
+}
+
+// collectDeltas streams with client and returns the deltas it received.
+func collectDeltas(t *testing.T, client llm.Streamer) (*llm.Response, []string, error) {
+	t.Helper()
+	var deltas []string
+	resp, err := client.Stream(context.Background(), testRequest, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	return resp, deltas, err
+}
+
+func TestOpenAI_Stream(t *testing.T) {
+	server, _, body := captureServer(t, "data: {\"model\":\"gpt-test\",\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n"+
+		"data: {\"choices\":[{\"delta\":{\"content\":\"short \"}}]}\n\n"+
+		"data: {\"choices\":[{\"delta\":{\"content\":\"summary\"},\"finish_reason\":\"stop\"}]}\n\n"+
+		"data: [DONE]\n\n")
+
+	resp, deltas, err := collectDeltas(t, openai.New("sk-test", server.URL, "gpt-test", time.Second))
+
+	require.NoError(t, err)
+	assert.Equal(t, true, body["stream"])
+	assert.Equal(t, []string{"short ", "summary"}, deltas)
+	assert.Equal(t, "short summary", resp.Content)
+	assert.Equal(t, "gpt-test", resp.Model)
+	assert.Equal(t, "stop", resp.FinishReason)
+}
+
+func TestAnthropic_Stream(t *testing.T) {
+	server, _, body := captureServer(t, "event: message_start\n"+
+		"data: {\"type\":\"message_start\",\"message\":{\"model\":\"claude-test\",\"usage\":{\"input_tokens\":20}}}\n\n"+
+		"event: ping\ndata: {\"type\":\"ping\"}\n\n"+
+		"event: content_block_delta\n"+
+		"data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"short \"}}\n\n"+
+		"event: content_block_delta\n"+
+		"data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"summary\"}}\n\n"+
+		"event: message_delta\n"+
+		"data: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":4}}\n\n"+
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+
+	resp, deltas, err := collectDeltas(t, anthropic.New("ak-test", server.URL, "claude-test", time.Second))
+
+	require.NoError(t, err)
+	assert.Equal(t, true, body["stream"])
+	assert.Equal(t, []string{"short ", "summary"}, deltas)
+	assert.Equal(t, "short summary", resp.Content)
+	assert.Equal(t, "end_turn", resp.FinishReason)
+	assert.Equal(t, llm.Usage{PromptTokens: 20, CompletionTokens: 4}, resp.Usage)
+}
+
+func TestAnthropic_StreamError(t *testing.T) {
+	server, _, _ := captureServer(t, "event: content_block_delta\n"+
+		"data: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"short \"}}\n\n"+
+		"event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+
+	_, deltas, err := collectDeltas(t, anthropic.New("ak-test", server.URL, "claude-test", time.Second))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Overloaded")
+	assert.Equal(t, []string{"short "}, deltas)
+}
+
+func TestOllama_Stream(t *testing.T) {
+	server, _, body := captureServer(t, `{"model":"llama3","message":{"role":"assistant","content":"short "},"done":false}
+{"model":"llama3","message":{"role":"assistant","content":"summary"},"done":false}
+{"model":"llama3","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":15,"eval_count":5}
+`)
+
+	resp, deltas, err := collectDeltas(t, ollama.New(server.URL, "llama3", time.Second))
+
+	require.NoError(t, err)
+	assert.Equal(t, true, body["stream"])
+	assert.Equal(t, []string{"short ", "summary"}, deltas)
+	assert.Equal(t, "short summary", resp.Content)
+	assert.Equal(t, "stop", resp.FinishReason)
+	assert.Equal(t, llm.Usage{PromptTokens: 15, CompletionTokens: 5}, resp.Usage)
+}
+
+func TestProvider_StreamOutlastsTimeout(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+	// Each server sends "a" five times, 50ms apart, so the stream runs well past the client's timeout.
+	tests := []struct {
+		name   string
+		chunk  string
+		end    string
+		client func(url string) llm.Streamer
+	}{
+		{"OpenAI", "data: {\"choices\":[{\"delta\":{\"content\":\"a\"}}]}\n\n", "data: [DONE]\n\n",
+			func(url string) llm.Streamer { return openai.New("sk-test", url, "gpt-test", timeout) }},
+		{"Anthropic", "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"a\"}}\n\n",
+			"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n",
+			func(url string) llm.Streamer { return anthropic.New("ak-test", url, "claude-test", timeout) }},
+		{"Ollama", "{\"message\":{\"content\":\"a\"},\"done\":false}\n", "{\"message\":{\"content\":\"\"},\"done\":true}\n",
+			func(url string) llm.Streamer { return ollama.New(url, "llama3", timeout) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				for i := 0; i < 5; i++ {
+					_, _ = w.Write([]byte(tt.chunk))
+					w.(http.Flusher).Flush()
+					time.Sleep(50 * time.Millisecond)
+				}
+				_, _ = w.Write([]byte(tt.end))
+			}))
+			t.Cleanup(server.Close)
+
+			resp, deltas, err := collectDeltas(t, tt.client(server.URL))
+
+			require.NoError(t, err)
+			assert.Len(t, deltas, 5)
+			assert.Equal(t, "aaaaa", resp.Content)
+		})
+	}
+}
+
+func TestProvider_StreamTruncated(t *testing.T) {
+	streams := map[string]struct {
+		body   string
+		client func(url string) llm.Streamer
+	}{
+		"OpenAI": {"data: {\"choices\":[{\"delta\":{\"content\":\"short \"}}]}\n\n",
+			func(url string) llm.Streamer { return openai.New("sk-test", url, "gpt-test", time.Second) }},
+		"Anthropic": {"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"short \"}}\n\n",
+			func(url string) llm.Streamer { return anthropic.New("ak-test", url, "claude-test", time.Second) }},
+		"Ollama": {"{\"message\":{\"content\":\"short \"},\"done\":false}\n",
+			func(url string) llm.Streamer { return ollama.New(url, "llama3", time.Second) }},
+	}
+	for name, stream := range streams {
+		t.Run(name, func(t *testing.T) {
+			server, _, _ := captureServer(t, stream.body)
+
+			_, deltas, err := collectDeltas(t, stream.client(server.URL))
+
+			assert.ErrorIs(t, err, transport.ErrTruncated)
+			assert.Equal(t, []string{"short "}, deltas)
+		})
+	}
+}
+
+func TestProvider_StreamHeaderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	t.Cleanup(server.Close)
+
+	_, _, err := collectDeltas(t, openai.New("sk-test", server.URL, "gpt-test", 50*time.Millisecond))
+
+	require.Error(t, err, "the wait for the response headers is still bounded")
+}
+
+func TestProvider_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"rate limited"}`, http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := openai.New("sk-test", server.URL, "gpt-test", time.Second).Complete(context.Background(), testRequest)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "429")
+	assert.Contains(t, err.Error(), "rate limited")
+}
+
+func TestNewLLM_SelectsProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.LLMConfig
+		want    interface{}
+		wantErr string
+	}{
+		{name: "default is openai", cfg: config.LLMConfig{}, want: &openai.Client{}},
+		{name: "anthropic", cfg: config.LLMConfig{LLM_PROVIDER: "anthropic", LLM_API_KEY: "k"}, want: &anthropic.Client{}},
+		{name: "anthropic without key", cfg: config.LLMConfig{LLM_PROVIDER: "anthropic"}, wantErr: "LLM_API_KEY is required"},
+		{name: "ollama", cfg: config.LLMConfig{LLM_PROVIDER: "Ollama", LLM_MODEL: "llama3"}, want: &ollama.Client{}},
+		{name: "unknown", cfg: config.LLMConfig{LLM_PROVIDER: "palm"}, wantErr: "unsupported LLM provider"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, err := ai.NewLLM(tt.cfg)
+			if tt.wantErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.want, model)
+		})
+	}
+}
+
+func TestOpenAI_Embed(t *testing.T) {
+	// Results may come back in any order; the index field says which input each belongs to.
+	server, req, body := captureServer(t, `{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`)
+
+	client := openai.New("sk-test", server.URL, "text-embedding-test", time.Second)
+	vectors, err := client.Embed(context.Background(), []string{"first", "second"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "/embeddings", req.URL.Path)
+	assert.Equal(t, "Bearer sk-test", req.Header.Get("Authorization"))
+	assert.Equal(t, "text-embedding-test", body["model"])
+	assert.Equal(t, []interface{}{"first", "second"}, body["input"])
+	assert.Equal(t, [][]float32{{1, 0}, {0, 1}}, vectors)
+}
+
+func TestOllama_Embed(t *testing.T) {
+	server, req, body := captureServer(t, `{"model":"nomic-embed-text","embeddings":[[0.5,0.5],[1,0]]}`)
+
+	client := ollama.New(server.URL, "nomic-embed-text", time.Second)
+	vectors, err := client.Embed(context.Background(), []string{"first", "second"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "/api/embed", req.URL.Path)
+	assert.Equal(t, "nomic-embed-text", body["model"])
+	assert.Equal(t, []interface{}{"first", "second"}, body["input"])
+	assert.Equal(t, [][]float32{{0.5, 0.5}, {1, 0}}, vectors)
+}
+
+func TestNewEmbedder_SelectsProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.LLMConfig
+		want    interface{}
+		wantErr string
+	}{
+		{name: "disabled without a model", cfg: config.LLMConfig{LLM_PROVIDER: "anthropic"}, want: nil},
+		{name: "openai", cfg: config.LLMConfig{LLM_EMBEDDING_MODEL: "text-embedding-3-small"}, want: &openai.Client{}},
+		{name: "ollama", cfg: config.LLMConfig{LLM_PROVIDER: "ollama", LLM_EMBEDDING_MODEL: "nomic-embed-text"}, want: &ollama.Client{}},
+		{name: "anthropic", cfg: config.LLMConfig{LLM_PROVIDER: "anthropic", LLM_EMBEDDING_MODEL: "m"}, wantErr: "no embeddings API"},
+		{name: "unknown", cfg: config.LLMConfig{LLM_PROVIDER: "palm", LLM_EMBEDDING_MODEL: "m"}, wantErr: "unsupported LLM provider"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embedder, err := ai.NewEmbedder(tt.cfg)
+			if tt.wantErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.want == nil {
+				assert.Nil(t, embedder)
+				return
+			}
+			assert.IsType(t, tt.want, embedder)
+		})
+	}
+}
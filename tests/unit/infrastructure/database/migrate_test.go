@@ -0,0 +1,292 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/domain/repository"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/database/migrate"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/database/sqlite"
+)
+
+func testMigrationFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_create_notes.up.sql":   {Data: []byte("CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT NOT NULL);")},
+		"0001_create_notes.down.sql": {Data: []byte("DROP TABLE notes;")},
+		"0002_add_title.up.sql":      {Data: []byte("ALTER TABLE notes ADD COLUMN title TEXT NOT NULL DEFAULT '';")},
+		"0002_add_title.down.sql":    {Data: []byte("ALTER TABLE notes DROP COLUMN title;")},
+		"README.md":                  {Data: []byte("ignored")},
+	}
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sqlite.NewConnection(filepath.Join(t.TempDir(), "migrate.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db.DB
+}
+
+func newTestMigrator(t *testing.T, db *sql.DB, files fstest.MapFS) *migrate.Migrator {
+	t.Helper()
+	migrations, err := migrate.Load(files)
+	require.NoError(t, err)
+	return migrate.New(db, migrate.SQLite, migrations, migrate.Options{})
+}
+
+func columnExists(t *testing.T, db *sql.DB, table, column string) bool {
+	t.Helper()
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	require.NoError(t, err)
+	return count > 0
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := migrate.Load(testMigrationFiles())
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "create_notes", migrations[0].Name)
+	assert.Equal(t, "DROP TABLE notes;", migrations[0].Down)
+	assert.Len(t, migrations[0].Checksum, 64)
+
+	_, err = migrate.Load(fstest.MapFS{"1-notes.sql": {Data: []byte("SELECT 1;")}})
+	assert.ErrorContains(t, err, "invalid migration file name")
+
+	_, err = migrate.Load(fstest.MapFS{"0001_notes.down.sql": {Data: []byte("SELECT 1;")}})
+	assert.ErrorContains(t, err, "has no up file")
+}
+
+func TestMigrator_UpDownStatus(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrator := newTestMigrator(t, db, testMigrationFiles())
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, applied)
+	assert.True(t, columnExists(t, db, "notes", "title"))
+
+	// Running again is a no-op.
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	reverted, err := migrator.Down(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, reverted)
+	assert.False(t, columnExists(t, db, "notes", "title"))
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Applied)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.False(t, statuses[1].Applied)
+
+	reverted, err = migrator.Down(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, reverted)
+
+	_, err = migrator.Down(ctx, 7)
+	assert.ErrorContains(t, err, "unknown target version")
+}
+
+func TestMigrator_RefusesModifiedMigrations(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	_, err := newTestMigrator(t, db, testMigrationFiles()).Up(ctx)
+	require.NoError(t, err)
+
+	files := testMigrationFiles()
+	files["0001_create_notes.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE notes (id INTEGER PRIMARY KEY);")}
+	migrator := newTestMigrator(t, db, files)
+
+	_, err = migrator.Up(ctx)
+	assert.ErrorContains(t, err, "checksum mismatch")
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Modified)
+}
+
+func TestMigrator_RefusesUnknownAppliedMigrations(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	_, err := newTestMigrator(t, db, testMigrationFiles()).Up(ctx)
+	require.NoError(t, err)
+
+	// An older build that does not know migration 2.
+	files := testMigrationFiles()
+	delete(files, "0002_add_title.up.sql")
+	delete(files, "0002_add_title.down.sql")
+
+	_, err = newTestMigrator(t, db, files).Up(ctx)
+	assert.ErrorContains(t, err, "missing from this build")
+}
+
+func TestMigrator_FailedMigrationIsRolledBack(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	files := testMigrationFiles()
+	files["0003_broken.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE tags (id INTEGER); SELECT * FROM no_such_table;")}
+	migrator := newTestMigrator(t, db, files)
+
+	applied, err := migrator.Up(ctx)
+	assert.ErrorContains(t, err, "0003_broken")
+	// Migrations before the broken one stay applied.
+	assert.Equal(t, []int64{1, 2}, applied)
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, statuses[1].Applied)
+	assert.False(t, statuses[2].Applied)
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'tags'`).Scan(&count))
+	assert.Zero(t, count, "partial migration left behind")
+}
+
+func TestMigrator_IrreversibleMigration(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	files := testMigrationFiles()
+	delete(files, "0002_add_title.down.sql")
+	migrator := newTestMigrator(t, db, files)
+	_, err := migrator.Up(ctx)
+	require.NoError(t, err)
+
+	_, err = migrator.Down(ctx, 0)
+	assert.ErrorContains(t, err, "cannot be rolled back")
+}
+
+func TestSQLite_AdoptsDatabasesWithoutLedger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := sqlite.NewConnection(path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// The summaries table as created by the first release.
+	_, err = db.Exec(`CREATE TABLE summaries (id TEXT PRIMARY KEY, document_id TEXT NOT NULL, content TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP, updated_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+		INSERT INTO summaries (id, document_id, content) VALUES ('s1', 'd1', 'old summary');`)
+	require.NoError(t, err)
+
+	require.NoError(t, db.RunMigrations())
+
+	assert.True(t, columnExists(t, db.DB, "summaries", "style"))
+	var status string
+	require.NoError(t, db.QueryRow(`SELECT status FROM summaries WHERE id = 's1'`).Scan(&status))
+	assert.Equal(t, "completed", status)
+	assert.True(t, columnExists(t, db.DB, "jobs", "payload"))
+}
+
+func TestSQLite_BaselineMigrationHoldsOnlyTheBaselineSchema(t *testing.T) {
+	db, err := sqlite.NewConnection(filepath.Join(t.TempDir(), "baseline.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrator, err := db.Migrator()
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	_, err = migrator.Down(ctx, 1)
+	require.NoError(t, err)
+
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		tables = append(tables, name)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{"documents", "schema_migrations", "summaries", "users"}, tables)
+}
+
+func TestSQLite_UniqueQueuedEmbeddingsKeepsTheOldest(t *testing.T) {
+	db, err := sqlite.NewConnection(filepath.Join(t.TempDir(), "jobs.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrator, err := db.Migrator()
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	_, err = migrator.Down(ctx, 17)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`INSERT INTO jobs (id, user_id, document_id, type, status, created_at) VALUES
+		('j2', 'u', 'd', 'embedding', 'queued', '2024-05-01 10:00:00.000000000+00:00'),
+		('j1', 'u', 'd', 'embedding', 'queued', '2024-05-01 09:00:00.000000000+00:00'),
+		('j3', 'u', 'd', 'embedding', 'running', '2024-05-01 08:00:00.000000000+00:00'),
+		('j4', 'u', 'd', 'summary', 'queued', '2024-05-01 11:00:00.000000000+00:00'),
+		('j5', 'u', 'd', 'summary', 'queued', '2024-05-01 12:00:00.000000000+00:00')`)
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	rows, err := db.Query(`SELECT id FROM jobs ORDER BY id`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		require.NoError(t, rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{"j1", "j3", "j4", "j5"}, ids)
+}
+
+func TestSQLite_SearchIndexMigrationsAreRecordedInEveryBuild(t *testing.T) {
+	db, err := sqlite.NewConnection(filepath.Join(t.TempDir(), "search.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrator, err := db.Migrator()
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	status, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	applied := map[int64]bool{}
+	for _, s := range status {
+		applied[s.Version] = s.Applied
+	}
+	assert.True(t, applied[3])
+	assert.True(t, applied[19])
+
+	// A build without FTS5 drops the triggers of an index; documents written meanwhile are found once the
+	// database is migrated again.
+	for _, trigger := range []string{"documents_fts_insert", "documents_fts_update", "documents_fts_delete",
+		"summaries_fts_insert", "summaries_fts_update", "summaries_fts_delete"} {
+		_, err = db.Exec("DROP TRIGGER IF EXISTS " + trigger)
+		require.NoError(t, err)
+	}
+	repos := sqlite.NewRepositories(db)
+	user := models.NewUser("search@example.com", "x", "Search")
+	require.NoError(t, repos.Users.Create(ctx, user))
+	require.NoError(t, repos.Documents.Create(ctx, models.NewDocument(user.ID, "議事録.md", "予算の見直し", models.DocumentTypeMD, 20)))
+
+	pending, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	hits, err := repos.Search.Search(ctx, user.ID, repository.SearchOptions{Terms: []string{"見直し"}, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "議事録.md", hits[0].Title)
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/domain/models"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/database/postgres"
+)
+
+// placeholderDriver accepts any statement and reports as many inputs as its highest $n placeholder, so that
+// database/sql rejects calls whose arguments do not match the statement without a Postgres server.
+type placeholderDriver struct{}
+
+var placeholderPattern = regexp.MustCompile(`\$(\d+)`)
+
+func (placeholderDriver) Open(string) (driver.Conn, error) { return placeholderConn{}, nil }
+
+type placeholderConn struct{}
+
+func (placeholderConn) Prepare(query string) (driver.Stmt, error) {
+	inputs := 0
+	for _, match := range placeholderPattern.FindAllStringSubmatch(query, -1) {
+		if n, _ := strconv.Atoi(match[1]); n > inputs {
+			inputs = n
+		}
+	}
+	return placeholderStmt{inputs: inputs}, nil
+}
+
+func (placeholderConn) Close() error              { return nil }
+func (placeholderConn) Begin() (driver.Tx, error) { return placeholderTx{}, nil }
+
+type placeholderTx struct{}
+
+func (placeholderTx) Commit() error   { return nil }
+func (placeholderTx) Rollback() error { return nil }
+
+type placeholderStmt struct{ inputs int }
+
+func (s placeholderStmt) Close() error  { return nil }
+func (s placeholderStmt) NumInput() int { return s.inputs }
+func (s placeholderStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+func (s placeholderStmt) Query([]driver.Value) (driver.Rows, error) { return placeholderRows{}, nil }
+
+type placeholderRows struct{}
+
+func (placeholderRows) Columns() []string         { return nil }
+func (placeholderRows) Close() error              { return nil }
+func (placeholderRows) Next([]driver.Value) error { return io.EOF }
+
+func init() {
+	sql.Register("placeholders", placeholderDriver{})
+}
+
+// TestPostgres_InsertsBindEveryPlaceholder runs where the contract suite cannot reach Postgres.
+func TestPostgres_InsertsBindEveryPlaceholder(t *testing.T) {
+	conn, err := sql.Open("placeholders", "")
+	require.NoError(t, err)
+	defer conn.Close()
+	repos := postgres.NewRepositories(&postgres.DB{DB: conn})
+	ctx := context.Background()
+
+	document := models.NewDocument(uuid.New(), "notes.md", "# Notes", models.DocumentTypeMD, 7)
+	assert.NoError(t, repos.Documents.Create(ctx, document))
+	assert.NoError(t, repos.Documents.Update(ctx, document))
+	summary := models.NewSummary(document.ID, "Summary")
+	assert.NoError(t, repos.Summaries.Create(ctx, summary))
+	assert.NoError(t, repos.Summaries.Update(ctx, summary))
+	assert.NoError(t, repos.Sessions.Create(ctx, models.NewSession(uuid.New(), "hash", "curl", "127.0.0.1", time.Hour)))
+	job := models.NewJob(uuid.New(), document.ID, models.JobTypeSummary, nil)
+	assert.NoError(t, repos.Jobs.Create(ctx, job))
+	_, err = repos.Jobs.UpdateLeased(ctx, job, "worker")
+	assert.NoError(t, err)
+	_, err = repos.Jobs.UpdateExpired(ctx, job, time.Now())
+	assert.NoError(t, err)
+}
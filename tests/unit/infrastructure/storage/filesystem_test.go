@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/infrastructure/storage"
+)
+
+func TestFileSystem(t *testing.T) {
+	ctx := context.Background()
+	root := filepath.Join(t.TempDir(), "blobs")
+	store, err := storage.NewFileSystem(root)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(ctx, "artifacts/ab/abc", []byte("first")))
+	require.NoError(t, store.Put(ctx, "artifacts/ab/abc", []byte("second")))
+	data, err := store.Get(ctx, "artifacts/ab/abc")
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	// Only the blob is left in its directory, no temporary files.
+	entries, err := os.ReadDir(filepath.Join(root, "artifacts", "ab"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "abc", entries[0].Name())
+
+	missing, err := store.Get(ctx, "artifacts/ab/missing")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	require.NoError(t, store.Delete(ctx, "artifacts/ab/abc"))
+	require.NoError(t, store.Delete(ctx, "artifacts/ab/abc"))
+	data, err = store.Get(ctx, "artifacts/ab/abc")
+	require.NoError(t, err)
+	assert.Nil(t, data)
+}
+
+func TestFileSystem_RejectsKeysOutsideTheRoot(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewFileSystem(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../escape", "a/../../escape", "/etc/passwd", "a//b", "a/.hidden", `a\b`, "a/b c"} {
+		assert.Error(t, store.Put(ctx, key, []byte("x")), key)
+		_, err := store.Get(ctx, key)
+		assert.Error(t, err, key)
+		assert.Error(t, store.Delete(ctx, key), key)
+	}
+}
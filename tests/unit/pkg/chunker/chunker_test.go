@@ -0,0 +1,108 @@
+package chunker
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/pkg/chunker"
+)
+
+func assertChunksValid(t *testing.T, content string, chunks []chunker.Chunk, maxChars int) {
+	t.Helper()
+	for i, chunk := range chunks {
+		assert.Equal(t, i, chunk.Index)
+		assert.Equal(t, content[chunk.Start:chunk.End], chunk.Text, "chunk %d offsets", i)
+		assert.LessOrEqual(t, utf8.RuneCountInString(chunk.Text), maxChars, "chunk %d too long", i)
+		if i > 0 {
+			assert.GreaterOrEqual(t, chunk.Start, chunks[i-1].End)
+		}
+	}
+}
+
+func TestSplit_SmallDocumentIsOneChunk(t *testing.T) {
+	content := "# Title\n\nShort body.\n"
+	chunks := chunker.Split(content, 100)
+
+	require.Len(t, chunks, 1)
+	assert.Equal(t, "# Title\n\nShort body.", chunks[0].Text)
+	assert.Equal(t, "Title", chunks[0].Heading)
+	assertChunksValid(t, content, chunks, 100)
+}
+
+func TestSplit_EmptyDocument(t *testing.T) {
+	assert.Empty(t, chunker.Split("", 100))
+	assert.Empty(t, chunker.Split("  \n\n \n", 100))
+}
+
+func TestSplit_BreaksAtHeadings(t *testing.T) {
+	intro := "# Intro\n\n" + strings.Repeat("a", 40) + "\n\n"
+	usage := "## Usage\n\n" + strings.Repeat("b", 40) + "\n"
+	content := intro + usage
+
+	chunks := chunker.Split(content, 60)
+
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "Intro", chunks[0].Heading)
+	assert.True(t, strings.HasPrefix(chunks[0].Text, "# Intro"))
+	assert.Equal(t, "Usage", chunks[1].Heading)
+	assert.True(t, strings.HasPrefix(chunks[1].Text, "## Usage"))
+	assertChunksValid(t, content, chunks, 60)
+}
+
+func TestSplit_BreaksLongSectionsAtParagraphs(t *testing.T) {
+	paragraphs := []string{strings.Repeat("x", 30), strings.Repeat("y", 30), strings.Repeat("z", 30)}
+	content := "# Long\n\n" + strings.Join(paragraphs, "\n\n") + "\n"
+
+	chunks := chunker.Split(content, 45)
+
+	require.Len(t, chunks, 3)
+	assert.Equal(t, "# Long\n\n"+paragraphs[0], chunks[0].Text)
+	assert.Equal(t, paragraphs[1], chunks[1].Text)
+	assert.Equal(t, paragraphs[2], chunks[2].Text)
+	for _, chunk := range chunks {
+		assert.Equal(t, "Long", chunk.Heading)
+	}
+	assertChunksValid(t, content, chunks, 45)
+}
+
+func TestSplit_IgnoresHeadingsInCodeFences(t *testing.T) {
+	content := "# Real\n\n```sh\n# not a heading\necho hi\n```\n"
+	chunks := chunker.Split(content, 1000)
+
+	require.Len(t, chunks, 1)
+	assert.Equal(t, "Real", chunks[0].Heading)
+}
+
+func TestSplit_HardSplitsLongLinesOnRuneBoundaries(t *testing.T) {
+	content := strings.Repeat("日本語の文章です。", 50)
+	chunks := chunker.Split(content, 40)
+
+	require.Greater(t, len(chunks), 1)
+	for _, chunk := range chunks {
+		assert.True(t, utf8.ValidString(chunk.Text))
+		assert.True(t, strings.HasSuffix(chunk.Text, "。"), "chunk should end at a sentence: %q", chunk.Text)
+	}
+	assertChunksValid(t, content, chunks, 40)
+}
+
+func TestSplit_CoversWholeDocument(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 50; i++ {
+		b.WriteString("## Section\n\n")
+		b.WriteString(strings.Repeat("word ", 20+i))
+		b.WriteString("\n\n")
+	}
+	content := b.String()
+
+	chunks := chunker.Split(content, 200)
+	assertChunksValid(t, content, chunks, 200)
+
+	var joined strings.Builder
+	for _, chunk := range chunks {
+		joined.WriteString(chunk.Text)
+	}
+	assert.Equal(t, strings.Join(strings.Fields(content), ""), strings.Join(strings.Fields(joined.String()), ""))
+}
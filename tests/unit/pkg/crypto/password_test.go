@@ -0,0 +1,96 @@
+package crypto
+
+import (
+	"strings"
+	"testing"
+
+	"github/k-tsurumaki/quilldeck/internal/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testArgon2Params = crypto.Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestPasswordHasher_HashAndVerify(t *testing.T) {
+	tests := []struct {
+		name       string
+		algorithm  string
+		bcryptCost int
+		prefix     string
+	}{
+		{name: "argon2id", algorithm: crypto.AlgorithmArgon2id, prefix: "$argon2id$v=19$m=1024,t=1,p=1$"},
+		{name: "bcrypt", algorithm: crypto.AlgorithmBcrypt, bcryptCost: 4, prefix: "$2a$04$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher, err := crypto.NewPasswordHasher(tt.algorithm, testArgon2Params, tt.bcryptCost)
+			require.NoError(t, err)
+
+			encoded, err := hasher.Hash("password123")
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(encoded, tt.prefix), encoded)
+
+			ok, err := hasher.Verify("password123", encoded)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = hasher.Verify("wrongpassword", encoded)
+			assert.NoError(t, err)
+			assert.False(t, ok)
+
+			assert.False(t, hasher.NeedsRehash(encoded))
+		})
+	}
+}
+
+func TestPasswordHasher_SaltsEachHash(t *testing.T) {
+	hasher, err := crypto.NewPasswordHasher(crypto.AlgorithmArgon2id, testArgon2Params, 0)
+	require.NoError(t, err)
+
+	first, err := hasher.Hash("password123")
+	require.NoError(t, err)
+	second, err := hasher.Hash("password123")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+}
+
+func TestPasswordHasher_NeedsRehash(t *testing.T) {
+	argon2Hasher, err := crypto.NewPasswordHasher(crypto.AlgorithmArgon2id, testArgon2Params, 0)
+	require.NoError(t, err)
+	strongerHasher, err := crypto.NewPasswordHasher(crypto.AlgorithmArgon2id, crypto.Argon2Params{Memory: 2048, Iterations: 2, Parallelism: 1}, 0)
+	require.NoError(t, err)
+	bcryptHasher, err := crypto.NewPasswordHasher(crypto.AlgorithmBcrypt, testArgon2Params, 4)
+	require.NoError(t, err)
+
+	argon2Hash, err := argon2Hasher.Hash("password123")
+	require.NoError(t, err)
+	bcryptHash, err := bcryptHasher.Hash("password123")
+	require.NoError(t, err)
+	legacyHash := "ef92b778bafe771e89245b89ecbc08a44a4e166c06659911881f383d4473e94f"
+
+	assert.True(t, argon2Hasher.NeedsRehash(legacyHash), "legacy sha256")
+	assert.True(t, argon2Hasher.NeedsRehash(bcryptHash), "different algorithm")
+	assert.True(t, strongerHasher.NeedsRehash(argon2Hash), "weaker parameters")
+	assert.False(t, argon2Hasher.NeedsRehash(argon2Hash))
+
+	// Hashes from any known algorithm remain verifiable after switching.
+	ok, err := bcryptHasher.Verify("password123", argon2Hash)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = argon2Hasher.Verify("password123", legacyHash)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestNewPasswordHasher_InvalidConfig(t *testing.T) {
+	_, err := crypto.NewPasswordHasher("md5", testArgon2Params, 0)
+	assert.Error(t, err)
+
+	_, err = crypto.NewPasswordHasher(crypto.AlgorithmBcrypt, testArgon2Params, 1)
+	assert.Error(t, err)
+
+	_, err = crypto.NewPasswordHasher(crypto.AlgorithmArgon2id, crypto.Argon2Params{}, 0)
+	assert.Error(t, err)
+}
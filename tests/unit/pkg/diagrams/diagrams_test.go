@@ -0,0 +1,203 @@
+package diagrams
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/pkg/diagrams"
+)
+
+func TestValidateMermaid_Valid(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		kind   diagrams.Kind
+	}{
+		{"flowchart", `%% approval flow
+flowchart TD
+    A[申請] --> B{承認?}
+    B -->|はい| C([完了])
+    B -- いいえ --> D["差し戻し (理由付き)"]
+    D -.-> A; C ==> E((終了))
+    F[[Sub]] --- G[(DB)] & H>Flag]
+    subgraph review [レビュー]
+        direction LR
+        R1 --o R2
+        web-app --> api-server
+    end
+    classDef done fill:#9f9
+    class C done
+`, diagrams.KindFlowchart},
+		{"graph without direction", "graph\n  A-->B\n", diagrams.KindFlowchart},
+		{"sequence", `sequenceDiagram
+    autonumber
+    participant U as 利用者
+    actor Admin
+    U->>+API: 申請する
+    API-->>-U: 受付番号
+    alt 承認
+        Admin->>API: approve
+    else 却下
+        Admin-xAPI: reject
+    end
+    loop 毎日
+        API-)Admin: 通知
+    end
+    Note over U,API: 非同期で処理
+`, diagrams.KindSequence},
+		{"mindmap", `mindmap
+  root((来期計画))
+    予算
+      広告費
+      id1["人件費 (増員)"]
+    日程
+      ))3月末((
+    ::icon(fa fa-book)
+`, diagrams.KindMindmap},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, err := diagrams.ValidateMermaid(tt.source)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.kind, kind)
+		})
+	}
+}
+
+func TestValidateMermaid_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		line   int
+		want   string
+	}{
+		{"empty", "%% nothing\n", 1, "empty"},
+		{"unsupported type", "classDiagram\n  A <|-- B\n", 1, "unsupported diagram type"},
+		{"bad direction", "flowchart XY\n  A-->B\n", 1, "direction"},
+		{"parentheses in label", "flowchart TD\n  A[予算 (案)] --> B\n", 2, "wrap the label in double quotes"},
+		{"unclosed shape", "flowchart TD\n  A[Start --> B\n", 2, "never closed"},
+		{"end as node id", "flowchart TD\n  A --> end\n", 2, `"end" cannot be a node id`},
+		{"single arrow", "flowchart TD\n  A -> B\n", 2, "expected a link"},
+		{"dangling link", "flowchart TD\n  A --> \n", 2, "no target"},
+		{"unclosed subgraph", "flowchart TD\n  subgraph S\n  A --> B\n", 2, "never closed with end"},
+		{"stray end", "flowchart TD\n  A --> B\n  end\n", 3, "without an open subgraph"},
+		{"message without text", "sequenceDiagram\n  A->>B\n", 2, "expected a message"},
+		{"semicolon in message", "sequenceDiagram\n  A->>B: a; b\n", 2, "#59;"},
+		{"else outside alt", "sequenceDiagram\n  loop x\n  else y\n  end\n", 3, "else is only allowed inside alt"},
+		{"unclosed loop", "sequenceDiagram\n  loop x\n  A->>B: hi\n", 2, "loop is never closed"},
+		{"two roots", "mindmap\n  root\n    a\n  other\n", 4, "single root"},
+		{"brackets in mindmap text", "mindmap\n  root\n    予算 (案)\n", 3, `id["text"]`},
+		{"unclosed mindmap shape", "mindmap\n  root((plan)\n", 2, "never closed"},
+		{"mindmap without nodes", "mindmap\n", 1, "no nodes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := diagrams.ValidateMermaid(tt.source)
+
+			var syntaxErr *diagrams.SyntaxError
+			require.ErrorAs(t, err, &syntaxErr)
+			assert.Equal(t, tt.line, syntaxErr.Line)
+			assert.Contains(t, syntaxErr.Message, tt.want)
+		})
+	}
+}
+
+func TestValidatePlantUML_Valid(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		kind   diagrams.Kind
+	}{
+		{"activity", `@startuml
+' approval flow
+start
+:申請する;
+if (承認?) then (yes)
+  :完了;
+elseif (保留?) then (yes)
+  :待つ;
+else (no)
+  :差し戻し
+  理由を添える;
+endif
+while (未処理あり?)
+  :処理する;
+endwhile
+fork
+  :通知;
+fork again
+  :記録;
+end fork
+note right
+  補足
+end note
+stop
+@enduml`, diagrams.KindFlowchart},
+		{"sequence", `@startuml
+actor 利用者
+participant API
+利用者 -> API: 申請
+alt 承認
+  API --> 利用者: 完了
+else 却下
+  API --> 利用者: 差し戻し
+end
+box "内部"
+  participant DB
+end box
+note over API
+  非同期
+end note
+@enduml`, diagrams.KindSequence},
+		{"mindmap", `@startmindmap
+title 来期計画
+* 来期計画
+** 予算
+***_ 広告費
+**[#Orange] 日程
+*** 3月末
+@endmindmap`, diagrams.KindMindmap},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, err := diagrams.ValidatePlantUML(tt.source)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.kind, kind)
+		})
+	}
+}
+
+func TestValidatePlantUML_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		line   int
+		want   string
+	}{
+		{"no start", "A -> B: hi\n", 1, "must start with"},
+		{"no end", "@startuml\nA -> B: hi\n", 2, "must end with @enduml"},
+		{"mismatched end", "@startmindmap\n* a\n@enduml\n", 3, "must end with @endmindmap"},
+		{"two diagrams", "@startuml\nA -> B: hi\n@enduml\n@startuml\nB -> A: hi\n@enduml\n", 3, "single diagram"},
+		{"unclosed if", "@startuml\nstart\nif (x) then (y)\n:a;\nstop\n@enduml\n", 3, "if is never closed with endif"},
+		{"endwhile without while", "@startuml\nstart\n:a;\nendwhile\n@enduml\n", 4, "without an open while"},
+		{"unterminated activity", "@startuml\nstart\n:a\nstop\n@enduml\n", 3, "never closed with ;"},
+		{"unclosed alt", "@startuml\nA -> B: hi\nalt ok\nB -> A: ok\n@enduml\n", 3, "alt is never closed"},
+		{"stray end", "@startuml\nA -> B: hi\nend\n@enduml\n", 3, "without an open block"},
+		{"two roots", "@startmindmap\n* a\n** b\n* c\n@endmindmap\n", 4, "single root"},
+		{"skipped level", "@startmindmap\n* a\n*** b\n@endmindmap\n", 3, "skips a level"},
+		{"not a node", "@startmindmap\n* a\nb\n@endmindmap\n", 3, "expected a node"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := diagrams.ValidatePlantUML(tt.source)
+
+			var syntaxErr *diagrams.SyntaxError
+			require.ErrorAs(t, err, &syntaxErr)
+			assert.Equal(t, tt.line, syntaxErr.Line)
+			assert.Contains(t, syntaxErr.Message, tt.want)
+		})
+	}
+}
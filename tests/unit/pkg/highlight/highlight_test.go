@@ -0,0 +1,60 @@
+package highlight
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github/k-tsurumaki/quilldeck/internal/pkg/highlight"
+)
+
+func TestSnippet_MarksEveryMatchCaseInsensitively(t *testing.T) {
+	snippet := highlight.Snippet("Budget review: the budget is approved.", []string{"BUDGET", "approved"}, 100)
+
+	assert.Equal(t, "<mark>Budget</mark> review: the <mark>budget</mark> is <mark>approved</mark>.", snippet)
+}
+
+func TestSnippet_MergesOverlappingMatches(t *testing.T) {
+	snippet := highlight.Snippet("予算の見直し", []string{"予算の", "の見直し"}, 100)
+
+	assert.Equal(t, "<mark>予算の見直し</mark>", snippet)
+}
+
+func TestSnippet_EscapesHTML(t *testing.T) {
+	snippet := highlight.Snippet(`<script>alert("x")</script> & more`, []string{"alert"}, 100)
+
+	assert.Equal(t, `&lt;script&gt;<mark>alert</mark>(&#34;x&#34;)&lt;/script&gt; &amp; more`, snippet)
+}
+
+func TestSnippet_CollapsesWhitespace(t *testing.T) {
+	snippet := highlight.Snippet("  # Title\n\n\tfirst   line\n", []string{"first"}, 100)
+
+	assert.Equal(t, "# Title <mark>first</mark> line", snippet)
+}
+
+func TestSnippet_WindowAroundFirstMatch(t *testing.T) {
+	text := strings.Repeat("前置き。", 50) + "重要な決定" + strings.Repeat("後書き。", 50)
+
+	snippet := highlight.Snippet(text, []string{"決定"}, 40)
+
+	assert.True(t, strings.HasPrefix(snippet, "…"))
+	assert.True(t, strings.HasSuffix(snippet, "…"))
+	assert.Contains(t, snippet, "重要な<mark>決定</mark>")
+	plain := strings.NewReplacer("<mark>", "", "</mark>", "", "…", "").Replace(snippet)
+	assert.Equal(t, 40, utf8.RuneCountInString(plain))
+}
+
+func TestSnippet_WithoutMatchStartsAtBeginning(t *testing.T) {
+	text := strings.Repeat("a", 300)
+
+	snippet := highlight.Snippet(text, []string{"zzz"}, 0)
+
+	assert.Equal(t, strings.Repeat("a", highlight.DefaultMaxChars)+"…", snippet)
+}
+
+func TestSnippet_MatchCutByWindowIsClosed(t *testing.T) {
+	snippet := highlight.Snippet("abcdefgh", []string{"efgh"}, 6)
+
+	assert.Equal(t, "…cd<mark>efgh</mark>", snippet)
+}
@@ -0,0 +1,48 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github/k-tsurumaki/quilldeck/internal/pkg/markdown"
+)
+
+func TestWriter(t *testing.T) {
+	var w markdown.Writer
+	w.Heading(0, "  Title ")
+	w.Paragraph("first  line \n\n  second line")
+	w.Paragraph(" \n ")
+	w.ListItem(0, false, "item")
+	w.ListItem(1, true, "nested")
+	w.Heading(9, "Deep")
+	w.Table([][]string{{"a", "b", ""}, {"x|y", "two\nlines", ""}})
+	w.Table([][]string{{"", " "}})
+
+	assert.Equal(t, "# Title\n\n"+
+		"first line\nsecond line\n\n"+
+		"- item\n"+
+		"  1. nested\n\n"+
+		"###### Deep\n\n"+
+		"| a | b |\n| --- | --- |\n| x\\|y | two<br>lines |", w.String())
+}
+
+func TestWriter_CodeBlock(t *testing.T) {
+	var w markdown.Writer
+	w.CodeBlock("\r\n  indented\r\n")
+	w.CodeBlock("uses ``` inside")
+	w.CodeBlock("\n \n")
+
+	assert.Equal(t, "```\n  indented\n```\n\n````\nuses ``` inside\n````", w.String())
+}
+
+func TestWriter_Quote(t *testing.T) {
+	var inner markdown.Writer
+	inner.Paragraph("quoted")
+	inner.ListItem(0, false, "point")
+
+	var w markdown.Writer
+	w.Quote(inner.String())
+	w.Quote("")
+
+	assert.Equal(t, "> quoted\n>\n> - point", w.String())
+}
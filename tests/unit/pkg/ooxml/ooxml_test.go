@@ -0,0 +1,228 @@
+package ooxml
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/pkg/ooxml"
+)
+
+const (
+	relsNS = `xmlns="http://schemas.openxmlformats.org/package/2006/relationships"`
+	relNS  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	wordNS = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="` + relNS + `"` +
+		` xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006"` +
+		` xmlns:wps="http://schemas.microsoft.com/office/word/2010/wordprocessingShape"`
+	presentationNS = `xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="` + relNS + `"` +
+		` xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"`
+	sheetNS = `xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="` + relNS + `"`
+)
+
+// buildPackage zips parts, given as name and content pairs.
+func buildPackage(parts ...string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for i := 0; i+1 < len(parts); i += 2 {
+		f, _ := w.Create(parts[i])
+		f.Write([]byte(parts[i+1]))
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+// rels writes a relationships part from ID, type and target triples; types are short names such as
+// "styles".
+func rels(items ...string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?><Relationships ` + relsNS + `>`)
+	for i := 0; i+2 < len(items); i += 3 {
+		b.WriteString(`<Relationship Id="` + items[i] + `" Type="` + relNS + `/` + items[i+1] + `" Target="` + items[i+2] + `"/>`)
+	}
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+func TestExtractDOCX(t *testing.T) {
+	styles := `<w:styles ` + wordNS + `>
+		<w:style w:type="paragraph" w:styleId="1"><w:name w:val="heading 1"/></w:style>
+		<w:style w:type="paragraph" w:styleId="MyHeading"><w:name w:val="My Heading"/><w:basedOn w:val="2"/></w:style>
+		<w:style w:type="paragraph" w:styleId="2"><w:name w:val="heading 2"/></w:style>
+		<w:style w:type="paragraph" w:styleId="ListBullet"><w:name w:val="List Bullet"/>
+			<w:pPr><w:numPr><w:numId w:val="1"/></w:numPr></w:pPr></w:style>
+	</w:styles>`
+	numbering := `<w:numbering ` + wordNS + `>
+		<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:numFmt w:val="bullet"/></w:lvl></w:abstractNum>
+		<w:abstractNum w:abstractNumId="5"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/></w:lvl>
+			<w:lvl w:ilvl="1"><w:numFmt w:val="lowerLetter"/></w:lvl></w:abstractNum>
+		<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>
+		<w:num w:numId="2"><w:abstractNumId w:val="5"/></w:num>
+	</w:numbering>`
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document ` + wordNS + `><w:body>
+	<w:p><w:pPr><w:pStyle w:val="1"/></w:pPr><w:r><w:t>議事録</w:t></w:r></w:p>
+	<w:p><w:r><w:t xml:space="preserve">Attendees:  </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>Sato</w:t></w:r>
+		<w:r><w:tab/><w:t>and Suzuki</w:t></w:r><w:r><w:rPr><w:vanish/></w:rPr><w:t>hidden</w:t></w:r>
+		<w:del><w:r><w:delText>removed</w:delText></w:r></w:del><w:ins><w:r><w:t>, Tanaka</w:t></w:r></w:ins></w:p>
+	<w:p/>
+	<w:p><w:pPr><w:pStyle w:val="MyHeading"/></w:pPr><w:hyperlink r:id="rId9"><w:r><w:t>Decisions</w:t></w:r></w:hyperlink></w:p>
+	<w:p><w:pPr><w:pStyle w:val="ListBullet"/></w:pPr><w:r><w:t>Approve the budget</w:t></w:r></w:p>
+	<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t>First step</w:t></w:r></w:p>
+	<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t>Detail</w:t></w:r></w:p>
+	<w:p><w:r><w:t>See the box</w:t></w:r><w:r><mc:AlternateContent>
+		<mc:Choice Requires="wps"><w:drawing><wps:txbx><w:txbxContent><w:p><w:r><w:t>Boxed note</w:t></w:r></w:p></w:txbxContent></wps:txbx></w:drawing></mc:Choice>
+		<mc:Fallback><w:pict><w:txbxContent><w:p><w:r><w:t>Boxed note</w:t></w:r></w:p></w:txbxContent></w:pict></mc:Fallback>
+	</mc:AlternateContent></w:r></w:p>
+	<w:tbl>
+		<w:tr><w:tc><w:p><w:r><w:t>Item</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Owner</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Due</w:t></w:r></w:p></w:tc></w:tr>
+		<w:tr><w:tc><w:tcPr><w:vMerge w:val="restart"/></w:tcPr><w:p><w:r><w:t>Ads | print</w:t></w:r></w:p></w:tc>
+			<w:tc><w:tcPr><w:gridSpan w:val="2"/></w:tcPr><w:p><w:r><w:t>Sato</w:t></w:r></w:p><w:p><w:r><w:t>by March</w:t></w:r></w:p></w:tc></w:tr>
+		<w:tr><w:tc><w:tcPr><w:vMerge/></w:tcPr><w:p><w:r><w:t>Ads | print</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Suzuki</w:t></w:r></w:p></w:tc><w:tc><w:p/></w:tc></w:tr>
+	</w:tbl>
+	<w:sectPr/>
+</w:body></w:document>`
+
+	data := buildPackage(
+		"_rels/.rels", rels("rId1", "officeDocument", "word/document.xml"),
+		"word/_rels/document.xml.rels", rels("rId1", "styles", "styles.xml", "rId2", "numbering", "/word/numbering.xml"),
+		"word/document.xml", document,
+		"word/styles.xml", styles,
+		"word/numbering.xml", numbering,
+	)
+
+	text, err := ooxml.ExtractDOCX(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, "# 議事録\n\n"+
+		"Attendees: Sato and Suzuki, Tanaka\n\n"+
+		"## Decisions\n\n"+
+		"- Approve the budget\n"+
+		"1. First step\n"+
+		"  1. Detail\n\n"+
+		"See the box\n\n"+
+		"Boxed note\n\n"+
+		"| Item | Owner | Due |\n"+
+		"| --- | --- | --- |\n"+
+		"| Ads \\| print | Sato<br>by March |  |\n"+
+		"|  | Suzuki |  |", text)
+}
+
+func TestExtractPPTX(t *testing.T) {
+	slide := func(shapes string) string {
+		return `<p:sld ` + presentationNS + `><p:cSld><p:spTree>` + shapes + `</p:spTree></p:cSld></p:sld>`
+	}
+	placeholder := func(phType, paragraphs string) string {
+		ph := `<p:ph/>`
+		if phType != "" {
+			ph = `<p:ph type="` + phType + `"/>`
+		}
+		return `<p:sp><p:nvSpPr><p:cNvPr id="2" name="x"/><p:cNvSpPr/><p:nvPr>` + ph + `</p:nvPr></p:nvSpPr>` +
+			`<p:txBody><a:bodyPr/>` + paragraphs + `</p:txBody></p:sp>`
+	}
+	para := func(props, text string) string {
+		return `<a:p>` + props + `<a:r><a:t>` + text + `</a:t></a:r></a:p>`
+	}
+	textBox := `<p:sp><p:nvSpPr><p:cNvPr id="3" name="box"/><p:cNvSpPr txBox="1"/><p:nvPr/></p:nvSpPr>` +
+		`<p:txBody>` + para("", "Draft") + `<a:p><a:pPr><a:buChar char="•"/></a:pPr><a:r><a:t>one</a:t></a:r><a:br/><a:r><a:t>line two</a:t></a:r></a:p></p:txBody></p:sp>`
+	table := `<p:graphicFrame><a:graphic><a:graphicData><a:tbl>` +
+		`<a:tr><a:tc><a:txBody>` + para("", "Quarter") + `</a:txBody></a:tc><a:tc><a:txBody>` + para("", "Sales") + `</a:txBody></a:tc></a:tr>` +
+		`<a:tr><a:tc><a:txBody>` + para("", "Q1") + `</a:txBody></a:tc><a:tc><a:txBody>` + para("", "120") + `</a:txBody></a:tc></a:tr>` +
+		`</a:tbl></a:graphicData></a:graphic></p:graphicFrame>`
+
+	data := buildPackage(
+		"_rels/.rels", rels("rId1", "officeDocument", "ppt/presentation.xml"),
+		"ppt/presentation.xml", `<p:presentation `+presentationNS+`><p:sldIdLst>`+
+			`<p:sldId id="257" r:id="rId8"/><p:sldId id="256" r:id="rId7"/></p:sldIdLst></p:presentation>`,
+		"ppt/_rels/presentation.xml.rels", rels("rId7", "slide", "slides/slide1.xml", "rId8", "slide", "slides/slide2.xml", "rId1", "slideMaster", "slideMasters/slideMaster1.xml"),
+		// The second slide file is shown first.
+		"ppt/slides/slide2.xml", slide(placeholder("ctrTitle", para("", "Plan for 2025"))+placeholder("subTitle", para("", "Board meeting"))),
+		"ppt/slides/slide1.xml", slide(placeholder("", para("", "Budget")+para(`<a:pPr lvl="1"/>`, "Cut ads")+para(`<a:pPr><a:buNone/></a:pPr>`, "Plain"))+
+			`<p:grpSp>`+textBox+`</p:grpSp>`+table+placeholder("title", para("", "Budget"))+placeholder("sldNum", para("", "2"))),
+		"ppt/slides/_rels/slide1.xml.rels", rels("rId2", "notesSlide", "../notesSlides/notesSlide1.xml"),
+		"ppt/notesSlides/notesSlide1.xml", `<p:notes `+presentationNS+`><p:cSld><p:spTree>`+
+			placeholder("sldImg", "")+placeholder("body", para("", "Mention the ad cuts")+para("", "Then questions"))+placeholder("sldNum", para("", "2"))+
+			`</p:spTree></p:cSld></p:notes>`,
+	)
+
+	text, err := ooxml.ExtractPPTX(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, "## Slide 1: Plan for 2025\n\n"+
+		"Board meeting\n\n"+
+		"## Slide 2: Budget\n\n"+
+		"- Budget\n"+
+		"  - Cut ads\n\n"+
+		"Plain\n\n"+
+		"Draft\n\n"+
+		"- one\nline two\n\n"+
+		"| Quarter | Sales |\n| --- | --- |\n| Q1 | 120 |\n\n"+
+		"### Speaker notes\n\n"+
+		"Mention the ad cuts\n\n"+
+		"Then questions", text)
+}
+
+func TestExtractXLSX(t *testing.T) {
+	workbook := `<workbook ` + sheetNS + `><workbookPr/><sheets>` +
+		`<sheet name="売上" sheetId="1" r:id="rId1"/><sheet name="Empty" sheetId="2" r:id="rId2"/>` +
+		`<sheet name="Chart" sheetId="3" r:id="rId3"/></sheets></workbook>`
+	shared := `<sst ` + sheetNS + ` count="4" uniqueCount="4">` +
+		`<si><t>支店</t></si><si><t>Date</t></si><si><t>Amount</t></si>` +
+		`<si><r><t>東京</t></r><r><rPr><b/></rPr><t>本店</t></r><rPh sb="0" eb="2"><t>トウキョウ</t></rPh></si></sst>`
+	styles := `<styleSheet ` + sheetNS + `><numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy/mm/dd;@"/></numFmts>` +
+		`<cellXfs count="4"><xf numFmtId="0"/><xf numFmtId="164"/><xf numFmtId="14"/><xf numFmtId="165"/></cellXfs></styleSheet>`
+	sheet := `<worksheet ` + sheetNS + `><sheetData>` +
+		`<row r="2"><c r="B2" t="s"><v>0</v></c><c r="C2" t="s"><v>1</v></c><c r="E2" t="s"><v>2</v></c><c r="F2" t="b"><v>1</v></c></row>` +
+		`<row r="3"><c r="B3" t="s"><v>3</v></c><c r="C3" s="1"><v>45383</v></c><c r="D3"/><c r="E3"><f>SUM(A1)</f><v>1200.5</v></c></row>` +
+		`<row r="5"><c r="B5" t="inlineStr"><is><t>大阪|梅田</t></is></c><c r="C5" s="2"><v>45383.75</v></c><c r="E5" t="e"><v>#DIV/0!</v></c></row>` +
+		`</sheetData></worksheet>`
+
+	data := buildPackage(
+		"_rels/.rels", rels("rId1", "officeDocument", "xl/workbook.xml"),
+		"xl/workbook.xml", workbook,
+		"xl/_rels/workbook.xml.rels", rels("rId1", "worksheet", "worksheets/sheet1.xml", "rId2", "worksheet", "worksheets/sheet2.xml",
+			"rId3", "chartsheet", "chartsheets/sheet1.xml", "rId4", "sharedStrings", "sharedStrings.xml", "rId5", "styles", "styles.xml"),
+		"xl/worksheets/sheet1.xml", sheet,
+		"xl/worksheets/sheet2.xml", `<worksheet `+sheetNS+`><sheetData/></worksheet>`,
+		"xl/chartsheets/sheet1.xml", `<chartsheet `+sheetNS+`/>`,
+		"xl/sharedStrings.xml", shared,
+		"xl/styles.xml", styles,
+	)
+
+	text, err := ooxml.ExtractXLSX(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, "## 売上\n\n"+
+		"| 支店 | Date | Amount | TRUE |\n"+
+		"| --- | --- | --- | --- |\n"+
+		"| 東京本店 | 2024-04-01 | 1200.5 |  |\n"+
+		"| 大阪\\|梅田 | 2024-04-01 18:00:00 | #DIV/0! |  |", text)
+}
+
+func TestExtract_Errors(t *testing.T) {
+	encrypted := append([]byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"), make([]byte, 512)...)
+	notWord := buildPackage("_rels/.rels", rels("rId1", "officeDocument", "xl/workbook.xml"), "xl/workbook.xml", "<workbook/>")
+	brokenXML := buildPackage("word/document.xml", "<w:document><w:body><w:p>")
+
+	tests := []struct {
+		name    string
+		extract func([]byte) (string, error)
+		data    []byte
+		want    error
+	}{
+		{"encrypted", ooxml.ExtractDOCX, encrypted, ooxml.ErrEncrypted},
+		{"not a zip archive", ooxml.ExtractPPTX, []byte("Hello, world"), ooxml.ErrInvalid},
+		{"missing main part", ooxml.ExtractDOCX, notWord, ooxml.ErrInvalid},
+		{"malformed XML", ooxml.ExtractDOCX, brokenXML, ooxml.ErrInvalid},
+		{"no sheets", ooxml.ExtractXLSX, notWord, ooxml.ErrInvalid},
+		{"no slides", ooxml.ExtractPPTX, buildPackage("ppt/presentation.xml", "<presentation/>"), ooxml.ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.extract(tt.data)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/pkg/pdf"
+)
+
+// buildPDF lays out objects 1, 2, ... as a PDF file with a cross-reference table; object 1 is the
+// catalog.
+func buildPDF(trailer string, objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R %s>>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, trailer, xref)
+	return buf.Bytes()
+}
+
+func streamObject(dict, data string) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func flateObject(dict, data string) string {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write([]byte(data))
+	w.Close()
+	return streamObject(dict+" /Filter /FlateDecode", buf.String())
+}
+
+const helvetica = "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"
+
+func TestExtractText_PagesLinesAndWords(t *testing.T) {
+	data := buildPDF("",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 5 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 6 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents [7 0 R 8 0 R] >>",
+		helvetica,
+		streamObject("", "BT /F1 12 Tf 72 720 Td (Hello) Tj ( World) Tj\n"+
+			"0 -14 Td (Second \\(line\\)) Tj\n0 -40 Td (New paragraph) Tj ET"),
+		streamObject("", "BT /F1 12 Tf 14 TL 72 720 Td [(Ke) 30 (rned) -600 (words)] TJ T* (caf\\351) Tj"),
+		flateObject("", "(next line) ' 300 0 Td (right) Tj ET"),
+	)
+
+	pages, err := pdf.ExtractText(data)
+
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+	assert.Equal(t, "Hello World\nSecond (line)\n\nNew paragraph", pages[0])
+	assert.Equal(t, "Kerned words\ncafé\nnext line right", pages[1])
+}
+
+func TestExtractText_CompositeFontInObjectStream(t *testing.T) {
+	toUnicode := "/CIDInit /ProcSet findresource begin 12 dict begin begincmap\n" +
+		"1 begincodespacerange <0000> <FFFF> endcodespacerange\n" +
+		"2 beginbfchar <0001> <65E5> <0002> <672C> endbfchar\n" +
+		"2 beginbfrange <0003> <0004> <8A9E> <0010> <0012> [<306E> <30C6> <30AD>] endbfrange\n" +
+		"endcmap CMapName currentdict /CMap defineresource pop end end"
+	// The page and the descendant font live in an object stream, and the content's length is an indirect
+	// object.
+	page := "<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /Font << /F0 5 0 R >> >> >>"
+	descendant := "<< /Type /Font /Subtype /CIDFontType0 /W [1 [1000 1000] 3 4 1000] >>"
+	header := fmt.Sprintf("8 0 9 %d ", len(page))
+	content := "BT /F0 10.5 Tf 1 0 0 1 50 800 Tm <00010002000300100011>Tj ET"
+	data := buildPDF("",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [8 0 R] /Count 1 >>",
+		flateObject(fmt.Sprintf("/Type /ObjStm /N 2 /First %d", len(header)), header+page+descendant),
+		fmt.Sprintf("<< /Length 7 0 R >>\nstream\n%s\nendstream", content),
+		"<< /Type /Font /Subtype /Type0 /BaseFont /IPAexMincho /Encoding /Identity-H /DescendantFonts [9 0 R] /ToUnicode 6 0 R >>",
+		flateObject("", toUnicode),
+		fmt.Sprint(len(content)),
+	)
+
+	pages, err := pdf.ExtractText(data)
+
+	require.NoError(t, err)
+	require.Len(t, pages, 1)
+	assert.Equal(t, "日本語のテ", pages[0])
+}
+
+func TestExtractText_GlyphNamesAndForms(t *testing.T) {
+	data := buildPDF("",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> /XObject << /X1 6 0 R /Im1 7 0 R >> >> >>",
+		streamObject("", "q 1 0 0 1 0 0 cm /Im1 Do Q BT /F1 12 Tf 72 720 Td <010203> Tj ET /X1 Do"),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Custom /FirstChar 1 /LastChar 3 /Widths [556 278 444]"+
+			" /Encoding << /Type /Encoding /Differences [1 /f_i /quoteright /uni00E9] >> >>",
+		streamObject("/Type /XObject /Subtype /Form /BBox [0 0 600 800] /Resources << /Font << /F2 8 0 R >> >>",
+			"BT /F2 12 Tf 1 0 0 1 72 700 Tm (In a form) Tj 1 0 0 1 300 700 Tm (apart) Tj ET"),
+		streamObject("/Type /XObject /Subtype /Image /Width 1 /Height 1 /BitsPerComponent 8 /ColorSpace /DeviceGray", "\x00"),
+		helvetica,
+	)
+
+	pages, err := pdf.ExtractText(data)
+
+	require.NoError(t, err)
+	require.Len(t, pages, 1)
+	assert.Equal(t, "fi’é\nIn a form apart", pages[0])
+}
+
+func TestExtractText_PageWithoutText(t *testing.T) {
+	data := buildPDF("",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /XObject << /Im1 5 0 R >> >> >>",
+		streamObject("", "q 595 0 0 842 0 0 cm BI /W 1 /H 1 /BPC 8 /CS /G ID \x00EI Q q /Im1 Do Q"),
+		streamObject("/Type /XObject /Subtype /Image /Width 1 /Height 1 /BitsPerComponent 8 /ColorSpace /DeviceGray /Filter /DCTDecode", "\xff\xd8"),
+	)
+
+	pages, err := pdf.ExtractText(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{""}, pages)
+}
+
+func TestExtractText_Errors(t *testing.T) {
+	encrypted := buildPDF("/Encrypt 5 0 R /ID [<01> <01>] ",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		streamObject("", "\x8a\x01\x9c"),
+		"<< /Filter /Standard /V 2 /R 3 /O <00> /U <00> /P -4 >>",
+	)
+	noPages := buildPDF("", "<< /Type /Catalog /Pages 2 0 R >>", "<< /Type /Pages /Kids [] /Count 0 >>")
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"encrypted", encrypted, pdf.ErrEncrypted},
+		{"no pages", noPages, pdf.ErrInvalid},
+		{"not a PDF", []byte("Hello, world"), pdf.ErrInvalid},
+		{"empty", nil, pdf.ErrInvalid},
+		{"hex string cut off by the end of the file", []byte("%PDF- 0 0 obj<<<"), pdf.ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := pdf.ExtractText(tt.data)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func FuzzExtractText(f *testing.F) {
+	f.Add(buildPDF("",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		streamObject("", "BT /F1 12 Tf (Hello) Tj ET"),
+	))
+	f.Add([]byte("%PDF- 0 0 obj<<<"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		pages, err := pdf.ExtractText(data)
+		if err != nil {
+			assert.Nil(t, pages)
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package slides
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/pkg/slides"
+)
+
+func samplePresentation() slides.Presentation {
+	return slides.Presentation{
+		Title:    "Q3 <Report> & \"Plan\"",
+		Language: "ja",
+		Created:  time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+		Slides: []slides.Slide{
+			{Cover: true, Title: "Q3 <Report> & \"Plan\"", Subtitle: "営業部\n2026年10月"},
+			{Title: "アジェンダ", Bullets: []string{"概要", "予算", "次のアクション"}},
+			{Title: "予算", Bullets: []string{"1. 広告費を削減", "# 見出しではない", "- 箇条書きではない"}, Notes: "300万円の内訳を説明する\n--> 質疑は最後に"},
+			{Title: "次のアクション"},
+		},
+	}
+}
+
+func TestRenderMarp(t *testing.T) {
+	markdown := slides.RenderMarp(samplePresentation())
+
+	assert.True(t, strings.HasPrefix(markdown, "---\nmarp: true\n"))
+	assert.Contains(t, markdown, "lang: \"ja\"\n")
+	assert.Contains(t, markdown, `title: "Q3 <Report> & \"Plan\""`)
+	// The front matter plus a rule between each of the four slides.
+	assert.Equal(t, 2+3, strings.Count(markdown, "\n---\n")+1)
+
+	assert.Contains(t, markdown, "<!-- _class: lead -->\n<!-- _paginate: false -->\n\n# Q3 <Report> & \"Plan\"\n\n営業部 2026年10月\n")
+	assert.Contains(t, markdown, "## アジェンダ\n\n- 概要\n- 予算\n- 次のアクション\n")
+	// Leading markers are escaped so each bullet stays one plain list item.
+	assert.Contains(t, markdown, "- 1\\. 広告費を削減\n- \\# 見出しではない\n- \\- 箇条書きではない\n")
+	assert.Contains(t, markdown, "<!--\n300万円の内訳を説明する\n-- > 質疑は最後に\n-->\n")
+	assert.True(t, strings.HasSuffix(markdown, "## 次のアクション\n"))
+}
+
+func readZip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	files := make(map[string]string)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[file.Name] = string(content)
+	}
+	return files
+}
+
+// texts returns the text runs of a DrawingML part, in order.
+func texts(t *testing.T, part string) []string {
+	t.Helper()
+	var result []string
+	decoder := xml.NewDecoder(strings.NewReader(part))
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return result
+		}
+		require.NoError(t, err)
+		switch token := token.(type) {
+		case xml.StartElement:
+			inText = token.Name.Local == "t"
+		case xml.CharData:
+			if inText {
+				result = append(result, string(token))
+			}
+		case xml.EndElement:
+			inText = false
+		}
+	}
+}
+
+func TestWritePPTX_PackageIsConsistent(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, slides.WritePPTX(&buf, samplePresentation()))
+	files := readZip(t, buf.Bytes())
+
+	// Every part is well-formed XML.
+	for name, content := range files {
+		decoder := xml.NewDecoder(strings.NewReader(content))
+		for {
+			_, err := decoder.Token()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err, name)
+		}
+	}
+
+	// Every part has a content type, and every declared part exists.
+	var types struct {
+		Overrides []struct {
+			PartName string `xml:"PartName,attr"`
+		} `xml:"Override"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(files["[Content_Types].xml"]), &types))
+	declared := make(map[string]bool)
+	for _, override := range types.Overrides {
+		name := strings.TrimPrefix(override.PartName, "/")
+		assert.Contains(t, files, name)
+		declared[name] = true
+	}
+	for name := range files {
+		if name != "[Content_Types].xml" && !strings.HasSuffix(name, ".rels") {
+			assert.True(t, declared[name], "%s has no content type", name)
+		}
+	}
+
+	// Every relationship points at an existing part.
+	for name, content := range files {
+		if !strings.HasSuffix(name, ".rels") {
+			continue
+		}
+		var rels struct {
+			Relationships []struct {
+				Target string `xml:"Target,attr"`
+			} `xml:"Relationship"`
+		}
+		require.NoError(t, xml.Unmarshal([]byte(content), &rels))
+		// ppt/slides/_rels/slide1.xml.rels describes ppt/slides/slide1.xml.
+		base := path.Dir(path.Dir(name))
+		for _, rel := range rels.Relationships {
+			assert.Contains(t, files, path.Join(base, rel.Target), "target of %s", name)
+		}
+	}
+
+	assert.Equal(t, 4, strings.Count(files["ppt/presentation.xml"], "<p:sldId "))
+	assert.Contains(t, files, "ppt/notesSlides/notesSlide3.xml")
+	assert.NotContains(t, files, "ppt/notesSlides/notesSlide1.xml")
+	assert.Contains(t, files["docProps/core.xml"], "<dc:title>Q3 &lt;Report&gt; &amp; &#34;Plan&#34;</dc:title>")
+	assert.Contains(t, files["docProps/core.xml"], "2026-10-01T09:00:00Z")
+}
+
+func TestWritePPTX_SlideText(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, slides.WritePPTX(&buf, samplePresentation()))
+	files := readZip(t, buf.Bytes())
+
+	assert.Equal(t, []string{"Q3 <Report> & \"Plan\"", "営業部", "2026年10月"}, texts(t, files["ppt/slides/slide1.xml"]))
+	assert.Contains(t, files["ppt/slides/_rels/slide1.xml.rels"], "slideLayout1.xml")
+	assert.Equal(t, []string{"予算", "1. 広告費を削減", "# 見出しではない", "- 箇条書きではない"}, texts(t, files["ppt/slides/slide3.xml"]))
+	assert.Contains(t, files["ppt/slides/_rels/slide3.xml.rels"], "slideLayout2.xml")
+	assert.Equal(t, []string{"300万円の内訳を説明する", "--> 質疑は最後に"}, texts(t, files["ppt/notesSlides/notesSlide3.xml"]))
+	assert.Equal(t, []string{"次のアクション"}, texts(t, files["ppt/slides/slide4.xml"]))
+	assert.Contains(t, files["ppt/slides/slide3.xml"], `lang="ja"`)
+}
@@ -0,0 +1,98 @@
+package textenc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github/k-tsurumaki/quilldeck/internal/pkg/textenc"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+const minutes = "議事録\r\n\r\n本日の会議では、来期の予算について話し合いました。\r\nｶﾀｶﾅ表記の品名（例：ｻﾝﾌﾟﾙ）も含みます。\r\n"
+
+func encode(t *testing.T, enc encoding.Encoding, text string) []byte {
+	t.Helper()
+	data, err := enc.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDecode(t *testing.T) {
+	want := "議事録\n\n本日の会議では、来期の予算について話し合いました。\nｶﾀｶﾅ表記の品名（例：ｻﾝﾌﾟﾙ）も含みます。\n"
+
+	tests := []struct {
+		name     string
+		data     []byte
+		encoding string
+	}{
+		{"UTF-8", []byte(minutes), textenc.UTF8},
+		{"UTF-8 with BOM", append([]byte("\xef\xbb\xbf"), minutes...), textenc.UTF8},
+		{"UTF-16LE with BOM", encode(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), minutes), textenc.UTF16LE},
+		{"UTF-16BE with BOM", encode(t, unicode.UTF16(unicode.BigEndian, unicode.UseBOM), minutes), textenc.UTF16BE},
+		{"UTF-16LE without BOM", encode(t, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), minutes), textenc.UTF16LE},
+		{"UTF-16BE without BOM", encode(t, unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), minutes), textenc.UTF16BE},
+		{"Shift_JIS", encode(t, japanese.ShiftJIS, minutes), textenc.ShiftJIS},
+		{"EUC-JP", encode(t, japanese.EUCJP, minutes), textenc.EUCJP},
+		{"ISO-2022-JP", encode(t, japanese.ISO2022JP, "議事録\r\n本日の会議では、予算について話し合いました。\r\n"), textenc.ISO2022JP},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, name := textenc.Decode(tt.data, "")
+			assert.Equal(t, tt.encoding, name)
+			if tt.encoding == textenc.ISO2022JP {
+				assert.Equal(t, "議事録\n本日の会議では、予算について話し合いました。\n", text)
+			} else {
+				assert.Equal(t, want, text)
+			}
+		})
+	}
+}
+
+func TestDecode_ShortText(t *testing.T) {
+	for _, enc := range []struct {
+		name     string
+		encoding encoding.Encoding
+	}{{textenc.ShiftJIS, japanese.ShiftJIS}, {textenc.EUCJP, japanese.EUCJP}} {
+		text, name := textenc.Decode(encode(t, enc.encoding, "こんにちは"), "")
+		assert.Equal(t, enc.name, name)
+		assert.Equal(t, "こんにちは", text)
+	}
+}
+
+func TestDecode_Latin1(t *testing.T) {
+	text, name := textenc.Decode(encode(t, charmap.Windows1252, "Café menu: crème brûlée, 5 €"), "")
+	assert.Equal(t, textenc.Windows1252, name)
+	assert.Equal(t, "Café menu: crème brûlée, 5 €", text)
+}
+
+func TestDecode_Declared(t *testing.T) {
+	sjis := encode(t, japanese.ShiftJIS, "お知らせ")
+
+	text, name := textenc.Decode(sjis, "Shift_JIS")
+	assert.Equal(t, textenc.ShiftJIS, name)
+	assert.Equal(t, "お知らせ", text)
+
+	text, name = textenc.Decode(sjis, "x-sjis")
+	assert.Equal(t, textenc.ShiftJIS, name, "labels are resolved as browsers resolve them")
+	assert.Equal(t, "お知らせ", text)
+
+	text, name = textenc.Decode([]byte("お知らせ"), "Shift_JIS")
+	assert.Equal(t, textenc.UTF8, name, "valid UTF-8 overrides a declared charset")
+	assert.Equal(t, "お知らせ", text)
+
+	_, name = textenc.Decode(sjis, "utf-8")
+	assert.Equal(t, textenc.ShiftJIS, name, "invalid UTF-8 declared as UTF-8 is detected")
+
+	_, name = textenc.Decode(sjis, "no-such-charset")
+	assert.Equal(t, textenc.ShiftJIS, name)
+}
+
+func TestNormalize(t *testing.T) {
+	// "が" as "か" and a combining voiced sound mark, and "é" as "e" and a combining acute accent.
+	assert.Equal(t, "が\ncafé\n\nend", textenc.Normalize("\uFEFFか\u3099\r\ncafe\u0301\r\rend"))
+}
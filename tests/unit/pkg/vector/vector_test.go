@@ -0,0 +1,62 @@
+package vector
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/pkg/vector"
+)
+
+func TestEncodeDecode_RoundTrips(t *testing.T) {
+	v := []float32{1, -0.5, 0.25, float32(math.Pi)}
+
+	buf := vector.Encode(v)
+	assert.Len(t, buf, 16)
+
+	decoded, err := vector.Decode(buf)
+	require.NoError(t, err)
+	assert.Equal(t, v, decoded)
+}
+
+func TestDecode_RejectsTruncatedInput(t *testing.T) {
+	_, err := vector.Decode([]byte{1, 2, 3})
+	assert.Error(t, err)
+}
+
+func TestNormalize_MakesDotCosineSimilarity(t *testing.T) {
+	a := vector.Normalize([]float32{3, 4})
+	b := vector.Normalize([]float32{6, 8})
+
+	assert.InDelta(t, 0.6, a[0], 1e-6)
+	assert.InDelta(t, 0.8, a[1], 1e-6)
+	assert.InDelta(t, 1, vector.Dot(a, b), 1e-6)
+	assert.InDelta(t, 0, vector.Dot(vector.Normalize([]float32{1, 0}), vector.Normalize([]float32{0, 2})), 1e-6)
+	assert.Equal(t, []float32{0, 0}, vector.Normalize([]float32{0, 0}))
+}
+
+func TestTopK_KeepsBestFirst(t *testing.T) {
+	top := vector.NewTopK[string](3)
+	for _, item := range []struct {
+		value string
+		score float32
+	}{{"a", 0.1}, {"b", 0.9}, {"c", 0.5}, {"d", 0.7}, {"e", -0.2}} {
+		top.Push(item.value, item.score)
+	}
+
+	results := top.Results()
+	require.Len(t, results, 3)
+	assert.Equal(t, []string{"b", "d", "c"}, []string{results[0].Value, results[1].Value, results[2].Value})
+	assert.Equal(t, float32(0.9), results[0].Score)
+}
+
+func TestTopK_FewerThanK(t *testing.T) {
+	top := vector.NewTopK[int](5)
+	top.Push(1, 0.2)
+	top.Push(2, 0.4)
+
+	results := top.Results()
+	require.Len(t, results, 2)
+	assert.Equal(t, 2, results[0].Value)
+}
@@ -0,0 +1,245 @@
+package webpage
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/pkg/textenc"
+	"github/k-tsurumaki/quilldeck/internal/pkg/webpage"
+	"golang.org/x/text/encoding/japanese"
+)
+
+const intranetPage = `<!DOCTYPE html>
+<html lang="ja">
+<head>
+	<meta charset="utf-8">
+	<title>  経費精算の手順 | 社内ポータル </title>
+	<link rel="stylesheet" href="/style.css">
+	<link rel="Canonical" href="/wiki/expenses?lang=ja">
+	<base href="https://portal.example.com/docs/">
+	<script>var tracking = "not content";</script>
+</head>
+<body>
+	<header class="site-header"><a href="/">社内ポータル</a><form><input name="q"><button>検索</button></form></header>
+	<nav><ul><li><a href="/">Home</a></li><li><a href="/wiki">Wiki</a></li><li><a href="/hr">HR</a></li></ul></nav>
+	<div id="wrapper">
+		<div class="breadcrumbs"><a href="/">Home</a> &gt; <a href="/wiki">Wiki</a></div>
+		<div class="post-body">
+			<h1>経費精算の手順</h1>
+			<p>経費の精算は、支出した月の翌月10日までに申請してください。領収書の原本は、経理部に郵送するか、スキャンしてアップロードします。</p>
+			<h2>Steps</h2>
+			<ol>
+				<li>Open the <a href="/expenses">expenses form</a>
+					<ul><li>Choose the <em>category</em></li><li>Attach receipts</li></ul>
+				</li>
+				<li>Submit it to your manager</li>
+			</ol>
+			<p>Approval usually takes two to three business days, after which the amount is paid with the next salary, or by transfer for amounts over 100,000 yen.</p>
+			<table>
+				<thead><tr><th>Category</th><th>Limit</th><th>Note</th></tr></thead>
+				<tbody>
+					<tr><td rowspan="2">Travel</td><td>¥50,000</td><td>Domestic | per trip</td></tr>
+					<tr><td>¥200,000</td><td>Overseas<br>with approval</td></tr>
+					<tr><td colspan="2">Meals</td><td>Receipts required</td></tr>
+				</tbody>
+			</table>
+			<pre>amount = price * (1 + tax)
+  rounded down</pre>
+			<blockquote><p>Late claims are not accepted.</p></blockquote>
+			<div class="share"><a href="#">Share</a> <a href="#">Print</a></div>
+			<ul class="links"><li><a href="/a">Related page one</a></li><li><a href="/b">Related page two</a></li></ul>
+			<p style="display: none">Hidden text</p>
+			<!-- a comment -->
+		</div>
+		<aside class="sidebar"><h3>Popular</h3><p>A sidebar paragraph that is long enough, with commas, to score, if it were kept.</p></aside>
+	</div>
+	<footer><p>Copyright, the company, all rights reserved, and so on and so forth.</p></footer>
+</body>
+</html>`
+
+func TestExtract(t *testing.T) {
+	page, err := webpage.Extract([]byte(intranetPage))
+	require.NoError(t, err)
+
+	assert.Equal(t, "経費精算の手順 | 社内ポータル", page.Title)
+	assert.Equal(t, "https://portal.example.com/wiki/expenses?lang=ja", page.CanonicalURL)
+
+	want := "# 経費精算の手順\n\n" +
+		"経費の精算は、支出した月の翌月10日までに申請してください。領収書の原本は、経理部に郵送するか、スキャンしてアップロードします。\n\n" +
+		"## Steps\n\n" +
+		"1. Open the expenses form\n" +
+		"  - Choose the category\n" +
+		"  - Attach receipts\n" +
+		"1. Submit it to your manager\n\n" +
+		"Approval usually takes two to three business days, after which the amount is paid with the next salary, or by transfer for amounts over 100,000 yen.\n\n" +
+		"| Category | Limit | Note |\n" +
+		"| --- | --- | --- |\n" +
+		"| Travel | ¥50,000 | Domestic \\| per trip |\n" +
+		"|  | ¥200,000 | Overseas<br>with approval |\n" +
+		"| Meals |  | Receipts required |\n\n" +
+		"```\namount = price * (1 + tax)\n  rounded down\n```\n\n" +
+		"> Late claims are not accepted."
+	assert.Equal(t, want, page.Content)
+}
+
+func TestExtract_MarkedContent(t *testing.T) {
+	page, err := webpage.Extract([]byte(`<html><head><meta property="og:url" content="https://example.com/news/1"></head><body>
+		<div class="menu"><p>A menu that goes on, and on, and on, with many words in it.</p></div>
+		<article>
+			<header><h1>Release notes</h1></header>
+			<p>Short.</p>
+			<div><strong>Version 2</strong> fixes<br>two bugs.</div>
+		</article>
+	</body></html>`))
+	require.NoError(t, err)
+
+	assert.Empty(t, page.Title)
+	assert.Equal(t, "https://example.com/news/1", page.CanonicalURL, "og:url stands in for a canonical link")
+	assert.Equal(t, "# Release notes\n\nShort.\n\nVersion 2 fixes\ntwo bugs.", page.Content)
+}
+
+func TestExtract_SiblingBlocks(t *testing.T) {
+	paragraph := strings.Repeat("A sentence of the minutes, with a comma. ", 4)
+	page, err := webpage.Extract([]byte(`<body><div id="page">
+		<div class="nav-links"><a href="/">Top</a></div>
+		<h2>First topic</h2>
+		<div class="section"><p>` + paragraph + `</p><p>` + paragraph + `</p></div>
+		<h2>Second topic</h2>
+		<div class="section"><p>` + paragraph + `</p></div>
+		<h2>Unrelated</h2>
+		<div><a href="/x">A link</a></div>
+	</div></body>`))
+	require.NoError(t, err)
+
+	p := strings.TrimSpace(paragraph)
+	assert.Equal(t, "## First topic\n\n"+p+"\n\n"+p+"\n\n## Second topic\n\n"+p, page.Content)
+}
+
+func TestExtract_ShortPage(t *testing.T) {
+	page, err := webpage.Extract([]byte("Just some text <b>without</b>\n markup"))
+	require.NoError(t, err)
+	assert.Equal(t, "Just some text without markup", page.Content)
+	assert.Empty(t, page.CanonicalURL)
+
+	page, err = webpage.Extract([]byte(`<html><head><title>Empty</title></head><body><nav>Menu</nav></body></html>`))
+	require.NoError(t, err)
+	assert.Equal(t, "Empty", page.Title)
+	assert.Empty(t, page.Content)
+}
+
+func TestExtract_MHTML(t *testing.T) {
+	t.Run("saved by Chrome", func(t *testing.T) {
+		archive := "From: <Saved by Blink>\r\n" +
+			"Snapshot-Content-Location: https://portal.example.com/wiki/holidays\r\n" +
+			"Subject: Holidays\r\n" +
+			"MIME-Version: 1.0\r\n" +
+			"Content-Type: multipart/related;\r\n\ttype=\"text/html\";\r\n\tboundary=\"----MultipartBoundary--abc----\"\r\n" +
+			"\r\n" +
+			"------MultipartBoundary--abc----\r\n" +
+			"Content-Type: text/html\r\n" +
+			"Content-ID: <frame-1@mhtml.blink>\r\n" +
+			"Content-Transfer-Encoding: quoted-printable\r\n" +
+			"Content-Location: https://portal.example.com/wiki/holidays\r\n" +
+			"\r\n" +
+			"<html><head><title>Holidays</title></head><body><main><h1 class=3D\"x\">Holidays</h1>=\r\n" +
+			"<p>The office is closed from December 29 to January 3.</p></main></body></html>\r\n" +
+			"------MultipartBoundary--abc----\r\n" +
+			"Content-Type: text/css\r\n" +
+			"Content-Location: https://portal.example.com/style.css\r\n" +
+			"\r\n" +
+			"body { color: black; }\r\n" +
+			"------MultipartBoundary--abc------\r\n"
+
+		page, err := webpage.Extract([]byte(archive))
+		require.NoError(t, err)
+		assert.Equal(t, "Holidays", page.Title)
+		assert.Equal(t, "https://portal.example.com/wiki/holidays", page.CanonicalURL)
+		assert.Equal(t, "# Holidays\n\nThe office is closed from December 29 to January 3.", page.Content)
+	})
+
+	t.Run("root part named by start", func(t *testing.T) {
+		html := `<html><head><link rel="canonical" href="page.html"></head><body><p>` +
+			strings.Repeat("Root page text. ", 3) + `</p></body></html>`
+		archive := "MIME-Version: 1.0\r\n" +
+			"Content-Type: multipart/related; boundary=\"b\"; start=\"<root>\"\r\n" +
+			"\r\n" +
+			"--b\r\n" +
+			"Content-Type: text/html\r\n" +
+			"Content-ID: <frame>\r\n" +
+			"\r\n" +
+			"<p>An embedded frame</p>\r\n" +
+			"--b\r\n" +
+			"Content-Type: text/html; charset=utf-8\r\n" +
+			"Content-ID: <root>\r\n" +
+			"Content-Location: http://intranet/site/index.html\r\n" +
+			"Content-Transfer-Encoding: base64\r\n" +
+			"\r\n" +
+			wrap(base64.StdEncoding.EncodeToString([]byte(html)), 76) +
+			"--b--\r\n"
+
+		page, err := webpage.Extract([]byte(archive))
+		require.NoError(t, err)
+		assert.Equal(t, "http://intranet/site/page.html", page.CanonicalURL)
+		assert.Equal(t, "Root page text. Root page text. Root page text.", page.Content)
+	})
+
+	t.Run("broken archives", func(t *testing.T) {
+		for name, archive := range map[string]string{
+			"no boundary":  "MIME-Version: 1.0\r\nContent-Type: multipart/related\r\n\r\nbody",
+			"no HTML part": "Content-Type: multipart/related; boundary=b\r\n\r\n--b\r\nContent-Type: text/css\r\n\r\nbody {}\r\n--b--\r\n",
+		} {
+			_, err := webpage.Extract([]byte(archive))
+			assert.ErrorIs(t, err, webpage.ErrInvalid, name)
+		}
+	})
+}
+
+func TestExtract_Encodings(t *testing.T) {
+	body := `<body><main><h1>お知らせ</h1><p>来週の月曜日は、システムの保守のため、午前中は利用できません。</p></main></body></html>`
+	want := "# お知らせ\n\n来週の月曜日は、システムの保守のため、午前中は利用できません。"
+	sjis := func(s string) string {
+		b, err := japanese.ShiftJIS.NewEncoder().String(s)
+		require.NoError(t, err)
+		return b
+	}
+	eucjp := func(s string) string {
+		b, err := japanese.EUCJP.NewEncoder().String(s)
+		require.NoError(t, err)
+		return b
+	}
+
+	tests := []struct {
+		name     string
+		page     string
+		encoding string
+	}{
+		{"UTF-8", `<html><head><title>お知らせ</title></head>` + body, textenc.UTF8},
+		{"meta charset", sjis(`<html><head><meta charset="Shift_JIS"><title>お知らせ</title></head>` + body), textenc.ShiftJIS},
+		{"meta http-equiv", eucjp(`<html><head><META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=EUC-JP"><title>お知らせ</title></head>` + body), textenc.EUCJP},
+		{"undeclared", sjis(`<html><head><title>お知らせ</title></head>` + body), textenc.ShiftJIS},
+		{"charset of an archive's part", "Content-Type: text/html; charset=\"shift_jis\"\r\n\r\n" + sjis(`<html><head><title>お知らせ</title></head>`+body), textenc.ShiftJIS},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := webpage.Extract([]byte(tt.page))
+			require.NoError(t, err)
+			assert.Equal(t, tt.encoding, page.Encoding)
+			assert.Equal(t, "お知らせ", page.Title)
+			assert.Equal(t, want, page.Content)
+		})
+	}
+}
+
+// wrap breaks s into CRLF-terminated lines of at most width characters.
+func wrap(s string, width int) string {
+	var b strings.Builder
+	for len(s) > width {
+		b.WriteString(s[:width] + "\r\n")
+		s = s[width:]
+	}
+	b.WriteString(s + "\r\n")
+	return b.String()
+}
@@ -0,0 +1,161 @@
+package ziparchive
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github/k-tsurumaki/quilldeck/internal/pkg/ziparchive"
+)
+
+type entry struct {
+	header  zip.FileHeader
+	content string
+	// raw writes content as the file's compressed data, with the sizes the header declares.
+	raw bool
+}
+
+func file(name, content string) entry {
+	return entry{header: zip.FileHeader{Name: name, Method: zip.Deflate}, content: content}
+}
+
+func archive(t *testing.T, entries ...entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, e := range entries {
+		create := w.CreateHeader
+		if e.raw {
+			create = w.CreateRaw
+		}
+		f, err := create(&e.header)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(e.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestRead(t *testing.T) {
+	link := file("notes/latest.md", "2024-04.md")
+	link.header.SetMode(fs.ModeSymlink | 0o777)
+	// "議事録.txt" as Windows zips it in Japanese: Shift_JIS, without the UTF-8 flag.
+	sjisName := file("\x8bc\x8e\x96\x98^.txt", "本文")
+	sjisName.header.NonUTF8 = true
+
+	files, err := ziparchive.Read(archive(t,
+		file("notes/", ""),
+		file("notes/2024-04.md", "# April"),
+		file(`notes\2024-05.md`, "# May"),
+		file("./notes/../notes/2024-06.md", "# June"),
+		file("../../etc/passwd", "root"),
+		file("/etc/hosts", "localhost"),
+		file("C:/Windows/win.ini", "[fonts]"),
+		link,
+		sjisName,
+		file("notes/.DS_Store", "junk"),
+		file("__MACOSX/notes/._2024-04.md", "junk"),
+	))
+	require.NoError(t, err)
+
+	assert.Equal(t, []ziparchive.File{
+		{Name: "notes/2024-04.md", Data: []byte("# April")},
+		{Name: "notes/2024-05.md", Data: []byte("# May")},
+		{Name: "./notes/../notes/2024-06.md", Err: ziparchive.ErrUnsafePath},
+		{Name: "../../etc/passwd", Err: ziparchive.ErrUnsafePath},
+		{Name: "/etc/hosts", Err: ziparchive.ErrUnsafePath},
+		{Name: "C:/Windows/win.ini", Err: ziparchive.ErrUnsafePath},
+		{Name: "notes/latest.md", Err: ziparchive.ErrUnsafePath},
+		{Name: "議事録.txt", Data: []byte("本文")},
+	}, files)
+}
+
+func TestRead_Limits(t *testing.T) {
+	t.Run("too many files", func(t *testing.T) {
+		entries := make([]entry, ziparchive.MaxFiles+1)
+		for i := range entries {
+			entries[i] = file(fmt.Sprintf("%d.txt", i), "x")
+		}
+		_, err := ziparchive.Read(archive(t, entries...))
+		assert.ErrorIs(t, err, ziparchive.ErrTooManyFiles)
+
+		_, err = ziparchive.Read(archive(t, entries[:ziparchive.MaxFiles]...))
+		assert.NoError(t, err)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		large := entry{header: zip.FileHeader{Name: "b.txt", Method: zip.Deflate, UncompressedSize64: ziparchive.MaxTotalSize}, raw: true}
+		_, err := ziparchive.Read(archive(t, file("a.txt", "x"), large))
+		assert.ErrorIs(t, err, ziparchive.ErrTooLarge)
+	})
+
+	t.Run("file too large", func(t *testing.T) {
+		large := entry{header: zip.FileHeader{Name: "b.txt", Method: zip.Deflate, UncompressedSize64: ziparchive.MaxFileSize + 1}, raw: true}
+		files, err := ziparchive.Read(archive(t, file("a.txt", "x"), large))
+		require.NoError(t, err)
+		assert.Equal(t, []ziparchive.File{
+			{Name: "a.txt", Data: []byte("x")},
+			{Name: "b.txt", Err: ziparchive.ErrFileTooLarge},
+		}, files)
+	})
+
+	t.Run("sizes that lie", func(t *testing.T) {
+		var compressed bytes.Buffer
+		fw, err := flate.NewWriter(&compressed, flate.BestCompression)
+		require.NoError(t, err)
+		_, err = fw.Write(make([]byte, 1<<20))
+		require.NoError(t, err)
+		require.NoError(t, fw.Close())
+
+		bomb := entry{header: zip.FileHeader{Name: "bomb.txt", Method: zip.Deflate, UncompressedSize64: 10}, content: compressed.String(), raw: true}
+		_, err = ziparchive.Read(archive(t, bomb))
+		assert.ErrorIs(t, err, ziparchive.ErrInvalid)
+	})
+
+	t.Run("not an archive", func(t *testing.T) {
+		_, err := ziparchive.Read([]byte("# notes"))
+		assert.ErrorIs(t, err, ziparchive.ErrInvalid)
+	})
+}
+
+func TestWalk(t *testing.T) {
+	data := archive(t, file("a.md", "# A"), file("../b.md", "# B"), file("c.md", "# C"))
+
+	var names []string
+	err := ziparchive.Walk(bytes.NewReader(data), int64(len(data)), func(f ziparchive.File) error {
+		names = append(names, f.Name)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.md", "../b.md", "c.md"}, names)
+
+	t.Run("stops at an error of fn", func(t *testing.T) {
+		stop := errors.New("stop")
+		var names []string
+		err := ziparchive.Walk(bytes.NewReader(data), int64(len(data)), func(f ziparchive.File) error {
+			names = append(names, f.Name)
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, []string{"a.md"}, names)
+	})
+
+	t.Run("rejects archives past the declared limits before reading", func(t *testing.T) {
+		large := entry{header: zip.FileHeader{Name: "b.txt", Method: zip.Deflate, UncompressedSize64: ziparchive.MaxTotalSize}, raw: true}
+		data := archive(t, file("a.txt", "x"), large)
+		called := false
+		err := ziparchive.Walk(bytes.NewReader(data), int64(len(data)), func(ziparchive.File) error {
+			called = true
+			return nil
+		})
+		assert.ErrorIs(t, err, ziparchive.ErrTooLarge)
+		assert.False(t, called)
+	})
+}
@@ -2,6 +2,11 @@ import React, { useState, useEffect } from 'react';
 import { AuthForm } from './components/AuthForm';
 import { FileUpload } from './components/FileUpload';
 import { SummaryGenerator } from './components/SummaryGenerator';
+import { SearchPanel } from './components/SearchPanel';
+import { DocumentQA } from './components/DocumentQA';
+import { SlideGenerator } from './components/SlideGenerator';
+import { DiagramGenerator } from './components/DiagramGenerator';
+import { ArtifactList } from './components/ArtifactList';
 import { api } from './api/client';
 
 interface UploadedDocument {
@@ -42,6 +47,7 @@ function App() {
   };
 
   const handleLogout = () => {
+    api.logout().catch((error) => console.error('Logout failed:', error));
     setUserId('');
     setUploadedDocuments([]);
   };
@@ -80,6 +86,9 @@ function App() {
       {/* Main Content */}
       <main className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-8">
         <div className="space-y-8">
+          {/* Search Section */}
+          <SearchPanel />
+
           {/* Upload Section */}
           <FileUpload onUploadSuccess={handleUploadSuccess} />
 
@@ -94,11 +103,16 @@ function App() {
               </h2>
               <div className="space-y-6">
                 {uploadedDocuments.map((doc) => (
-                  <SummaryGenerator
-                    key={doc.id}
-                    documentId={doc.id}
-                    fileName={doc.fileName}
-                  />
+                  <React.Fragment key={doc.id}>
+                    <SummaryGenerator
+                      documentId={doc.id}
+                      fileName={doc.fileName}
+                    />
+                    <DocumentQA documentId={doc.id} />
+                    <SlideGenerator documentId={doc.id} />
+                    <DiagramGenerator documentId={doc.id} />
+                    <ArtifactList documentId={doc.id} />
+                  </React.Fragment>
                 ))}
               </div>
             </div>
@@ -14,11 +14,149 @@ export interface Document {
   uploaded_at: string;
 }
 
-export interface Summary {
+export type SummaryLength = 'short' | 'medium' | 'long';
+export type SummaryStyle = 'business' | 'bullets' | 'executive' | 'casual';
+
+export interface SummaryOptions {
+  length?: SummaryLength;
+  target_chars?: number;
+  target_words?: number;
+  style?: SummaryStyle;
+  language?: string;
+}
+
+export type SummaryStatus = 'completed' | 'partial' | 'failed';
+
+export interface Summary extends SummaryOptions {
+  id: string;
+  content: string;
+  status: SummaryStatus;
+}
+
+export interface SummaryStreamHandlers {
+  onDelta: (text: string) => void;
+  signal?: AbortSignal;
+}
+
+export type JobStatus = 'queued' | 'running' | 'succeeded' | 'failed';
+
+export interface Job {
+  id: string;
+  type: string;
+  status: JobStatus;
+  document_id: string;
+  error?: string;
+  summary?: Summary;
+}
+
+export interface SearchResult {
+  type: 'document' | 'summary';
+  document_id: string;
+  summary_id?: string;
+  title: string;
+  // HTML-escaped text with matches wrapped in <mark>
+  snippet: string;
+  created_at: string;
+}
+
+export interface SemanticSearchResult {
+  document_id: string;
+  title: string;
+  chunk_index: number;
+  heading?: string;
+  content: string;
+  start: number;
+  end: number;
+  score: number;
+}
+
+export interface Citation {
+  passage: number;
+  heading?: string;
+  // character offsets into the document content
+  start: number;
+  end: number;
+  // page of a PDF document the passage is on
+  page?: number;
+  text: string;
+}
+
+export interface Answer {
+  document_id: string;
+  question: string;
+  answer: string;
+  citations: Citation[];
+}
+
+export interface Conversation {
   id: string;
+  document_id: string;
+  title: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface ChatMessage {
+  id: string;
+  role: 'user' | 'assistant';
   content: string;
+  citations: Citation[];
+  created_at: string;
+}
+
+export interface Slide {
+  kind: 'title' | 'agenda' | 'points' | 'actions';
+  title: string;
+  subtitle?: string;
+  bullets: string[];
+  notes?: string;
+}
+
+export interface Deck {
+  id: string;
+  document_id: string;
+  title: string;
+  slides: Slide[];
+  slide_count: number;
+  language: string;
+  created_at: string;
 }
 
+export interface Diagram {
+  id: string;
+  document_id: string;
+  kind: 'flowchart' | 'sequence' | 'mindmap';
+  syntax: 'mermaid' | 'plantuml';
+  title: string;
+  description: string;
+  source: string;
+  language: string;
+  created_at: string;
+}
+
+export interface Artifact {
+  id: string;
+  document_id: string;
+  kind: 'deck' | 'diagram' | 'image';
+  name: string;
+  mime_type: string;
+  params: Record<string, unknown>;
+  size: number;
+  checksum: string;
+  created_at: string;
+}
+
+let accessToken = '';
+let refreshToken = '';
+
+export const setAccessToken = (token: string, refresh = '') => {
+  accessToken = token;
+  refreshToken = refresh;
+};
+
+const authHeaders = (): Record<string, string> =>
+  accessToken ? { Authorization: `Bearer ${accessToken}` } : {};
+
 export const api = {
   // 認証
   register: async (email: string, password: string, name: string) => {
@@ -48,13 +186,39 @@ export const api = {
       if (!response.ok) {
         throw new Error(`HTTP error! status: ${response.status}`);
       }
-      return response.json();
+      const result = await response.json();
+      if (result.access_token) {
+        setAccessToken(result.access_token, result.refresh_token);
+      }
+      return result;
     } catch (error) {
       console.error('Login API error:', error);
       throw error;
     }
   },
 
+  refresh: async () => {
+    const response = await fetch(`${API_BASE}/auth/refresh`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ refresh_token: refreshToken }),
+    });
+    const result = await response.json();
+    if (result.access_token) {
+      setAccessToken(result.access_token, result.refresh_token);
+    }
+    return result;
+  },
+
+  logout: async () => {
+    const response = await fetch(`${API_BASE}/auth/logout`, {
+      method: 'POST',
+      headers: authHeaders(),
+    });
+    setAccessToken('');
+    return response.json();
+  },
+
   // ドキュメント
   uploadDocument: async (file: File) => {
     const formData = new FormData();
@@ -62,20 +226,236 @@ export const api = {
     
     const response = await fetch(`${API_BASE}/documents/upload`, {
       method: 'POST',
+      headers: authHeaders(),
       body: formData,
     });
     return response.json();
   },
 
-  generateSummary: async (documentId: string) => {
+  generateSummary: async (documentId: string, options: SummaryOptions = {}) => {
     const response = await fetch(`${API_BASE}/documents/summary`, {
       method: 'POST',
-      headers: { 'Content-Type': 'application/json' },
-      body: JSON.stringify({ document_id: documentId }),
+      headers: { 'Content-Type': 'application/json', ...authHeaders() },
+      body: JSON.stringify({ document_id: documentId, ...options }),
     });
     return response.json();
   },
 
+  // 要約をSSEで受け取り、生成されたテキストを順次 onDelta に渡す。完了時は保存された要約を返す
+  streamSummary: async (
+    documentId: string,
+    options: SummaryOptions,
+    { onDelta, signal }: SummaryStreamHandlers,
+  ): Promise<Summary> => {
+    const response = await fetch(`${API_BASE}/documents/summary/stream`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json', ...authHeaders() },
+      body: JSON.stringify({ document_id: documentId, ...options }),
+      signal,
+    });
+    if (!response.ok || !response.body) {
+      const result = await response.json().catch(() => ({}));
+      throw new Error(result.error || `HTTP error! status: ${response.status}`);
+    }
+
+    const reader = response.body.getReader();
+    const decoder = new TextDecoder();
+    let buffer = '';
+    for (;;) {
+      const { done, value } = await reader.read();
+      if (done) break;
+      buffer += decoder.decode(value, { stream: true });
+
+      let end;
+      while ((end = buffer.indexOf('\n\n')) >= 0) {
+        const block = buffer.slice(0, end);
+        buffer = buffer.slice(end + 2);
+
+        let event = 'message';
+        let data = '';
+        for (const line of block.split('\n')) {
+          if (line.startsWith('event:')) event = line.slice(6).trim();
+          else if (line.startsWith('data:')) data += line.slice(5).trim();
+        }
+        const payload = data ? JSON.parse(data) : {};
+        if (event === 'delta') onDelta(payload.text);
+        else if (event === 'done') return payload as Summary;
+        else if (event === 'error') throw new Error(payload.error);
+      }
+    }
+    throw new Error('要約のストリームが途中で終了しました');
+  },
+
+  // ドキュメントへの質問。回答と根拠となった箇所を返す
+  ask: async (documentId: string, question: string) => {
+    const response = await fetch(`${API_BASE}/documents/${documentId}/ask`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json', ...authHeaders() },
+      body: JSON.stringify({ question }),
+    });
+    const result = await response.json();
+    if (!response.ok) {
+      throw new Error(result.error || `HTTP error! status: ${response.status}`);
+    }
+    return result as Answer;
+  },
+
+  // ドキュメントについてのチャットスレッド
+  createConversation: async (documentId: string, title = '') => {
+    const response = await fetch(`${API_BASE}/documents/${documentId}/conversations`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json', ...authHeaders() },
+      body: JSON.stringify({ title }),
+    });
+    const result = await response.json();
+    if (!response.ok) {
+      throw new Error(result.error || `HTTP error! status: ${response.status}`);
+    }
+    return result as Conversation;
+  },
+
+  postMessage: async (conversationId: string, content: string) => {
+    const response = await fetch(`${API_BASE}/conversations/${conversationId}/messages`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json', ...authHeaders() },
+      body: JSON.stringify({ content }),
+    });
+    const result = await response.json();
+    if (!response.ok) {
+      throw new Error(result.error || `HTTP error! status: ${response.status}`);
+    }
+    return result as { question: ChatMessage; answer: ChatMessage };
+  },
+
+  deleteConversation: async (conversationId: string) => {
+    const response = await fetch(`${API_BASE}/conversations/${conversationId}`, {
+      method: 'DELETE',
+      headers: authHeaders(),
+    });
+    const result = await response.json();
+    if (!response.ok) {
+      throw new Error(result.error || `HTTP error! status: ${response.status}`);
+    }
+  },
+
+  // 報告用スライドの生成とダウンロード
+  generateDeck: async (documentId: string, slideCount: number) => {
+    const response = await fetch(`${API_BASE}/documents/${documentId}/decks`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json', ...authHeaders() },
+      body: JSON.stringify({ slide_count: slideCount }),
+    });
+    const result = await response.json();
+    if (!response.ok) {
+      throw new Error(result.error || `HTTP error! status: ${response.status}`);
+    }
+    return result as Deck;
+  },
+
+  exportDeck: async (deckId: string, format: 'pptx' | 'marp') => {
+    const response = await fetch(`${API_BASE}/decks/${deckId}/export?format=${format}`, {
+      headers: authHeaders(),
+    });
+    if (!response.ok) {
+      const result = await response.json();
+      throw new Error(result.error || `HTTP error! status: ${response.status}`);
+    }
+    return response.blob();
+  },
+
+  // 図の候補の生成と別案の生成
+  generateDiagrams: async (documentId: string, syntax: 'mermaid' | 'plantuml') => {
+    const response = await fetch(`${API_BASE}/documents/${documentId}/diagrams`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json', ...authHeaders() },
+      body: JSON.stringify({ syntax }),
+    });
+    const result = await response.json();
+    if (!response.ok) {
+      throw new Error(result.error || `HTTP error! status: ${response.status}`);
+    }
+    return result as Diagram[];
+  },
+
+  // 生成物の一覧・ダウンロード・削除
+  listArtifacts: async (documentId: string) => {
+    const response = await fetch(`${API_BASE}/documents/${documentId}/artifacts`, {
+      headers: authHeaders(),
+    });
+    const result = await response.json();
+    if (!response.ok) {
+      throw new Error(result.error || `HTTP error! status: ${response.status}`);
+    }
+    return result as Artifact[];
+  },
+
+  downloadArtifact: async (artifactId: string) => {
+    const response = await fetch(`${API_BASE}/artifacts/${artifactId}/download`, {
+      headers: authHeaders(),
+    });
+    if (!response.ok) {
+      const result = await response.json();
+      throw new Error(result.error || `HTTP error! status: ${response.status}`);
+    }
+    return response.blob();
+  },
+
+  deleteArtifact: async (artifactId: string) => {
+    const response = await fetch(`${API_BASE}/artifacts/${artifactId}`, {
+      method: 'DELETE',
+      headers: authHeaders(),
+    });
+    const result = await response.json();
+    if (!response.ok) {
+      throw new Error(result.error || `HTTP error! status: ${response.status}`);
+    }
+    return result;
+  },
+
+  regenerateDiagram: async (diagramId: string) => {
+    const response = await fetch(`${API_BASE}/diagrams/${diagramId}/regenerate`, {
+      method: 'POST',
+      headers: authHeaders(),
+    });
+    const result = await response.json();
+    if (!response.ok) {
+      throw new Error(result.error || `HTTP error! status: ${response.status}`);
+    }
+    return result as Diagram[];
+  },
+
+  getJob: async (jobId: string) => {
+    const response = await fetch(`${API_BASE}/jobs/${jobId}`, {
+      headers: authHeaders(),
+    });
+    return response.json();
+  },
+
+  // 検索
+  search: async (query: string, limit = 20) => {
+    const params = new URLSearchParams({ q: query, limit: String(limit) });
+    const response = await fetch(`${API_BASE}/search?${params}`, {
+      headers: authHeaders(),
+    });
+    const result = await response.json();
+    if (!response.ok) {
+      throw new Error(result.error || `HTTP error! status: ${response.status}`);
+    }
+    return result.results as SearchResult[];
+  },
+
+  semanticSearch: async (query: string, limit = 10) => {
+    const params = new URLSearchParams({ q: query, limit: String(limit) });
+    const response = await fetch(`${API_BASE}/search/semantic?${params}`, {
+      headers: authHeaders(),
+    });
+    const result = await response.json();
+    if (!response.ok) {
+      throw new Error(result.error || `HTTP error! status: ${response.status}`);
+    }
+    return result.results as SemanticSearchResult[];
+  },
+
   // ヘルスチェック
   health: async () => {
     try {
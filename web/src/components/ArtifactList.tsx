@@ -0,0 +1,99 @@
+import React, { useState } from 'react';
+import { api, Artifact } from '../api/client';
+
+interface ArtifactListProps {
+  documentId: string;
+}
+
+const kindLabels: Record<Artifact['kind'], string> = {
+  deck: 'スライド',
+  diagram: '図',
+  image: '画像',
+};
+
+const formatSize = (size: number) => (size < 1024 ? `${size} B` : `${(size / 1024).toFixed(1)} KB`);
+
+export const ArtifactList: React.FC<ArtifactListProps> = ({ documentId }) => {
+  const [artifacts, setArtifacts] = useState<Artifact[] | null>(null);
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState('');
+
+  const handleLoad = async () => {
+    setLoading(true);
+    setError('');
+    try {
+      setArtifacts(await api.listArtifacts(documentId));
+    } catch (err) {
+      setError(err instanceof Error ? err.message : '成果物の取得に失敗しました');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleDownload = async (artifact: Artifact) => {
+    setError('');
+    try {
+      const blob = await api.downloadArtifact(artifact.id);
+      const url = URL.createObjectURL(blob);
+      const link = document.createElement('a');
+      link.href = url;
+      link.download = artifact.name;
+      link.click();
+      URL.revokeObjectURL(url);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'ダウンロードに失敗しました');
+    }
+  };
+
+  const handleDelete = async (artifact: Artifact) => {
+    setError('');
+    try {
+      await api.deleteArtifact(artifact.id);
+      setArtifacts((prev) => (prev ?? []).filter((a) => a.id !== artifact.id));
+    } catch (err) {
+      setError(err instanceof Error ? err.message : '削除に失敗しました');
+    }
+  };
+
+  return (
+    <div className="bg-white p-8 rounded-lg shadow-md">
+      <div className="flex items-center justify-between">
+        <h3 className="text-xl font-semibold text-gray-700">成果物</h3>
+        <button
+          type="button"
+          onClick={handleLoad}
+          disabled={loading}
+          className="text-indigo-600 hover:text-indigo-800 disabled:text-gray-400 text-sm font-medium"
+        >
+          {loading ? '読み込み中...' : artifacts ? '更新' : '一覧を表示'}
+        </button>
+      </div>
+
+      {artifacts && artifacts.length === 0 && <p className="mt-4 text-sm text-gray-500">まだ成果物はありません</p>}
+
+      {artifacts && artifacts.length > 0 && (
+        <ul className="mt-4 divide-y divide-gray-100">
+          {artifacts.map((artifact) => (
+            <li key={artifact.id} className="py-2 flex items-center justify-between">
+              <p className="text-gray-800">
+                <span className="text-xs text-indigo-600 mr-2">{kindLabels[artifact.kind]}</span>
+                {artifact.name}
+                <span className="text-xs text-gray-400 ml-2">{formatSize(artifact.size)}</span>
+              </p>
+              <div className="space-x-3 text-sm">
+                <button type="button" onClick={() => handleDownload(artifact)} className="text-indigo-600 hover:text-indigo-800">
+                  ダウンロード
+                </button>
+                <button type="button" onClick={() => handleDelete(artifact)} className="text-red-500 hover:text-red-700">
+                  削除
+                </button>
+              </div>
+            </li>
+          ))}
+        </ul>
+      )}
+
+      {error && <p className="mt-4 text-sm text-red-600">{error}</p>}
+    </div>
+  );
+};
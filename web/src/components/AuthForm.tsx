@@ -19,9 +19,14 @@ export const AuthForm: React.FC<AuthFormProps> = ({ onSuccess }) => {
     setError('');
 
     try {
-      const result = isLogin 
-        ? await api.login(email, password)
-        : await api.register(email, password, name);
+      if (!isLogin) {
+        const registered = await api.register(email, password, name);
+        if (registered.error) {
+          setError(registered.error);
+          return;
+        }
+      }
+      const result = await api.login(email, password);
 
       if (result.error) {
         setError(result.error);
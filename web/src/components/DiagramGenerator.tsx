@@ -0,0 +1,107 @@
+import React, { useState } from 'react';
+import { api, Diagram } from '../api/client';
+
+interface DiagramGeneratorProps {
+  documentId: string;
+}
+
+const kindLabels: Record<Diagram['kind'], string> = {
+  flowchart: 'フローチャート',
+  sequence: 'シーケンス図',
+  mindmap: 'マインドマップ',
+};
+
+export const DiagramGenerator: React.FC<DiagramGeneratorProps> = ({ documentId }) => {
+  const [syntax, setSyntax] = useState<'mermaid' | 'plantuml'>('mermaid');
+  const [diagrams, setDiagrams] = useState<Diagram[]>([]);
+  const [busy, setBusy] = useState('');
+  const [error, setError] = useState('');
+
+  const run = async (key: string, action: () => Promise<void>) => {
+    setBusy(key);
+    setError('');
+    try {
+      await action();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : '図の生成に失敗しました');
+    } finally {
+      setBusy('');
+    }
+  };
+
+  const handleGenerate = () =>
+    run('generate', async () => {
+      setDiagrams(await api.generateDiagrams(documentId, syntax));
+    });
+
+  // 別案は元の図の直後に並べる
+  const handleRegenerate = (diagram: Diagram) =>
+    run(diagram.id, async () => {
+      const alternatives = await api.regenerateDiagram(diagram.id);
+      setDiagrams((prev) => {
+        const index = prev.findIndex((d) => d.id === diagram.id);
+        return [...prev.slice(0, index + 1), ...alternatives, ...prev.slice(index + 1)];
+      });
+    });
+
+  return (
+    <div className="bg-white p-8 rounded-lg shadow-md">
+      <h3 className="text-xl font-semibold text-gray-700 mb-4">図の候補</h3>
+
+      <div className="flex items-center space-x-3">
+        <select
+          value={syntax}
+          onChange={(e) => setSyntax(e.target.value as 'mermaid' | 'plantuml')}
+          className="border border-gray-300 rounded-lg px-3 py-2 focus:outline-none focus:ring-2 focus:ring-indigo-500"
+        >
+          <option value="mermaid">Mermaid</option>
+          <option value="plantuml">PlantUML</option>
+        </select>
+        <button
+          type="button"
+          onClick={handleGenerate}
+          disabled={busy !== ''}
+          className="bg-indigo-600 hover:bg-indigo-700 disabled:bg-gray-400 text-white px-6 py-2 rounded-lg font-medium transition-colors duration-200"
+        >
+          {busy === 'generate' ? '生成中...' : '図を生成'}
+        </button>
+      </div>
+
+      {diagrams.length > 0 && (
+        <ul className="mt-6 space-y-6">
+          {diagrams.map((diagram) => (
+            <li key={diagram.id} className="border-l-4 border-indigo-200 pl-3">
+              <div className="flex items-center justify-between">
+                <p className="font-medium text-gray-800">
+                  <span className="text-xs text-indigo-600 mr-2">{kindLabels[diagram.kind]}</span>
+                  {diagram.title}
+                </p>
+                <div className="space-x-3 text-sm">
+                  <button
+                    type="button"
+                    onClick={() => navigator.clipboard.writeText(diagram.source)}
+                    className="text-gray-500 hover:text-gray-700"
+                  >
+                    コピー
+                  </button>
+                  <button
+                    type="button"
+                    onClick={() => handleRegenerate(diagram)}
+                    disabled={busy !== ''}
+                    className="text-indigo-600 hover:text-indigo-800 disabled:text-gray-400"
+                  >
+                    {busy === diagram.id ? '生成中...' : '別案'}
+                  </button>
+                </div>
+              </div>
+              {diagram.description && <p className="text-sm text-gray-500">{diagram.description}</p>}
+              <pre className="mt-2 bg-gray-50 rounded-lg p-3 text-xs text-gray-700 overflow-x-auto">{diagram.source}</pre>
+            </li>
+          ))}
+        </ul>
+      )}
+
+      {error && <p className="mt-4 text-sm text-red-600">{error}</p>}
+    </div>
+  );
+};
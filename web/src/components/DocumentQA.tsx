@@ -0,0 +1,120 @@
+import React, { useState } from 'react';
+import { api, ChatMessage } from '../api/client';
+
+interface DocumentQAProps {
+  documentId: string;
+}
+
+export const DocumentQA: React.FC<DocumentQAProps> = ({ documentId }) => {
+  const [question, setQuestion] = useState('');
+  const [conversationId, setConversationId] = useState('');
+  const [messages, setMessages] = useState<ChatMessage[]>([]);
+  const [asking, setAsking] = useState(false);
+  const [error, setError] = useState('');
+
+  const handleAsk = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!question.trim()) return;
+
+    setAsking(true);
+    setError('');
+    try {
+      // 最初の質問でスレッドを作り、以降の質問ではそれまでのやり取りを文脈として送る
+      let id = conversationId;
+      if (!id) {
+        id = (await api.createConversation(documentId)).id;
+        setConversationId(id);
+      }
+      const result = await api.postMessage(id, question);
+      setMessages((prev) => [...prev, result.question, result.answer]);
+      setQuestion('');
+    } catch (err) {
+      setError(err instanceof Error ? err.message : '回答の取得に失敗しました');
+    } finally {
+      setAsking(false);
+    }
+  };
+
+  const handleReset = async () => {
+    if (conversationId) {
+      try {
+        await api.deleteConversation(conversationId);
+      } catch {
+        // 削除に失敗しても新しいスレッドで続けられる
+      }
+    }
+    setConversationId('');
+    setMessages([]);
+    setError('');
+  };
+
+  return (
+    <div className="bg-white p-8 rounded-lg shadow-md">
+      <div className="flex items-center justify-between mb-4">
+        <h3 className="text-xl font-semibold text-gray-700">ドキュメントに質問</h3>
+        {messages.length > 0 && (
+          <button
+            type="button"
+            onClick={handleReset}
+            disabled={asking}
+            className="text-sm text-gray-500 hover:text-gray-700"
+          >
+            新しい会話
+          </button>
+        )}
+      </div>
+
+      {messages.length > 0 && (
+        <div className="mb-6 space-y-4">
+          {messages.map((message) =>
+            message.role === 'user' ? (
+              <p key={message.id} className="text-right">
+                <span className="inline-block bg-indigo-50 text-indigo-900 rounded-lg px-4 py-2 whitespace-pre-wrap">
+                  {message.content}
+                </span>
+              </p>
+            ) : (
+              <div key={message.id} className="space-y-2">
+                <p className="text-gray-800 whitespace-pre-wrap">{message.content}</p>
+                {message.citations.length > 0 && (
+                  <ul className="space-y-2">
+                    {message.citations.map((citation) => (
+                      <li key={citation.passage} className="border-l-4 border-indigo-200 pl-3 text-sm text-gray-600">
+                        <span className="font-medium text-indigo-600">[{citation.passage}]</span>
+                        {citation.page && <span className="ml-2 text-gray-500">p.{citation.page}</span>}
+                        {citation.heading && <span className="ml-2 text-gray-500">{citation.heading}</span>}
+                        <span className="ml-2 text-xs text-gray-400">
+                          {citation.start}–{citation.end}文字目
+                        </span>
+                        <p className="mt-1 whitespace-pre-wrap line-clamp-4">{citation.text}</p>
+                      </li>
+                    ))}
+                  </ul>
+                )}
+              </div>
+            ),
+          )}
+        </div>
+      )}
+
+      <form onSubmit={handleAsk} className="flex space-x-3">
+        <input
+          type="text"
+          value={question}
+          onChange={(e) => setQuestion(e.target.value)}
+          placeholder={messages.length > 0 ? '続けて質問' : '例: 締め切りはいつですか？'}
+          className="flex-1 border border-gray-300 rounded-lg px-4 py-2 focus:outline-none focus:ring-2 focus:ring-indigo-500"
+        />
+        <button
+          type="submit"
+          disabled={asking || !question.trim()}
+          className="bg-indigo-600 hover:bg-indigo-700 disabled:bg-gray-400 text-white px-6 py-2 rounded-lg font-medium transition-colors duration-200"
+        >
+          {asking ? '回答中...' : '質問'}
+        </button>
+      </form>
+
+      {error && <p className="mt-4 text-sm text-red-600">{error}</p>}
+    </div>
+  );
+};
@@ -15,12 +15,12 @@ export const FileUpload: React.FC<FileUploadProps> = ({ onUploadSuccess }) => {
     const selectedFile = e.target.files?.[0];
     if (selectedFile) {
       const fileExtension = selectedFile.name.toLowerCase();
-      if (fileExtension.endsWith('.txt') || fileExtension.endsWith('.md')) {
+      if (['.txt', '.md', '.pdf', '.docx', '.pptx', '.xlsx', '.html', '.htm', '.mhtml', '.mht'].some(ext => fileExtension.endsWith(ext))) {
         setFile(selectedFile);
         setError('');
         setUploadProgress(0);
       } else {
-        setError('TXTまたはMDファイルのみアップロード可能です');
+        setError('TXT、MD、PDF、Word、PowerPoint、Excel、HTML・MHTMLファイルのみアップロード可能です');
         setFile(null);
       }
     }
@@ -87,7 +87,7 @@ export const FileUpload: React.FC<FileUploadProps> = ({ onUploadSuccess }) => {
         <input
           id="file-input"
           type="file"
-          accept=".txt,.md"
+          accept=".txt,.md,.pdf,.docx,.pptx,.xlsx,.html,.htm,.mhtml,.mht"
           onChange={handleFileChange}
           className="hidden"
         />
@@ -96,7 +96,7 @@ export const FileUpload: React.FC<FileUploadProps> = ({ onUploadSuccess }) => {
             <path strokeLinecap="round" strokeLinejoin="round" strokeWidth="1" d="M7 16a4 4 0 01-.88-7.903A5 5 0 1115.9 6L16 6a3 3 0 013 3v10a2 2 0 01-2 2H7a2 2 0 01-2-2V16m2-2l4-4m0 0l4 4m-4-4v10"></path>
           </svg>
           <p className="text-gray-600 text-lg">ファイルをドラッグ＆ドロップするか、<span className="text-indigo-600 font-medium">クリックして選択</span></p>
-          <p className="text-gray-500 text-sm mt-2">対応形式: .txt, .md (最大10MB)</p>
+          <p className="text-gray-500 text-sm mt-2">対応形式: .txt, .md, .pdf, .docx, .pptx, .xlsx, .html, .mhtml (最大10MB)</p>
         </label>
       </div>
 
@@ -0,0 +1,106 @@
+import React, { useState } from 'react';
+import { api, SearchResult, SemanticSearchResult } from '../api/client';
+
+export const SearchPanel: React.FC = () => {
+  const [query, setQuery] = useState('');
+  const [semantic, setSemantic] = useState(false);
+  const [results, setResults] = useState<SearchResult[] | null>(null);
+  const [chunks, setChunks] = useState<SemanticSearchResult[] | null>(null);
+  const [searching, setSearching] = useState(false);
+  const [error, setError] = useState('');
+
+  const handleSearch = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!query.trim()) return;
+
+    setSearching(true);
+    setError('');
+    setResults(null);
+    setChunks(null);
+    try {
+      if (semantic) {
+        setChunks(await api.semanticSearch(query));
+      } else {
+        setResults(await api.search(query));
+      }
+    } catch (err) {
+      setError(err instanceof Error ? err.message : '検索に失敗しました');
+    } finally {
+      setSearching(false);
+    }
+  };
+
+  return (
+    <div className="bg-white rounded-xl shadow-md p-6">
+      <form onSubmit={handleSearch} className="flex space-x-3">
+        <input
+          type="search"
+          value={query}
+          onChange={(e) => setQuery(e.target.value)}
+          placeholder="ドキュメントと要約を検索"
+          className="flex-1 border border-gray-300 rounded-lg px-4 py-2 focus:outline-none focus:ring-2 focus:ring-indigo-500"
+        />
+        <button
+          type="submit"
+          disabled={searching || !query.trim()}
+          className="bg-indigo-600 hover:bg-indigo-700 disabled:bg-gray-400 text-white px-6 py-2 rounded-lg font-medium transition-colors duration-200"
+        >
+          {searching ? '検索中...' : '検索'}
+        </button>
+      </form>
+      <label className="mt-3 flex items-center space-x-2 text-sm text-gray-600">
+        <input type="checkbox" checked={semantic} onChange={(e) => setSemantic(e.target.checked)} />
+        <span>意味で検索（言い換えにも一致）</span>
+      </label>
+
+      {error && <p className="mt-4 text-sm text-red-600">{error}</p>}
+
+      {results && (
+        <div className="mt-6">
+          {results.length === 0 ? (
+            <p className="text-sm text-gray-500">一致する結果はありません</p>
+          ) : (
+            <ul className="divide-y divide-gray-200">
+              {results.map((result) => (
+                <li key={result.summary_id ?? result.document_id} className="py-4">
+                  <div className="flex items-center space-x-2">
+                    <span className="px-2 py-0.5 bg-gray-100 text-gray-600 text-xs font-medium rounded">
+                      {result.type === 'summary' ? '要約' : 'ドキュメント'}
+                    </span>
+                    <span className="font-medium text-gray-800">{result.title}</span>
+                  </div>
+                  {/* The server escapes the snippet and only adds <mark> tags. */}
+                  <p
+                    className="mt-1 text-sm text-gray-600 [&_mark]:bg-yellow-200"
+                    dangerouslySetInnerHTML={{ __html: result.snippet }}
+                  />
+                </li>
+              ))}
+            </ul>
+          )}
+        </div>
+      )}
+
+      {chunks && (
+        <div className="mt-6">
+          {chunks.length === 0 ? (
+            <p className="text-sm text-gray-500">一致する結果はありません</p>
+          ) : (
+            <ul className="divide-y divide-gray-200">
+              {chunks.map((chunk) => (
+                <li key={`${chunk.document_id}-${chunk.chunk_index}`} className="py-4">
+                  <div className="flex items-center space-x-2">
+                    <span className="font-medium text-gray-800">{chunk.title}</span>
+                    {chunk.heading && <span className="text-sm text-gray-500">› {chunk.heading}</span>}
+                    <span className="ml-auto text-xs text-gray-400">{chunk.score.toFixed(2)}</span>
+                  </div>
+                  <p className="mt-1 text-sm text-gray-600 line-clamp-3">{chunk.content}</p>
+                </li>
+              ))}
+            </ul>
+          )}
+        </div>
+      )}
+    </div>
+  );
+};
@@ -0,0 +1,109 @@
+import React, { useState } from 'react';
+import { api, Deck } from '../api/client';
+
+interface SlideGeneratorProps {
+  documentId: string;
+}
+
+export const SlideGenerator: React.FC<SlideGeneratorProps> = ({ documentId }) => {
+  const [slideCount, setSlideCount] = useState(8);
+  const [deck, setDeck] = useState<Deck | null>(null);
+  const [generating, setGenerating] = useState(false);
+  const [error, setError] = useState('');
+
+  const handleGenerate = async () => {
+    setGenerating(true);
+    setError('');
+    try {
+      setDeck(await api.generateDeck(documentId, slideCount));
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'スライドの生成に失敗しました');
+    } finally {
+      setGenerating(false);
+    }
+  };
+
+  const handleDownload = async (format: 'pptx' | 'marp') => {
+    if (!deck) return;
+    setError('');
+    try {
+      const blob = await api.exportDeck(deck.id, format);
+      const url = URL.createObjectURL(blob);
+      const link = document.createElement('a');
+      link.href = url;
+      link.download = `${deck.title}.${format === 'pptx' ? 'pptx' : 'md'}`;
+      link.click();
+      URL.revokeObjectURL(url);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'ダウンロードに失敗しました');
+    }
+  };
+
+  return (
+    <div className="bg-white p-8 rounded-lg shadow-md">
+      <h3 className="text-xl font-semibold text-gray-700 mb-4">報告用スライド</h3>
+
+      <div className="flex items-center space-x-3">
+        <label className="text-sm text-gray-600">
+          枚数
+          <input
+            type="number"
+            min={4}
+            max={20}
+            value={slideCount}
+            onChange={(e) => setSlideCount(Number(e.target.value))}
+            className="ml-2 w-20 border border-gray-300 rounded-lg px-3 py-2 focus:outline-none focus:ring-2 focus:ring-indigo-500"
+          />
+        </label>
+        <button
+          type="button"
+          onClick={handleGenerate}
+          disabled={generating}
+          className="bg-indigo-600 hover:bg-indigo-700 disabled:bg-gray-400 text-white px-6 py-2 rounded-lg font-medium transition-colors duration-200"
+        >
+          {generating ? '生成中...' : 'スライドを生成'}
+        </button>
+      </div>
+
+      {deck && (
+        <div className="mt-6 space-y-4">
+          <ol className="space-y-3">
+            {deck.slides.map((slide, i) => (
+              <li key={i} className="border-l-4 border-indigo-200 pl-3">
+                <p className="font-medium text-gray-800">
+                  {i + 1}. {slide.title}
+                </p>
+                {slide.subtitle && <p className="text-sm text-gray-500">{slide.subtitle}</p>}
+                {slide.bullets.length > 0 && (
+                  <ul className="mt-1 list-disc list-inside text-sm text-gray-600">
+                    {slide.bullets.map((bullet, j) => (
+                      <li key={j}>{bullet}</li>
+                    ))}
+                  </ul>
+                )}
+              </li>
+            ))}
+          </ol>
+          <div className="flex space-x-3">
+            <button
+              type="button"
+              onClick={() => handleDownload('pptx')}
+              className="border border-indigo-600 text-indigo-600 hover:bg-indigo-50 px-4 py-2 rounded-lg text-sm font-medium"
+            >
+              PowerPoint をダウンロード
+            </button>
+            <button
+              type="button"
+              onClick={() => handleDownload('marp')}
+              className="border border-indigo-600 text-indigo-600 hover:bg-indigo-50 px-4 py-2 rounded-lg text-sm font-medium"
+            >
+              Marp Markdown をダウンロード
+            </button>
+          </div>
+        </div>
+      )}
+
+      {error && <p className="mt-4 text-sm text-red-600">{error}</p>}
+    </div>
+  );
+};
@@ -1,5 +1,9 @@
 import React, { useState } from 'react';
-import { api } from '../api/client';
+import { api, Job, SummaryLength, SummaryStyle } from '../api/client';
+
+const POLL_INTERVAL_MS = 1500;
+
+const sleep = (ms: number) => new Promise((resolve) => setTimeout(resolve, ms));
 
 interface SummaryGeneratorProps {
   documentId: string;
@@ -13,6 +17,10 @@ export const SummaryGenerator: React.FC<SummaryGeneratorProps> = ({
   const [summary, setSummary] = useState<string>('');
   const [generating, setGenerating] = useState(false);
   const [error, setError] = useState('');
+  const [length, setLength] = useState<SummaryLength>('medium');
+  const [style, setStyle] = useState<SummaryStyle>('business');
+  const [language, setLanguage] = useState('ja');
+  const [streaming, setStreaming] = useState(true);
 
   const handleGenerate = async () => {
     setGenerating(true);
@@ -20,15 +28,35 @@ export const SummaryGenerator: React.FC<SummaryGeneratorProps> = ({
     setSummary('');
 
     try {
-      const result = await api.generateSummary(documentId);
-      
+      if (streaming) {
+        // 生成されたテキストを届いた順に表示する
+        const result = await api.streamSummary(documentId, { length, style, language }, {
+          onDelta: (text) => setSummary((current) => current + text),
+        });
+        setSummary(result.content);
+        return;
+      }
+
+      const result = await api.generateSummary(documentId, { length, style, language });
       if (result.error) {
         setError(result.error);
+        return;
+      }
+
+      // 要約はバックグラウンドジョブで生成されるため、完了までポーリングする
+      let job: Job = await api.getJob(result.job_id);
+      while (job.status === 'queued' || job.status === 'running') {
+        await sleep(POLL_INTERVAL_MS);
+        job = await api.getJob(result.job_id);
+      }
+
+      if (job.status === 'succeeded' && job.summary) {
+        setSummary(job.summary.content);
       } else {
-        setSummary(result.content);
+        setError(job.error || '要約生成に失敗しました');
       }
     } catch (err) {
-      setError('要約生成に失敗しました');
+      setError(err instanceof Error && err.message ? err.message : '要約生成に失敗しました');
     } finally {
       setGenerating(false);
     }
@@ -48,6 +76,57 @@ export const SummaryGenerator: React.FC<SummaryGeneratorProps> = ({
         <p className="font-medium text-gray-800">{fileName}</p>
       </div>
 
+      <div className="grid grid-cols-1 md:grid-cols-3 gap-4 mb-6">
+        <label className="text-sm text-gray-600">
+          長さ
+          <select
+            value={length}
+            onChange={(e) => setLength(e.target.value as SummaryLength)}
+            className="mt-1 block w-full border border-gray-300 rounded-lg p-2"
+          >
+            <option value="short">短め</option>
+            <option value="medium">標準</option>
+            <option value="long">詳しめ</option>
+          </select>
+        </label>
+        <label className="text-sm text-gray-600">
+          スタイル
+          <select
+            value={style}
+            onChange={(e) => setStyle(e.target.value as SummaryStyle)}
+            className="mt-1 block w-full border border-gray-300 rounded-lg p-2"
+          >
+            <option value="business">ビジネスレポート</option>
+            <option value="bullets">箇条書き</option>
+            <option value="executive">エグゼクティブ向け</option>
+            <option value="casual">カジュアル</option>
+          </select>
+        </label>
+        <label className="text-sm text-gray-600">
+          出力言語
+          <select
+            value={language}
+            onChange={(e) => setLanguage(e.target.value)}
+            className="mt-1 block w-full border border-gray-300 rounded-lg p-2"
+          >
+            <option value="ja">日本語</option>
+            <option value="en">English</option>
+            <option value="zh">中文</option>
+            <option value="ko">한국어</option>
+          </select>
+        </label>
+      </div>
+
+      <label className="flex items-center text-sm text-gray-600 mb-6">
+        <input
+          type="checkbox"
+          checked={streaming}
+          onChange={(e) => setStreaming(e.target.checked)}
+          className="mr-2"
+        />
+        生成中のテキストを逐次表示する（オフの場合はバックグラウンドジョブで生成）
+      </label>
+
       <div className="grid grid-cols-1 md:grid-cols-2 gap-8">
         <div>
           <button